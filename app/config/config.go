@@ -0,0 +1,71 @@
+package config
+
+import (
+	"embed"
+
+	libconfig "github.com/kujilabo/redstart/lib/config"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+type AppConfig struct {
+	Name        string `yaml:"name" validate:"required"`
+	HTTPPort    int    `yaml:"httpPort" validate:"required"`
+	GRPCPort    int    `yaml:"grpcPort" validate:"required"`
+	MetricsPort int    `yaml:"metricsPort" validate:"required"`
+	// EnforcerCacheTTLSec is how long the policies of an organization are cached. 0 means until they are changed
+	EnforcerCacheTTLSec int `yaml:"enforcerCacheTtlSec" validate:"gte=0"`
+	// PolicyWatcherIntervalSec is how often the policy changes made by the other instances are checked
+	PolicyWatcherIntervalSec int `yaml:"policyWatcherIntervalSec" validate:"gte=1"`
+	// GrantExpiryIntervalSec is how often the memberships and the policies whose validity has ended are removed
+	GrantExpiryIntervalSec int `yaml:"grantExpiryIntervalSec" validate:"gte=1"`
+}
+
+type AuthorizeConfig struct {
+	// ServiceTokens are the bearer tokens of the services calling /v1/authorize and the gRPC API, keyed by the service names.
+	// No token is configured by default
+	ServiceTokens map[string]string `yaml:"serviceTokens" validate:"dive,min=16"`
+	// CacheTTLMSec is how long the decisions are cached. 0 disables the cache
+	CacheTTLMSec int `yaml:"cacheTtlMsec" validate:"gte=0"`
+}
+
+type AdminConfig struct {
+	// Tokens are the bearer tokens of the system administrators calling /v1/admin, keyed by their names.
+	// No token is configured by default
+	Tokens map[string]string `yaml:"tokens" validate:"dive,min=16"`
+}
+
+type ShutdownConfig struct {
+	TimeSec1 int `yaml:"timeSec1" validate:"gte=1"`
+	TimeSec2 int `yaml:"timeSec2" validate:"gte=1"`
+}
+
+type Config struct {
+	App       *AppConfig               `yaml:"app" validate:"required"`
+	DB        *libconfig.DBConfig      `yaml:"db" validate:"required"`
+	Auth      *libconfig.AuthConfig    `yaml:"auth" validate:"required"`
+	Authorize *AuthorizeConfig         `yaml:"authorize" validate:"required"`
+	Admin     *AdminConfig             `yaml:"admin" validate:"required"`
+	Trace     *libconfig.TraceConfig   `yaml:"trace" validate:"required"`
+	CORS      *libconfig.CORSConfig    `yaml:"cors" validate:"required"`
+	Shutdown  *ShutdownConfig          `yaml:"shutdown" validate:"required"`
+	Log       *libconfig.LogConfig     `yaml:"log" validate:"required"`
+	Swagger   *libconfig.SwaggerConfig `yaml:"swagger" validate:"required"`
+}
+
+//go:embed local.yml
+var config embed.FS
+
+// LoadConfig reads the embedded local.yml, overridden by the config file at filePath if not empty and by REDSTART_ environment variables
+func LoadConfig(filePath string) (*Config, error) {
+	confContent, err := config.ReadFile("local.yml")
+	if err != nil {
+		return nil, liberrors.Errorf("config.ReadFile. err: %w", err)
+	}
+
+	var conf Config
+	if err := libconfig.Load(&conf, confContent, filePath); err != nil {
+		return nil, liberrors.Errorf("libconfig.Load. err: %w", err)
+	}
+
+	return &conf, nil
+}
@@ -1,5 +1,165 @@
 package main
 
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/kujilabo/redstart/app/config"
+	libconfig "github.com/kujilabo/redstart/lib/config"
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
+	"github.com/kujilabo/redstart/sqls"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+const (
+	AppContextKey libdomain.ContextKey = "redstart"
+)
+
+var errUsage = errors.New("usage")
+
+const usage = `usage: redstart <command> [<args>]
+
+commands:
+  serve                                       run the server
+  migrate up|down|status                      manage the database schema
+  org create                                  create an organization and its first owner
+  user create                                 create an app user in an organization
+  policy add|list|explain|export|import       manage the policies of an organization
+
+run "redstart <command> -h" for the flags of each command
+`
+
 func main() {
+	ctx := context.Background()
+	if err := run(ctx, os.Args[1:]); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "serve":
+		return serveCommand(ctx, args[1:])
+	case "migrate":
+		return migrateCommand(ctx, args[1:])
+	case "org":
+		return orgCommand(ctx, args[1:])
+	case "user":
+		return userCommand(ctx, args[1:])
+	case "policy":
+		return policyCommand(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to the config file. the embedded local.yml is used when omitted")
+	return fs, configPath
+}
+
+func loadConfig(configPath string) (*config.Config, error) {
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return nil, liberrors.Errorf("config.LoadConfig. err: %w", err)
+	}
+
+	if err := libconfig.InitLog(cfg.Log); err != nil {
+		return nil, liberrors.Errorf("libconfig.InitLog. err: %w", err)
+	}
+
+	return cfg, nil
+}
+
+type application struct {
+	dialect       libgateway.DialectRDBMS
+	db            *gorm.DB
+	sqlDB         *sql.DB
+	rf            service.RepositoryFactory
+	enforcerCache *gateway.EnforcerCache
+	txManager     service.TransactionManager
+	nonTxManager  service.TransactionManager
+}
+
+func newApplication(ctx context.Context, dialect libgateway.DialectRDBMS, db *gorm.DB, sqlDB *sql.DB, enforcerCacheTTL time.Duration) (*application, error) {
+	enforcerCache := gateway.NewEnforcerCache(enforcerCacheTTL)
+	rff := func(ctx context.Context, db *gorm.DB) (service.RepositoryFactory, error) {
+		return gateway.NewRepositoryFactory(ctx, dialect, dialect.Name(), db, time.UTC, enforcerCache)
+	}
+
+	rf, err := rff(ctx, db)
+	if err != nil {
+		return nil, liberrors.Errorf("gateway.NewRepositoryFactory. err: %w", err)
+	}
+
+	txManager, err := gateway.NewTransactionManager(db, rff)
+	if err != nil {
+		return nil, liberrors.Errorf("gateway.NewTransactionManager. err: %w", err)
+	}
+
+	nonTxManager, err := gateway.NewNoneTransactionManager(rf)
+	if err != nil {
+		return nil, liberrors.Errorf("gateway.NewNoneTransactionManager. err: %w", err)
+	}
+
+	return &application{
+		dialect:       dialect,
+		db:            db,
+		sqlDB:         sqlDB,
+		rf:            rf,
+		enforcerCache: enforcerCache,
+		txManager:     txManager,
+		nonTxManager:  nonTxManager,
+	}, nil
+}
+
+// initApplication opens the database, applies pending migrations and wires the repositories
+func initApplication(ctx context.Context, cfg *config.Config) (*application, error) {
+	dialect, db, sqlDB, err := libconfig.InitDB(cfg.DB, sqls.SQL)
+	if err != nil {
+		return nil, liberrors.Errorf("libconfig.InitDB. err: %w", err)
+	}
+
+	app, err := newApplication(ctx, dialect, db, sqlDB, time.Duration(cfg.App.EnforcerCacheTTLSec)*time.Second)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
+	return app, nil
+}
+
+func (a *application) Close() error {
+	return a.sqlDB.Close()
+}
+
+func requireFlags(fs *flag.FlagSet, names ...string) error {
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil || f.Value.String() == "" {
+			return liberrors.Errorf("flag -%s is required. err: %w", name, libdomain.ErrInvalidArgument)
+		}
+	}
 
+	return nil
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+
+	libconfig "github.com/kujilabo/redstart/lib/config"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/sqls"
+)
+
+func migrateCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	fs, configPath := newFlagSet("migrate " + args[0])
+	steps := fs.Int("steps", 1, "number of migrations to revert (down only)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	_, db, sqlDB, err := libconfig.OpenDB(cfg.DB)
+	if err != nil {
+		return liberrors.Errorf("libconfig.OpenDB. err: %w", err)
+	}
+	defer sqlDB.Close()
+
+	m, err := libconfig.NewMigrate(cfg.DB, db, sqls.SQL)
+	if err != nil {
+		return liberrors.Errorf("libconfig.NewMigrate. err: %w", err)
+	}
+
+	switch args[0] {
+	case "up":
+		if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return liberrors.Errorf("m.Up. err: %w", err)
+		}
+	case "down":
+		if *steps < 1 {
+			return errUsage
+		}
+		if err := m.Steps(-*steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return liberrors.Errorf("m.Steps. err: %w", err)
+		}
+	case "status":
+	default:
+		return errUsage
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("version: none")
+		return nil
+	} else if err != nil {
+		return liberrors.Errorf("m.Version. err: %w", err)
+	}
+
+	fmt.Printf("version: %d, dirty: %v\n", version, dirty)
+	return nil
+}
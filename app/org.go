@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func orgCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return errUsage
+	}
+
+	fs, configPath := newFlagSet("org create")
+	name := fs.String("name", "", "organization name")
+	ownerLoginID := fs.String("owner-login-id", "", "login ID of the first owner")
+	ownerUsername := fs.String("owner-username", "", "username of the first owner")
+	ownerPassword := fs.String("owner-password", "", "password of the first owner")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "name", "owner-login-id", "owner-username", "owner-password"); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	app, err := initApplication(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	firstOwnerAddParam, err := service.NewAppUserAddParameter(*ownerLoginID, *ownerUsername, *ownerPassword, "", "", "", "")
+	if err != nil {
+		return liberrors.Errorf("service.NewAppUserAddParameter. err: %w", err)
+	}
+
+	orgAddParam, err := service.NewOrganizationAddParameter(*name, firstOwnerAddParam)
+	if err != nil {
+		return liberrors.Errorf("service.NewOrganizationAddParameter. err: %w", err)
+	}
+
+	var organizationID *domain.OrganizationID
+	if err := app.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		organizationIDTmp, err := sysAd.AddOrganization(ctx, orgAddParam)
+		if err != nil {
+			return liberrors.Errorf("sysAd.AddOrganization. err: %w", err)
+		}
+
+		organizationID = organizationIDTmp
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("organization ID: %d\n", organizationID.Int())
+	return nil
+}
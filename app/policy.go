@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func policyCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "add":
+		return policyAddCommand(ctx, args[1:])
+	case "list":
+		return policyListCommand(ctx, args[1:])
+	case "explain":
+		return policyExplainCommand(ctx, args[1:])
+	case "export":
+		return policyExportCommand(ctx, args[1:])
+	case "import":
+		return policyImportCommand(ctx, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+// policySubjectTypes are the types of the subjects of the policies. "group" and "role" are the role of the user group
+var policySubjectTypes = []string{"user", "group", "role"}
+
+func policyAddCommand(ctx context.Context, args []string) error {
+	fs, configPath := newFlagSet("policy add")
+	organizationName := fs.String("org", "", "organization name")
+	subjectType := fs.String("subject-type", "user", strings.Join(policySubjectTypes, ", "))
+	subject := fs.String("subject", "", `subject. e.g. "user:2", "domain:1_role:3"`)
+	action := fs.String("action", "", `action. e.g. "Set"`)
+	object := fs.String("object", "", `object. e.g. "domain:1_role:*"`)
+	effect := fs.String("effect", service.RBACAllowEffect.Effect(), "allow or deny")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "org", "subject", "action", "object"); err != nil {
+		return err
+	}
+
+	rbacEffect, err := domain.ParseRBACEffect(*effect)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(policySubjectTypes, *subjectType) {
+		return liberrors.Errorf("unsupported subject type. subject type: %s, err: %w", *subjectType, libdomain.ErrInvalidArgument)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	app, err := initApplication(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	return app.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		org, err := sysAd.FindOrganizationByName(ctx, *organizationName)
+		if err != nil {
+			return liberrors.Errorf("sysAd.FindOrganizationByName. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		rbacAction := domain.NewRBACAction(*action)
+		rbacObject := domain.NewRBACObject(*object)
+		if *subjectType == "user" {
+			if err := authorizationManager.AddPolicyToUserBySystemAdmin(ctx, sysAd, org.OrganizationID(), domain.NewRBACUser(*subject), rbacAction, rbacObject, rbacEffect); err != nil {
+				return liberrors.Errorf("authorizationManager.AddPolicyToUserBySystemAdmin. err: %w", err)
+			}
+			return nil
+		}
+
+		if err := authorizationManager.AddPolicyToGroupBySystemAdmin(ctx, sysAd, org.OrganizationID(), domain.NewRBACRole(*subject), rbacAction, rbacObject, rbacEffect); err != nil {
+			return liberrors.Errorf("authorizationManager.AddPolicyToGroupBySystemAdmin. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func policyListCommand(ctx context.Context, args []string) error {
+	fs, configPath := newFlagSet("policy list")
+	organizationName := fs.String("org", "", "organization name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "org"); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	app, err := initApplication(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	sysAd, err := service.NewSystemAdmin(ctx, app.rf)
+	if err != nil {
+		return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+	}
+
+	org, err := sysAd.FindOrganizationByName(ctx, *organizationName)
+	if err != nil {
+		return liberrors.Errorf("sysAd.FindOrganizationByName. err: %w", err)
+	}
+
+	authorizationManager := app.rf.NewAuthorizationManager(ctx)
+	ruleSet, err := authorizationManager.ExportPoliciesBySystemAdmin(ctx, sysAd, org.OrganizationID())
+	if err != nil {
+		return liberrors.Errorf("authorizationManager.ExportPoliciesBySystemAdmin. err: %w", err)
+	}
+
+	// the conditions of the policies are printed too
+	for _, line := range (&domain.RBACRuleSet{Policies: ruleSet.Policies}).Lines() {
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+// policyExplainCommand prints the rules which decide whether the app user is allowed to perform the action on the object
+func policyExplainCommand(ctx context.Context, args []string) error {
+	fs, configPath := newFlagSet("policy explain")
+	organizationName := fs.String("org", "", "organization name")
+	loginID := fs.String("login-id", "", "login ID of the app user")
+	action := fs.String("action", "", `action. e.g. "Set"`)
+	object := fs.String("object", "", `object. e.g. "domain:1_role:3"`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "org", "login-id", "action", "object"); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	app, err := initApplication(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	sysAd, err := service.NewSystemAdmin(ctx, app.rf)
+	if err != nil {
+		return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+	}
+
+	sysOwner, err := sysAd.FindSystemOwnerByOrganizationName(ctx, *organizationName)
+	if err != nil {
+		return liberrors.Errorf("sysAd.FindSystemOwnerByOrganizationName. err: %w", err)
+	}
+
+	appUser, err := sysOwner.FindAppUserByLoginID(ctx, *loginID)
+	if err != nil {
+		return liberrors.Errorf("sysOwner.FindAppUserByLoginID. err: %w", err)
+	}
+
+	authorizationManager := app.rf.NewAuthorizationManager(ctx)
+	explanation, err := authorizationManager.Explain(ctx, appUser, domain.NewRBACAction(*action), domain.NewRBACObject(*object))
+	if err != nil {
+		return liberrors.Errorf("authorizationManager.Explain. err: %w", err)
+	}
+
+	if explanation.Allowed {
+		fmt.Println("allowed")
+	} else {
+		fmt.Println("denied")
+	}
+	if explanation.Policy == nil {
+		fmt.Println("no policy matched")
+	}
+	for _, line := range explanation.Lines() {
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+// policyExportCommand writes the policies of the organization to the file, or to the standard output
+func policyExportCommand(ctx context.Context, args []string) error {
+	fs, configPath := newFlagSet("policy export")
+	organizationName := fs.String("org", "", "organization name")
+	format := fs.String("format", string(domain.RBACRuleSetFormatCSV), "csv or json")
+	outPath := fs.String("out", "", "output file. the standard output if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "org"); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	app, err := initApplication(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	sysAd, err := service.NewSystemAdmin(ctx, app.rf)
+	if err != nil {
+		return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+	}
+
+	org, err := sysAd.FindOrganizationByName(ctx, *organizationName)
+	if err != nil {
+		return liberrors.Errorf("sysAd.FindOrganizationByName. err: %w", err)
+	}
+
+	authorizationManager := app.rf.NewAuthorizationManager(ctx)
+	ruleSet, err := authorizationManager.ExportPoliciesBySystemAdmin(ctx, sysAd, org.OrganizationID())
+	if err != nil {
+		return liberrors.Errorf("authorizationManager.ExportPoliciesBySystemAdmin. err: %w", err)
+	}
+
+	data, err := domain.MarshalRBACRuleSet(ruleSet, domain.RBACRuleSetFormat(*format))
+	if err != nil {
+		return liberrors.Errorf("domain.MarshalRBACRuleSet. err: %w", err)
+	}
+
+	if *outPath == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return liberrors.Errorf("os.Stdout.Write. err: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.WriteFile(*outPath, data, 0o600); err != nil {
+		return liberrors.Errorf("os.WriteFile. err: %w", err)
+	}
+
+	return nil
+}
+
+// policyImportCommand imports the policies in the file and prints the added ("+") and removed ("-") rules
+func policyImportCommand(ctx context.Context, args []string) error {
+	fs, configPath := newFlagSet("policy import")
+	organizationName := fs.String("org", "", "organization name")
+	format := fs.String("format", string(domain.RBACRuleSetFormatCSV), "csv or json")
+	filePath := fs.String("file", "", "input file")
+	mode := fs.String("mode", string(service.PolicyImportModeMerge), "merge or replace")
+	dryRun := fs.Bool("dry-run", false, "print the changes without applying them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "org", "file"); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*filePath)
+	if err != nil {
+		return liberrors.Errorf("os.ReadFile. err: %w", err)
+	}
+
+	ruleSet, err := domain.UnmarshalRBACRuleSet(data, domain.RBACRuleSetFormat(*format))
+	if err != nil {
+		return liberrors.Errorf("domain.UnmarshalRBACRuleSet. err: %w", err)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	app, err := initApplication(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	var diff *domain.RBACRuleSetDiff
+	if err := app.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		org, err := sysAd.FindOrganizationByName(ctx, *organizationName)
+		if err != nil {
+			return liberrors.Errorf("sysAd.FindOrganizationByName. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		tmpDiff, err := authorizationManager.ImportPoliciesBySystemAdmin(ctx, sysAd, org.OrganizationID(), ruleSet, service.PolicyImportMode(*mode), *dryRun)
+		if err != nil {
+			return liberrors.Errorf("authorizationManager.ImportPoliciesBySystemAdmin. err: %w", err)
+		}
+
+		diff = tmpDiff
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, line := range diff.Removed.Lines() {
+		fmt.Println("- " + line)
+	}
+	for _, line := range diff.Added.Lines() {
+		fmt.Println("+ " + line)
+	}
+	if *dryRun {
+		fmt.Println("dry run. nothing is changed")
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+
+	"github.com/kujilabo/redstart/app/config"
+	libconfig "github.com/kujilabo/redstart/lib/config"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+func newRouter(cfg *config.Config, app *application) (*gin.Engine, error) {
+	signingMethod, signingKey, verifyKey, err := libconfig.InitJWT(cfg.Auth)
+	if err != nil {
+		return nil, liberrors.Errorf("libconfig.InitJWT. err: %w", err)
+	}
+
+	accessTokenTTL := time.Duration(cfg.Auth.AccessTokenTTLMin) * time.Minute
+	refreshTokenTTL := time.Duration(cfg.Auth.RefreshTokenTTLHour) * time.Hour
+	authTokenManager, err := gateway.NewAuthTokenManager(signingMethod, signingKey, verifyKey, accessTokenTTL, refreshTokenTTL)
+	if err != nil {
+		return nil, liberrors.Errorf("gateway.NewAuthTokenManager. err: %w", err)
+	}
+
+	authenticationUsecase := usecase.NewAuthenticationUsecase(app.nonTxManager, authTokenManager)
+	appUserUsecase := usecase.NewAppUserUsecase(app.txManager, app.nonTxManager)
+	userGroupUsecase := usecase.NewUserGroupUsecase(app.txManager, app.nonTxManager)
+	policyUsecase := usecase.NewPolicyUsecase(app.txManager)
+	auditLogUsecase := usecase.NewAuditLogUsecase(app.nonTxManager)
+	systemAdminUsecase := usecase.NewSystemAdminUsecase(app.txManager, app.nonTxManager)
+	authorizeUsecase := usecase.NewAuthorizeUsecase(app.nonTxManager, time.Duration(cfg.Authorize.CacheTTLMSec)*time.Millisecond)
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(cors.New(libconfig.InitCORS(cfg.CORS)))
+	router.Use(controller.NewRequestIDMiddleware())
+
+	controller.InitAuthRouterGroup(router, controller.NewAuthHandler(authenticationUsecase))
+	controller.InitRouterGroup(router,
+		controller.NewAppUserHandler(appUserUsecase),
+		controller.NewUserGroupHandler(userGroupUsecase),
+		controller.NewPolicyHandler(policyUsecase),
+		controller.NewAuthMiddleware(authenticationUsecase),
+	)
+
+	controller.InitAuditLogRouterGroup(router,
+		controller.NewAuditLogHandler(auditLogUsecase),
+		controller.NewAuthMiddleware(authenticationUsecase),
+	)
+
+	controller.InitAuthorizeRouterGroup(router,
+		controller.NewAuthorizeHandler(authorizeUsecase),
+		controller.NewServiceTokenMiddleware(cfg.Authorize.ServiceTokens),
+	)
+
+	controller.InitAdminRouterGroup(router,
+		controller.NewSystemAdminHandler(systemAdminUsecase),
+		controller.NewSystemAdminTokenMiddleware(cfg.Admin.Tokens),
+	)
+
+	return router, nil
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kujilabo/redstart/app/config"
+	"github.com/kujilabo/redstart/user/controller"
+)
+
+const (
+	testSystemAdminToken = "SYSTEM_ADMIN_TOKEN"
+	testServiceToken     = "BILLING_SERVICE_TOKEN"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestRouter returns the router of the serve command on a new SQLite database
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	t.Setenv("REDSTART_DB_SQLITE3_FILE", filepath.Join(t.TempDir(), "redstart.db"))
+	t.Setenv("REDSTART_AUTH_SIGNING_KEY", strings.Repeat("K", 32))
+	t.Setenv("REDSTART_ADMIN_TOKENS_TEST", testSystemAdminToken)
+	t.Setenv("REDSTART_AUTHORIZE_SERVICE_TOKENS_BILLING", testServiceToken)
+
+	cfg, err := config.LoadConfig("")
+	require.NoError(t, err)
+	app, err := initApplication(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { app.Close() })
+
+	router, err := newRouter(cfg, app)
+	require.NoError(t, err)
+	return router
+}
+
+func doTestRequest(t *testing.T, router http.Handler, method, path, token string, body interface{}, resp interface{}) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		bytesBody, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(bytesBody)
+	}
+
+	req, err := http.NewRequest(method, path, reader)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if resp != nil && w.Code < http.StatusBadRequest {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+	}
+	return w.Code
+}
+
+// testAddOrganization adds the organization through the admin endpoint and returns its ID and the ID of its first owner
+func testAddOrganization(t *testing.T, router http.Handler, name string) (int, int) {
+	t.Helper()
+	organization := controller.IDResponse{}
+	status := doTestRequest(t, router, http.MethodPost, "/v1/admin/organization", testSystemAdminToken, map[string]interface{}{
+		"name":       name,
+		"firstOwner": map[string]string{"loginId": "OWNER", "username": "OWNER_NAME", "password": "PASSWORD"},
+	}, &organization)
+	require.Equal(t, http.StatusCreated, status)
+
+	auth := controller.AuthResponse{}
+	status = doTestRequest(t, router, http.MethodPost, "/v1/auth/password", "", map[string]string{
+		"organizationName": name,
+		"loginId":          "OWNER",
+		"password":         "PASSWORD",
+	}, &auth)
+	require.Equal(t, http.StatusOK, status)
+
+	me := controller.AppUserResponse{}
+	status = doTestRequest(t, router, http.MethodGet, "/v1/me", auth.AccessToken, nil, &me)
+	require.Equal(t, http.StatusOK, status)
+
+	return organization.ID, me.ID
+}
+
+func TestNewRouter_admin(t *testing.T) {
+	router := newTestRouter(t)
+
+	// when
+	status := doTestRequest(t, router, http.MethodPost, "/v1/admin/organization", "", map[string]interface{}{"name": "ORG"}, nil)
+	// then
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// when
+	status = doTestRequest(t, router, http.MethodGet, "/v1/admin/organization/1", "INVALID_TOKEN", nil, nil)
+	// then
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// when
+	organizationID, _ := testAddOrganization(t, router, "ORG")
+	organization := controller.OrganizationResponse{}
+	status = doTestRequest(t, router, http.MethodGet, fmt.Sprintf("/v1/admin/organization/%d", organizationID), testSystemAdminToken, nil, &organization)
+	// then
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ORG", organization.Name)
+}
+
+func TestNewRouter_explain(t *testing.T) {
+	router := newTestRouter(t)
+	organizationID, ownerID := testAddOrganization(t, router, "ORG")
+	path := fmt.Sprintf("/v1/admin/organization/%d/user/%d/explain?action=Set&object=domain:%d_role:*", organizationID, ownerID, organizationID)
+
+	// when
+	status := doTestRequest(t, router, http.MethodGet, path, "", nil, nil)
+	// then
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// when
+	explanation := controller.ExplanationResponse{}
+	status = doTestRequest(t, router, http.MethodGet, path, testSystemAdminToken, nil, &explanation)
+	// then
+	// - the first owner can set all the user roles through the owner group
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, explanation.Allowed)
+	require.NotNil(t, explanation.Policy)
+	assert.NotEmpty(t, explanation.SubjectChain)
+}
+
+func TestNewRouter_exportAndImportPolicies(t *testing.T) {
+	router := newTestRouter(t)
+	organizationID, _ := testAddOrganization(t, router, "ORG")
+	exportPath := fmt.Sprintf("/v1/admin/organization/%d/policy/export?format=json", organizationID)
+	importPath := fmt.Sprintf("/v1/admin/organization/%d/policy/import?format=json&dryRun=true", organizationID)
+
+	// when
+	status := doTestRequest(t, router, http.MethodGet, exportPath, "", nil, nil)
+	// then
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// when
+	ruleSet := map[string]interface{}{}
+	status = doTestRequest(t, router, http.MethodGet, exportPath, testSystemAdminToken, nil, &ruleSet)
+	// then
+	require.Equal(t, http.StatusOK, status)
+	assert.NotEmpty(t, ruleSet)
+
+	// when
+	// - the exported rules are imported again
+	diff := controller.RuleSetDiffResponse{}
+	status = doTestRequest(t, router, http.MethodPost, importPath, testSystemAdminToken, ruleSet, &diff)
+	// then
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, diff.DryRun)
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Removed)
+}
+
+func TestNewRouter_authorizeBatch(t *testing.T) {
+	router := newTestRouter(t)
+	organizationID, ownerID := testAddOrganization(t, router, "ORG")
+	roleObject := fmt.Sprintf("domain:%d_role:*", organizationID)
+
+	// when
+	// - the app user of the second request does not exist
+	resp := controller.AuthorizeBatchResponse{}
+	status := doTestRequest(t, router, http.MethodPost, "/v1/authorize/batch", testServiceToken, map[string]interface{}{
+		"requests": []map[string]interface{}{
+			{"organizationId": organizationID, "appUserId": ownerID, "action": "Set", "object": roleObject},
+			{"organizationId": organizationID, "appUserId": ownerID + 100, "action": "Set", "object": roleObject},
+		},
+	}, &resp)
+	// then
+	// - only the requests of the app user who is not found are denied
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, resp.Results, 2)
+	assert.True(t, resp.Results[0].Allowed)
+	assert.False(t, resp.Results[1].Allowed)
+
+	// when
+	status = doTestRequest(t, router, http.MethodPost, "/v1/authorize", testServiceToken, map[string]interface{}{
+		"organizationId": organizationID, "appUserId": ownerID + 100, "action": "Set", "object": roleObject,
+	}, nil)
+	// then
+	assert.Equal(t, http.StatusNotFound, status)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/kujilabo/redstart/app/config"
+	libconfig "github.com/kujilabo/redstart/lib/config"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
+	liblog "github.com/kujilabo/redstart/lib/log"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/gateway"
+)
+
+func serveCommand(ctx context.Context, args []string) error {
+	fs, configPath := newFlagSet("serve")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	logger := liblog.GetLoggerFromContext(ctx, AppContextKey)
+
+	tp, err := libconfig.InitTracerProvider(ctx, cfg.App.Name, cfg.Trace)
+	if err != nil {
+		return liberrors.Errorf("libconfig.InitTracerProvider. err: %w", err)
+	}
+	defer tp.Shutdown(context.Background()) // nolint:errcheck
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	app, err := initApplication(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	err = serve(ctx, cfg, app)
+
+	gracefulShutdownTime2 := time.Duration(cfg.Shutdown.TimeSec2) * time.Second
+	time.Sleep(gracefulShutdownTime2)
+	logger.InfoContext(ctx, "exited")
+
+	return err
+}
+
+func serve(ctx context.Context, cfg *config.Config, app *application) error {
+	logger := liblog.GetLoggerFromContext(ctx, AppContextKey)
+
+	if len(cfg.Authorize.ServiceTokens) == 0 {
+		logger.WarnContext(ctx, "no service tokens are configured. /v1/authorize and the gRPC API reject all the requests")
+	}
+
+	router, err := newRouter(cfg, app)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := controller.NewGRPCServer(otel.GetTracerProvider(), otel.GetTextMapPropagator(), cfg.Authorize.ServiceTokens, controller.NewUserGRPCServer(app.rf))
+
+	policyWatcher, err := gateway.NewPolicyWatcher(ctx, app.db, time.Duration(cfg.App.PolicyWatcherIntervalSec)*time.Second)
+	if err != nil {
+		return liberrors.Errorf("gateway.NewPolicyWatcher. err: %w", err)
+	}
+	defer policyWatcher.Close()
+	if err := policyWatcher.SetUpdateCallback(app.enforcerCache.PolicyChanged); err != nil {
+		return liberrors.Errorf("policyWatcher.SetUpdateCallback. err: %w", err)
+	}
+
+	var eg *errgroup.Group
+	eg, ctx = errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		return libgateway.AppServerProcess(ctx, router, cfg.App.HTTPPort, cfg.Shutdown.TimeSec1) // nolint:wrapcheck
+	})
+	eg.Go(func() error {
+		return libgateway.GRPCServerProcess(ctx, grpcServer, cfg.App.GRPCPort, cfg.Shutdown.TimeSec1) // nolint:wrapcheck
+	})
+	eg.Go(func() error {
+		return libgateway.MetricsServerProcess(ctx, cfg.App.MetricsPort, cfg.Shutdown.TimeSec1) // nolint:wrapcheck
+	})
+	eg.Go(func() error {
+		return policyWatcher.Run(ctx) // nolint:wrapcheck
+	})
+	eg.Go(func() error {
+		return gateway.GrantExpiryProcess(ctx, app.db, time.Duration(cfg.App.GrantExpiryIntervalSec)*time.Second) // nolint:wrapcheck
+	})
+	eg.Go(func() error {
+		return libgateway.SignalWatchProcess(ctx) // nolint:wrapcheck
+	})
+	eg.Go(func() error {
+		<-ctx.Done()
+		return ctx.Err() // nolint:wrapcheck
+	})
+
+	if err := eg.Wait(); err != nil {
+		if !errors.Is(err, libgateway.ErrSignalReceived) {
+			return err // nolint:wrapcheck
+		}
+		logger.InfoContext(ctx, fmt.Sprintf("shutting down. reason: %v", err))
+	}
+
+	return nil
+}
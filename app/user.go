@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func userCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "create" {
+		return errUsage
+	}
+
+	fs, configPath := newFlagSet("user create")
+	organizationName := fs.String("org", "", "organization name")
+	loginID := fs.String("login-id", "", "login ID")
+	username := fs.String("username", "", "username")
+	password := fs.String("password", "", "password")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := requireFlags(fs, "org", "login-id", "username"); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	app, err := initApplication(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	appUserAddParam, err := service.NewAppUserAddParameter(*loginID, *username, *password, "", "", "", "")
+	if err != nil {
+		return liberrors.Errorf("service.NewAppUserAddParameter. err: %w", err)
+	}
+
+	var appUserID *domain.AppUserID
+	if err := app.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		sysOwner, err := sysAd.FindSystemOwnerByOrganizationName(ctx, *organizationName)
+		if err != nil {
+			return liberrors.Errorf("sysAd.FindSystemOwnerByOrganizationName. err: %w", err)
+		}
+
+		appUserIDTmp, err := sysOwner.AddAppUser(ctx, appUserAddParam)
+		if err != nil {
+			return liberrors.Errorf("sysOwner.AddAppUser. err: %w", err)
+		}
+
+		appUserID = appUserIDTmp
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("app user ID: %d\n", appUserID.Int())
+	return nil
+}
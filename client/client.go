@@ -0,0 +1,155 @@
+// Package client is the client of the permission check endpoints of redstart for the other services
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+const defaultTimeout = 5 * time.Second
+
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// Error is the error response of redstart
+type Error struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("redstart responded %d. code: %s, message: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// AuthorizeRequest asks whether the app user of the organization is allowed to perform the action on the object
+type AuthorizeRequest struct {
+	OrganizationID int                    `json:"organizationId"`
+	AppUserID      int                    `json:"appUserId"`
+	Action         string                 `json:"action"`
+	Object         string                 `json:"object"`
+	Attributes     map[string]interface{} `json:"attributes,omitempty"`
+}
+
+type authorizeResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+type authorizeBatchRequest struct {
+	Requests []AuthorizeRequest `json:"requests"`
+}
+
+type authorizeBatchResponse struct {
+	Results []authorizeResponse `json:"results"`
+}
+
+type Client struct {
+	baseURL      string
+	serviceToken string
+	httpClient   *http.Client
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the default HTTP client, whose timeout is 5 seconds
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient returns the client of the redstart at baseURL. e.g. http://redstart:8080
+func NewClient(baseURL, serviceToken string, options ...Option) (*Client, error) {
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, liberrors.Errorf("invalid base URL. baseURL: %s, err: %w", baseURL, ErrInvalidArgument)
+	}
+	if serviceToken == "" {
+		return nil, liberrors.Errorf("serviceToken is required. err: %w", ErrInvalidArgument)
+	}
+
+	c := &Client{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		serviceToken: serviceToken,
+		httpClient:   &http.Client{Timeout: defaultTimeout},
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	return c, nil
+}
+
+func (c *Client) Authorize(ctx context.Context, req AuthorizeRequest) (bool, error) {
+	resp := authorizeResponse{}
+	if err := c.post(ctx, "/v1/authorize", req, &resp); err != nil {
+		return false, err
+	}
+
+	return resp.Allowed, nil
+}
+
+// AuthorizeBatch returns whether each of the requests is allowed, keeping their order. Up to 100 requests can be sent at once.
+// The requests of the app users who are not found are denied
+func (c *Client) AuthorizeBatch(ctx context.Context, reqs []AuthorizeRequest) ([]bool, error) {
+	resp := authorizeBatchResponse{}
+	if err := c.post(ctx, "/v1/authorize/batch", authorizeBatchRequest{Requests: reqs}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(reqs) {
+		return nil, liberrors.Errorf("unexpected number of results. requests: %d, results: %d", len(reqs), len(resp.Results))
+	}
+
+	results := make([]bool, len(resp.Results))
+	for i, r := range resp.Results {
+		results[i] = r.Allowed
+	}
+
+	return results, nil
+}
+
+func (c *Client) post(ctx context.Context, path string, reqBody, respBody interface{}) error {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return liberrors.Errorf("json.Marshal. err: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return liberrors.Errorf("http.NewRequestWithContext. err: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.serviceToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return liberrors.Errorf("c.httpClient.Do. err: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return liberrors.Errorf("io.ReadAll. err: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errResp := Error{StatusCode: resp.StatusCode}
+		// the body of the error which is not returned by redstart may not be JSON
+		_ = json.Unmarshal(respBytes, &errResp)
+		return &errResp
+	}
+
+	if err := json.Unmarshal(respBytes, respBody); err != nil {
+		return liberrors.Errorf("json.Unmarshal. err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package client_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kujilabo/redstart/client"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/service"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// fakeAuthorizeUsecase allows the app users of organization 1 to read. The app users of the other organizations are not found
+type fakeAuthorizeUsecase struct{}
+
+func (u *fakeAuthorizeUsecase) Authorize(ctx context.Context, req *usecase.AuthorizeRequest) (bool, error) {
+	results, err := u.AuthorizeBatch(ctx, []*usecase.AuthorizeRequest{req})
+	if err != nil {
+		return false, err
+	}
+	return results[0], nil
+}
+
+func (u *fakeAuthorizeUsecase) AuthorizeBatch(ctx context.Context, reqs []*usecase.AuthorizeRequest) ([]bool, error) {
+	results := make([]bool, len(reqs))
+	for i, req := range reqs {
+		if req.OrganizationID.Int() != 1 {
+			return nil, liberrors.Errorf("AuthorizeBatch. err: %w", service.ErrAppUserNotFound)
+		}
+		results[i] = req.Action.Action() == "read"
+	}
+	return results, nil
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	router := gin.New()
+	controller.InitAuthorizeRouterGroup(router, controller.NewAuthorizeHandler(&fakeAuthorizeUsecase{}), controller.NewServiceTokenMiddleware(map[string]string{"test": "TEST_SERVICE_TOKEN"}))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Authorize(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server := newTestServer(t)
+	c, err := client.NewClient(server.URL+"/", "TEST_SERVICE_TOKEN", client.WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+
+	allowed, err := c.Authorize(ctx, client.AuthorizeRequest{OrganizationID: 1, AppUserID: 3, Action: "read", Object: "doc:1"})
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = c.Authorize(ctx, client.AuthorizeRequest{OrganizationID: 1, AppUserID: 3, Action: "write", Object: "doc:1", Attributes: map[string]interface{}{"resource.locked": true}})
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	results, err := c.AuthorizeBatch(ctx, []client.AuthorizeRequest{
+		{OrganizationID: 1, AppUserID: 3, Action: "write", Object: "doc:1"},
+		{OrganizationID: 1, AppUserID: 3, Action: "read", Object: "doc:1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{false, true}, results)
+}
+
+func TestClient_Authorize_error(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server := newTestServer(t)
+
+	// - the app user is not found
+	c, err := client.NewClient(server.URL, "TEST_SERVICE_TOKEN", client.WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+	_, err = c.Authorize(ctx, client.AuthorizeRequest{OrganizationID: 2, AppUserID: 3, Action: "read", Object: "doc:1"})
+	var clientErr *client.Error
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, 404, clientErr.StatusCode)
+	assert.Equal(t, "not_found", clientErr.Code)
+
+	// - the service token is invalid
+	c, err = client.NewClient(server.URL, "INVALID_SERVICE_TOKEN", client.WithHTTPClient(server.Client()))
+	require.NoError(t, err)
+	_, err = c.AuthorizeBatch(ctx, []client.AuthorizeRequest{{OrganizationID: 1, AppUserID: 3, Action: "read", Object: "doc:1"}})
+	require.ErrorAs(t, err, &clientErr)
+	assert.Equal(t, 401, clientErr.StatusCode)
+	assert.Equal(t, "unauthenticated", clientErr.Code)
+
+	// - the arguments are invalid
+	_, err = client.NewClient("redstart", "TEST_SERVICE_TOKEN")
+	assert.ErrorIs(t, err, client.ErrInvalidArgument)
+	_, err = client.NewClient(server.URL, "")
+	assert.ErrorIs(t, err, client.ErrInvalidArgument)
+}
@@ -0,0 +1,334 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+// EnvPrefix is the prefix of environment variables overriding config fields.
+// e.g. REDSTART_DB_MYSQL_PORT overrides db.mysql.port
+var EnvPrefix = "REDSTART"
+
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+type FieldError struct {
+	Path string
+	Tag  string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Tag)
+}
+
+// Load decodes defaultConf and then the YAML file at overridePath (if not empty) into cfg,
+// applies EnvPrefix-prefixed environment variables and validates the result.
+// ${ENV_VAR} and ${ENV_VAR:-default} in the values of the YAML are expanded after parsing,
+// so the environment variables may contain any characters including ": " and newlines.
+func Load(cfg interface{}, defaultConf []byte, overridePath string) error {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return liberrors.Errorf("cfg must be a pointer to struct. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	if err := unmarshalYAML(defaultConf, cfg); err != nil {
+		return liberrors.Errorf("unmarshalYAML. err: %w", err)
+	}
+
+	if overridePath != "" {
+		content, err := os.ReadFile(overridePath)
+		if err != nil {
+			return liberrors.Errorf("os.ReadFile. filePath: %s, err: %w", overridePath, err)
+		}
+
+		if err := unmarshalYAML(content, cfg); err != nil {
+			return liberrors.Errorf("unmarshalYAML. filePath: %s, err: %w", overridePath, err)
+		}
+	}
+
+	if err := applyEnv(rv.Elem(), EnvPrefix, environ()); err != nil {
+		return err
+	}
+
+	if err := Validate(cfg); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate validates cfg with libdomain.Validator and reports failures by their YAML path
+func Validate(cfg interface{}) error {
+	err := libdomain.Validator.Struct(cfg)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return liberrors.Errorf("libdomain.Validator.Struct. err: %w", err)
+	}
+
+	t := reflect.TypeOf(cfg)
+	fieldErrs := make([]error, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		tag := fe.Tag()
+		if fe.Param() != "" {
+			tag += "=" + fe.Param()
+		}
+		fieldErrs = append(fieldErrs, &FieldError{Path: yamlPath(t, fe.StructNamespace()), Tag: tag})
+	}
+
+	return liberrors.Errorf("invalid config. err: %w", errors.Join(fieldErrs...))
+}
+
+// unmarshalYAML parses content, expands the environment variables in the values and decodes them into cfg
+func unmarshalYAML(content []byte, cfg interface{}) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return err
+	}
+	if node.Kind == 0 {
+		return nil
+	}
+
+	expandEnvInNode(&node)
+
+	return node.Decode(cfg)
+}
+
+// expandEnvInNode expands the environment variables in the scalar values, not in the keys of the mappings.
+// The tags of the plain scalars are resolved again so that ${PORT} can be decoded into an int
+func expandEnvInNode(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if !envVarPattern.MatchString(node.Value) {
+			return
+		}
+		node.Value = expandEnv(node.Value)
+		if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			node.Tag = ""
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			expandEnvInNode(node.Content[i])
+		}
+	default:
+		for _, child := range node.Content {
+			expandEnvInNode(child)
+		}
+	}
+}
+
+func expandEnv(value string) string {
+	return envVarPattern.ReplaceAllStringFunc(value, func(match string) string {
+		groups := envVarPattern.FindStringSubmatch(match)
+		if value, ok := os.LookupEnv(groups[1]); ok {
+			return value
+		}
+		return groups[3]
+	})
+}
+
+func environ() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+func yamlName(f reflect.StructField) string {
+	tag := f.Tag.Get("yaml")
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return strings.ToLower(f.Name)
+	}
+	return name
+}
+
+// envName converts a YAML key to its environment variable form. e.g. driverName -> DRIVER_NAME
+func envName(yamlKey string) string {
+	var b strings.Builder
+	for i, r := range yamlKey {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// yamlPath converts a validator struct namespace such as "Config.DB.MySQL.Port" to "db.mysql.port"
+func yamlPath(t reflect.Type, structNamespace string) string {
+	names := strings.Split(structNamespace, ".")
+	if len(names) > 0 {
+		names = names[1:]
+	}
+
+	path := make([]string, 0, len(names))
+	for _, name := range names {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			path = append(path, name)
+			continue
+		}
+
+		fieldName, index, _ := strings.Cut(name, "[")
+		f, ok := t.FieldByName(fieldName)
+		if !ok {
+			path = append(path, name)
+			continue
+		}
+		key := yamlName(f)
+		if index != "" {
+			key += "[" + index
+		}
+		path = append(path, key)
+		t = f.Type
+	}
+
+	return strings.Join(path, ".")
+}
+
+func hasEnvWithPrefix(env map[string]string, prefix string) bool {
+	for k := range env {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func applyEnv(v reflect.Value, prefix string, env map[string]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := prefix + "_" + envName(yamlName(f))
+		fv := v.Field(i)
+
+		switch {
+		case f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct:
+			if !hasEnvWithPrefix(env, name+"_") {
+				continue
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.New(f.Type.Elem()))
+			}
+			if err := applyEnv(fv.Elem(), name, env); err != nil {
+				return err
+			}
+		case f.Type.Kind() == reflect.Struct:
+			if err := applyEnv(fv, name, env); err != nil {
+				return err
+			}
+		case f.Type.Kind() == reflect.Map && f.Type.Key().Kind() == reflect.String:
+			if err := applyEnvToMap(fv, name, env); err != nil {
+				return err
+			}
+		default:
+			value, ok := env[name]
+			if !ok {
+				continue
+			}
+			if err := setValue(fv, value); err != nil {
+				return liberrors.Errorf("invalid environment variable. name: %s, err: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyEnvToMap sets map entries from variables such as REDSTART_LOG_LEVEL_USER_GATEWAY, whose key becomes "user_gateway"
+func applyEnvToMap(v reflect.Value, prefix string, env map[string]string) error {
+	keys := make([]string, 0)
+	for k := range env {
+		if strings.HasPrefix(k, prefix+"_") {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	for _, k := range keys {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := setValue(elem, env[k]); err != nil {
+			return liberrors.Errorf("invalid environment variable. name: %s, err: %w", k, err)
+		}
+		mapKey := strings.ToLower(strings.TrimPrefix(k, prefix+"_"))
+		v.SetMapIndex(reflect.ValueOf(mapKey).Convert(v.Type().Key()), elem)
+	}
+
+	return nil
+}
+
+func setValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		values := strings.Split(value, ",")
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, s := range values {
+			if err := setValue(slice.Index(i), strings.TrimSpace(s)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return liberrors.Errorf("unsupported type. type: %s, err: %w", v.Type(), libdomain.ErrInvalidArgument)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"crypto/ed25519"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+type AuthConfig struct {
+	// SigningMethod is one of HS256, HS384, HS512 and EdDSA
+	SigningMethod string `yaml:"signingMethod" validate:"required,oneof=HS256 HS384 HS512 EdDSA"`
+	// SigningKey is the secret for HMAC or the PEM encoded PKCS #8 Ed25519 private key for EdDSA.
+	// The secret for HMAC must be at least as long as the hash, e.g. 32 bytes for HS256
+	SigningKey          string `yaml:"signingKey" validate:"required"`
+	AccessTokenTTLMin   int    `yaml:"accessTokenTtlMin" validate:"gte=1"`
+	RefreshTokenTTLHour int    `yaml:"refreshTokenTtlHour" validate:"gte=1"`
+}
+
+// InitJWT returns the signing method and the keys to sign and verify tokens
+func InitJWT(cfg *AuthConfig) (jwt.SigningMethod, interface{}, interface{}, error) {
+	signingMethod := jwt.GetSigningMethod(cfg.SigningMethod)
+	if signingMethod == nil {
+		return nil, nil, nil, liberrors.Errorf("unsupported signing method. method: %s, err: %w", cfg.SigningMethod, libdomain.ErrInvalidArgument)
+	}
+
+	switch m := signingMethod.(type) {
+	case *jwt.SigningMethodHMAC:
+		key := []byte(cfg.SigningKey)
+		if len(key) < m.Hash.Size() {
+			return nil, nil, nil, liberrors.Errorf("signing key is too short. method: %s, min: %d, err: %w", cfg.SigningMethod, m.Hash.Size(), libdomain.ErrInvalidArgument)
+		}
+		return signingMethod, key, key, nil
+	case *jwt.SigningMethodEd25519:
+		privateKey, err := jwt.ParseEdPrivateKeyFromPEM([]byte(cfg.SigningKey))
+		if err != nil {
+			return nil, nil, nil, liberrors.Errorf("jwt.ParseEdPrivateKeyFromPEM. err: %w", err)
+		}
+		edPrivateKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, nil, nil, liberrors.Errorf("signing key is not an Ed25519 private key. err: %w", libdomain.ErrInvalidArgument)
+		}
+		return signingMethod, edPrivateKey, edPrivateKey.Public(), nil
+	default:
+		return nil, nil, nil, liberrors.Errorf("unsupported signing method. method: %s, err: %w", cfg.SigningMethod, libdomain.ErrInvalidArgument)
+	}
+}
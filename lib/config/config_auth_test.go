@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libconfig "github.com/kujilabo/redstart/lib/config"
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+)
+
+func TestInitJWT(t *testing.T) {
+	t.Parallel()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	privateKeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	t.Run("HMAC", func(t *testing.T) {
+		t.Parallel()
+		key := strings.Repeat("K", 32)
+		signingMethod, signingKey, verifyKey, err := libconfig.InitJWT(&libconfig.AuthConfig{SigningMethod: "HS256", SigningKey: key})
+		require.NoError(t, err)
+		assert.Equal(t, jwt.SigningMethodHS256, signingMethod)
+		assert.Equal(t, []byte(key), signingKey)
+		assert.Equal(t, []byte(key), verifyKey)
+	})
+
+	t.Run("HMAC with short key", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			signingMethod string
+			keyLength     int
+		}{
+			{signingMethod: "HS256", keyLength: 31},
+			{signingMethod: "HS384", keyLength: 47},
+			{signingMethod: "HS512", keyLength: 63},
+		}
+		for _, tt := range tests {
+			_, _, _, err := libconfig.InitJWT(&libconfig.AuthConfig{SigningMethod: tt.signingMethod, SigningKey: strings.Repeat("K", tt.keyLength)})
+			assert.ErrorIs(t, err, libdomain.ErrInvalidArgument, tt.signingMethod)
+		}
+	})
+
+	t.Run("EdDSA", func(t *testing.T) {
+		t.Parallel()
+		signingMethod, signingKey, verifyKey, err := libconfig.InitJWT(&libconfig.AuthConfig{SigningMethod: "EdDSA", SigningKey: privateKeyPEM})
+		require.NoError(t, err)
+		assert.Equal(t, jwt.SigningMethodEdDSA, signingMethod)
+		assert.Equal(t, privateKey, signingKey)
+		assert.Equal(t, publicKey, verifyKey)
+	})
+
+	t.Run("EdDSA with invalid key", func(t *testing.T) {
+		t.Parallel()
+		_, _, _, err := libconfig.InitJWT(&libconfig.AuthConfig{SigningMethod: "EdDSA", SigningKey: "KEY"})
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported method", func(t *testing.T) {
+		t.Parallel()
+		_, _, _, err := libconfig.InitJWT(&libconfig.AuthConfig{SigningMethod: "RS256", SigningKey: "KEY"})
+		assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+	})
+}
+
+func TestAuthConfig_shouldRequireSigningKey(t *testing.T) {
+	t.Parallel()
+	for _, signingMethod := range []string{"HS256", "EdDSA"} {
+		err := libconfig.Validate(&libconfig.AuthConfig{SigningMethod: signingMethod, AccessTokenTTLMin: 5, RefreshTokenTTLHour: 720})
+		require.Error(t, err, signingMethod)
+		assert.Equal(t, []string{"signingKey: required"}, fieldPaths(t, err), signingMethod)
+	}
+}
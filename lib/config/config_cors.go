@@ -6,18 +6,21 @@ type CORSConfig struct {
 	AllowOrigins []string `yaml:"allowOrigins"`
 }
 
+// InitCORS returns the CORS config. ETag is exposed to the browsers for the optimistic locking with If-Match
 func InitCORS(cfg *CORSConfig) cors.Config {
 	if len(cfg.AllowOrigins) == 1 && cfg.AllowOrigins[0] == "*" {
 		return cors.Config{
 			AllowAllOrigins: true,
 			AllowMethods:    []string{"*"},
 			AllowHeaders:    []string{"*"},
+			ExposeHeaders:   []string{"ETag"},
 		}
 	}
 
 	return cors.Config{
-		AllowOrigins: cfg.AllowOrigins,
-		AllowMethods: []string{"*"},
-		AllowHeaders: []string{"*"},
+		AllowOrigins:  cfg.AllowOrigins,
+		AllowMethods:  []string{"*"},
+		AllowHeaders:  []string{"*"},
+		ExposeHeaders: []string{"ETag"},
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/golang-migrate/migrate/v4"
 	"gorm.io/gorm"
 
 	libdomain "github.com/kujilabo/redstart/lib/domain"
@@ -34,10 +35,10 @@ type PostgresConfig struct {
 }
 
 type DBConfig struct {
-	DriverName string          `yaml:"driverName"`
-	SQLite3    *SQLite3Config  `yaml:"sqlite3"`
-	MySQL      *MySQLConfig    `yaml:"mysql"`
-	Postgres   *PostgresConfig `yaml:"postgres"`
+	DriverName string          `yaml:"driverName" validate:"required,oneof=sqlite3 mysql postgres"`
+	SQLite3    *SQLite3Config  `yaml:"sqlite3" validate:"required_if=DriverName sqlite3"`
+	MySQL      *MySQLConfig    `yaml:"mysql" validate:"required_if=DriverName mysql"`
+	Postgres   *PostgresConfig `yaml:"postgres" validate:"required_if=DriverName postgres"`
 	Migration  bool            `yaml:"migration"`
 }
 
@@ -79,81 +80,117 @@ func (f *mergedFS) ReadDir(name string) ([]fs.DirEntry, error) {
 	return f.entries, nil
 }
 
-func InitDB(cfg *DBConfig, sqlFSs ...fs.FS) (libgateway.DialectRDBMS, *gorm.DB, *sql.DB, error) {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	}))
-	mergedFS, err := newMergedFS(cfg.DriverName, sqlFSs...)
+// openDB opens the database which fills the audit columns with the operator in the context
+func openDB(cfg *DBConfig, logger *slog.Logger) (libgateway.DialectRDBMS, *gorm.DB, error) {
+	dialect, db, err := openDialectDB(cfg, logger)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, err
+	}
+
+	if err := db.Use(libgateway.NewAuditColumnsPlugin()); err != nil {
+		return nil, nil, liberrors.Errorf("failed to Use AuditColumnsPlugin. err: %w", err)
 	}
 
+	return dialect, db, nil
+}
+
+func openDialectDB(cfg *DBConfig, logger *slog.Logger) (libgateway.DialectRDBMS, *gorm.DB, error) {
 	switch cfg.DriverName {
-	// case "sqlite3":
-	// 	db, err := libgateway.OpenSQLite("./"+cfg.SQLite3.File, logger)
-	// 	if err != nil {
-	// 		return nil, nil, liberrors.Errorf("OpenSQLite. err: %w", err)
-	// 	}
-
-	// 	sqlDB, err := db.DB()
-	// 	if err != nil {
-	// 		return nil, nil, err
-	// 	}
-
-	// 	if err := sqlDB.Ping(); err != nil {
-	// 		return nil, nil, err
-	// 	}
-
-	// 	if cfg.Migration {
-	// 		if err := libgateway.MigrateSQLiteDB(db, sqlFS); err != nil {
-	// 			return nil, nil, err
-	// 		}
-	// 	}
-
-	// 	return db, sqlDB, nil
-	case "mysql":
-		db, err := libgateway.OpenMySQL(cfg.MySQL.Username, cfg.MySQL.Password, cfg.MySQL.Host, cfg.MySQL.Port, cfg.MySQL.Database, logger)
+	case "sqlite3":
+		db, err := libgateway.OpenSQLite(cfg.SQLite3.File, logger)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, err
 		}
 
-		sqlDB, err := db.DB()
+		dialect := libgateway.DialectSQLite{}
+		return &dialect, db, nil
+	case "mysql":
+		db, err := libgateway.OpenMySQL(cfg.MySQL.Username, cfg.MySQL.Password, cfg.MySQL.Host, cfg.MySQL.Port, cfg.MySQL.Database, logger)
 		if err != nil {
-			return nil, nil, nil, err
-		}
-
-		if err := sqlDB.Ping(); err != nil {
-			return nil, nil, nil, err
-		}
-
-		if err := libgateway.MigrateMySQLDB(db, mergedFS); err != nil {
-			return nil, nil, nil, liberrors.Errorf("failed to MigrateMySQLDB. err: %w", err)
+			return nil, nil, err
 		}
 
 		dialect := libgateway.DialectMySQL{}
-		return &dialect, db, sqlDB, nil
+		return &dialect, db, nil
 	case "postgres":
 		db, err := libgateway.OpenPostgres(cfg.Postgres.Username, cfg.Postgres.Password, cfg.Postgres.Host, cfg.Postgres.Port, cfg.Postgres.Database, logger)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, err
 		}
 
-		sqlDB, err := db.DB()
-		if err != nil {
-			return nil, nil, nil, err
-		}
+		dialect := libgateway.DialectPostgres{}
+		return &dialect, db, nil
+	default:
+		return nil, nil, libdomain.ErrInvalidArgument
+	}
+}
 
-		if err := sqlDB.Ping(); err != nil {
-			return nil, nil, nil, err
-		}
+// OpenDB opens the database without applying migrations
+func OpenDB(cfg *DBConfig) (libgateway.DialectRDBMS, *gorm.DB, *sql.DB, error) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
 
-		if err := libgateway.MigratePostgresDB(db, mergedFS); err != nil {
-			return nil, nil, nil, liberrors.Errorf("failed to MigrateMySQLDB. err: %w", err)
-		}
+	dialect, db, err := openDB(cfg, logger)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-		dialect := libgateway.DialectPostgres{}
-		return &dialect, db, sqlDB, nil
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if err := sqlDB.Ping(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return dialect, db, sqlDB, nil
+}
+
+func NewMigrate(cfg *DBConfig, db *gorm.DB, sqlFSs ...fs.FS) (*migrate.Migrate, error) {
+	mergedFS, err := newMergedFS(cfg.DriverName, sqlFSs...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.DriverName {
+	case "sqlite3":
+		return libgateway.NewSQLiteMigrate(db, mergedFS)
+	case "mysql":
+		return libgateway.NewMySQLMigrate(db, mergedFS)
+	case "postgres":
+		return libgateway.NewPostgresMigrate(db, mergedFS)
 	default:
-		return nil, nil, nil, libdomain.ErrInvalidArgument
+		return nil, libdomain.ErrInvalidArgument
+	}
+}
+
+func InitDB(cfg *DBConfig, sqlFSs ...fs.FS) (libgateway.DialectRDBMS, *gorm.DB, *sql.DB, error) {
+	mergedFS, err := newMergedFS(cfg.DriverName, sqlFSs...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	dialect, db, sqlDB, err := OpenDB(cfg)
+	if err != nil {
+		return nil, nil, nil, err
 	}
+
+	switch cfg.DriverName {
+	case "sqlite3":
+		if err := libgateway.MigrateSQLiteDB(db, mergedFS); err != nil {
+			return nil, nil, nil, liberrors.Errorf("failed to MigrateSQLiteDB. err: %w", err)
+		}
+	case "mysql":
+		if err := libgateway.MigrateMySQLDB(db, mergedFS); err != nil {
+			return nil, nil, nil, liberrors.Errorf("failed to MigrateMySQLDB. err: %w", err)
+		}
+	case "postgres":
+		if err := libgateway.MigratePostgresDB(db, mergedFS); err != nil {
+			return nil, nil, nil, liberrors.Errorf("failed to MigratePostgresDB. err: %w", err)
+		}
+	}
+
+	return dialect, db, sqlDB, nil
 }
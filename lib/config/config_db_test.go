@@ -0,0 +1,24 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libconfig "github.com/kujilabo/redstart/lib/config"
+)
+
+func TestOpenDB_auditColumnsPlugin(t *testing.T) {
+	t.Parallel()
+	_, db, sqlDB, err := libconfig.OpenDB(&libconfig.DBConfig{
+		DriverName: "sqlite3",
+		SQLite3:    &libconfig.SQLite3Config{File: filepath.Join(t.TempDir(), "test.db")},
+	})
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	_, ok := db.Config.Plugins["redstart:audit_columns"]
+	assert.True(t, ok)
+}
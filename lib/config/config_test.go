@@ -0,0 +1,186 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libconfig "github.com/kujilabo/redstart/lib/config"
+)
+
+type testAppConfig struct {
+	Name        string   `yaml:"name" validate:"required"`
+	MetricsPort int      `yaml:"metricsPort" validate:"required"`
+	Tags        []string `yaml:"tags"`
+}
+
+type testConfig struct {
+	App   *testAppConfig         `yaml:"app" validate:"required"`
+	DB    *libconfig.DBConfig    `yaml:"db" validate:"required"`
+	Trace *libconfig.TraceConfig `yaml:"trace" validate:"required"`
+	Log   *libconfig.LogConfig   `yaml:"log" validate:"required"`
+}
+
+const testDefaultConf = `
+app:
+  name: redstart
+  metricsPort: 8081
+db:
+  driverName: sqlite3
+  sqlite3:
+    file: ${TEST_SQLITE_FILE:-default.db}
+trace:
+  exporter: none
+log:
+  level:
+    default: info
+`
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+	return filePath
+}
+
+func fieldPaths(t *testing.T, err error) []string {
+	t.Helper()
+	paths := make([]string, 0)
+	var joined interface{ Unwrap() []error }
+	require.True(t, errors.As(err, &joined))
+	for _, e := range joined.Unwrap() {
+		var fieldErr *libconfig.FieldError
+		require.True(t, errors.As(e, &fieldErr))
+		paths = append(paths, fieldErr.Error())
+	}
+	return paths
+}
+
+func TestLoad_default(t *testing.T) {
+	var cfg testConfig
+	err := libconfig.Load(&cfg, []byte(testDefaultConf), "")
+	require.NoError(t, err)
+	assert.Equal(t, "redstart", cfg.App.Name)
+	assert.Equal(t, 8081, cfg.App.MetricsPort)
+	assert.Equal(t, "default.db", cfg.DB.SQLite3.File)
+	assert.Nil(t, cfg.DB.MySQL)
+}
+
+func TestLoad_expandEnv(t *testing.T) {
+	t.Setenv("TEST_SQLITE_FILE", "env.db")
+	t.Setenv("TEST_MYSQL_PASSWORD", "pa$$word")
+	filePath := writeFile(t, `
+db:
+  driverName: mysql
+  mysql:
+    username: user
+    password: ${TEST_MYSQL_PASSWORD}
+    host: localhost
+    port: 3306
+    database: redstart
+`)
+
+	var cfg testConfig
+	err := libconfig.Load(&cfg, []byte(testDefaultConf), filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "env.db", cfg.DB.SQLite3.File)
+	assert.Equal(t, "mysql", cfg.DB.DriverName)
+	assert.Equal(t, "pa$$word", cfg.DB.MySQL.Password)
+	// values not in the override file are kept
+	assert.Equal(t, "redstart", cfg.App.Name)
+	assert.Equal(t, "info", cfg.Log.Level["default"])
+}
+
+func TestLoad_expandEnv_shouldKeepValue_whenValueHasYAMLSyntax(t *testing.T) {
+	t.Setenv("TEST_SQLITE_FILE", "a: b\nc: d")
+	t.Setenv("TEST_MYSQL_PORT", "3306")
+	filePath := writeFile(t, `
+db:
+  driverName: mysql
+  mysql:
+    username: user
+    password: "${TEST_MYSQL_PASSWORD:-x: y}"
+    host: localhost
+    port: ${TEST_MYSQL_PORT}
+    database: redstart
+`)
+
+	var cfg testConfig
+	err := libconfig.Load(&cfg, []byte(testDefaultConf), filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "a: b\nc: d", cfg.DB.SQLite3.File)
+	assert.Equal(t, "x: y", cfg.DB.MySQL.Password)
+	assert.Equal(t, 3306, cfg.DB.MySQL.Port)
+	// no other keys are added
+	assert.Equal(t, "redstart", cfg.App.Name)
+}
+
+func TestLoad_envOverride(t *testing.T) {
+	t.Setenv("REDSTART_APP_METRICS_PORT", "9090")
+	t.Setenv("REDSTART_APP_TAGS", "a, b")
+	t.Setenv("REDSTART_DB_DRIVER_NAME", "postgres")
+	t.Setenv("REDSTART_DB_POSTGRES_USERNAME", "user")
+	t.Setenv("REDSTART_DB_POSTGRES_PASSWORD", "password")
+	t.Setenv("REDSTART_DB_POSTGRES_HOST", "localhost")
+	t.Setenv("REDSTART_DB_POSTGRES_PORT", "5432")
+	t.Setenv("REDSTART_DB_POSTGRES_DATABASE", "redstart")
+	t.Setenv("REDSTART_LOG_LEVEL_USER_GATEWAY", "debug")
+
+	var cfg testConfig
+	err := libconfig.Load(&cfg, []byte(testDefaultConf), "")
+	require.NoError(t, err)
+	assert.Equal(t, 9090, cfg.App.MetricsPort)
+	assert.Equal(t, []string{"a", "b"}, cfg.App.Tags)
+	assert.Equal(t, "postgres", cfg.DB.DriverName)
+	require.NotNil(t, cfg.DB.Postgres)
+	assert.Equal(t, 5432, cfg.DB.Postgres.Port)
+	assert.Equal(t, "info", cfg.Log.Level["default"])
+	assert.Equal(t, "debug", cfg.Log.Level["user_gateway"])
+}
+
+func TestLoad_invalidEnv(t *testing.T) {
+	t.Setenv("REDSTART_APP_METRICS_PORT", "abc")
+
+	var cfg testConfig
+	err := libconfig.Load(&cfg, []byte(testDefaultConf), "")
+	assert.ErrorContains(t, err, "REDSTART_APP_METRICS_PORT")
+}
+
+func TestLoad_validationError(t *testing.T) {
+	t.Setenv("REDSTART_DB_DRIVER_NAME", "mysql")
+	t.Setenv("REDSTART_DB_MYSQL_HOST", "localhost")
+	filePath := writeFile(t, `
+trace:
+  exporter: otlp
+`)
+
+	var cfg testConfig
+	err := libconfig.Load(&cfg, []byte(testDefaultConf), filePath)
+	require.Error(t, err)
+	assert.ElementsMatch(t, []string{
+		"db.mysql.username: required",
+		"db.mysql.password: required",
+		"db.mysql.port: required",
+		"db.mysql.database: required",
+		"trace.otlp: required_if=Exporter otlp",
+	}, fieldPaths(t, err))
+}
+
+func TestLoad_unsupportedDriver(t *testing.T) {
+	t.Setenv("REDSTART_DB_DRIVER_NAME", "oracle")
+
+	var cfg testConfig
+	err := libconfig.Load(&cfg, []byte(testDefaultConf), "")
+	require.Error(t, err)
+	assert.Equal(t, []string{"db.driverName: oneof=sqlite3 mysql postgres"}, fieldPaths(t, err))
+}
+
+func TestLoad_fileNotFound(t *testing.T) {
+	var cfg testConfig
+	err := libconfig.Load(&cfg, []byte(testDefaultConf), filepath.Join(t.TempDir(), "not_found.yml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
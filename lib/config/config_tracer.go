@@ -22,7 +22,7 @@ type OTLPConfig struct {
 
 type TraceConfig struct {
 	Exporter string      `yaml:"exporter" validate:"required"`
-	OTLP     *OTLPConfig `yaml:"otlp"`
+	OTLP     *OTLPConfig `yaml:"otlp" validate:"required_if=Exporter otlp"`
 }
 
 func initTracerExporter(ctx context.Context, traceConfig *TraceConfig) (sdktrace.SpanExporter, error) {
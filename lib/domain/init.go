@@ -13,4 +13,6 @@ var (
 
 	ErrInvalidArgument  = errors.New("invalid argument")
 	ErrPermissionDenied = errors.New("permission denied")
+	// ErrConcurrentModification is returned when the version of the record to update is not the expected one
+	ErrConcurrentModification = errors.New("concurrent modification")
 )
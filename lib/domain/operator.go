@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+const operatorIDContextKey ContextKey = "operator_id"
+
+// WithOperatorID returns the context carrying the ID of the app user performing the operation
+func WithOperatorID(ctx context.Context, operatorID int) context.Context {
+	return context.WithValue(ctx, operatorIDContextKey, operatorID)
+}
+
+// OperatorIDFromContext returns the ID set by WithOperatorID
+func OperatorIDFromContext(ctx context.Context) (int, bool) {
+	operatorID, ok := ctx.Value(operatorIDContextKey).(int)
+	if !ok || operatorID <= 0 {
+		return 0, false
+	}
+
+	return operatorID, true
+}
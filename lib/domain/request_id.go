@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+const requestIDContextKey ContextKey = "request_id"
+
+// WithRequestID returns the context carrying the ID of the request being processed
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the ID set by WithRequestID, or empty when it is not set
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
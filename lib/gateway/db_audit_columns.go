@@ -0,0 +1,205 @@
+package gateway
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+var (
+	ErrOperatorNotInContext = errors.New("operator not in context")
+	ErrVersionNotSet        = errors.New("version not set")
+)
+
+type auditColumnsPlugin struct{}
+
+// NewAuditColumnsPlugin returns the gorm plugin which fills the audit columns of the entities having
+// version, created_at, updated_at, created_by and updated_by, and of the junction entities having created_at and created_by.
+// On create, zero values are filled. created_by and updated_by are the ID of the operator set by libdomain.WithOperatorID.
+// On update with a map, updated_at and updated_by are filled and version is incremented unless the map has them.
+// On update with a struct, the struct must have the version loaded from the record, and the incremented version is written.
+// UpdateColumn and UpdateColumns skip the plugin
+func NewAuditColumnsPlugin() gorm.Plugin {
+	return &auditColumnsPlugin{}
+}
+
+func (p *auditColumnsPlugin) Name() string {
+	return "redstart:audit_columns"
+}
+
+func (p *auditColumnsPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("redstart:audit_columns_create", p.beforeCreate); err != nil {
+		return liberrors.Errorf("Register create callback. err: %w", err)
+	}
+
+	if err := db.Callback().Update().Before("gorm:update").Register("redstart:audit_columns_update", p.beforeUpdate); err != nil {
+		return liberrors.Errorf("Register update callback. err: %w", err)
+	}
+
+	return nil
+}
+
+type auditFields struct {
+	version   *schema.Field
+	createdAt *schema.Field
+	updatedAt *schema.Field
+	createdBy *schema.Field
+	updatedBy *schema.Field
+}
+
+func lookUpAuditFields(s *schema.Schema) (*auditFields, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	fields := auditFields{
+		version:   s.LookUpField("version"),
+		createdAt: s.LookUpField("created_at"),
+		updatedAt: s.LookUpField("updated_at"),
+		createdBy: s.LookUpField("created_by"),
+		updatedBy: s.LookUpField("updated_by"),
+	}
+	if fields.version == nil || fields.createdAt == nil || fields.updatedAt == nil || fields.createdBy == nil || fields.updatedBy == nil {
+		return nil, false
+	}
+
+	return &fields, true
+}
+
+// lookUpCreateFields returns the audit fields of the entity, or created_at and created_by of the junction entity
+func lookUpCreateFields(s *schema.Schema) (*auditFields, bool) {
+	if fields, ok := lookUpAuditFields(s); ok {
+		return fields, true
+	}
+	if s == nil {
+		return nil, false
+	}
+
+	fields := auditFields{
+		createdAt: s.LookUpField("created_at"),
+		createdBy: s.LookUpField("created_by"),
+	}
+	if fields.createdAt == nil || fields.createdBy == nil {
+		return nil, false
+	}
+
+	return &fields, true
+}
+
+func (p *auditColumnsPlugin) beforeCreate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SkipHooks {
+		return
+	}
+
+	fields, ok := lookUpCreateFields(db.Statement.Schema)
+	if !ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	operatorID, hasOperator := libdomain.OperatorIDFromContext(ctx)
+	now := db.NowFunc()
+
+	fill := func(rv reflect.Value) {
+		setIfZero := func(field *schema.Field, value interface{}) {
+			if field == nil {
+				return
+			}
+			if _, isZero := field.ValueOf(ctx, rv); isZero {
+				if err := field.Set(ctx, rv, value); err != nil {
+					_ = db.AddError(err)
+				}
+			}
+		}
+
+		setIfZero(fields.version, 1)
+		setIfZero(fields.createdAt, now)
+		setIfZero(fields.updatedAt, now)
+
+		for _, field := range []*schema.Field{fields.createdBy, fields.updatedBy} {
+			if field == nil {
+				continue
+			}
+			if _, isZero := field.ValueOf(ctx, rv); !isZero {
+				continue
+			}
+			if !hasOperator {
+				_ = db.AddError(liberrors.Errorf("table: %s, column: %s, err: %w", db.Statement.Table, field.DBName, ErrOperatorNotInContext))
+				return
+			}
+			setIfZero(field, operatorID)
+		}
+	}
+
+	switch rv := reflect.Indirect(db.Statement.ReflectValue); rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			fill(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		fill(rv)
+	}
+}
+
+func (p *auditColumnsPlugin) beforeUpdate(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SkipHooks {
+		return
+	}
+
+	fields, ok := lookUpAuditFields(db.Statement.Schema)
+	if !ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	operatorID, hasOperator := libdomain.OperatorIDFromContext(ctx)
+	now := db.NowFunc()
+
+	if values, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		has := func(field *schema.Field) bool {
+			_, ok1 := values[field.DBName]
+			_, ok2 := values[field.Name]
+			return ok1 || ok2
+		}
+
+		if !has(fields.version) {
+			values[fields.version.DBName] = gorm.Expr("? + 1", clause.Column{Name: fields.version.DBName})
+		}
+		if !has(fields.updatedAt) {
+			values[fields.updatedAt.DBName] = now
+		}
+		if !has(fields.updatedBy) {
+			if !hasOperator {
+				_ = db.AddError(liberrors.Errorf("table: %s, column: %s, err: %w", db.Statement.Table, fields.updatedBy.DBName, ErrOperatorNotInContext))
+				return
+			}
+			values[fields.updatedBy.DBName] = operatorID
+		}
+		return
+	}
+
+	destValue := reflect.Indirect(reflect.ValueOf(db.Statement.Dest))
+	if destValue.Kind() != reflect.Struct {
+		return
+	}
+	value, isZero := fields.version.ValueOf(ctx, destValue)
+	version, ok := value.(int)
+	if isZero || !ok {
+		_ = db.AddError(liberrors.Errorf("table: %s, err: %w", db.Statement.Table, ErrVersionNotSet))
+		return
+	}
+	if !hasOperator {
+		_ = db.AddError(liberrors.Errorf("table: %s, column: %s, err: %w", db.Statement.Table, fields.updatedBy.DBName, ErrOperatorNotInContext))
+		return
+	}
+
+	db.Statement.SetColumn(fields.version.DBName, version+1, true)
+	db.Statement.SetColumn(fields.updatedAt.DBName, now, true)
+	db.Statement.SetColumn(fields.updatedBy.DBName, operatorID, true)
+}
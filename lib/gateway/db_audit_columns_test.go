@@ -0,0 +1,165 @@
+package gateway_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gorm_sqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
+)
+
+type auditedEntity struct {
+	ID        int
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy int
+	UpdatedBy int
+	Name      string
+}
+
+func (e *auditedEntity) TableName() string {
+	return "audited"
+}
+
+type junctionEntity struct {
+	ID        int
+	CreatedAt time.Time
+	CreatedBy int
+}
+
+func (e *junctionEntity) TableName() string {
+	return "junction"
+}
+
+type notAuditedEntity struct {
+	ID        int
+	CreatedBy int
+	Name      string
+}
+
+func (e *notAuditedEntity) TableName() string {
+	return "not_audited"
+}
+
+func openAuditColumnsTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(gorm_sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(libgateway.NewAuditColumnsPlugin()))
+	require.NoError(t, db.Exec("create table audited (id integer primary key autoincrement, version int not null, created_at datetime not null, updated_at datetime not null, created_by int not null, updated_by int not null, name text not null)").Error)
+	require.NoError(t, db.Exec("create table junction (id integer primary key autoincrement, created_at datetime not null, created_by int not null)").Error)
+	require.NoError(t, db.Exec("create table not_audited (id integer primary key autoincrement, created_by int not null, name text not null)").Error)
+	return db
+}
+
+func TestAuditColumnsPlugin_Create(t *testing.T) {
+	db := openAuditColumnsTestDB(t)
+	ctx := libdomain.WithOperatorID(context.Background(), 3)
+
+	// filled with the operator in the context
+	entity := auditedEntity{Name: "a"}
+	require.NoError(t, db.WithContext(ctx).Create(&entity).Error)
+	found := auditedEntity{}
+	require.NoError(t, db.First(&found, entity.ID).Error)
+	assert.Equal(t, 1, found.Version)
+	assert.Equal(t, 3, found.CreatedBy)
+	assert.Equal(t, 3, found.UpdatedBy)
+	assert.False(t, found.CreatedAt.IsZero())
+	assert.Equal(t, found.CreatedAt, found.UpdatedAt)
+
+	// the values set explicitly are kept
+	entity = auditedEntity{Version: 5, CreatedBy: 7, UpdatedBy: 8, Name: "b"}
+	require.NoError(t, db.WithContext(ctx).Create(&entity).Error)
+	found = auditedEntity{}
+	require.NoError(t, db.First(&found, entity.ID).Error)
+	assert.Equal(t, 5, found.Version)
+	assert.Equal(t, 7, found.CreatedBy)
+	assert.Equal(t, 8, found.UpdatedBy)
+
+	// batch
+	entities := []auditedEntity{{Name: "c"}, {Name: "d"}}
+	require.NoError(t, db.WithContext(ctx).Create(&entities).Error)
+	for _, e := range entities {
+		found = auditedEntity{}
+		require.NoError(t, db.First(&found, e.ID).Error)
+		assert.Equal(t, 3, found.CreatedBy)
+		assert.Equal(t, 1, found.Version)
+	}
+
+	// no operator in the context
+	entity = auditedEntity{Name: "e"}
+	err := db.WithContext(context.Background()).Create(&entity).Error
+	assert.ErrorIs(t, err, libgateway.ErrOperatorNotInContext)
+
+	// the junction entities are filled with created_at and created_by
+	junction := junctionEntity{}
+	require.NoError(t, db.WithContext(ctx).Create(&junction).Error)
+	foundJunction := junctionEntity{}
+	require.NoError(t, db.First(&foundJunction, junction.ID).Error)
+	assert.Equal(t, 3, foundJunction.CreatedBy)
+	assert.False(t, foundJunction.CreatedAt.IsZero())
+	err = db.WithContext(context.Background()).Create(&junctionEntity{}).Error
+	assert.ErrorIs(t, err, libgateway.ErrOperatorNotInContext)
+
+	// the entities without the audit columns are not changed
+	notAudited := notAuditedEntity{Name: "f"}
+	err = db.WithContext(context.Background()).Create(&notAudited).Error
+	assert.NoError(t, err)
+}
+
+func TestAuditColumnsPlugin_Update(t *testing.T) {
+	db := openAuditColumnsTestDB(t)
+	createdCtx := libdomain.WithOperatorID(context.Background(), 3)
+	updatedCtx := libdomain.WithOperatorID(context.Background(), 4)
+
+	entity := auditedEntity{Name: "a"}
+	require.NoError(t, db.WithContext(createdCtx).Create(&entity).Error)
+
+	// map
+	time.Sleep(10 * time.Millisecond)
+	result := db.WithContext(updatedCtx).Model(&auditedEntity{}).Where("id = ?", entity.ID).Updates(map[string]interface{}{"name": "b"})
+	require.NoError(t, result.Error)
+	assert.Equal(t, int64(1), result.RowsAffected)
+	found := auditedEntity{}
+	require.NoError(t, db.First(&found, entity.ID).Error)
+	assert.Equal(t, "b", found.Name)
+	assert.Equal(t, 2, found.Version)
+	assert.Equal(t, 3, found.CreatedBy)
+	assert.Equal(t, 4, found.UpdatedBy)
+	assert.True(t, found.UpdatedAt.After(found.CreatedAt))
+
+	// single column
+	require.NoError(t, db.WithContext(updatedCtx).Model(&auditedEntity{}).Where("id = ?", entity.ID).Update("name", "c").Error)
+	require.NoError(t, db.First(&found, entity.ID).Error)
+	assert.Equal(t, 3, found.Version)
+
+	// struct loaded from the record
+	found.Name = "d"
+	require.NoError(t, db.WithContext(createdCtx).Save(&found).Error)
+	require.NoError(t, db.First(&found, entity.ID).Error)
+	assert.Equal(t, "d", found.Name)
+	assert.Equal(t, 4, found.Version)
+	assert.Equal(t, 3, found.UpdatedBy)
+
+	// struct without the version
+	err := db.WithContext(updatedCtx).Model(&auditedEntity{ID: entity.ID}).Updates(&auditedEntity{Name: "e"}).Error
+	assert.ErrorIs(t, err, libgateway.ErrVersionNotSet)
+
+	// no operator in the context
+	err = db.WithContext(context.Background()).Model(&auditedEntity{}).Where("id = ?", entity.ID).Updates(map[string]interface{}{"name": "f"}).Error
+	assert.ErrorIs(t, err, libgateway.ErrOperatorNotInContext)
+
+	// UpdateColumns skips the plugin
+	require.NoError(t, db.WithContext(context.Background()).Model(&auditedEntity{}).Where("id = ?", entity.ID).UpdateColumns(map[string]interface{}{"name": "g"}).Error)
+	require.NoError(t, db.First(&found, entity.ID).Error)
+	assert.Equal(t, "g", found.Name)
+	assert.Equal(t, 4, found.Version)
+}
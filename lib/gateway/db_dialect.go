@@ -26,3 +26,14 @@ func (d *DialectPostgres) Name() string {
 func (d *DialectPostgres) BoolDefaultValue() string {
 	return "false"
 }
+
+type DialectSQLite struct {
+}
+
+func (d *DialectSQLite) Name() string {
+	return "sqlite3"
+}
+
+func (d *DialectSQLite) BoolDefaultValue() string {
+	return "0"
+}
@@ -0,0 +1,196 @@
+package gateway
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+const MYSQL_ER_DUP_ENTRY = 1062
+const MYSQL_ER_BAD_NULL_ERROR = 1048
+const MYSQL_ER_LOCK_WAIT_TIMEOUT = 1205
+const MYSQL_ER_LOCK_DEADLOCK = 1213
+const MYSQL_ER_ROW_IS_REFERENCED_2 = 1451
+const MYSQL_ER_NO_REFERENCED_ROW_2 = 1452
+const MYSQL_ER_CHECK_CONSTRAINT_VIOLATED = 3819
+
+const POSTGRES_NOT_NULL_VIOLATION = "23502"
+const POSTGRES_FOREIGN_KEY_VIOLATION = "23503"
+const POSTGRES_UNIQUE_VIOLATION = "23505"
+const POSTGRES_CHECK_VIOLATION = "23514"
+const POSTGRES_LOCK_NOT_AVAILABLE = "55P03"
+const POSTGRES_SERIALIZATION_FAILURE = "40001"
+const POSTGRES_DEADLOCK_DETECTED = "40P01"
+
+const SQLITE_CONSTRAINT_CHECK = 275
+const SQLITE_CONSTRAINT_FOREIGNKEY = 787
+const SQLITE_CONSTRAINT_NOTNULL = 1299
+const SQLITE_CONSTRAINT_PRIMARYKEY = 1555
+const SQLITE_CONSTRAINT_UNIQUE = 2067
+const SQLITE_BUSY_SNAPSHOT = 517
+
+type DBErrorType int
+
+const (
+	DBErrorTypeUnknown DBErrorType = iota
+	DBErrorTypeUniqueViolation
+	// DBErrorTypeForeignKeyViolation is the row referring to the row which does not exist.
+	// Postgres and SQLite report the row being referenced by other rows as the same violation
+	DBErrorTypeForeignKeyViolation
+	DBErrorTypeNotNullViolation
+	DBErrorTypeCheckViolation
+	DBErrorTypeDeadlock
+	DBErrorTypeSerializationFailure
+	// DBErrorTypeRowReferenced is the row which cannot be deleted or updated because other rows refer to it
+	DBErrorTypeRowReferenced
+	// DBErrorTypeLockTimeout is the lock which could not be acquired in time
+	DBErrorTypeLockTimeout
+)
+
+func (t DBErrorType) String() string {
+	switch t {
+	case DBErrorTypeUniqueViolation:
+		return "UniqueViolation"
+	case DBErrorTypeForeignKeyViolation:
+		return "ForeignKeyViolation"
+	case DBErrorTypeNotNullViolation:
+		return "NotNullViolation"
+	case DBErrorTypeCheckViolation:
+		return "CheckViolation"
+	case DBErrorTypeDeadlock:
+		return "Deadlock"
+	case DBErrorTypeSerializationFailure:
+		return "SerializationFailure"
+	case DBErrorTypeRowReferenced:
+		return "RowReferenced"
+	case DBErrorTypeLockTimeout:
+		return "LockTimeout"
+	default:
+		return "Unknown"
+	}
+}
+
+// ClassifyDBError returns the kind of constraint violation or concurrency failure reported by MySQL, Postgres or SQLite
+func ClassifyDBError(err error) DBErrorType {
+	if err == nil {
+		return DBErrorTypeUnknown
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if ok := errors.As(err, &mysqlErr); ok {
+		return classifyMySQLError(mysqlErr)
+	}
+
+	var pgErr *pgconn.PgError
+	if ok := errors.As(err, &pgErr); ok {
+		return classifyPostgresError(pgErr)
+	}
+
+	var sqlite3Err sqlite3.Error
+	if ok := errors.As(err, &sqlite3Err); ok {
+		return classifySQLiteError(sqlite3Err)
+	}
+
+	return DBErrorTypeUnknown
+}
+
+func classifyMySQLError(err *mysql.MySQLError) DBErrorType {
+	switch err.Number {
+	case MYSQL_ER_DUP_ENTRY:
+		return DBErrorTypeUniqueViolation
+	case MYSQL_ER_NO_REFERENCED_ROW_2:
+		return DBErrorTypeForeignKeyViolation
+	case MYSQL_ER_ROW_IS_REFERENCED_2:
+		return DBErrorTypeRowReferenced
+	case MYSQL_ER_BAD_NULL_ERROR:
+		return DBErrorTypeNotNullViolation
+	case MYSQL_ER_CHECK_CONSTRAINT_VIOLATED:
+		return DBErrorTypeCheckViolation
+	case MYSQL_ER_LOCK_DEADLOCK:
+		return DBErrorTypeDeadlock
+	case MYSQL_ER_LOCK_WAIT_TIMEOUT:
+		return DBErrorTypeLockTimeout
+	default:
+		return DBErrorTypeUnknown
+	}
+}
+
+func classifyPostgresError(err *pgconn.PgError) DBErrorType {
+	switch err.Code {
+	case POSTGRES_UNIQUE_VIOLATION:
+		return DBErrorTypeUniqueViolation
+	case POSTGRES_FOREIGN_KEY_VIOLATION:
+		return DBErrorTypeForeignKeyViolation
+	case POSTGRES_NOT_NULL_VIOLATION:
+		return DBErrorTypeNotNullViolation
+	case POSTGRES_CHECK_VIOLATION:
+		return DBErrorTypeCheckViolation
+	case POSTGRES_DEADLOCK_DETECTED:
+		return DBErrorTypeDeadlock
+	case POSTGRES_SERIALIZATION_FAILURE:
+		return DBErrorTypeSerializationFailure
+	case POSTGRES_LOCK_NOT_AVAILABLE:
+		return DBErrorTypeLockTimeout
+	default:
+		return DBErrorTypeUnknown
+	}
+}
+
+func classifySQLiteError(err sqlite3.Error) DBErrorType {
+	switch int(err.ExtendedCode) {
+	case SQLITE_CONSTRAINT_UNIQUE, SQLITE_CONSTRAINT_PRIMARYKEY:
+		return DBErrorTypeUniqueViolation
+	case SQLITE_CONSTRAINT_FOREIGNKEY:
+		return DBErrorTypeForeignKeyViolation
+	case SQLITE_CONSTRAINT_NOTNULL:
+		return DBErrorTypeNotNullViolation
+	case SQLITE_CONSTRAINT_CHECK:
+		return DBErrorTypeCheckViolation
+	case SQLITE_BUSY_SNAPSHOT:
+		// a WAL snapshot became stale, which is what other databases report as a serialization failure
+		return DBErrorTypeSerializationFailure
+	}
+
+	// the lock could not be acquired within busy_timeout. SQLite does not roll back the transaction, unlike a deadlock
+	if err.Code == sqlite3.ErrBusy || err.Code == sqlite3.ErrLocked {
+		return DBErrorTypeLockTimeout
+	}
+
+	return DBErrorTypeUnknown
+}
+
+// IsRetryableDBError reports whether the transaction that caused err can be safely retried.
+// Lock timeouts are not retried because the database has already waited for the lock
+func IsRetryableDBError(err error) bool {
+	errType := ClassifyDBError(err)
+	return errType == DBErrorTypeDeadlock || errType == DBErrorTypeSerializationFailure
+}
+
+func ConvertDuplicatedError(err error, newErr error) error {
+	if ClassifyDBError(err) == DBErrorTypeUniqueViolation {
+		return newErr
+	}
+
+	return err
+}
+
+// ConvertRelationError returns newErr when err is the row referring to the row which does not exist
+func ConvertRelationError(err error, newErr error) error {
+	if ClassifyDBError(err) == DBErrorTypeForeignKeyViolation {
+		return newErr
+	}
+
+	return err
+}
+
+// ConvertReferencedError returns newErr when err is the row which cannot be deleted because other rows refer to it.
+// Only MySQL distinguishes it from the foreign key violation on insert
+func ConvertReferencedError(err error, newErr error) error {
+	if ClassifyDBError(err) == DBErrorTypeRowReferenced {
+		return newErr
+	}
+
+	return err
+}
@@ -0,0 +1,100 @@
+package gateway_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
+)
+
+func TestClassifyDBError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		want libgateway.DBErrorType
+	}{
+		{name: "nil", err: nil, want: libgateway.DBErrorTypeUnknown},
+		{name: "other", err: errors.New("ERROR"), want: libgateway.DBErrorTypeUnknown},
+		{name: "mysql duplicate entry", err: &mysql.MySQLError{Number: libgateway.MYSQL_ER_DUP_ENTRY}, want: libgateway.DBErrorTypeUniqueViolation},
+		{name: "mysql no referenced row", err: &mysql.MySQLError{Number: libgateway.MYSQL_ER_NO_REFERENCED_ROW_2}, want: libgateway.DBErrorTypeForeignKeyViolation},
+		{name: "mysql row is referenced", err: &mysql.MySQLError{Number: libgateway.MYSQL_ER_ROW_IS_REFERENCED_2}, want: libgateway.DBErrorTypeRowReferenced},
+		{name: "mysql bad null", err: &mysql.MySQLError{Number: libgateway.MYSQL_ER_BAD_NULL_ERROR}, want: libgateway.DBErrorTypeNotNullViolation},
+		{name: "mysql check", err: &mysql.MySQLError{Number: libgateway.MYSQL_ER_CHECK_CONSTRAINT_VIOLATED}, want: libgateway.DBErrorTypeCheckViolation},
+		{name: "mysql deadlock", err: &mysql.MySQLError{Number: libgateway.MYSQL_ER_LOCK_DEADLOCK}, want: libgateway.DBErrorTypeDeadlock},
+		{name: "mysql lock wait timeout", err: &mysql.MySQLError{Number: libgateway.MYSQL_ER_LOCK_WAIT_TIMEOUT}, want: libgateway.DBErrorTypeLockTimeout},
+		{name: "postgres unique", err: &pgconn.PgError{Code: libgateway.POSTGRES_UNIQUE_VIOLATION}, want: libgateway.DBErrorTypeUniqueViolation},
+		{name: "postgres foreign key", err: &pgconn.PgError{Code: libgateway.POSTGRES_FOREIGN_KEY_VIOLATION}, want: libgateway.DBErrorTypeForeignKeyViolation},
+		{name: "postgres not null", err: &pgconn.PgError{Code: libgateway.POSTGRES_NOT_NULL_VIOLATION}, want: libgateway.DBErrorTypeNotNullViolation},
+		{name: "postgres check", err: &pgconn.PgError{Code: libgateway.POSTGRES_CHECK_VIOLATION}, want: libgateway.DBErrorTypeCheckViolation},
+		{name: "postgres deadlock", err: &pgconn.PgError{Code: libgateway.POSTGRES_DEADLOCK_DETECTED}, want: libgateway.DBErrorTypeDeadlock},
+		{name: "postgres serialization", err: &pgconn.PgError{Code: libgateway.POSTGRES_SERIALIZATION_FAILURE}, want: libgateway.DBErrorTypeSerializationFailure},
+		{name: "postgres lock not available", err: &pgconn.PgError{Code: libgateway.POSTGRES_LOCK_NOT_AVAILABLE}, want: libgateway.DBErrorTypeLockTimeout},
+		{name: "sqlite unique", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, want: libgateway.DBErrorTypeUniqueViolation},
+		{name: "sqlite primary key", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintPrimaryKey}, want: libgateway.DBErrorTypeUniqueViolation},
+		{name: "sqlite foreign key", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey}, want: libgateway.DBErrorTypeForeignKeyViolation},
+		{name: "sqlite not null", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintNotNull}, want: libgateway.DBErrorTypeNotNullViolation},
+		{name: "sqlite check", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintCheck}, want: libgateway.DBErrorTypeCheckViolation},
+		{name: "sqlite busy", err: sqlite3.Error{Code: sqlite3.ErrBusy, ExtendedCode: sqlite3.ErrBusy.Extend(0)}, want: libgateway.DBErrorTypeLockTimeout},
+		{name: "sqlite locked", err: sqlite3.Error{Code: sqlite3.ErrLocked, ExtendedCode: sqlite3.ErrLocked.Extend(0)}, want: libgateway.DBErrorTypeLockTimeout},
+		{name: "sqlite busy snapshot", err: sqlite3.Error{Code: sqlite3.ErrBusy, ExtendedCode: sqlite3.ErrBusy.Extend(2)}, want: libgateway.DBErrorTypeSerializationFailure},
+		{name: "wrapped", err: fmt.Errorf("db.Create. err: %w", &pgconn.PgError{Code: libgateway.POSTGRES_UNIQUE_VIOLATION}), want: libgateway.DBErrorTypeUniqueViolation},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, libgateway.ClassifyDBError(tt.err))
+		})
+	}
+}
+
+func TestIsRetryableDBError(t *testing.T) {
+	t.Parallel()
+	assert.True(t, libgateway.IsRetryableDBError(&mysql.MySQLError{Number: libgateway.MYSQL_ER_LOCK_DEADLOCK}))
+	assert.True(t, libgateway.IsRetryableDBError(&pgconn.PgError{Code: libgateway.POSTGRES_SERIALIZATION_FAILURE}))
+	assert.True(t, libgateway.IsRetryableDBError(sqlite3.Error{Code: sqlite3.ErrBusy, ExtendedCode: sqlite3.ErrBusy.Extend(2)}))
+	assert.False(t, libgateway.IsRetryableDBError(sqlite3.Error{Code: sqlite3.ErrBusy, ExtendedCode: sqlite3.ErrBusy.Extend(0)}))
+	assert.False(t, libgateway.IsRetryableDBError(&mysql.MySQLError{Number: libgateway.MYSQL_ER_LOCK_WAIT_TIMEOUT}))
+	assert.False(t, libgateway.IsRetryableDBError(&mysql.MySQLError{Number: libgateway.MYSQL_ER_DUP_ENTRY}))
+}
+
+func TestConvertDuplicatedError(t *testing.T) {
+	t.Parallel()
+	errAlreadyExists := errors.New("already exists")
+	otherErr := errors.New("ERROR")
+
+	assert.Equal(t, errAlreadyExists, libgateway.ConvertDuplicatedError(&mysql.MySQLError{Number: libgateway.MYSQL_ER_DUP_ENTRY}, errAlreadyExists))
+	assert.Equal(t, errAlreadyExists, libgateway.ConvertDuplicatedError(&pgconn.PgError{Code: libgateway.POSTGRES_UNIQUE_VIOLATION}, errAlreadyExists))
+	assert.Equal(t, errAlreadyExists, libgateway.ConvertDuplicatedError(sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, errAlreadyExists))
+	assert.Equal(t, otherErr, libgateway.ConvertDuplicatedError(otherErr, errAlreadyExists))
+}
+
+func TestConvertRelationError(t *testing.T) {
+	t.Parallel()
+	errNotFound := errors.New("not found")
+	otherErr := errors.New("ERROR")
+
+	assert.Equal(t, errNotFound, libgateway.ConvertRelationError(&mysql.MySQLError{Number: libgateway.MYSQL_ER_NO_REFERENCED_ROW_2}, errNotFound))
+	assert.Equal(t, errNotFound, libgateway.ConvertRelationError(&pgconn.PgError{Code: libgateway.POSTGRES_FOREIGN_KEY_VIOLATION}, errNotFound))
+	assert.Equal(t, errNotFound, libgateway.ConvertRelationError(sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey}, errNotFound))
+	assert.Equal(t, otherErr, libgateway.ConvertRelationError(otherErr, errNotFound))
+	// the row being referenced is not the missing relation
+	rowReferencedErr := &mysql.MySQLError{Number: libgateway.MYSQL_ER_ROW_IS_REFERENCED_2}
+	assert.Equal(t, rowReferencedErr, libgateway.ConvertRelationError(rowReferencedErr, errNotFound))
+}
+
+func TestConvertReferencedError(t *testing.T) {
+	t.Parallel()
+	errInUse := errors.New("in use")
+	noReferencedRowErr := &mysql.MySQLError{Number: libgateway.MYSQL_ER_NO_REFERENCED_ROW_2}
+
+	assert.Equal(t, errInUse, libgateway.ConvertReferencedError(&mysql.MySQLError{Number: libgateway.MYSQL_ER_ROW_IS_REFERENCED_2}, errInUse))
+	assert.Equal(t, noReferencedRowErr, libgateway.ConvertReferencedError(noReferencedRowErr, errInUse))
+}
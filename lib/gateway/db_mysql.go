@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database"
 	migrate_mysql "github.com/golang-migrate/migrate/v4/database/mysql"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -51,3 +52,15 @@ func MigrateMySQLDB(db *gorm.DB, sqlFS fs.FS) error {
 		return migrate_mysql.WithInstance(sqlDB, &migrate_mysql.Config{})
 	})
 }
+
+func NewMySQLMigrate(db *gorm.DB, sqlFS fs.FS) (*migrate.Migrate, error) {
+	driverName := "mysql"
+	sourceDriver, err := iofs.New(sqlFS, driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMigrate(db, driverName, sourceDriver, func(sqlDB *sql.DB) (database.Driver, error) {
+		return migrate_mysql.WithInstance(sqlDB, &migrate_mysql.Config{})
+	})
+}
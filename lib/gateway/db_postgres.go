@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database"
 	migrate_postgres "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -40,3 +41,15 @@ func MigratePostgresDB(db *gorm.DB, sqlFS fs.FS) error {
 		return migrate_postgres.WithInstance(sqlDB, &migrate_postgres.Config{})
 	})
 }
+
+func NewPostgresMigrate(db *gorm.DB, sqlFS fs.FS) (*migrate.Migrate, error) {
+	driverName := "postgres"
+	sourceDriver, err := iofs.New(sqlFS, driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMigrate(db, driverName, sourceDriver, func(sqlDB *sql.DB) (database.Driver, error) {
+		return migrate_postgres.WithInstance(sqlDB, &migrate_postgres.Config{})
+	})
+}
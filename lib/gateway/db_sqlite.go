@@ -2,9 +2,11 @@ package gateway
 
 import (
 	"database/sql"
-	"embed"
+	"io/fs"
 	"log/slog"
+	"strings"
 
+	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database"
 	migrate_sqlite3 "github.com/golang-migrate/migrate/v4/database/sqlite3"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -14,8 +16,18 @@ import (
 	"gorm.io/gorm"
 )
 
+// SQLiteDSNParams enables foreign keys and makes writers wait for the lock instead of failing
+const SQLiteDSNParams = "_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL"
+
+func SQLiteDSN(filePath string) string {
+	if strings.Contains(filePath, "?") {
+		return filePath + "&" + SQLiteDSNParams
+	}
+	return filePath + "?" + SQLiteDSNParams
+}
+
 func OpenSQLite(filePath string, logger *slog.Logger) (*gorm.DB, error) {
-	return gorm.Open(gorm_sqlite.Open(filePath), &gorm.Config{
+	return gorm.Open(gorm_sqlite.Open(SQLiteDSN(filePath)), &gorm.Config{
 		Logger: slog_gorm.New(
 			slog_gorm.WithLogger(logger), // Optional, use slog.Default() by default
 			slog_gorm.WithTraceAll(),     // trace all messages
@@ -23,14 +35,26 @@ func OpenSQLite(filePath string, logger *slog.Logger) (*gorm.DB, error) {
 	})
 }
 
-func MigrateSQLiteDB(db *gorm.DB, sqlFS embed.FS) error {
+func MigrateSQLiteDB(db *gorm.DB, sqlFS fs.FS) error {
 	driverName := "sqlite3"
 	sourceDriver, err := iofs.New(sqlFS, driverName)
 	if err != nil {
 		return err
 	}
 
-	return migrateDB(db, "sqlite3", sourceDriver, func(sqlDB *sql.DB) (database.Driver, error) {
+	return migrateDB(db, driverName, sourceDriver, func(sqlDB *sql.DB) (database.Driver, error) {
+		return migrate_sqlite3.WithInstance(sqlDB, &migrate_sqlite3.Config{})
+	})
+}
+
+func NewSQLiteMigrate(db *gorm.DB, sqlFS fs.FS) (*migrate.Migrate, error) {
+	driverName := "sqlite3"
+	sourceDriver, err := iofs.New(sqlFS, driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMigrate(db, driverName, sourceDriver, func(sqlDB *sql.DB) (database.Driver, error) {
 		return migrate_sqlite3.WithInstance(sqlDB, &migrate_sqlite3.Config{})
 	})
 }
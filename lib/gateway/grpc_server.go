@@ -0,0 +1,52 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/kujilabo/redstart/lib/log"
+)
+
+// GRPCServerProcess serves grpcServer until ctx is done and stops it gracefully
+func GRPCServerProcess(ctx context.Context, grpcServer *grpc.Server, port int, gracefulShutdownTimeSec int) error {
+	logger := log.GetLoggerFromContext(ctx, LibGatewayContextKey)
+
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return err
+	}
+	logger.InfoContext(ctx, fmt.Sprintf("grpc server listening at %v", listener.Addr()))
+
+	errCh := make(chan error)
+	go func() {
+		defer close(errCh)
+		if err := grpcServer.Serve(listener); err != nil {
+			logger.InfoContext(ctx, fmt.Sprintf("failed to Serve. err: %v", err))
+			errCh <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(time.Duration(gracefulShutdownTimeSec) * time.Second):
+			logger.InfoContext(ctx, "Server forced to stop")
+			grpcServer.Stop()
+		}
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
@@ -0,0 +1,51 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kujilabo/redstart/lib/log"
+)
+
+// runHTTPServer serves httpServer until ctx is done and shuts it down gracefully
+func runHTTPServer(ctx context.Context, name string, httpServer *http.Server, gracefulShutdownTimeSec int) error {
+	logger := log.GetLoggerFromContext(ctx, LibGatewayContextKey)
+	logger.InfoContext(ctx, fmt.Sprintf("%s server listening at %v", name, httpServer.Addr))
+
+	errCh := make(chan error)
+	go func() {
+		defer close(errCh)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			logger.InfoContext(ctx, fmt.Sprintf("failed to ListenAndServe. err: %v", err))
+			errCh <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		gracefulShutdownTime1 := time.Duration(gracefulShutdownTimeSec) * time.Second
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), gracefulShutdownTime1)
+		defer shutdownCancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.InfoContext(ctx, fmt.Sprintf("Server forced to shutdown. err: %v", err))
+			return err
+		}
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+func AppServerProcess(ctx context.Context, router http.Handler, port int, gracefulShutdownTimeSec int) error {
+	httpServer := http.Server{
+		Addr:              ":" + strconv.Itoa(port),
+		Handler:           router,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	return runHTTPServer(ctx, "app", &httpServer, gracefulShutdownTimeSec)
+}
@@ -2,16 +2,12 @@ package gateway
 
 import (
 	"context"
-	"errors"
-	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-
-	"github.com/kujilabo/redstart/lib/log"
 )
 
 const readHeaderTimeout = time.Duration(30) * time.Second
@@ -31,29 +27,5 @@ func MetricsServerProcess(ctx context.Context, port int, gracefulShutdownTimeSec
 	})
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	logger := log.GetLoggerFromContext(ctx, LibGatewayContextKey)
-	logger.InfoContext(ctx, fmt.Sprintf("metrics server listening at %v", httpServer.Addr))
-
-	errCh := make(chan error)
-	go func() {
-		defer close(errCh)
-		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-			logger.InfoContext(ctx, fmt.Sprintf("failed to ListenAndServe. err: %v", err))
-			errCh <- err
-		}
-	}()
-
-	select {
-	case <-ctx.Done():
-		gracefulShutdownTime1 := time.Duration(gracefulShutdownTimeSec) * time.Second
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), gracefulShutdownTime1)
-		defer shutdownCancel()
-		if err := httpServer.Shutdown(shutdownCtx); err != nil {
-			logger.InfoContext(ctx, fmt.Sprintf("Server forced to shutdown. err: %v", err))
-			return err
-		}
-		return nil
-	case err := <-errCh:
-		return err
-	}
+	return runHTTPServer(ctx, "metrics", &httpServer, gracefulShutdownTimeSec)
 }
@@ -2,12 +2,15 @@ package gateway
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 )
 
+var ErrSignalReceived = errors.New("signal received")
+
 func SignalWatchProcess(ctx context.Context) error {
 	sigs := make(chan os.Signal, 1)
 
@@ -18,6 +21,6 @@ func SignalWatchProcess(ctx context.Context) error {
 		signal.Reset()
 		return nil
 	case sig := <-sigs:
-		return fmt.Errorf("signal received: %v", sig.String())
+		return fmt.Errorf("%w: %v", ErrSignalReceived, sig.String())
 	}
 }
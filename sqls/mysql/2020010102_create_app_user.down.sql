@@ -0,0 +1 @@
+drop table `app_user`;
@@ -0,0 +1 @@
+drop table `user_n_group`;
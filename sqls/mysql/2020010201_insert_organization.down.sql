@@ -0,0 +1 @@
+delete from `organization` where `name` = 'system';
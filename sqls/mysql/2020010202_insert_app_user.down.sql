@@ -0,0 +1 @@
+delete from `app_user` where `organization_id` = 1 and `login_id` = '__system_admin';
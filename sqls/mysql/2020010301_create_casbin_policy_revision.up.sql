@@ -0,0 +1,6 @@
+create table `casbin_policy_revision` (
+ `domain` varchar(40) character set ascii not null
+,`revision` bigint not null
+,`updated_at` datetime not null default current_timestamp on update current_timestamp
+,primary key(`domain`)
+);
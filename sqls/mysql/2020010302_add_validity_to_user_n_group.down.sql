@@ -0,0 +1,4 @@
+alter table `user_n_group`
+ drop index `idx_user_n_group_valid_until`
+,drop column `valid_from`
+,drop column `valid_until`;
@@ -0,0 +1,4 @@
+alter table `user_n_group`
+ add column `valid_from` datetime null
+,add column `valid_until` datetime null
+,add index `idx_user_n_group_valid_until` (`valid_until`);
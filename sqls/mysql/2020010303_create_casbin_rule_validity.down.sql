@@ -0,0 +1 @@
+drop table `casbin_rule_validity`;
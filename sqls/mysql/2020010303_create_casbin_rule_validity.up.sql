@@ -0,0 +1,15 @@
+create table `casbin_rule_validity` (
+ `created_at` datetime not null default current_timestamp
+,`ptype` varchar(100) not null
+,`v0` varchar(100) not null
+,`v1` varchar(100) not null
+,`v2` varchar(100) not null
+,`v3` varchar(100) not null
+,`v4` varchar(100) not null
+,`domain` varchar(40) not null
+,`valid_from` datetime null
+,`valid_until` datetime null
+,primary key(`ptype`, `v0`, `v1`, `v2`, `v3`, `v4`)
+,index `idx_casbin_rule_validity_domain` (`domain`)
+,index `idx_casbin_rule_validity_valid_until` (`valid_until`)
+);
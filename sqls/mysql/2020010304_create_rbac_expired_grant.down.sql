@@ -0,0 +1 @@
+drop table `rbac_expired_grant`;
@@ -0,0 +1,17 @@
+create table `audit_event` (
+ `id` int auto_increment
+,`occurred_at` datetime not null
+,`actor_id` int not null
+,`organization_id` int not null
+,`action` varchar(40) not null
+,`target_type` varchar(20) not null
+,`target_id` varchar(200) not null
+,`before_json` text null
+,`after_json` text null
+,`request_id` varchar(64) not null
+,`trace_id` varchar(32) not null
+,primary key(`id`)
+,index `idx_audit_event_occurred_at` (`organization_id`, `occurred_at`)
+,index `idx_audit_event_actor` (`organization_id`, `actor_id`, `occurred_at`)
+,index `idx_audit_event_target` (`organization_id`, `target_type`, `target_id`, `occurred_at`)
+);
@@ -0,0 +1,2 @@
+alter table `app_user`
+ drop column `removed_login_id`;
@@ -0,0 +1,2 @@
+alter table `app_user`
+ add column `removed_login_id` varchar(200) null;
@@ -0,0 +1,7 @@
+alter table `user_n_group`
+ drop index `idx_user_n_group_user_group`;
+alter table `app_user`
+ drop index `idx_app_user_username`
+,drop index `idx_app_user_created_at`
+,drop index `idx_app_user_provider`
+,drop index `idx_app_user_removed_login_id`;
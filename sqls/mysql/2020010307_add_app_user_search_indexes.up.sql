@@ -0,0 +1,7 @@
+alter table `app_user`
+ add index `idx_app_user_username` (`organization_id`, `removed`, `username`, `id`)
+,add index `idx_app_user_created_at` (`organization_id`, `removed`, `created_at`, `id`)
+,add index `idx_app_user_provider` (`organization_id`, `provider`)
+,add index `idx_app_user_removed_login_id` (`organization_id`, `removed_login_id`);
+alter table `user_n_group`
+ add index `idx_user_n_group_user_group` (`organization_id`, `user_group_id`, `app_user_id`);
@@ -0,0 +1,2 @@
+-- casbin_rule is not dropped because it may have been created by the casbin adapter before this migration and holds all the policies
+select 1;
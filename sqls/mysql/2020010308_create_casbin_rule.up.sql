@@ -0,0 +1,12 @@
+create table if not exists `casbin_rule` (
+ `id` bigint unsigned auto_increment
+,`ptype` varchar(100)
+,`v0` varchar(100)
+,`v1` varchar(100)
+,`v2` varchar(100)
+,`v3` varchar(100)
+,`v4` varchar(100)
+,`v5` varchar(100)
+,primary key(`id`)
+,unique index `idx_casbin_rule` (`ptype`, `v0`, `v1`, `v2`, `v3`, `v4`, `v5`)
+);
@@ -0,0 +1,6 @@
+create table casbin_policy_revision (
+ domain varchar(40) not null
+,revision bigint not null
+,updated_at timestamp not null default current_timestamp
+,primary key(domain)
+);
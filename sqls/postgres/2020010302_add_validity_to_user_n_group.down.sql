@@ -0,0 +1,3 @@
+drop index idx_user_n_group_valid_until;
+alter table user_n_group drop column valid_until;
+alter table user_n_group drop column valid_from;
@@ -0,0 +1,15 @@
+create table rbac_expired_grant (
+ id serial not null
+,expired_at timestamp not null
+,domain varchar(40) not null
+,ptype varchar(100) not null
+,v0 varchar(100) not null
+,v1 varchar(100) not null
+,v2 varchar(100) not null
+,v3 varchar(100) not null
+,v4 varchar(100) not null
+,valid_from timestamp null
+,valid_until timestamp not null
+,primary key(id)
+);
+create index idx_rbac_expired_grant_domain on rbac_expired_grant(domain, expired_at);
@@ -0,0 +1,17 @@
+create table audit_event (
+ id serial not null
+,occurred_at timestamp not null
+,actor_id int not null
+,organization_id int not null
+,action varchar(40) not null
+,target_type varchar(20) not null
+,target_id varchar(200) not null
+,before_json text null
+,after_json text null
+,request_id varchar(64) not null
+,trace_id varchar(32) not null
+,primary key(id)
+);
+create index idx_audit_event_occurred_at on audit_event(organization_id, occurred_at);
+create index idx_audit_event_actor on audit_event(organization_id, actor_id, occurred_at);
+create index idx_audit_event_target on audit_event(organization_id, target_type, target_id, occurred_at);
@@ -0,0 +1 @@
+drop table group_n_group;
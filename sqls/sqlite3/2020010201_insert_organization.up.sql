@@ -0,0 +1,2 @@
+insert into organization (created_by, updated_by, name) values
+(0, 0, 'system');
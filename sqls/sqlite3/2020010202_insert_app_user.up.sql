@@ -0,0 +1,2 @@
+insert into app_user (created_by, updated_by, organization_id, login_id, hashed_password, username, removed) values
+(0, 0, 1, '__system_admin', '', 'Administrator', 0);
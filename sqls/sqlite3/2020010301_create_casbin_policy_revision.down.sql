@@ -0,0 +1 @@
+drop table casbin_policy_revision;
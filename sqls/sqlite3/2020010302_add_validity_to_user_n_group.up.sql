@@ -0,0 +1,3 @@
+alter table user_n_group add column valid_from datetime null;
+alter table user_n_group add column valid_until datetime null;
+create index idx_user_n_group_valid_until on user_n_group(valid_until);
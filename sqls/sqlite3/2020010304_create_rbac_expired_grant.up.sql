@@ -0,0 +1,14 @@
+create table rbac_expired_grant (
+ id integer primary key autoincrement
+,expired_at datetime not null
+,domain varchar(40) not null
+,ptype varchar(100) not null
+,v0 varchar(100) not null
+,v1 varchar(100) not null
+,v2 varchar(100) not null
+,v3 varchar(100) not null
+,v4 varchar(100) not null
+,valid_from datetime null
+,valid_until datetime not null
+);
+create index idx_rbac_expired_grant_domain on rbac_expired_grant(domain, expired_at);
@@ -0,0 +1 @@
+alter table app_user add column removed_login_id varchar(200) null;
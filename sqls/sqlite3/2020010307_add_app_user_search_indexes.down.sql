@@ -0,0 +1,5 @@
+drop index idx_app_user_username;
+drop index idx_app_user_created_at;
+drop index idx_app_user_provider;
+drop index idx_app_user_removed_login_id;
+drop index idx_user_n_group_user_group;
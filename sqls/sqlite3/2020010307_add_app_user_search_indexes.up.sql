@@ -0,0 +1,5 @@
+create index idx_app_user_username on app_user(organization_id, removed, username, id);
+create index idx_app_user_created_at on app_user(organization_id, removed, created_at, id);
+create index idx_app_user_provider on app_user(organization_id, provider);
+create index idx_app_user_removed_login_id on app_user(organization_id, removed_login_id);
+create index idx_user_n_group_user_group on user_n_group(organization_id, user_group_id, app_user_id);
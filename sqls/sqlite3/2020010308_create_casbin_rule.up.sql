@@ -0,0 +1,2 @@
+create table if not exists `casbin_rule` (`id` integer primary key autoincrement, `ptype` text, `v0` text, `v1` text, `v2` text, `v3` text, `v4` text, `v5` text);
+create unique index if not exists `idx_casbin_rule` on `casbin_rule`(`ptype`, `v0`, `v1`, `v2`, `v3`, `v4`, `v5`);
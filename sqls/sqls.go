@@ -7,6 +7,5 @@ import (
 
 //go:embed mysql/*.sql
 //go:embed postgres/*.sql
+//go:embed sqlite3/*.sql
 var SQL embed.FS
-
-////go:embed sqlite3/*.sql
@@ -16,25 +16,32 @@ import (
 
 func ListDB() map[libgateway.DialectRDBMS]*gorm.DB {
 	list := make(map[libgateway.DialectRDBMS]*gorm.DB)
-	m, err := openMySQLForTest()
-	if err != nil {
-		panic(err)
+	if testDBHost != "" {
+		m, err := openMySQLForTest()
+		if err != nil {
+			panic(err)
+		}
+		mysql := libgateway.DialectMySQL{}
+		list[&mysql] = m
 	}
-	mysql := libgateway.DialectMySQL{}
-	list[&mysql] = m
 
-	p, err := openPostgresForTest()
-	if err != nil {
-		panic(err)
+	if testPostgresHost != "" {
+		p, err := openPostgresForTest()
+		if err != nil {
+			panic(err)
+		}
+		postgres := libgateway.DialectPostgres{}
+		list[&postgres] = p
 	}
-	postgres := libgateway.DialectPostgres{}
-	list[&postgres] = p
 
-	// s, err := openSQLiteForTest()
-	// if err != nil {
-	// 	panic(err)
-	// }
-	// list["sqlite3"] = s
+	if testDBFile != "" {
+		s, err := openSQLiteForTest()
+		if err != nil {
+			panic(err)
+		}
+		sqlite := libgateway.DialectSQLite{}
+		list[&sqlite] = s
+	}
 
 	return list
 }
@@ -16,6 +16,7 @@ import (
 	"gorm.io/gorm"
 
 	liberrors "github.com/kujilabo/redstart/lib/errors"
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
 )
 
 var testPostgresHost string
@@ -33,6 +34,9 @@ func openPostgresForTest() (*gorm.DB, error) {
 	if err != nil {
 		return nil, liberrors.Errorf("gorm.Open. err: %w", err)
 	}
+	if err := db.Use(libgateway.NewAuditColumnsPlugin()); err != nil {
+		return nil, liberrors.Errorf("db.Use. err: %w", err)
+	}
 	return db, nil
 }
 
@@ -14,18 +14,27 @@ import (
 	slog_gorm "github.com/orandin/slog-gorm"
 	gormSQLite "gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
 )
 
 var testDBFile string
 
 func openSQLiteForTest() (*gorm.DB, error) {
 	logger := slog.Default()
-	return gorm.Open(gormSQLite.Open(testDBFile), &gorm.Config{
+	db, err := gorm.Open(gormSQLite.Open(libgateway.SQLiteDSN(testDBFile)), &gorm.Config{
 		Logger: slog_gorm.New(
 			slog_gorm.WithLogger(logger), // Optional, use slog.Default() by default
-			slog_gorm.WithTraceAll(),     // trace all messages
+			// slog_gorm.WithTraceAll(),     // trace all messages
 		),
 	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(libgateway.NewAuditColumnsPlugin()); err != nil {
+		return nil, err
+	}
+	return db, nil
 }
 
 // func OpenSQLiteInMemory(sqlFS embed.FS) (*gorm.DB, error) {
@@ -0,0 +1,378 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+type OrganizationResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type AppUserResponse struct {
+	ID             int    `json:"id"`
+	OrganizationID int    `json:"organizationId"`
+	LoginID        string `json:"loginId"`
+	Username       string `json:"username"`
+}
+
+// AppUserListItemResponse is the app user in the list with the version, which is used in the If-Match header
+type AppUserListItemResponse struct {
+	AppUserResponse
+	Version int `json:"version"`
+}
+
+type AppUsersResponse struct {
+	AppUsers   []*AppUserListItemResponse `json:"appUsers"`
+	TotalCount int                        `json:"totalCount"`
+	NextCursor string                     `json:"nextCursor,omitempty"`
+}
+
+type AppUserAddRequest struct {
+	LoginID  string `json:"loginId" binding:"required"`
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password"`
+}
+
+type AppUserUpdateRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type AppUserPasswordChangeRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type IDResponse struct {
+	ID int `json:"id"`
+}
+
+func toOrganizationResponse(organization *service.Organization) *OrganizationResponse {
+	return &OrganizationResponse{
+		ID:   organization.OrganizationID().Int(),
+		Name: organization.Name(),
+	}
+}
+
+func toAppUserResponse(appUser service.AppUserInterface) *AppUserResponse {
+	return &AppUserResponse{
+		ID:             appUser.AppUserID().Int(),
+		OrganizationID: appUser.OrganizationID().Int(),
+		LoginID:        appUser.LoginID(),
+		Username:       appUser.Username(),
+	}
+}
+
+func toAppUsersResponse(result *service.AppUserSearchResult) *AppUsersResponse {
+	resp := AppUsersResponse{
+		AppUsers:   make([]*AppUserListItemResponse, len(result.AppUsers)),
+		TotalCount: result.TotalCount,
+		NextCursor: result.NextCursor,
+	}
+	for i, appUser := range result.AppUsers {
+		resp.AppUsers[i] = &AppUserListItemResponse{
+			AppUserResponse: *toAppUserResponse(appUser),
+			Version:         appUser.Version,
+		}
+	}
+	return &resp
+}
+
+// appUserSearchConditionQuery returns the condition in the query parameters, which are the filters "loginIdPrefix", "username",
+// "userGroupId", "provider" and "removed", the order "sort" and "order", and the page "pageNo", "pageSize" or "cursor"
+func appUserSearchConditionQuery(c *gin.Context) (*service.AppUserSearchCondition, error) {
+	filter := service.AppUserFilter{
+		LoginIDPrefix:    c.Query("loginIdPrefix"),
+		UsernameContains: c.Query("username"),
+		Provider:         c.Query("provider"),
+	}
+
+	userGroupIDValue, err := intQuery(c, "userGroupId")
+	if err != nil {
+		return nil, err
+	}
+	if userGroupIDValue != 0 {
+		userGroupID, err := domain.NewUserGroupID(userGroupIDValue)
+		if err != nil {
+			return nil, liberrors.Errorf("domain.NewUserGroupID. err: %w", err)
+		}
+		filter.UserGroupID = userGroupID
+	}
+
+	removed, err := boolQuery(c, "removed")
+	if err != nil {
+		return nil, err
+	}
+	filter.Removed = removed
+
+	pageNo, err := intQuery(c, "pageNo")
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize, err := intQuery(c, "pageSize")
+	if err != nil {
+		return nil, err
+	}
+
+	condition, err := service.NewAppUserSearchCondition(filter, service.AppUserSortKey(c.Query("sort")), service.SortOrder(c.Query("order")), pageNo, pageSize, c.Query("cursor"))
+	if err != nil {
+		return nil, liberrors.Errorf("service.NewAppUserSearchCondition. err: %w", err)
+	}
+
+	return condition, nil
+}
+
+type AppUserHandler struct {
+	appUserUsecase usecase.AppUserUsecase
+}
+
+func NewAppUserHandler(appUserUsecase usecase.AppUserUsecase) *AppUserHandler {
+	return &AppUserHandler{
+		appUserUsecase: appUserUsecase,
+	}
+}
+
+// GetOrganization returns the organization of the operator
+func (h *AppUserHandler) GetOrganization(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOperator(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	organization, err := h.appUserUsecase.GetOrganization(ctx, operator)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.appUserUsecase.GetOrganization. err: %w", err))
+		return
+	}
+
+	setETag(c, organization.BaseModel)
+	c.JSON(http.StatusOK, toOrganizationResponse(organization))
+}
+
+// GetMe returns the operator
+func (h *AppUserHandler) GetMe(c *gin.Context) {
+	operator, err := GetOperator(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toAppUserResponse(operator))
+}
+
+func (h *AppUserHandler) FindAppUserByID(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOperator(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUser, err := h.appUserUsecase.FindAppUserByID(ctx, operator, appUserID)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.appUserUsecase.FindAppUserByID. err: %w", err))
+		return
+	}
+
+	setETag(c, appUser.BaseModel)
+	c.JSON(http.StatusOK, toAppUserResponse(appUser))
+}
+
+// FindAppUsers returns the page of the app users in the organization of the operator filtered by the query parameters
+func (h *AppUserHandler) FindAppUsers(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	condition, err := appUserSearchConditionQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	result, err := h.appUserUsecase.FindAppUsers(ctx, operator, condition)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.appUserUsecase.FindAppUsers. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toAppUsersResponse(result))
+}
+
+func (h *AppUserHandler) AddAppUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	req := AppUserAddRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	param, err := service.NewAppUserAddParameter(req.LoginID, req.Username, req.Password, "", "", "", "")
+	if err != nil {
+		handleError(c, liberrors.Errorf("service.NewAppUserAddParameter. err: %w", err))
+		return
+	}
+
+	appUserID, err := h.appUserUsecase.AddAppUser(ctx, operator, param)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.appUserUsecase.AddAppUser. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, IDResponse{ID: appUserID.Int()})
+}
+
+// UpdateAppUser updates the app user whose version is the one in the If-Match header
+func (h *AppUserHandler) UpdateAppUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	req := AppUserUpdateRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	param, err := service.NewAppUserUpdateParameter(req.Username)
+	if err != nil {
+		handleError(c, liberrors.Errorf("service.NewAppUserUpdateParameter. err: %w", err))
+		return
+	}
+
+	appUser, err := h.appUserUsecase.UpdateAppUser(ctx, operator, appUserID, version, param)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.appUserUsecase.UpdateAppUser. err: %w", err))
+		return
+	}
+
+	setETag(c, appUser.BaseModel)
+	c.JSON(http.StatusOK, toAppUserResponse(appUser))
+}
+
+func (h *AppUserHandler) ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	req := AppUserPasswordChangeRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	if err := h.appUserUsecase.ChangePassword(ctx, operator, appUserID, req.Password); err != nil {
+		handleError(c, liberrors.Errorf("h.appUserUsecase.ChangePassword. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveAppUser removes the app user whose version is in the If-Match header
+func (h *AppUserHandler) RemoveAppUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.appUserUsecase.RemoveAppUser(ctx, operator, appUserID, version); err != nil {
+		handleError(c, liberrors.Errorf("h.appUserUsecase.RemoveAppUser. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreAppUser restores the removed app user whose version is in the If-Match header
+func (h *AppUserHandler) RestoreAppUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUser, err := h.appUserUsecase.RestoreAppUser(ctx, operator, appUserID, version)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.appUserUsecase.RestoreAppUser. err: %w", err))
+		return
+	}
+
+	setETag(c, appUser.BaseModel)
+	c.JSON(http.StatusOK, toAppUserResponse(appUser))
+}
@@ -0,0 +1,376 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type fakeAppUserUsecase struct {
+	appUsers  map[int]*service.AppUser
+	removed   map[int]*service.AppUser
+	passwords map[int]string
+	addErr    error
+	added     []service.AppUserAddParameterInterface
+	condition *service.AppUserSearchCondition
+}
+
+func (u *fakeAppUserUsecase) GetOrganization(ctx context.Context, operator service.AppUserInterface) (*service.Organization, error) {
+	baseModel, err := libdomain.NewBaseModel(1, time.Now(), time.Now(), 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	orgModel, err := domain.NewOrganizationModel(baseModel, operator.OrganizationID(), "ORG")
+	if err != nil {
+		return nil, err
+	}
+	return service.NewOrganization(orgModel)
+}
+
+func (u *fakeAppUserUsecase) FindAppUserByID(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID) (*service.AppUser, error) {
+	appUser, ok := u.appUsers[appUserID.Int()]
+	if !ok {
+		return nil, liberrors.Errorf("FindAppUserByID. err: %w", service.ErrAppUserNotFound)
+	}
+	return appUser, nil
+}
+
+func (u *fakeAppUserUsecase) FindAppUsers(ctx context.Context, operator service.OwnerModelInterface, condition *service.AppUserSearchCondition) (*service.AppUserSearchResult, error) {
+	u.condition = condition
+	appUsers := u.appUsers
+	if condition.Filter.Removed {
+		appUsers = u.removed
+	}
+	result := service.AppUserSearchResult{TotalCount: len(appUsers), NextCursor: "NEXT"}
+	for _, appUser := range appUsers {
+		result.AppUsers = append(result.AppUsers, appUser)
+	}
+	return &result, nil
+}
+
+func (u *fakeAppUserUsecase) AddAppUser(ctx context.Context, operator service.OwnerModelInterface, param service.AppUserAddParameterInterface) (*domain.AppUserID, error) {
+	if u.addErr != nil {
+		return nil, u.addErr
+	}
+	u.added = append(u.added, param)
+	return domain.NewAppUserID(10)
+}
+
+func (u *fakeAppUserUsecase) UpdateAppUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, version int, param service.AppUserUpdateParameterInterface) (*service.AppUser, error) {
+	appUser, ok := u.appUsers[appUserID.Int()]
+	if !ok {
+		return nil, liberrors.Errorf("UpdateAppUser. err: %w", service.ErrAppUserNotFound)
+	}
+	if appUser.Version != version {
+		return nil, liberrors.Errorf("UpdateAppUser. err: %w", libdomain.ErrConcurrentModification)
+	}
+	baseModel, err := libdomain.NewBaseModel(version+1, appUser.CreatedAt, time.Now(), appUser.CreatedBy, operator.AppUserID().Int())
+	if err != nil {
+		return nil, err
+	}
+	appUserModel, err := domain.NewAppUserModel(baseModel, appUser.AppUserModel.AppUserID, appUser.AppUserModel.OrganizationID, appUser.AppUserModel.LoginID, param.Username(), nil)
+	if err != nil {
+		return nil, err
+	}
+	u.appUsers[appUserID.Int()] = &service.AppUser{AppUserModel: appUserModel}
+	return u.appUsers[appUserID.Int()], nil
+}
+
+func (u *fakeAppUserUsecase) ChangePassword(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, password string) error {
+	if _, ok := u.appUsers[appUserID.Int()]; !ok {
+		return liberrors.Errorf("ChangePassword. err: %w", service.ErrAppUserNotFound)
+	}
+	u.passwords[appUserID.Int()] = password
+	return nil
+}
+
+func (u *fakeAppUserUsecase) RemoveAppUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, version int) error {
+	appUser, ok := u.appUsers[appUserID.Int()]
+	if !ok {
+		return liberrors.Errorf("RemoveAppUser. err: %w", service.ErrAppUserNotFound)
+	}
+	if appUser.Version != version {
+		return liberrors.Errorf("RemoveAppUser. err: %w", libdomain.ErrConcurrentModification)
+	}
+	appUser.Version++
+	u.removed[appUserID.Int()] = appUser
+	delete(u.appUsers, appUserID.Int())
+	return nil
+}
+
+func (u *fakeAppUserUsecase) RestoreAppUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, version int) (*service.AppUser, error) {
+	appUser, ok := u.removed[appUserID.Int()]
+	if !ok {
+		return nil, liberrors.Errorf("RestoreAppUser. err: %w", service.ErrAppUserNotFound)
+	}
+	if appUser.Version != version {
+		return nil, liberrors.Errorf("RestoreAppUser. err: %w", libdomain.ErrConcurrentModification)
+	}
+	appUser.Version++
+	u.appUsers[appUserID.Int()] = appUser
+	delete(u.removed, appUserID.Int())
+	return appUser, nil
+}
+
+func newAppUserRouter(operator service.AppUserInterface, usecase *fakeAppUserUsecase) *gin.Engine {
+	return newTestRouter(operator, func(router gin.IRouter, middleware gin.HandlerFunc) {
+		controller.InitRouterGroup(router, controller.NewAppUserHandler(usecase), controller.NewUserGroupHandler(nil), controller.NewPolicyHandler(nil), middleware)
+	})
+}
+
+func TestAppUserHandler_FindAppUserByID(t *testing.T) {
+	t.Parallel()
+	appUser := &service.AppUser{AppUserModel: newTestAppUserModel(t, 5, 1, "USER5")}
+	usecase := &fakeAppUserUsecase{appUsers: map[int]*service.AppUser{5: appUser}}
+
+	tests := []struct {
+		name     string
+		operator service.AppUserInterface
+		path     string
+		status   int
+		code     string
+	}{
+		{name: "found", operator: newTestAppUser(t), path: "/v1/user/5", status: http.StatusOK},
+		{name: "not found", operator: newTestAppUser(t), path: "/v1/user/6", status: http.StatusNotFound, code: "not_found"},
+		{name: "invalid ID", operator: newTestAppUser(t), path: "/v1/user/abc", status: http.StatusBadRequest, code: "invalid_argument"},
+		{name: "unauthenticated", operator: nil, path: "/v1/user/5", status: http.StatusUnauthorized, code: "unauthenticated"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			status, body := doRequest(t, newAppUserRouter(tt.operator, usecase), http.MethodGet, tt.path, nil)
+			if tt.code != "" {
+				assertErrorResponse(t, tt.status, tt.code, status, body)
+				return
+			}
+
+			require.Equal(t, tt.status, status)
+			resp := controller.AppUserResponse{}
+			require.NoError(t, json.Unmarshal(body, &resp))
+			assert.Equal(t, controller.AppUserResponse{ID: 5, OrganizationID: 1, LoginID: "USER5", Username: "USER5_NAME"}, resp)
+		})
+	}
+}
+
+func TestAppUserHandler_FindAppUsers(t *testing.T) {
+	t.Parallel()
+	appUser := &service.AppUser{AppUserModel: newTestAppUserModel(t, 5, 1, "USER5")}
+	usecase := &fakeAppUserUsecase{appUsers: map[int]*service.AppUser{5: appUser}, removed: map[int]*service.AppUser{}}
+
+	status, body := doRequest(t, newAppUserRouter(newTestOwner(t), usecase), http.MethodGet, "/v1/user", nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	resp := controller.AppUsersResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Len(t, resp.AppUsers, 1)
+	assert.Equal(t, 5, resp.AppUsers[0].ID)
+	assert.Equal(t, "USER5", resp.AppUsers[0].LoginID)
+	assert.Equal(t, 1, resp.AppUsers[0].Version)
+	assert.Equal(t, 1, resp.TotalCount)
+	assert.Equal(t, "NEXT", resp.NextCursor)
+	// default condition
+	assert.Equal(t, service.AppUserSortKeyID, usecase.condition.SortKey)
+	assert.Equal(t, service.SortOrderAsc, usecase.condition.SortOrder)
+	assert.Equal(t, 1, usecase.condition.PageNo)
+	assert.Equal(t, service.DefaultAppUserPageSize, usecase.condition.PageSize)
+
+	status, body = doRequest(t, newAppUserRouter(newTestOwner(t), usecase), http.MethodGet, "/v1/user?loginIdPrefix=US&username=er&userGroupId=7&provider=google&removed=true&sort=username&order=desc&pageNo=2&pageSize=10", nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	resp = controller.AppUsersResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Len(t, resp.AppUsers, 0)
+	assert.Equal(t, "US", usecase.condition.Filter.LoginIDPrefix)
+	assert.Equal(t, "er", usecase.condition.Filter.UsernameContains)
+	assert.Equal(t, 7, usecase.condition.Filter.UserGroupID.Int())
+	assert.Equal(t, "google", usecase.condition.Filter.Provider)
+	assert.True(t, usecase.condition.Filter.Removed)
+	assert.Equal(t, service.AppUserSortKeyUsername, usecase.condition.SortKey)
+	assert.Equal(t, service.SortOrderDesc, usecase.condition.SortOrder)
+	assert.Equal(t, 2, usecase.condition.PageNo)
+	assert.Equal(t, 10, usecase.condition.PageSize)
+
+	status, body = doRequest(t, newAppUserRouter(newTestOwner(t), usecase), http.MethodGet, "/v1/user?cursor=CURSOR", nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, "CURSOR", usecase.condition.Cursor)
+
+	invalidQueries := []string{
+		"sort=password",
+		"order=random",
+		"pageNo=0",
+		"pageSize=1001",
+		"pageNo=2&cursor=CURSOR",
+		"userGroupId=abc",
+		"removed=maybe",
+	}
+	for _, query := range invalidQueries {
+		status, body = doRequest(t, newAppUserRouter(newTestOwner(t), usecase), http.MethodGet, "/v1/user?"+query, nil)
+		assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+	}
+
+	status, body = doRequest(t, newAppUserRouter(newTestAppUser(t), usecase), http.MethodGet, "/v1/user", nil)
+	assertErrorResponse(t, http.StatusForbidden, "permission_denied", status, body)
+}
+
+func TestAppUserHandler_GetOrganization(t *testing.T) {
+	t.Parallel()
+	status, body := doRequest(t, newAppUserRouter(newTestAppUser(t), &fakeAppUserUsecase{}), http.MethodGet, "/v1/organization", nil)
+	require.Equal(t, http.StatusOK, status)
+	resp := controller.OrganizationResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, controller.OrganizationResponse{ID: 1, Name: "ORG"}, resp)
+}
+
+func TestAppUserHandler_AddAppUser(t *testing.T) {
+	t.Parallel()
+	validBody := map[string]string{"loginId": "LOGIN_ID", "username": "USERNAME", "password": "PASSWORD"}
+
+	t.Run("created", func(t *testing.T) {
+		t.Parallel()
+		usecase := &fakeAppUserUsecase{}
+		status, body := doRequest(t, newAppUserRouter(newTestOwner(t), usecase), http.MethodPost, "/v1/user", validBody)
+		require.Equal(t, http.StatusCreated, status)
+		resp := controller.IDResponse{}
+		require.NoError(t, json.Unmarshal(body, &resp))
+		assert.Equal(t, 10, resp.ID)
+		require.Len(t, usecase.added, 1)
+		assert.Equal(t, "LOGIN_ID", usecase.added[0].LoginID())
+	})
+
+	tests := []struct {
+		name     string
+		operator service.AppUserInterface
+		addErr   error
+		body     interface{}
+		status   int
+		code     string
+	}{
+		{name: "not owner", operator: newTestAppUser(t), body: validBody, status: http.StatusForbidden, code: "permission_denied"},
+		{name: "invalid body", operator: newTestOwner(t), body: map[string]string{"loginId": "LOGIN_ID"}, status: http.StatusBadRequest, code: "invalid_argument"},
+		{name: "already exists", operator: newTestOwner(t), addErr: liberrors.Errorf("AddAppUser. err: %w", service.ErrAppUserAlreadyExists), body: validBody, status: http.StatusConflict, code: "already_exists"},
+		{name: "internal error", operator: newTestOwner(t), addErr: errors.New("connection refused"), body: validBody, status: http.StatusInternalServerError, code: "internal_error"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := &fakeAppUserUsecase{addErr: tt.addErr}
+			status, body := doRequest(t, newAppUserRouter(tt.operator, usecase), http.MethodPost, "/v1/user", tt.body)
+			assertErrorResponse(t, tt.status, tt.code, status, body)
+			assert.NotContains(t, string(body), "connection refused")
+			assert.Empty(t, usecase.added)
+		})
+	}
+}
+
+func TestAppUserHandler_UpdateAppUser(t *testing.T) {
+	t.Parallel()
+	appUser := &service.AppUser{AppUserModel: newTestAppUserModel(t, 5, 1, "USER5")}
+	usecase := &fakeAppUserUsecase{appUsers: map[int]*service.AppUser{5: appUser}}
+	router := newAppUserRouter(newTestOwner(t), usecase)
+
+	status, header, _ := doRequestWithHeader(t, router, http.MethodGet, "/v1/user/5", nil, nil)
+	require.Equal(t, http.StatusOK, status)
+	etag := header.Get("ETag")
+	assert.Equal(t, `"1"`, etag)
+
+	// - the version in If-Match is the current one
+	status, header, body := doRequestWithHeader(t, router, http.MethodPut, "/v1/user/5", map[string]string{"If-Match": etag}, map[string]string{"username": "NEW_NAME"})
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, `"2"`, header.Get("ETag"))
+	resp := controller.AppUserResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, "NEW_NAME", resp.Username)
+
+	// - the app user has been updated since the ETag was returned
+	status, _, body = doRequestWithHeader(t, router, http.MethodPut, "/v1/user/5", map[string]string{"If-Match": etag}, map[string]string{"username": "OTHER_NAME"})
+	assertErrorResponse(t, http.StatusConflict, "concurrent_modification", status, body)
+
+	tests := []struct {
+		name     string
+		operator service.AppUserInterface
+		header   map[string]string
+		body     interface{}
+		status   int
+		code     string
+	}{
+		{name: "no If-Match", operator: newTestOwner(t), body: map[string]string{"username": "NAME"}, status: http.StatusPreconditionRequired, code: "precondition_required"},
+		{name: "invalid If-Match", operator: newTestOwner(t), header: map[string]string{"If-Match": `"abc"`}, body: map[string]string{"username": "NAME"}, status: http.StatusBadRequest, code: "invalid_argument"},
+		{name: "no username", operator: newTestOwner(t), header: map[string]string{"If-Match": `"2"`}, body: map[string]string{}, status: http.StatusBadRequest, code: "invalid_argument"},
+		{name: "not owner", operator: newTestAppUser(t), header: map[string]string{"If-Match": `"2"`}, body: map[string]string{"username": "NAME"}, status: http.StatusForbidden, code: "permission_denied"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			status, _, body := doRequestWithHeader(t, newAppUserRouter(tt.operator, &fakeAppUserUsecase{}), http.MethodPut, "/v1/user/5", tt.header, tt.body)
+			assertErrorResponse(t, tt.status, tt.code, status, body)
+		})
+	}
+}
+
+func TestAppUserHandler_ChangePassword(t *testing.T) {
+	t.Parallel()
+	appUser := &service.AppUser{AppUserModel: newTestAppUserModel(t, 5, 1, "USER5")}
+	usecase := &fakeAppUserUsecase{appUsers: map[int]*service.AppUser{5: appUser}, passwords: map[int]string{}}
+
+	status, body := doRequest(t, newAppUserRouter(newTestOwner(t), usecase), http.MethodPut, "/v1/user/5/password", map[string]string{"password": "NEW_PASSWORD"})
+	require.Equal(t, http.StatusNoContent, status, string(body))
+	assert.Equal(t, "NEW_PASSWORD", usecase.passwords[5])
+
+	status, body = doRequest(t, newAppUserRouter(newTestOwner(t), usecase), http.MethodPut, "/v1/user/6/password", map[string]string{"password": "NEW_PASSWORD"})
+	assertErrorResponse(t, http.StatusNotFound, "not_found", status, body)
+
+	status, body = doRequest(t, newAppUserRouter(newTestOwner(t), usecase), http.MethodPut, "/v1/user/5/password", map[string]string{})
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+
+	status, body = doRequest(t, newAppUserRouter(newTestAppUser(t), usecase), http.MethodPut, "/v1/user/5/password", map[string]string{"password": "NEW_PASSWORD"})
+	assertErrorResponse(t, http.StatusForbidden, "permission_denied", status, body)
+}
+
+func TestAppUserHandler_RemoveAndRestoreAppUser(t *testing.T) {
+	t.Parallel()
+	appUser := &service.AppUser{AppUserModel: newTestAppUserModel(t, 5, 1, "USER5")}
+	usecase := &fakeAppUserUsecase{appUsers: map[int]*service.AppUser{5: appUser}, removed: map[int]*service.AppUser{}}
+	router := newAppUserRouter(newTestOwner(t), usecase)
+
+	status, _, body := doRequestWithHeader(t, router, http.MethodDelete, "/v1/user/5", nil, nil)
+	assertErrorResponse(t, http.StatusPreconditionRequired, "precondition_required", status, body)
+
+	status, _, body = doRequestWithHeader(t, router, http.MethodDelete, "/v1/user/5", map[string]string{"If-Match": `"2"`}, nil)
+	assertErrorResponse(t, http.StatusConflict, "concurrent_modification", status, body)
+
+	status, _, body = doRequestWithHeader(t, router, http.MethodDelete, "/v1/user/5", map[string]string{"If-Match": `"1"`}, nil)
+	require.Equal(t, http.StatusNoContent, status, string(body))
+
+	status, body = doRequest(t, router, http.MethodGet, "/v1/user/5", nil)
+	assertErrorResponse(t, http.StatusNotFound, "not_found", status, body)
+
+	status, _, body = doRequestWithHeader(t, router, http.MethodDelete, "/v1/user/5", map[string]string{"If-Match": `"2"`}, nil)
+	assertErrorResponse(t, http.StatusNotFound, "not_found", status, body)
+
+	status, header, body := doRequestWithHeader(t, router, http.MethodPost, "/v1/user/5/restore", map[string]string{"If-Match": `"2"`}, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, `"3"`, header.Get("ETag"))
+	resp := controller.AppUserResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, "USER5", resp.LoginID)
+
+	status, _, body = doRequestWithHeader(t, router, http.MethodPost, "/v1/user/5/restore", map[string]string{"If-Match": `"3"`}, nil)
+	assertErrorResponse(t, http.StatusNotFound, "not_found", status, body)
+
+	status, _, body = doRequestWithHeader(t, newAppUserRouter(newTestAppUser(t), usecase), http.MethodDelete, "/v1/user/5", map[string]string{"If-Match": `"3"`}, nil)
+	assertErrorResponse(t, http.StatusForbidden, "permission_denied", status, body)
+}
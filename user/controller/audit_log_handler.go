@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+type AuditEventResponse struct {
+	ID             int             `json:"id"`
+	OccurredAt     time.Time       `json:"occurredAt"`
+	ActorID        int             `json:"actorId"`
+	OrganizationID int             `json:"organizationId"`
+	Action         string          `json:"action"`
+	TargetType     string          `json:"targetType"`
+	TargetID       string          `json:"targetId"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	RequestID      string          `json:"requestId,omitempty"`
+	TraceID        string          `json:"traceId,omitempty"`
+}
+
+type AuditEventsResponse struct {
+	Events []*AuditEventResponse `json:"events"`
+}
+
+func toAuditEventsResponse(events []*domain.AuditEventModel) *AuditEventsResponse {
+	resp := AuditEventsResponse{Events: make([]*AuditEventResponse, len(events))}
+	for i, e := range events {
+		resp.Events[i] = &AuditEventResponse{
+			ID:             e.ID,
+			OccurredAt:     e.OccurredAt,
+			ActorID:        e.ActorID.Int(),
+			OrganizationID: e.OrganizationID.Int(),
+			Action:         string(e.Action),
+			TargetType:     string(e.Target.Type),
+			TargetID:       e.Target.ID,
+			RequestID:      e.RequestID,
+			TraceID:        e.TraceID,
+		}
+		if e.Before != "" {
+			resp.Events[i].Before = json.RawMessage(e.Before)
+		}
+		if e.After != "" {
+			resp.Events[i].After = json.RawMessage(e.After)
+		}
+	}
+	return &resp
+}
+
+// auditEventSearchConditionQuery returns the condition in the query parameters,
+// which are "from" and "to" in RFC 3339, "actorId", "targetType" with "targetId", and "limit"
+func auditEventSearchConditionQuery(c *gin.Context) (*service.AuditEventSearchCondition, error) {
+	from, err := timeQuery(c, "from")
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := timeQuery(c, "to")
+	if err != nil {
+		return nil, err
+	}
+
+	var actorID *domain.AppUserID
+	actorIDValue, err := intQuery(c, "actorId")
+	if err != nil {
+		return nil, err
+	}
+	if actorIDValue != 0 {
+		tmpActorID, err := domain.NewAppUserID(actorIDValue)
+		if err != nil {
+			return nil, liberrors.Errorf("domain.NewAppUserID. err: %w", err)
+		}
+		actorID = tmpActorID
+	}
+
+	var target *domain.AuditTarget
+	targetType := c.Query("targetType")
+	targetID := c.Query("targetId")
+	if targetType != "" || targetID != "" {
+		if targetType == "" || targetID == "" {
+			return nil, liberrors.Errorf("targetType and targetId must be specified together. err: %w", libdomain.ErrInvalidArgument)
+		}
+		tmpTarget, err := domain.NewAuditTarget(domain.AuditTargetType(targetType), targetID)
+		if err != nil {
+			return nil, liberrors.Errorf("domain.NewAuditTarget. err: %w", err)
+		}
+		target = tmpTarget
+	}
+
+	limit, err := intQuery(c, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	condition, err := service.NewAuditEventSearchCondition(from, to, actorID, target, limit)
+	if err != nil {
+		return nil, liberrors.Errorf("service.NewAuditEventSearchCondition. err: %w", err)
+	}
+
+	return condition, nil
+}
+
+type AuditLogHandler struct {
+	auditLogUsecase usecase.AuditLogUsecase
+}
+
+func NewAuditLogHandler(auditLogUsecase usecase.AuditLogUsecase) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditLogUsecase: auditLogUsecase,
+	}
+}
+
+// FindAuditEvents returns the audit events of the organization of the operator filtered by the query parameters
+func (h *AuditLogHandler) FindAuditEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	condition, err := auditEventSearchConditionQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	events, err := h.auditLogUsecase.FindAuditEvents(ctx, operator, condition)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.auditLogUsecase.FindAuditEvents. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toAuditEventsResponse(events))
+}
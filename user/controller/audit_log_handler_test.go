@@ -0,0 +1,63 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type fakeAuditLogUsecase struct{}
+
+// FindAuditEvents returns the event of the app user added by the operator
+func (u *fakeAuditLogUsecase) FindAuditEvents(ctx context.Context, operator service.OwnerModelInterface, condition *service.AuditEventSearchCondition) ([]*domain.AuditEventModel, error) {
+	target, err := domain.NewAuditTarget(domain.AuditTargetTypeAppUser, "4")
+	if err != nil {
+		return nil, err
+	}
+	return []*domain.AuditEventModel{
+		{
+			ID:             1,
+			ActorID:        operator.AppUserID(),
+			OrganizationID: operator.OrganizationID(),
+			Action:         domain.AuditActionAddAppUser,
+			Target:         target,
+			After:          `{"loginId":"USER","username":"USER_NAME"}`,
+		},
+	}, nil
+}
+
+func newAuditLogRouter(operator service.AppUserInterface) *gin.Engine {
+	return newTestRouter(operator, func(router gin.IRouter, middleware gin.HandlerFunc) {
+		controller.InitAuditLogRouterGroup(router, controller.NewAuditLogHandler(&fakeAuditLogUsecase{}), middleware)
+	})
+}
+
+func TestAuditLogHandler_FindAuditEvents(t *testing.T) {
+	t.Parallel()
+
+	status, body := doRequest(t, newAuditLogRouter(newTestOwner(t)), http.MethodGet, "/v1/audit_event?targetType=app_user&targetId=4", nil)
+	require.Equal(t, http.StatusOK, status)
+	resp := controller.AuditEventsResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, 2, resp.Events[0].ActorID)
+	assert.Equal(t, 1, resp.Events[0].OrganizationID)
+	assert.Equal(t, "app_user.add", resp.Events[0].Action)
+	assert.Empty(t, resp.Events[0].Before)
+	assert.JSONEq(t, `{"loginId":"USER","username":"USER_NAME"}`, string(resp.Events[0].After))
+
+	status, body = doRequest(t, newAuditLogRouter(newTestOwner(t)), http.MethodGet, "/v1/audit_event?limit=x", nil)
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+
+	status, body = doRequest(t, newAuditLogRouter(newTestAppUser(t)), http.MethodGet, "/v1/audit_event", nil)
+	assertErrorResponse(t, http.StatusForbidden, "permission_denied", status, body)
+}
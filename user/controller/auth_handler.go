@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/service"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+type PasswordAuthRequest struct {
+	OrganizationName string `json:"organizationName" binding:"required"`
+	LoginID          string `json:"loginId" binding:"required"`
+	Password         string `json:"password" binding:"required"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type AuthResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+type AuthHandler struct {
+	authenticationUsecase usecase.AuthenticationUsecase
+}
+
+func NewAuthHandler(authenticationUsecase usecase.AuthenticationUsecase) *AuthHandler {
+	return &AuthHandler{
+		authenticationUsecase: authenticationUsecase,
+	}
+}
+
+func (h *AuthHandler) PasswordAuth(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	req := PasswordAuthRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	tokenSet, err := h.authenticationUsecase.Authenticate(ctx, req.OrganizationName, req.LoginID, req.Password)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.authenticationUsecase.Authenticate. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{
+		AccessToken:  tokenSet.AccessToken,
+		RefreshToken: tokenSet.RefreshToken,
+	})
+}
+
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	req := RefreshTokenRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	accessToken, err := h.authenticationUsecase.RefreshAccessToken(ctx, req.RefreshToken)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.authenticationUsecase.RefreshAccessToken. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthResponse{AccessToken: accessToken})
+}
+
+// NewAuthMiddleware returns a middleware which resolves the bearer token into the operator
+func NewAuthMiddleware(authenticationUsecase usecase.AuthenticationUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		authorization := c.GetHeader("Authorization")
+		accessToken, ok := strings.CutPrefix(authorization, "Bearer ")
+		if !ok || accessToken == "" {
+			handleError(c, liberrors.Errorf("bearer token not found. err: %w", service.ErrUnauthenticated))
+			c.Abort()
+			return
+		}
+
+		operator, err := authenticationUsecase.GetOperator(ctx, accessToken)
+		if err != nil {
+			handleError(c, liberrors.Errorf("authenticationUsecase.GetOperator. err: %w", err))
+			c.Abort()
+			return
+		}
+
+		SetOperator(c, operator)
+		c.Next()
+	}
+}
@@ -0,0 +1,120 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type fakeAuthenticationUsecase struct {
+	operators map[string]service.AppUserInterface
+}
+
+func (u *fakeAuthenticationUsecase) Authenticate(ctx context.Context, organizationName, loginID, password string) (*service.TokenSet, error) {
+	if organizationName != "ORG" || loginID != "OWNER" || password != "PASSWORD" {
+		return nil, liberrors.Errorf("Authenticate. err: %w", service.ErrUnauthenticated)
+	}
+	return &service.TokenSet{AccessToken: "ACCESS_TOKEN", RefreshToken: "REFRESH_TOKEN"}, nil
+}
+
+func (u *fakeAuthenticationUsecase) RefreshAccessToken(ctx context.Context, refreshToken string) (string, error) {
+	if refreshToken != "REFRESH_TOKEN" {
+		return "", liberrors.Errorf("RefreshAccessToken. err: %w", service.ErrUnauthenticated)
+	}
+	return "NEW_ACCESS_TOKEN", nil
+}
+
+func (u *fakeAuthenticationUsecase) GetOperator(ctx context.Context, accessToken string) (service.AppUserInterface, error) {
+	operator, ok := u.operators[accessToken]
+	if !ok {
+		return nil, liberrors.Errorf("GetOperator. err: %w", service.ErrUnauthenticated)
+	}
+	return operator, nil
+}
+
+func TestAuthHandler_PasswordAuth(t *testing.T) {
+	t.Parallel()
+	router := gin.New()
+	controller.InitAuthRouterGroup(router, controller.NewAuthHandler(&fakeAuthenticationUsecase{}))
+
+	status, body := doRequest(t, router, http.MethodPost, "/v1/auth/password", map[string]string{"organizationName": "ORG", "loginId": "OWNER", "password": "PASSWORD"})
+	require.Equal(t, http.StatusOK, status)
+	resp := controller.AuthResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, controller.AuthResponse{AccessToken: "ACCESS_TOKEN", RefreshToken: "REFRESH_TOKEN"}, resp)
+
+	status, body = doRequest(t, router, http.MethodPost, "/v1/auth/password", map[string]string{"organizationName": "ORG", "loginId": "OWNER", "password": "WRONG"})
+	assertErrorResponse(t, http.StatusUnauthorized, "unauthenticated", status, body)
+
+	status, body = doRequest(t, router, http.MethodPost, "/v1/auth/password", map[string]string{"organizationName": "ORG", "loginId": "OWNER"})
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+}
+
+func TestAuthHandler_RefreshToken(t *testing.T) {
+	t.Parallel()
+	router := gin.New()
+	controller.InitAuthRouterGroup(router, controller.NewAuthHandler(&fakeAuthenticationUsecase{}))
+
+	status, body := doRequest(t, router, http.MethodPost, "/v1/auth/refresh", map[string]string{"refreshToken": "REFRESH_TOKEN"})
+	require.Equal(t, http.StatusOK, status)
+	resp := controller.AuthResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, controller.AuthResponse{AccessToken: "NEW_ACCESS_TOKEN"}, resp)
+
+	status, body = doRequest(t, router, http.MethodPost, "/v1/auth/refresh", map[string]string{"refreshToken": "ACCESS_TOKEN"})
+	assertErrorResponse(t, http.StatusUnauthorized, "unauthenticated", status, body)
+}
+
+func TestNewAuthMiddleware(t *testing.T) {
+	t.Parallel()
+	usecase := &fakeAuthenticationUsecase{operators: map[string]service.AppUserInterface{
+		"OWNER_TOKEN": newTestOwner(t),
+		"USER_TOKEN":  newTestAppUser(t),
+	}}
+	router := gin.New()
+	controller.InitRouterGroup(router, controller.NewAppUserHandler(nil), controller.NewUserGroupHandler(nil), controller.NewPolicyHandler(nil), controller.NewAuthMiddleware(usecase))
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+		loginID       string
+	}{
+		{name: "owner", authorization: "Bearer OWNER_TOKEN", status: http.StatusOK, loginID: "OWNER"},
+		{name: "user", authorization: "Bearer USER_TOKEN", status: http.StatusOK, loginID: "USER"},
+		{name: "unknown token", authorization: "Bearer UNKNOWN", status: http.StatusUnauthorized},
+		{name: "no scheme", authorization: "OWNER_TOKEN", status: http.StatusUnauthorized},
+		{name: "no header", authorization: "", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, err := http.NewRequest(http.MethodGet, "/v1/me", nil)
+			require.NoError(t, err)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.status, w.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+			resp := controller.AppUserResponse{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.loginID, resp.LoginID)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+const callerKey = "redstart.caller"
+
+var (
+	authorizeDecisionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "redstart",
+		Name:      "authorize_decisions_total",
+		Help:      "The number of the permission checks by the caller and the decision",
+	}, []string{"caller", "decision"})
+	authorizeDurationSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "redstart",
+		Name:      "authorize_duration_seconds",
+		Help:      "The latency of the permission check endpoints",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"endpoint", "status"})
+)
+
+type AuthorizeRequest struct {
+	OrganizationID int                    `json:"organizationId" binding:"required,gte=1"`
+	AppUserID      int                    `json:"appUserId" binding:"required,gte=1"`
+	Action         string                 `json:"action" binding:"required"`
+	Object         string                 `json:"object" binding:"required"`
+	Attributes     map[string]interface{} `json:"attributes"`
+}
+
+type AuthorizeBatchRequest struct {
+	Requests []*AuthorizeRequest `json:"requests" binding:"required,min=1,max=100,dive,required"`
+}
+
+type AuthorizeResponse struct {
+	Allowed bool `json:"allowed"`
+}
+
+type AuthorizeBatchResponse struct {
+	Results []*AuthorizeResponse `json:"results"`
+}
+
+func (r *AuthorizeRequest) toUsecaseRequest() (*usecase.AuthorizeRequest, error) {
+	organizationID, err := domain.NewOrganizationID(r.OrganizationID)
+	if err != nil {
+		return nil, liberrors.Errorf("domain.NewOrganizationID. err: %w", err)
+	}
+
+	appUserID, err := domain.NewAppUserID(r.AppUserID)
+	if err != nil {
+		return nil, liberrors.Errorf("domain.NewAppUserID. err: %w", err)
+	}
+
+	return &usecase.AuthorizeRequest{
+		OrganizationID: organizationID,
+		AppUserID:      appUserID,
+		Action:         domain.NewRBACAction(r.Action),
+		Object:         domain.NewRBACObject(r.Object),
+		Attributes:     r.Attributes,
+	}, nil
+}
+
+// AuthorizeHandler serves the permission checks of the other services.
+// The router group must be protected by NewServiceTokenMiddleware.
+type AuthorizeHandler struct {
+	authorizeUsecase usecase.AuthorizeUsecase
+}
+
+func NewAuthorizeHandler(authorizeUsecase usecase.AuthorizeUsecase) *AuthorizeHandler {
+	return &AuthorizeHandler{
+		authorizeUsecase: authorizeUsecase,
+	}
+}
+
+func (h *AuthorizeHandler) Authorize(c *gin.Context) {
+	ctx := c.Request.Context()
+	defer observeAuthorizeDuration(c, "authorize", time.Now())
+
+	req := AuthorizeRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	usecaseReq, err := req.toUsecaseRequest()
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	allowed, err := h.authorizeUsecase.Authorize(ctx, usecaseReq)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.authorizeUsecase.Authorize. err: %w", err))
+		return
+	}
+
+	countAuthorizeDecisions(c, []bool{allowed})
+	c.JSON(http.StatusOK, AuthorizeResponse{Allowed: allowed})
+}
+
+// AuthorizeBatch checks up to 100 requests. The results are in the order of the requests
+func (h *AuthorizeHandler) AuthorizeBatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	defer observeAuthorizeDuration(c, "authorize_batch", time.Now())
+
+	req := AuthorizeBatchRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	usecaseReqs := make([]*usecase.AuthorizeRequest, len(req.Requests))
+	for i, r := range req.Requests {
+		usecaseReq, err := r.toUsecaseRequest()
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		usecaseReqs[i] = usecaseReq
+	}
+
+	results, err := h.authorizeUsecase.AuthorizeBatch(ctx, usecaseReqs)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.authorizeUsecase.AuthorizeBatch. err: %w", err))
+		return
+	}
+
+	countAuthorizeDecisions(c, results)
+	resp := AuthorizeBatchResponse{Results: make([]*AuthorizeResponse, len(results))}
+	for i, allowed := range results {
+		resp.Results[i] = &AuthorizeResponse{Allowed: allowed}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func observeAuthorizeDuration(c *gin.Context, endpoint string, startedAt time.Time) {
+	authorizeDurationSeconds.WithLabelValues(endpoint, strconv.Itoa(c.Writer.Status())).Observe(time.Since(startedAt).Seconds())
+}
+
+func countAuthorizeDecisions(c *gin.Context, results []bool) {
+	caller := c.GetString(callerKey)
+	for _, allowed := range results {
+		decision := "deny"
+		if allowed {
+			decision = "allow"
+		}
+		authorizeDecisionsTotal.WithLabelValues(caller, decision).Inc()
+	}
+}
+
+// NewServiceTokenMiddleware returns a middleware which accepts the bearer tokens of the services.
+// serviceTokens is keyed by the service names, which are recorded as the callers in the metrics
+func NewServiceTokenMiddleware(serviceTokens map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c)
+		if !ok {
+			handleError(c, liberrors.Errorf("bearer token not found. err: %w", service.ErrUnauthenticated))
+			c.Abort()
+			return
+		}
+
+		caller, ok := findServiceByToken(serviceTokens, token)
+		if !ok {
+			handleError(c, liberrors.Errorf("invalid service token. err: %w", service.ErrUnauthenticated))
+			c.Abort()
+			return
+		}
+
+		c.Set(callerKey, caller)
+		c.Next()
+	}
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return token, ok && token != ""
+}
+
+// findServiceByToken returns the name of the service whose token is the specified one. All the tokens are compared to take the same time
+func findServiceByToken(serviceTokens map[string]string, token string) (string, bool) {
+	caller := ""
+	for name, serviceToken := range serviceTokens {
+		if serviceToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(serviceToken)) == 1 {
+			caller = name
+		}
+	}
+
+	return caller, caller != ""
+}
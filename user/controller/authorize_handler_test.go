@@ -0,0 +1,148 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/service"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+// fakeAuthorizeUsecase allows app user 3 to read. The other app users are not found, and their requests in a batch are denied
+type fakeAuthorizeUsecase struct {
+	requests []*usecase.AuthorizeRequest
+}
+
+func (u *fakeAuthorizeUsecase) Authorize(ctx context.Context, req *usecase.AuthorizeRequest) (bool, error) {
+	if req.AppUserID.Int() != 3 {
+		return false, liberrors.Errorf("Authorize. err: %w", service.ErrAppUserNotFound)
+	}
+	results, err := u.AuthorizeBatch(ctx, []*usecase.AuthorizeRequest{req})
+	if err != nil {
+		return false, err
+	}
+	return results[0], nil
+}
+
+func (u *fakeAuthorizeUsecase) AuthorizeBatch(ctx context.Context, reqs []*usecase.AuthorizeRequest) ([]bool, error) {
+	results := make([]bool, len(reqs))
+	for i, req := range reqs {
+		results[i] = req.AppUserID.Int() == 3 && req.Action.Action() == "read"
+	}
+	u.requests = append(u.requests, reqs...)
+	return results, nil
+}
+
+func newAuthorizeRouter(usecase *fakeAuthorizeUsecase) *gin.Engine {
+	router := gin.New()
+	controller.InitAuthorizeRouterGroup(router, controller.NewAuthorizeHandler(usecase), controller.NewServiceTokenMiddleware(map[string]string{"billing": "BILLING_SERVICE_TOKEN"}))
+	return router
+}
+
+func doAuthorizeRequest(t *testing.T, router http.Handler, path, token, body string) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code, w.Body.Bytes()
+}
+
+func TestAuthorizeHandler_Authorize(t *testing.T) {
+	t.Parallel()
+	usecase := &fakeAuthorizeUsecase{}
+	router := newAuthorizeRouter(usecase)
+
+	status, body := doAuthorizeRequest(t, router, "/v1/authorize", "BILLING_SERVICE_TOKEN", `{"organizationId":1,"appUserId":3,"action":"read","object":"doc:1","attributes":{"resource.owner":"user:3"}}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.JSONEq(t, `{"allowed":true}`, string(body))
+	require.Len(t, usecase.requests, 1)
+	assert.Equal(t, 1, usecase.requests[0].OrganizationID.Int())
+	assert.Equal(t, "doc:1", usecase.requests[0].Object.Object())
+	assert.Equal(t, "user:3", usecase.requests[0].Attributes["resource.owner"])
+
+	status, body = doAuthorizeRequest(t, router, "/v1/authorize", "BILLING_SERVICE_TOKEN", `{"organizationId":1,"appUserId":3,"action":"write","object":"doc:1"}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.JSONEq(t, `{"allowed":false}`, string(body))
+}
+
+func TestAuthorizeHandler_Authorize_error(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		token          string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "no token", body: `{"organizationId":1,"appUserId":3,"action":"read","object":"doc:1"}`, expectedStatus: http.StatusUnauthorized, expectedCode: "unauthenticated"},
+		{name: "invalid token", token: "ACCESS_TOKEN", body: `{"organizationId":1,"appUserId":3,"action":"read","object":"doc:1"}`, expectedStatus: http.StatusUnauthorized, expectedCode: "unauthenticated"},
+		{name: "no object", token: "BILLING_SERVICE_TOKEN", body: `{"organizationId":1,"appUserId":3,"action":"read"}`, expectedStatus: http.StatusBadRequest, expectedCode: "invalid_argument"},
+		{name: "invalid organization ID", token: "BILLING_SERVICE_TOKEN", body: `{"organizationId":-1,"appUserId":3,"action":"read","object":"doc:1"}`, expectedStatus: http.StatusBadRequest, expectedCode: "invalid_argument"},
+		{name: "app user not found", token: "BILLING_SERVICE_TOKEN", body: `{"organizationId":1,"appUserId":4,"action":"read","object":"doc:1"}`, expectedStatus: http.StatusNotFound, expectedCode: "not_found"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			router := newAuthorizeRouter(&fakeAuthorizeUsecase{})
+			status, body := doAuthorizeRequest(t, router, "/v1/authorize", tt.token, tt.body)
+			assertErrorResponse(t, tt.expectedStatus, tt.expectedCode, status, body)
+		})
+	}
+}
+
+func TestAuthorizeHandler_AuthorizeBatch(t *testing.T) {
+	t.Parallel()
+	router := newAuthorizeRouter(&fakeAuthorizeUsecase{})
+
+	status, body := doAuthorizeRequest(t, router, "/v1/authorize/batch", "BILLING_SERVICE_TOKEN", `{"requests":[
+		{"organizationId":1,"appUserId":3,"action":"read","object":"doc:1"},
+		{"organizationId":1,"appUserId":3,"action":"write","object":"doc:1"}
+	]}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	resp := controller.AuthorizeBatchResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Len(t, resp.Results, 2)
+	assert.True(t, resp.Results[0].Allowed)
+	assert.False(t, resp.Results[1].Allowed)
+
+	// - the requests of the app user who is not found are denied
+	status, body = doAuthorizeRequest(t, router, "/v1/authorize/batch", "BILLING_SERVICE_TOKEN", `{"requests":[
+		{"organizationId":1,"appUserId":3,"action":"read","object":"doc:1"},
+		{"organizationId":1,"appUserId":4,"action":"read","object":"doc:1"}
+	]}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	resp = controller.AuthorizeBatchResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Len(t, resp.Results, 2)
+	assert.True(t, resp.Results[0].Allowed)
+	assert.False(t, resp.Results[1].Allowed)
+
+	// - empty batch
+	status, body = doAuthorizeRequest(t, router, "/v1/authorize/batch", "BILLING_SERVICE_TOKEN", `{"requests":[]}`)
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+
+	// - too many requests
+	reqs := make([]string, 101)
+	for i := range reqs {
+		reqs[i] = `{"organizationId":1,"appUserId":3,"action":"read","object":"doc:1"}`
+	}
+	status, body = doAuthorizeRequest(t, router, "/v1/authorize/batch", "BILLING_SERVICE_TOKEN", `{"requests":[`+strings.Join(reqs, ",")+`]}`)
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+}
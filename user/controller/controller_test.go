@@ -0,0 +1,125 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type testAppUser struct {
+	appUserID      *domain.AppUserID
+	organizationID *domain.OrganizationID
+	loginID        string
+	username       string
+}
+
+func (m *testAppUser) AppUserID() *domain.AppUserID {
+	return m.appUserID
+}
+func (m *testAppUser) OrganizationID() *domain.OrganizationID {
+	return m.organizationID
+}
+func (m *testAppUser) LoginID() string {
+	return m.loginID
+}
+func (m *testAppUser) Username() string {
+	return m.username
+}
+
+func newTestAppUserModel(t *testing.T, appUserID, organizationID int, loginID string) *domain.AppUserModel {
+	t.Helper()
+	baseModel, err := libdomain.NewBaseModel(1, time.Now(), time.Now(), 1, 1)
+	require.NoError(t, err)
+	appUserIDTmp, err := domain.NewAppUserID(appUserID)
+	require.NoError(t, err)
+	organizationIDTmp, err := domain.NewOrganizationID(organizationID)
+	require.NoError(t, err)
+	appUserModel, err := domain.NewAppUserModel(baseModel, appUserIDTmp, organizationIDTmp, loginID, loginID+"_NAME", nil)
+	require.NoError(t, err)
+	return appUserModel
+}
+
+func newTestAppUser(t *testing.T) service.AppUserInterface {
+	t.Helper()
+	m := newTestAppUserModel(t, 3, 1, "USER")
+	return &testAppUser{appUserID: m.AppUserID, organizationID: m.OrganizationID, loginID: m.LoginID, username: m.Username}
+}
+
+func newTestOwner(t *testing.T) *service.Owner {
+	t.Helper()
+	ownerModel, err := domain.NewOwnerModel(newTestAppUserModel(t, 2, 1, "OWNER"))
+	require.NoError(t, err)
+	return service.NewOwner(nil, ownerModel)
+}
+
+// newTestRouter returns a router whose requests are made by operator. No operator is set when operator is nil
+func newTestRouter(operator service.AppUserInterface, init func(router gin.IRouter, middleware gin.HandlerFunc)) *gin.Engine {
+	router := gin.New()
+	init(router, func(c *gin.Context) {
+		if operator != nil {
+			controller.SetOperator(c, operator)
+		}
+		c.Next()
+	})
+	return router
+}
+
+func doRequest(t *testing.T, router http.Handler, method, path string, body interface{}) (int, []byte) {
+	t.Helper()
+	status, _, respBody := doRequestWithHeader(t, router, method, path, nil, body)
+	return status, respBody
+}
+
+func doRequestWithHeader(t *testing.T, router http.Handler, method, path string, header map[string]string, body interface{}) (int, http.Header, []byte) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		bytesBody, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(bytesBody)
+	}
+
+	req, err := http.NewRequest(method, path, reader)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	respBody, err := io.ReadAll(w.Result().Body)
+	require.NoError(t, err)
+	return w.Code, w.Header(), respBody
+}
+
+func parseErrorResponse(t *testing.T, body []byte) controller.ErrorResponse {
+	t.Helper()
+	resp := controller.ErrorResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp
+}
+
+func assertErrorResponse(t *testing.T, expectedStatus int, expectedCode string, status int, body []byte) {
+	t.Helper()
+	assert.Equal(t, expectedStatus, status)
+	assert.Equal(t, expectedCode, parseErrorResponse(t, body).Code)
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	liblog "github.com/kujilabo/redstart/lib/log"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type errorMapping struct {
+	err      error
+	status   int
+	code     string
+	grpcCode codes.Code
+}
+
+var errorMappings = []errorMapping{
+	{err: service.ErrUnauthenticated, status: http.StatusUnauthorized, code: "unauthenticated", grpcCode: codes.Unauthenticated},
+	{err: libdomain.ErrPermissionDenied, status: http.StatusForbidden, code: "permission_denied", grpcCode: codes.PermissionDenied},
+	{err: libdomain.ErrInvalidArgument, status: http.StatusBadRequest, code: "invalid_argument", grpcCode: codes.InvalidArgument},
+	{err: service.ErrOrganizationNotFound, status: http.StatusNotFound, code: "not_found", grpcCode: codes.NotFound},
+	{err: service.ErrAppUserNotFound, status: http.StatusNotFound, code: "not_found", grpcCode: codes.NotFound},
+	{err: service.ErrSystemOwnerNotFound, status: http.StatusNotFound, code: "not_found", grpcCode: codes.NotFound},
+	{err: service.ErrUserGroupNotFound, status: http.StatusNotFound, code: "not_found", grpcCode: codes.NotFound},
+	{err: service.ErrPairOfUserAndGroupNotFound, status: http.StatusNotFound, code: "not_found", grpcCode: codes.NotFound},
+	{err: service.ErrPairOfGroupAndGroupNotFound, status: http.StatusNotFound, code: "not_found", grpcCode: codes.NotFound},
+	{err: service.ErrRBACPolicyNotFound, status: http.StatusNotFound, code: "not_found", grpcCode: codes.NotFound},
+	{err: service.ErrUserGroupCycle, status: http.StatusBadRequest, code: "invalid_argument", grpcCode: codes.InvalidArgument},
+	{err: service.ErrOrganizationAlreadyExists, status: http.StatusConflict, code: "already_exists", grpcCode: codes.AlreadyExists},
+	{err: service.ErrAppUserAlreadyExists, status: http.StatusConflict, code: "already_exists", grpcCode: codes.AlreadyExists},
+	{err: service.ErrPairOfGroupAndGroupAlreadyExists, status: http.StatusConflict, code: "already_exists", grpcCode: codes.AlreadyExists},
+	{err: libdomain.ErrConcurrentModification, status: http.StatusConflict, code: "concurrent_modification", grpcCode: codes.Aborted},
+	{err: errPreconditionRequired, status: http.StatusPreconditionRequired, code: "precondition_required", grpcCode: codes.FailedPrecondition},
+}
+
+// handleError writes the JSON error response for err.
+// Only the message of the sentinel error is returned to the client, the whole error is logged.
+func handleError(c *gin.Context, err error) {
+	ctx := c.Request.Context()
+	logger := liblog.GetLoggerFromContext(ctx, UserControllerContextKey)
+
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			logger.InfoContext(ctx, fmt.Sprintf("request failed. status: %d, err: %v", m.status, err))
+			c.JSON(m.status, ErrorResponse{Code: m.code, Message: m.err.Error()})
+			return
+		}
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		logger.InfoContext(ctx, fmt.Sprintf("request failed. status: %d, err: %v", http.StatusBadRequest, err))
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "invalid_argument", Message: libdomain.ErrInvalidArgument.Error()})
+		return
+	}
+
+	logger.ErrorContext(ctx, fmt.Sprintf("request failed. err: %+v", err))
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "internal_error", Message: http.StatusText(http.StatusInternalServerError)})
+}
+
+// bindError converts the error of gin binding to ErrInvalidArgument
+func bindError(err error) error {
+	return liberrors.Errorf("%w. err: %v", libdomain.ErrInvalidArgument, err)
+}
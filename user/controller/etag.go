@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+var errPreconditionRequired = errors.New("If-Match header is required")
+
+// setETag returns the version of the resource as its ETag. Nothing is set if the version is unknown
+func setETag(c *gin.Context, baseModel *libdomain.BaseModel) {
+	if baseModel == nil {
+		return
+	}
+	c.Header("ETag", strconv.Quote(strconv.Itoa(baseModel.Version)))
+}
+
+// ifMatchVersion returns the version in the If-Match header, which must be the ETag returned by setETag
+func ifMatchVersion(c *gin.Context) (int, error) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return 0, liberrors.Errorf("If-Match header not found. err: %w", errPreconditionRequired)
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+	if err != nil || version <= 0 {
+		return 0, liberrors.Errorf("invalid If-Match header. If-Match: %s, err: %w", ifMatch, libdomain.ErrInvalidArgument)
+	}
+
+	return version, nil
+}
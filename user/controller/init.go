@@ -0,0 +1,9 @@
+package controller
+
+import (
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+)
+
+const (
+	UserControllerContextKey libdomain.ContextKey = "user_controller"
+)
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+const operatorKey = "redstart.operator"
+
+// SetOperator stores the authenticated operator in the gin context for the handlers, and in the request context for the records written in the request
+func SetOperator(c *gin.Context, operator service.AppUserInterface) {
+	c.Set(operatorKey, operator)
+	c.Request = c.Request.WithContext(service.WithOperator(c.Request.Context(), operator))
+}
+
+func GetOperator(c *gin.Context) (service.AppUserInterface, error) {
+	value, ok := c.Get(operatorKey)
+	if !ok {
+		return nil, service.ErrUnauthenticated
+	}
+
+	operator, ok := value.(service.AppUserInterface)
+	if !ok {
+		return nil, service.ErrUnauthenticated
+	}
+
+	return operator, nil
+}
+
+// GetOwner returns the operator if it is an owner of the organization
+func GetOwner(c *gin.Context) (service.OwnerModelInterface, error) {
+	operator, err := GetOperator(c)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, ok := operator.(service.OwnerModelInterface)
+	if !ok || !owner.IsOwner() {
+		return nil, libdomain.ErrPermissionDenied
+	}
+
+	return owner, nil
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+func intParam(c *gin.Context, name string) (int, error) {
+	value, err := strconv.Atoi(c.Param(name))
+	if err != nil || value <= 0 {
+		return 0, liberrors.Errorf("invalid path parameter. name: %s, value: %s, err: %w", name, c.Param(name), libdomain.ErrInvalidArgument)
+	}
+
+	return value, nil
+}
+
+func timeQuery(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, liberrors.Errorf("invalid query parameter. name: %s, value: %s, err: %w", name, value, libdomain.ErrInvalidArgument)
+	}
+
+	return t, nil
+}
+
+func intQuery(c *gin.Context, name string) (int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil || i <= 0 {
+		return 0, liberrors.Errorf("invalid query parameter. name: %s, value: %s, err: %w", name, value, libdomain.ErrInvalidArgument)
+	}
+
+	return i, nil
+}
+
+func boolQuery(c *gin.Context, name string) (bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, liberrors.Errorf("invalid query parameter. name: %s, value: %s, err: %w", name, value, libdomain.ErrInvalidArgument)
+	}
+
+	return b, nil
+}
+
+func organizationIDParam(c *gin.Context) (*domain.OrganizationID, error) {
+	value, err := intParam(c, "organizationId")
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewOrganizationID(value)
+}
+
+func appUserIDParam(c *gin.Context) (*domain.AppUserID, error) {
+	value, err := intParam(c, "appUserId")
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewAppUserID(value)
+}
+
+func userGroupIDParam(c *gin.Context) (*domain.UserGroupID, error) {
+	value, err := intParam(c, "userGroupId")
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewUserGroupID(value)
+}
+
+func parentUserGroupIDParam(c *gin.Context) (*domain.UserGroupID, error) {
+	value, err := intParam(c, "parentUserGroupId")
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewUserGroupID(value)
+}
@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+type PolicyAddRequest struct {
+	Action string `json:"action" binding:"required"`
+	Object string `json:"object" binding:"required"`
+	Effect string `json:"effect" binding:"required"`
+}
+
+type PolicyResponse struct {
+	Subject string `json:"subject"`
+	Action  string `json:"action"`
+	Object  string `json:"object"`
+	Effect  string `json:"effect"`
+}
+
+type PoliciesResponse struct {
+	Policies []*PolicyResponse `json:"policies"`
+}
+
+func toPoliciesResponse(policies []*domain.RBACPolicy) *PoliciesResponse {
+	resp := PoliciesResponse{Policies: make([]*PolicyResponse, len(policies))}
+	for i, p := range policies {
+		resp.Policies[i] = &PolicyResponse{
+			Subject: p.Subject.Subject(),
+			Action:  p.Action.Action(),
+			Object:  p.Object.Object(),
+			Effect:  p.Effect.Effect(),
+		}
+	}
+	return &resp
+}
+
+type PolicyHandler struct {
+	policyUsecase usecase.PolicyUsecase
+}
+
+func NewPolicyHandler(policyUsecase usecase.PolicyUsecase) *PolicyHandler {
+	return &PolicyHandler{
+		policyUsecase: policyUsecase,
+	}
+}
+
+func bindPolicyRequest(c *gin.Context) (domain.RBACAction, domain.RBACObject, domain.RBACEffect, error) {
+	req := PolicyAddRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		return nil, nil, nil, bindError(err)
+	}
+
+	effect, err := domain.ParseRBACEffect(req.Effect)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return domain.NewRBACAction(req.Action), domain.NewRBACObject(req.Object), effect, nil
+}
+
+func (h *PolicyHandler) AddPolicyToUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	action, object, effect, err := bindPolicyRequest(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.policyUsecase.AddPolicyToUser(ctx, operator, appUserID, action, object, effect); err != nil {
+		handleError(c, liberrors.Errorf("h.policyUsecase.AddPolicyToUser. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *PolicyHandler) AddPolicyToGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroupID, err := userGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	action, object, effect, err := bindPolicyRequest(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.policyUsecase.AddPolicyToGroup(ctx, operator, userGroupID, action, object, effect); err != nil {
+		handleError(c, liberrors.Errorf("h.policyUsecase.AddPolicyToGroup. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *PolicyHandler) ListPoliciesOfUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	policies, err := h.policyUsecase.ListPoliciesOfUser(ctx, operator, appUserID)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.policyUsecase.ListPoliciesOfUser. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toPoliciesResponse(policies))
+}
+
+func (h *PolicyHandler) ListPoliciesOfGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroupID, err := userGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	policies, err := h.policyUsecase.ListPoliciesOfGroup(ctx, operator, userGroupID)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.policyUsecase.ListPoliciesOfGroup. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toPoliciesResponse(policies))
+}
+
+// ListPoliciesForObject returns the policies of the object specified by the "object" query parameter
+func (h *PolicyHandler) ListPoliciesForObject(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	object := c.Query("object")
+	if object == "" {
+		handleError(c, liberrors.Errorf("object is required. err: %w", libdomain.ErrInvalidArgument))
+		return
+	}
+
+	policies, err := h.policyUsecase.ListPoliciesForObject(ctx, operator, domain.NewRBACObject(object))
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.policyUsecase.ListPoliciesForObject. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toPoliciesResponse(policies))
+}
+
+// RemovePolicyFromUser removes the policy in the request body from the app user
+func (h *PolicyHandler) RemovePolicyFromUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	action, object, effect, err := bindPolicyRequest(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.policyUsecase.RemovePolicyFromUser(ctx, operator, appUserID, action, object, effect); err != nil {
+		handleError(c, liberrors.Errorf("h.policyUsecase.RemovePolicyFromUser. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemovePolicyFromGroup removes the policy in the request body from the user group
+func (h *PolicyHandler) RemovePolicyFromGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroupID, err := userGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	action, object, effect, err := bindPolicyRequest(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.policyUsecase.RemovePolicyFromGroup(ctx, operator, userGroupID, action, object, effect); err != nil {
+		handleError(c, liberrors.Errorf("h.policyUsecase.RemovePolicyFromGroup. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
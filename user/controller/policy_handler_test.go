@@ -0,0 +1,278 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type fakePolicyUsecase struct {
+	policies  []string
+	removeErr error
+}
+
+func (u *fakePolicyUsecase) AddPolicyToUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	u.policies = append(u.policies, service.NewRBACAppUser(operator.OrganizationID(), appUserID).Subject()+","+object.Object()+","+action.Action()+","+effect.Effect())
+	return nil
+}
+
+func (u *fakePolicyUsecase) AddPolicyToGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	u.policies = append(u.policies, service.NewRBACUserRole(operator.OrganizationID(), userGroupID).Subject()+","+object.Object()+","+action.Action()+","+effect.Effect())
+	return nil
+}
+
+func (u *fakePolicyUsecase) listPolicies(subject string) []*domain.RBACPolicy {
+	policies := make([]*domain.RBACPolicy, 0)
+	for _, p := range u.policies {
+		values := strings.Split(p, ",")
+		if subject == "" || values[0] == subject {
+			policies = append(policies, domain.NewRBACPolicy(domain.NewRBACDomain("domain:1"), domain.NewRBACUser(values[0]), domain.NewRBACAction(values[2]), domain.NewRBACObject(values[1]), domain.NewRBACEffect(values[3])))
+		}
+	}
+	return policies
+}
+
+func (u *fakePolicyUsecase) ListPoliciesOfUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID) ([]*domain.RBACPolicy, error) {
+	return u.listPolicies(service.NewRBACAppUser(operator.OrganizationID(), appUserID).Subject()), nil
+}
+
+func (u *fakePolicyUsecase) ListPoliciesOfGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID) ([]*domain.RBACPolicy, error) {
+	return u.listPolicies(service.NewRBACUserRole(operator.OrganizationID(), userGroupID).Subject()), nil
+}
+
+func (u *fakePolicyUsecase) ListPoliciesForObject(ctx context.Context, operator service.OwnerModelInterface, object domain.RBACObject) ([]*domain.RBACPolicy, error) {
+	policies := make([]*domain.RBACPolicy, 0)
+	for _, p := range u.listPolicies("") {
+		if p.Object.Object() == object.Object() {
+			policies = append(policies, p)
+		}
+	}
+	return policies, nil
+}
+
+func (u *fakePolicyUsecase) remove(policy string) error {
+	if u.removeErr != nil {
+		return u.removeErr
+	}
+	policies := make([]string, 0)
+	for _, p := range u.policies {
+		if p != policy {
+			policies = append(policies, p)
+		}
+	}
+	u.policies = policies
+	return nil
+}
+
+func (u *fakePolicyUsecase) RemovePolicyFromUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	return u.remove(service.NewRBACAppUser(operator.OrganizationID(), appUserID).Subject() + "," + object.Object() + "," + action.Action() + "," + effect.Effect())
+}
+
+func (u *fakePolicyUsecase) RemovePolicyFromGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	return u.remove(service.NewRBACUserRole(operator.OrganizationID(), userGroupID).Subject() + "," + object.Object() + "," + action.Action() + "," + effect.Effect())
+}
+
+func newPolicyRouter(operator service.AppUserInterface, usecase *fakePolicyUsecase) *gin.Engine {
+	return newTestRouter(operator, func(router gin.IRouter, middleware gin.HandlerFunc) {
+		controller.InitRouterGroup(router, controller.NewAppUserHandler(nil), controller.NewUserGroupHandler(nil), controller.NewPolicyHandler(usecase), middleware)
+	})
+}
+
+func TestPolicyHandler_AddPolicy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		operator service.AppUserInterface
+		path     string
+		body     interface{}
+		status   int
+		code     string
+		policies []string
+	}{
+		{
+			name:     "user",
+			operator: newTestOwner(t),
+			path:     "/v1/user/5/policy",
+			body:     map[string]string{"action": "Set", "object": "domain:1_role:2", "effect": "allow"},
+			status:   http.StatusNoContent,
+			policies: []string{"user:5,domain:1_role:2,Set,allow"},
+		},
+		{
+			name:     "group",
+			operator: newTestOwner(t),
+			path:     "/v1/user_group/2/policy",
+			body:     map[string]string{"action": "Unset", "object": "domain:1_role:3", "effect": "deny"},
+			status:   http.StatusNoContent,
+			policies: []string{"domain:1_role:2,domain:1_role:3,Unset,deny"},
+		},
+		{
+			name:     "unsupported effect",
+			operator: newTestOwner(t),
+			path:     "/v1/user/5/policy",
+			body:     map[string]string{"action": "Set", "object": "domain:1_role:2", "effect": "maybe"},
+			status:   http.StatusBadRequest,
+			code:     "invalid_argument",
+		},
+		{
+			name:     "not owner",
+			operator: newTestAppUser(t),
+			path:     "/v1/user_group/2/policy",
+			body:     map[string]string{"action": "Set", "object": "domain:1_role:2", "effect": "allow"},
+			status:   http.StatusForbidden,
+			code:     "permission_denied",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := &fakePolicyUsecase{}
+			status, body := doRequest(t, newPolicyRouter(tt.operator, usecase), http.MethodPost, tt.path, tt.body)
+			if tt.code != "" {
+				assertErrorResponse(t, tt.status, tt.code, status, body)
+			} else {
+				assert.Equal(t, tt.status, status)
+			}
+			assert.Equal(t, tt.policies, usecase.policies)
+		})
+	}
+}
+
+func TestPolicyHandler_ListPolicies(t *testing.T) {
+	t.Parallel()
+	usecase := &fakePolicyUsecase{policies: []string{
+		"user:5,domain:1_role:2,Set,allow",
+		"domain:1_role:2,domain:1_role:3,Unset,deny",
+		"domain:1_role:2,domain:1_role:2,Set,allow",
+	}}
+	tests := []struct {
+		name     string
+		operator service.AppUserInterface
+		path     string
+		status   int
+		code     string
+		policies []*controller.PolicyResponse
+	}{
+		{
+			name:     "user",
+			operator: newTestOwner(t),
+			path:     "/v1/user/5/policy",
+			status:   http.StatusOK,
+			policies: []*controller.PolicyResponse{{Subject: "user:5", Action: "Set", Object: "domain:1_role:2", Effect: "allow"}},
+		},
+		{
+			name:     "group",
+			operator: newTestOwner(t),
+			path:     "/v1/user_group/2/policy",
+			status:   http.StatusOK,
+			policies: []*controller.PolicyResponse{
+				{Subject: "domain:1_role:2", Action: "Unset", Object: "domain:1_role:3", Effect: "deny"},
+				{Subject: "domain:1_role:2", Action: "Set", Object: "domain:1_role:2", Effect: "allow"},
+			},
+		},
+		{
+			name:     "object",
+			operator: newTestOwner(t),
+			path:     "/v1/policy?object=domain:1_role:2",
+			status:   http.StatusOK,
+			policies: []*controller.PolicyResponse{
+				{Subject: "user:5", Action: "Set", Object: "domain:1_role:2", Effect: "allow"},
+				{Subject: "domain:1_role:2", Action: "Set", Object: "domain:1_role:2", Effect: "allow"},
+			},
+		},
+		{name: "object is required", operator: newTestOwner(t), path: "/v1/policy", status: http.StatusBadRequest, code: "invalid_argument"},
+		{name: "not owner", operator: newTestAppUser(t), path: "/v1/user/5/policy", status: http.StatusForbidden, code: "permission_denied"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			status, body := doRequest(t, newPolicyRouter(tt.operator, usecase), http.MethodGet, tt.path, nil)
+			if tt.code != "" {
+				assertErrorResponse(t, tt.status, tt.code, status, body)
+				return
+			}
+
+			require.Equal(t, tt.status, status)
+			resp := controller.PoliciesResponse{}
+			require.NoError(t, json.Unmarshal(body, &resp))
+			assert.Equal(t, tt.policies, resp.Policies)
+		})
+	}
+}
+
+func TestPolicyHandler_RemovePolicy(t *testing.T) {
+	t.Parallel()
+	policies := []string{"user:5,domain:1_role:2,Set,allow", "domain:1_role:2,domain:1_role:3,Unset,deny"}
+	tests := []struct {
+		name      string
+		operator  service.AppUserInterface
+		removeErr error
+		path      string
+		body      interface{}
+		status    int
+		code      string
+		policies  []string
+	}{
+		{
+			name:     "user",
+			operator: newTestOwner(t),
+			path:     "/v1/user/5/policy",
+			body:     map[string]string{"action": "Set", "object": "domain:1_role:2", "effect": "allow"},
+			status:   http.StatusNoContent,
+			policies: []string{"domain:1_role:2,domain:1_role:3,Unset,deny"},
+		},
+		{
+			name:     "group",
+			operator: newTestOwner(t),
+			path:     "/v1/user_group/2/policy",
+			body:     map[string]string{"action": "Unset", "object": "domain:1_role:3", "effect": "deny"},
+			status:   http.StatusNoContent,
+			policies: []string{"user:5,domain:1_role:2,Set,allow"},
+		},
+		{
+			name:      "not found",
+			operator:  newTestOwner(t),
+			removeErr: liberrors.Errorf("err: %w", service.ErrRBACPolicyNotFound),
+			path:      "/v1/user/5/policy",
+			body:      map[string]string{"action": "Set", "object": "domain:1_role:9", "effect": "allow"},
+			status:    http.StatusNotFound,
+			code:      "not_found",
+			policies:  policies,
+		},
+		{
+			name:     "not owner",
+			operator: newTestAppUser(t),
+			path:     "/v1/user/5/policy",
+			body:     map[string]string{"action": "Set", "object": "domain:1_role:2", "effect": "allow"},
+			status:   http.StatusForbidden,
+			code:     "permission_denied",
+			policies: policies,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := &fakePolicyUsecase{policies: append([]string{}, policies...), removeErr: tt.removeErr}
+			status, body := doRequest(t, newPolicyRouter(tt.operator, usecase), http.MethodDelete, tt.path, tt.body)
+			if tt.code != "" {
+				assertErrorResponse(t, tt.status, tt.code, status, body)
+			} else {
+				assert.Equal(t, tt.status, status)
+			}
+			assert.Equal(t, tt.policies, usecase.policies)
+		})
+	}
+}
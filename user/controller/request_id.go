@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+)
+
+const (
+	RequestIDHeader = "X-Request-Id"
+
+	maxRequestIDLength = 64
+)
+
+// NewRequestIDMiddleware returns a middleware which sets the request ID in the context of the request.
+// The ID in the X-Request-Id header is used if it is valid, otherwise a new one is generated
+func NewRequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Request = c.Request.WithContext(libdomain.WithRequestID(c.Request.Context(), requestID))
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
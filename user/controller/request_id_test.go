@@ -0,0 +1,38 @@
+package controller_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/controller"
+)
+
+func TestNewRequestIDMiddleware(t *testing.T) {
+	t.Parallel()
+	router := gin.New()
+	router.Use(controller.NewRequestIDMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, libdomain.RequestIDFromContext(c.Request.Context()))
+	})
+
+	// the ID in the header is used
+	status, header, body := doRequestWithHeader(t, router, http.MethodGet, "/", map[string]string{controller.RequestIDHeader: "REQUEST_ID"}, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "REQUEST_ID", string(body))
+	assert.Equal(t, "REQUEST_ID", header.Get(controller.RequestIDHeader))
+
+	// a new ID is generated when the header is missing or invalid
+	for _, requestID := range []string{"", "REQUEST ID", strings.Repeat("a", 65)} {
+		status, header, body = doRequestWithHeader(t, router, http.MethodGet, "/", map[string]string{controller.RequestIDHeader: requestID}, nil)
+		require.Equal(t, http.StatusOK, status)
+		assert.Len(t, string(body), 36)
+		assert.NotEqual(t, requestID, string(body))
+		assert.Equal(t, string(body), header.Get(controller.RequestIDHeader))
+	}
+}
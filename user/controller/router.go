@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// InitAuthRouterGroup registers the endpoints to issue tokens
+func InitAuthRouterGroup(parent gin.IRouter, authHandler *AuthHandler) {
+	auth := parent.Group("v1/auth")
+	{
+		auth.POST("password", authHandler.PasswordAuth)
+		auth.POST("refresh", authHandler.RefreshToken)
+	}
+}
+
+// InitRouterGroup registers the endpoints for the app users.
+// middlewares must authenticate the request and set the operator with SetOperator.
+func InitRouterGroup(parent gin.IRouter, appUserHandler *AppUserHandler, userGroupHandler *UserGroupHandler, policyHandler *PolicyHandler, middlewares ...gin.HandlerFunc) {
+	v1 := parent.Group("v1", middlewares...)
+	{
+		v1.GET("organization", appUserHandler.GetOrganization)
+
+		v1.GET("me", appUserHandler.GetMe)
+		v1.GET("user", appUserHandler.FindAppUsers)
+		v1.POST("user", appUserHandler.AddAppUser)
+		v1.GET("user/:appUserId", appUserHandler.FindAppUserByID)
+		v1.PUT("user/:appUserId", appUserHandler.UpdateAppUser)
+		v1.DELETE("user/:appUserId", appUserHandler.RemoveAppUser)
+		v1.PUT("user/:appUserId/password", appUserHandler.ChangePassword)
+		v1.POST("user/:appUserId/restore", appUserHandler.RestoreAppUser)
+		v1.GET("user/:appUserId/policy", policyHandler.ListPoliciesOfUser)
+		v1.POST("user/:appUserId/policy", policyHandler.AddPolicyToUser)
+		v1.DELETE("user/:appUserId/policy", policyHandler.RemovePolicyFromUser)
+
+		v1.GET("user_group", userGroupHandler.FindAllUserGroups)
+		v1.POST("user_group", userGroupHandler.AddUserGroup)
+		v1.GET("user_group/:userGroupId", userGroupHandler.FindUserGroupByID)
+		v1.PUT("user_group/:userGroupId", userGroupHandler.UpdateUserGroup)
+		v1.PUT("user_group/:userGroupId/user/:appUserId", userGroupHandler.AddUserToGroup)
+		v1.DELETE("user_group/:userGroupId/user/:appUserId", userGroupHandler.RemoveUserFromGroup)
+		v1.PUT("user_group/:userGroupId/parent/:parentUserGroupId", userGroupHandler.AddGroupToGroup)
+		v1.DELETE("user_group/:userGroupId/parent/:parentUserGroupId", userGroupHandler.RemoveGroupFromGroup)
+		v1.GET("user_group/:userGroupId/policy", policyHandler.ListPoliciesOfGroup)
+		v1.POST("user_group/:userGroupId/policy", policyHandler.AddPolicyToGroup)
+		v1.DELETE("user_group/:userGroupId/policy", policyHandler.RemovePolicyFromGroup)
+
+		v1.GET("policy", policyHandler.ListPoliciesForObject)
+	}
+}
+
+// InitAuditLogRouterGroup registers the endpoints to browse the audit log of the organization.
+// middlewares must authenticate the request and set the operator with SetOperator.
+func InitAuditLogRouterGroup(parent gin.IRouter, auditLogHandler *AuditLogHandler, middlewares ...gin.HandlerFunc) {
+	v1 := parent.Group("v1", middlewares...)
+	{
+		v1.GET("audit_event", auditLogHandler.FindAuditEvents)
+	}
+}
+
+// InitAuthorizeRouterGroup registers the permission check endpoints for the other services.
+// middlewares must authenticate the services with NewServiceTokenMiddleware.
+func InitAuthorizeRouterGroup(parent gin.IRouter, authorizeHandler *AuthorizeHandler, middlewares ...gin.HandlerFunc) {
+	v1 := parent.Group("v1/authorize", middlewares...)
+	{
+		v1.POST("", authorizeHandler.Authorize)
+		v1.POST("batch", authorizeHandler.AuthorizeBatch)
+	}
+}
+
+// InitAdminRouterGroup registers the endpoints for the system administrator.
+// middlewares must restrict the access to the system administrator.
+func InitAdminRouterGroup(parent gin.IRouter, systemAdminHandler *SystemAdminHandler, middlewares ...gin.HandlerFunc) {
+	v1 := parent.Group("v1/admin", middlewares...)
+	{
+		v1.POST("organization", systemAdminHandler.AddOrganization)
+		v1.GET("organization/:organizationId", systemAdminHandler.FindOrganizationByID)
+		v1.PUT("organization/:organizationId", systemAdminHandler.UpdateOrganization)
+		v1.GET("organization/:organizationId/policy", systemAdminHandler.ListPolicies)
+		v1.GET("organization/:organizationId/user/:appUserId/explain", systemAdminHandler.ExplainAuthorization)
+		v1.GET("organization/:organizationId/policy/export", systemAdminHandler.ExportPolicies)
+		v1.POST("organization/:organizationId/policy/import", systemAdminHandler.ImportPolicies)
+		v1.GET("organization/:organizationId/audit_event", systemAdminHandler.FindAuditEvents)
+	}
+}
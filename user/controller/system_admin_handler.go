@@ -0,0 +1,350 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+// NewSystemAdminTokenMiddleware returns a middleware which accepts the bearer tokens of the system administrators.
+// adminTokens is keyed by the names of the administrators
+func NewSystemAdminTokenMiddleware(adminTokens map[string]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c)
+		if !ok {
+			handleError(c, liberrors.Errorf("bearer token not found. err: %w", service.ErrUnauthenticated))
+			c.Abort()
+			return
+		}
+
+		if _, ok := findServiceByToken(adminTokens, token); !ok {
+			handleError(c, liberrors.Errorf("invalid system admin token. err: %w", service.ErrUnauthenticated))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+type OrganizationAddRequest struct {
+	Name       string            `json:"name" binding:"required"`
+	FirstOwner AppUserAddRequest `json:"firstOwner" binding:"required"`
+}
+
+type OrganizationUpdateRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type GroupingRuleResponse struct {
+	PType  string `json:"ptype"`
+	Child  string `json:"child"`
+	Parent string `json:"parent"`
+}
+
+type ExplanationResponse struct {
+	Allowed      bool                    `json:"allowed"`
+	Policy       *PolicyResponse         `json:"policy,omitempty"`
+	SubjectChain []*GroupingRuleResponse `json:"subjectChain"`
+	ObjectChain  []*GroupingRuleResponse `json:"objectChain"`
+	Lines        []string                `json:"lines"`
+}
+
+func toGroupingRuleResponses(rules []*domain.RBACGroupingRule) []*GroupingRuleResponse {
+	resp := make([]*GroupingRuleResponse, len(rules))
+	for i, r := range rules {
+		resp[i] = &GroupingRuleResponse{PType: r.PType, Child: r.Child, Parent: r.Parent}
+	}
+	return resp
+}
+
+func toExplanationResponse(explanation *domain.RBACExplanation) *ExplanationResponse {
+	resp := ExplanationResponse{
+		Allowed:      explanation.Allowed,
+		SubjectChain: toGroupingRuleResponses(explanation.SubjectChain),
+		ObjectChain:  toGroupingRuleResponses(explanation.ObjectChain),
+		Lines:        explanation.Lines(),
+	}
+	if explanation.Policy != nil {
+		resp.Policy = toPoliciesResponse([]*domain.RBACPolicy{explanation.Policy}).Policies[0]
+	}
+	return &resp
+}
+
+type RuleSetDiffResponse struct {
+	DryRun  bool     `json:"dryRun"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+var ruleSetContentTypes = map[domain.RBACRuleSetFormat]string{
+	domain.RBACRuleSetFormatCSV:  "text/csv; charset=utf-8",
+	domain.RBACRuleSetFormatJSON: "application/json; charset=utf-8",
+}
+
+// ruleSetFormatQuery returns the "format" in the query parameters. The default is CSV
+func ruleSetFormatQuery(c *gin.Context) (domain.RBACRuleSetFormat, error) {
+	format := domain.RBACRuleSetFormat(c.DefaultQuery("format", string(domain.RBACRuleSetFormatCSV)))
+	if _, ok := ruleSetContentTypes[format]; !ok {
+		return "", liberrors.Errorf("unsupported format. format: %s, err: %w", format, libdomain.ErrInvalidArgument)
+	}
+	return format, nil
+}
+
+// SystemAdminHandler serves the endpoints for the system administrator.
+// The router group must be protected by the caller.
+type SystemAdminHandler struct {
+	systemAdminUsecase usecase.SystemAdminUsecase
+}
+
+func NewSystemAdminHandler(systemAdminUsecase usecase.SystemAdminUsecase) *SystemAdminHandler {
+	return &SystemAdminHandler{
+		systemAdminUsecase: systemAdminUsecase,
+	}
+}
+
+func (h *SystemAdminHandler) AddOrganization(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	req := OrganizationAddRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	firstOwnerAddParam, err := service.NewAppUserAddParameter(req.FirstOwner.LoginID, req.FirstOwner.Username, req.FirstOwner.Password, "", "", "", "")
+	if err != nil {
+		handleError(c, liberrors.Errorf("service.NewAppUserAddParameter. err: %w", err))
+		return
+	}
+
+	param, err := service.NewOrganizationAddParameter(req.Name, firstOwnerAddParam)
+	if err != nil {
+		handleError(c, liberrors.Errorf("service.NewOrganizationAddParameter. err: %w", err))
+		return
+	}
+
+	organizationID, err := h.systemAdminUsecase.AddOrganization(ctx, param)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.systemAdminUsecase.AddOrganization. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, IDResponse{ID: organizationID.Int()})
+}
+
+func (h *SystemAdminHandler) FindOrganizationByID(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	organizationID, err := organizationIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	organization, err := h.systemAdminUsecase.FindOrganizationByID(ctx, organizationID)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.systemAdminUsecase.FindOrganizationByID. err: %w", err))
+		return
+	}
+
+	setETag(c, organization.BaseModel)
+	c.JSON(http.StatusOK, toOrganizationResponse(organization))
+}
+
+// UpdateOrganization updates the organization whose version is the one in the If-Match header
+func (h *SystemAdminHandler) UpdateOrganization(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	organizationID, err := organizationIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	req := OrganizationUpdateRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	param, err := service.NewOrganizationUpdateParameter(req.Name)
+	if err != nil {
+		handleError(c, liberrors.Errorf("service.NewOrganizationUpdateParameter. err: %w", err))
+		return
+	}
+
+	organization, err := h.systemAdminUsecase.UpdateOrganization(ctx, organizationID, version, param)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.systemAdminUsecase.UpdateOrganization. err: %w", err))
+		return
+	}
+
+	setETag(c, organization.BaseModel)
+	c.JSON(http.StatusOK, toOrganizationResponse(organization))
+}
+
+func (h *SystemAdminHandler) ListPolicies(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	organizationID, err := organizationIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	policies, err := h.systemAdminUsecase.ListPolicies(ctx, organizationID)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.systemAdminUsecase.ListPolicies. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toPoliciesResponse(policies))
+}
+
+// ExplainAuthorization returns why the app user is allowed or denied the "action" on the "object" in the query parameters
+func (h *SystemAdminHandler) ExplainAuthorization(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	organizationID, err := organizationIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	action := c.Query("action")
+	object := c.Query("object")
+	if action == "" || object == "" {
+		handleError(c, liberrors.Errorf("action and object are required. err: %w", libdomain.ErrInvalidArgument))
+		return
+	}
+
+	explanation, err := h.systemAdminUsecase.ExplainAuthorization(ctx, organizationID, appUserID, domain.NewRBACAction(action), domain.NewRBACObject(object))
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.systemAdminUsecase.ExplainAuthorization. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toExplanationResponse(explanation))
+}
+
+// ExportPolicies returns the policies of the organization in the "format" in the query parameters
+func (h *SystemAdminHandler) ExportPolicies(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	organizationID, err := organizationIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	format, err := ruleSetFormatQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	ruleSet, err := h.systemAdminUsecase.ExportPolicies(ctx, organizationID)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.systemAdminUsecase.ExportPolicies. err: %w", err))
+		return
+	}
+
+	data, err := domain.MarshalRBACRuleSet(ruleSet, format)
+	if err != nil {
+		handleError(c, liberrors.Errorf("domain.MarshalRBACRuleSet. err: %w", err))
+		return
+	}
+
+	c.Data(http.StatusOK, ruleSetContentTypes[format], data)
+}
+
+// ImportPolicies imports the rule set in the request body.
+// The query parameters are "format", "mode" ("merge" or "replace") and "dryRun"
+func (h *SystemAdminHandler) ImportPolicies(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	organizationID, err := organizationIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	format, err := ruleSetFormatQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	mode := service.PolicyImportMode(c.DefaultQuery("mode", string(service.PolicyImportModeMerge)))
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		handleError(c, liberrors.Errorf("invalid dryRun. err: %v: %w", err, libdomain.ErrInvalidArgument))
+		return
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		handleError(c, liberrors.Errorf("io.ReadAll. err: %v: %w", err, libdomain.ErrInvalidArgument))
+		return
+	}
+
+	ruleSet, err := domain.UnmarshalRBACRuleSet(data, format)
+	if err != nil {
+		handleError(c, liberrors.Errorf("domain.UnmarshalRBACRuleSet. err: %w", err))
+		return
+	}
+
+	diff, err := h.systemAdminUsecase.ImportPolicies(ctx, organizationID, ruleSet, mode, dryRun)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.systemAdminUsecase.ImportPolicies. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, RuleSetDiffResponse{DryRun: dryRun, Added: diff.Added.Lines(), Removed: diff.Removed.Lines()})
+}
+
+// FindAuditEvents returns the audit events of the organization filtered by the query parameters
+func (h *SystemAdminHandler) FindAuditEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	organizationID, err := organizationIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	condition, err := auditEventSearchConditionQuery(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	events, err := h.systemAdminUsecase.FindAuditEvents(ctx, organizationID, condition)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.systemAdminUsecase.FindAuditEvents. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toAuditEventsResponse(events))
+}
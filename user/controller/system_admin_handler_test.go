@@ -0,0 +1,333 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type fakeSystemAdminUsecase struct {
+	organizations  map[string]int
+	auditCondition *service.AuditEventSearchCondition
+}
+
+func (u *fakeSystemAdminUsecase) AddOrganization(ctx context.Context, param service.OrganizationAddParameterInterface) (*domain.OrganizationID, error) {
+	if _, ok := u.organizations[param.Name()]; ok {
+		return nil, liberrors.Errorf("AddOrganization. err: %w", service.ErrOrganizationAlreadyExists)
+	}
+	u.organizations[param.Name()] = len(u.organizations) + 1
+	return domain.NewOrganizationID(u.organizations[param.Name()])
+}
+
+func (u *fakeSystemAdminUsecase) FindOrganizationByID(ctx context.Context, organizationID *domain.OrganizationID) (*service.Organization, error) {
+	return nil, liberrors.Errorf("FindOrganizationByID. err: %w", service.ErrOrganizationNotFound)
+}
+
+// UpdateOrganization accepts only version 1
+func (u *fakeSystemAdminUsecase) UpdateOrganization(ctx context.Context, organizationID *domain.OrganizationID, version int, param service.OrganizationUpdateParameterInterface) (*service.Organization, error) {
+	if version != 1 {
+		return nil, liberrors.Errorf("UpdateOrganization. err: %w", libdomain.ErrConcurrentModification)
+	}
+	baseModel, err := libdomain.NewBaseModel(version+1, time.Now(), time.Now(), 1, 1)
+	if err != nil {
+		return nil, err
+	}
+	orgModel, err := domain.NewOrganizationModel(baseModel, organizationID, param.Name())
+	if err != nil {
+		return nil, err
+	}
+	return service.NewOrganization(orgModel)
+}
+
+func (u *fakeSystemAdminUsecase) ListPolicies(ctx context.Context, organizationID *domain.OrganizationID) ([]*domain.RBACPolicy, error) {
+	return []*domain.RBACPolicy{
+		domain.NewRBACPolicy(service.NewRBACOrganization(organizationID), domain.NewRBACUser("user:2"), service.RBACSetAction, service.NewRBACAllUserRolesObject(organizationID), service.RBACAllowEffect),
+	}, nil
+}
+
+func (u *fakeSystemAdminUsecase) ExplainAuthorization(ctx context.Context, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, action domain.RBACAction, object domain.RBACObject) (*domain.RBACExplanation, error) {
+	if appUserID.Int() != 3 {
+		return nil, liberrors.Errorf("ExplainAuthorization. err: %w", service.ErrAppUserNotFound)
+	}
+
+	rbacDomain := service.NewRBACOrganization(organizationID)
+	return &domain.RBACExplanation{
+		Allowed: true,
+		Policy:  domain.NewRBACPolicy(rbacDomain, domain.NewRBACRole("domain:1_role:4"), action, object, service.RBACAllowEffect),
+		SubjectChain: []*domain.RBACGroupingRule{
+			domain.NewRBACGroupingRule("g", "user:3", "domain:1_role:4", rbacDomain),
+		},
+	}, nil
+}
+
+func (u *fakeSystemAdminUsecase) ExportPolicies(ctx context.Context, organizationID *domain.OrganizationID) (*domain.RBACRuleSet, error) {
+	rbacDomain := service.NewRBACOrganization(organizationID)
+	return &domain.RBACRuleSet{
+		Policies: []*domain.RBACPolicy{
+			domain.NewRBACPolicy(rbacDomain, domain.NewRBACRole("domain:1_role:4"), domain.NewRBACAction("read"), domain.NewRBACObject("data"), service.RBACAllowEffect),
+		},
+		GroupingRules: []*domain.RBACGroupingRule{
+			domain.NewRBACGroupingRule("g", "user:3", "domain:1_role:4", rbacDomain),
+		},
+	}, nil
+}
+
+func (u *fakeSystemAdminUsecase) ImportPolicies(ctx context.Context, organizationID *domain.OrganizationID, ruleSet *domain.RBACRuleSet, mode service.PolicyImportMode, dryRun bool) (*domain.RBACRuleSetDiff, error) {
+	if mode != service.PolicyImportModeMerge && mode != service.PolicyImportModeReplace {
+		return nil, liberrors.Errorf("ImportPolicies. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	removed := &domain.RBACRuleSet{}
+	if mode == service.PolicyImportModeReplace {
+		removed.GroupingRules = []*domain.RBACGroupingRule{
+			domain.NewRBACGroupingRule("g", "user:3", "domain:1_role:4", service.NewRBACOrganization(organizationID)),
+		}
+	}
+	return &domain.RBACRuleSetDiff{Added: ruleSet, Removed: removed}, nil
+}
+
+func (u *fakeSystemAdminUsecase) FindAuditEvents(ctx context.Context, organizationID *domain.OrganizationID, condition *service.AuditEventSearchCondition) ([]*domain.AuditEventModel, error) {
+	u.auditCondition = condition
+	actorID, err := domain.NewAppUserID(2)
+	if err != nil {
+		return nil, err
+	}
+	target, err := domain.NewAuditTarget(domain.AuditTargetTypeOrganization, "3")
+	if err != nil {
+		return nil, err
+	}
+	return []*domain.AuditEventModel{
+		{
+			ID:             1,
+			OccurredAt:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			ActorID:        actorID,
+			OrganizationID: organizationID,
+			Action:         domain.AuditActionUpdateOrganization,
+			Target:         target,
+			Before:         `{"name":"ORG"}`,
+			After:          `{"name":"NEW_ORG"}`,
+			RequestID:      "REQUEST_ID",
+		},
+	}, nil
+}
+
+func newSystemAdminRouter(usecase *fakeSystemAdminUsecase) *gin.Engine {
+	router := gin.New()
+	controller.InitAdminRouterGroup(router, controller.NewSystemAdminHandler(usecase))
+	return router
+}
+
+func TestSystemAdminTokenMiddleware(t *testing.T) {
+	t.Parallel()
+	router := gin.New()
+	controller.InitAdminRouterGroup(router, controller.NewSystemAdminHandler(&fakeSystemAdminUsecase{}), controller.NewSystemAdminTokenMiddleware(map[string]string{"admin": "SYSTEM_ADMIN_TOKEN"}))
+	tests := []struct {
+		name           string
+		header         map[string]string
+		expectedStatus int
+	}{
+		{name: "no token", expectedStatus: http.StatusUnauthorized},
+		{name: "invalid token", header: map[string]string{"Authorization": "Bearer ACCESS_TOKEN"}, expectedStatus: http.StatusUnauthorized},
+		{name: "system admin token", header: map[string]string{"Authorization": "Bearer SYSTEM_ADMIN_TOKEN"}, expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		status, _, body := doRequestWithHeader(t, router, http.MethodGet, "/v1/admin/organization/1", tt.header, nil)
+		assert.Equal(t, tt.expectedStatus, status, tt.name)
+		if tt.expectedStatus == http.StatusUnauthorized {
+			assertErrorResponse(t, http.StatusUnauthorized, "unauthenticated", status, body)
+		}
+	}
+}
+
+func TestSystemAdminHandler_AddOrganization(t *testing.T) {
+	t.Parallel()
+	router := newSystemAdminRouter(&fakeSystemAdminUsecase{organizations: map[string]int{}})
+	reqBody := map[string]interface{}{
+		"name":       "ORG",
+		"firstOwner": map[string]string{"loginId": "OWNER", "username": "OWNER_NAME", "password": "PASSWORD"},
+	}
+
+	status, body := doRequest(t, router, http.MethodPost, "/v1/admin/organization", reqBody)
+	require.Equal(t, http.StatusCreated, status)
+	resp := controller.IDResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, 1, resp.ID)
+
+	status, body = doRequest(t, router, http.MethodPost, "/v1/admin/organization", reqBody)
+	assertErrorResponse(t, http.StatusConflict, "already_exists", status, body)
+
+	status, body = doRequest(t, router, http.MethodPost, "/v1/admin/organization", map[string]string{"name": "ORG2"})
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+}
+
+func TestSystemAdminHandler_FindOrganizationByID(t *testing.T) {
+	t.Parallel()
+	status, body := doRequest(t, newSystemAdminRouter(&fakeSystemAdminUsecase{}), http.MethodGet, "/v1/admin/organization/1", nil)
+	assertErrorResponse(t, http.StatusNotFound, "not_found", status, body)
+	assert.Equal(t, service.ErrOrganizationNotFound.Error(), parseErrorResponse(t, body).Message)
+}
+
+func TestSystemAdminHandler_UpdateOrganization(t *testing.T) {
+	t.Parallel()
+	router := newSystemAdminRouter(&fakeSystemAdminUsecase{})
+
+	status, header, body := doRequestWithHeader(t, router, http.MethodPut, "/v1/admin/organization/3", map[string]string{"If-Match": `"1"`}, map[string]string{"name": "NEW_ORG"})
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, `"2"`, header.Get("ETag"))
+	resp := controller.OrganizationResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, controller.OrganizationResponse{ID: 3, Name: "NEW_ORG"}, resp)
+
+	status, _, body = doRequestWithHeader(t, router, http.MethodPut, "/v1/admin/organization/3", map[string]string{"If-Match": `"2"`}, map[string]string{"name": "NEW_ORG"})
+	assertErrorResponse(t, http.StatusConflict, "concurrent_modification", status, body)
+
+	status, _, body = doRequestWithHeader(t, router, http.MethodPut, "/v1/admin/organization/3", nil, map[string]string{"name": "NEW_ORG"})
+	assertErrorResponse(t, http.StatusPreconditionRequired, "precondition_required", status, body)
+}
+
+func TestSystemAdminHandler_ListPolicies(t *testing.T) {
+	t.Parallel()
+	status, body := doRequest(t, newSystemAdminRouter(&fakeSystemAdminUsecase{}), http.MethodGet, "/v1/admin/organization/3/policy", nil)
+	require.Equal(t, http.StatusOK, status)
+	resp := controller.PoliciesResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, []*controller.PolicyResponse{
+		{Subject: "user:2", Action: "Set", Object: "domain:3_role:*", Effect: "allow"},
+	}, resp.Policies)
+}
+
+func TestSystemAdminHandler_ExplainAuthorization(t *testing.T) {
+	t.Parallel()
+	router := newSystemAdminRouter(&fakeSystemAdminUsecase{})
+
+	status, body := doRequest(t, router, http.MethodGet, "/v1/admin/organization/1/user/3/explain?action=read&object=data", nil)
+	require.Equal(t, http.StatusOK, status)
+	resp := controller.ExplanationResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.True(t, resp.Allowed)
+	assert.Equal(t, &controller.PolicyResponse{Subject: "domain:1_role:4", Action: "read", Object: "data", Effect: "allow"}, resp.Policy)
+	assert.Equal(t, []*controller.GroupingRuleResponse{{PType: "g", Child: "user:3", Parent: "domain:1_role:4"}}, resp.SubjectChain)
+	assert.Empty(t, resp.ObjectChain)
+	assert.Equal(t, []string{"p, domain:1_role:4, data, read, allow, domain:1", "g, user:3, domain:1_role:4, domain:1"}, resp.Lines)
+
+	status, body = doRequest(t, router, http.MethodGet, "/v1/admin/organization/1/user/3/explain?action=read", nil)
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+
+	status, body = doRequest(t, router, http.MethodGet, "/v1/admin/organization/1/user/4/explain?action=read&object=data", nil)
+	assertErrorResponse(t, http.StatusNotFound, "not_found", status, body)
+}
+
+func TestSystemAdminHandler_ExportPolicies(t *testing.T) {
+	t.Parallel()
+	router := newSystemAdminRouter(&fakeSystemAdminUsecase{})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/organization/1/policy/export", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "p, domain:1_role:4, data, read, allow, domain:1\ng, user:3, domain:1_role:4, domain:1\n", w.Body.String())
+
+	status, body := doRequest(t, router, http.MethodGet, "/v1/admin/organization/1/policy/export?format=json", nil)
+	require.Equal(t, http.StatusOK, status)
+	ruleSet, err := domain.UnmarshalRBACRuleSet(body, domain.RBACRuleSetFormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p, domain:1_role:4, data, read, allow, domain:1", "g, user:3, domain:1_role:4, domain:1"}, ruleSet.Lines())
+
+	status, body = doRequest(t, router, http.MethodGet, "/v1/admin/organization/1/policy/export?format=xml", nil)
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+}
+
+func TestSystemAdminHandler_ImportPolicies(t *testing.T) {
+	t.Parallel()
+	router := newSystemAdminRouter(&fakeSystemAdminUsecase{})
+	importPolicies := func(query, data string) (int, []byte) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/organization/1/policy/import"+query, strings.NewReader(data))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code, w.Body.Bytes()
+	}
+
+	status, body := importPolicies("?mode=replace&dryRun=true", "p, user:3, data, read, allow\n")
+	require.Equal(t, http.StatusOK, status)
+	resp := controller.RuleSetDiffResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.True(t, resp.DryRun)
+	assert.Equal(t, []string{"p, user:3, data, read, allow, "}, resp.Added)
+	assert.Equal(t, []string{"g, user:3, domain:1_role:4, domain:1"}, resp.Removed)
+
+	status, body = importPolicies("?format=json", `{"groupingRules": [{"ptype": "g2", "child": "doc:1", "parent": "folder:1"}]}`)
+	require.Equal(t, http.StatusOK, status)
+	resp = controller.RuleSetDiffResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.False(t, resp.DryRun)
+	assert.Equal(t, []string{"g2, doc:1, folder:1, "}, resp.Added)
+	assert.Empty(t, resp.Removed)
+
+	status, body = importPolicies("", "x, user:3\n")
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+
+	status, body = importPolicies("?mode=overwrite", "p, user:3, data, read, allow\n")
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+
+	status, body = importPolicies("?dryRun=maybe", "p, user:3, data, read, allow\n")
+	assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+}
+
+func TestSystemAdminHandler_FindAuditEvents(t *testing.T) {
+	t.Parallel()
+	usecase := &fakeSystemAdminUsecase{}
+	router := newSystemAdminRouter(usecase)
+
+	status, body := doRequest(t, router, http.MethodGet, "/v1/admin/organization/3/audit_event?from=2024-01-01T00:00:00Z&to=2024-01-03T00:00:00%2B09:00&actorId=2&targetType=organization&targetId=3&limit=10", nil)
+	require.Equal(t, http.StatusOK, status)
+	resp := controller.AuditEventsResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Len(t, resp.Events, 1)
+	assert.Equal(t, 2, resp.Events[0].ActorID)
+	assert.Equal(t, 3, resp.Events[0].OrganizationID)
+	assert.Equal(t, "organization.update", resp.Events[0].Action)
+	assert.Equal(t, "organization", resp.Events[0].TargetType)
+	assert.Equal(t, "3", resp.Events[0].TargetID)
+	assert.JSONEq(t, `{"name":"ORG"}`, string(resp.Events[0].Before))
+	assert.JSONEq(t, `{"name":"NEW_ORG"}`, string(resp.Events[0].After))
+	assert.Equal(t, "REQUEST_ID", resp.Events[0].RequestID)
+
+	assert.True(t, usecase.auditCondition.From.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, usecase.auditCondition.To.Equal(time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 2, usecase.auditCondition.ActorID.Int())
+	assert.Equal(t, &domain.AuditTarget{Type: domain.AuditTargetTypeOrganization, ID: "3"}, usecase.auditCondition.Target)
+	assert.Equal(t, 10, usecase.auditCondition.Limit)
+
+	// the default limit
+	status, _ = doRequest(t, router, http.MethodGet, "/v1/admin/organization/3/audit_event", nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, service.DefaultAuditEventLimit, usecase.auditCondition.Limit)
+	assert.Nil(t, usecase.auditCondition.ActorID)
+	assert.Nil(t, usecase.auditCondition.Target)
+
+	for _, query := range []string{
+		"from=2024-01-01",
+		"from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z",
+		"actorId=0",
+		"targetType=organization",
+		"limit=1001",
+	} {
+		status, body = doRequest(t, router, http.MethodGet, "/v1/admin/organization/3/audit_event?"+query, nil)
+		assertErrorResponse(t, http.StatusBadRequest, "invalid_argument", status, body)
+	}
+}
@@ -0,0 +1,289 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+	"github.com/kujilabo/redstart/user/usecase"
+)
+
+type UserGroupResponse struct {
+	ID             int    `json:"id"`
+	OrganizationID int    `json:"organizationId"`
+	Key            string `json:"key"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+}
+
+type UserGroupsResponse struct {
+	UserGroups []*UserGroupResponse `json:"userGroups"`
+}
+
+type UserGroupAddRequest struct {
+	Key         string `json:"key" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type UserGroupUpdateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+func toUserGroupResponse(userGroup *domain.UserGroupModel) *UserGroupResponse {
+	return &UserGroupResponse{
+		ID:             userGroup.UserGroupID.Int(),
+		OrganizationID: userGroup.OrganizationID.Int(),
+		Key:            userGroup.Key,
+		Name:           userGroup.Name,
+		Description:    userGroup.Description,
+	}
+}
+
+type UserGroupHandler struct {
+	userGroupUsecase usecase.UserGroupUsecase
+}
+
+func NewUserGroupHandler(userGroupUsecase usecase.UserGroupUsecase) *UserGroupHandler {
+	return &UserGroupHandler{
+		userGroupUsecase: userGroupUsecase,
+	}
+}
+
+func (h *UserGroupHandler) FindAllUserGroups(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOperator(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroups, err := h.userGroupUsecase.FindAllUserGroups(ctx, operator)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.userGroupUsecase.FindAllUserGroups. err: %w", err))
+		return
+	}
+
+	resp := UserGroupsResponse{UserGroups: make([]*UserGroupResponse, len(userGroups))}
+	for i, userGroup := range userGroups {
+		resp.UserGroups[i] = toUserGroupResponse(userGroup)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *UserGroupHandler) FindUserGroupByID(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOperator(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroupID, err := userGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroup, err := h.userGroupUsecase.FindUserGroupByID(ctx, operator, userGroupID)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.userGroupUsecase.FindUserGroupByID. err: %w", err))
+		return
+	}
+
+	setETag(c, userGroup.BaseModel)
+	c.JSON(http.StatusOK, toUserGroupResponse(userGroup.UserGroupModel))
+}
+
+func (h *UserGroupHandler) AddUserGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	req := UserGroupAddRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	param, err := service.NewUserGroupAddParameter(req.Key, req.Name, req.Description)
+	if err != nil {
+		handleError(c, liberrors.Errorf("service.NewUserGroupAddParameter. err: %w", err))
+		return
+	}
+
+	userGroupID, err := h.userGroupUsecase.AddUserGroup(ctx, operator, param)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.userGroupUsecase.AddUserGroup. err: %w", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, IDResponse{ID: userGroupID.Int()})
+}
+
+// UpdateUserGroup updates the name and the description of the user group whose version is the one in the If-Match header
+func (h *UserGroupHandler) UpdateUserGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroupID, err := userGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	req := UserGroupUpdateRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, bindError(err))
+		return
+	}
+
+	param, err := service.NewUserGroupUpdateParameter(req.Name, req.Description)
+	if err != nil {
+		handleError(c, liberrors.Errorf("service.NewUserGroupUpdateParameter. err: %w", err))
+		return
+	}
+
+	userGroup, err := h.userGroupUsecase.UpdateUserGroup(ctx, operator, userGroupID, version, param)
+	if err != nil {
+		handleError(c, liberrors.Errorf("h.userGroupUsecase.UpdateUserGroup. err: %w", err))
+		return
+	}
+
+	setETag(c, userGroup.BaseModel)
+	c.JSON(http.StatusOK, toUserGroupResponse(userGroup.UserGroupModel))
+}
+
+// AddUserToGroup makes the app user a member of the user group
+func (h *UserGroupHandler) AddUserToGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOwner(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroupID, err := userGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.userGroupUsecase.AddUserToGroup(ctx, operator, appUserID, userGroupID); err != nil {
+		handleError(c, liberrors.Errorf("h.userGroupUsecase.AddUserToGroup. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveUserFromGroup removes the app user from the user group. The operator must be allowed to unset the user group
+func (h *UserGroupHandler) RemoveUserFromGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOperator(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroupID, err := userGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	appUserID, err := appUserIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.userGroupUsecase.RemoveUserFromGroup(ctx, operator, appUserID, userGroupID); err != nil {
+		handleError(c, liberrors.Errorf("h.userGroupUsecase.RemoveUserFromGroup. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddGroupToGroup makes the user group a child of the parent user group. The members of the child inherit the permissions of the parent
+func (h *UserGroupHandler) AddGroupToGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOperator(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroupID, err := userGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	parentUserGroupID, err := parentUserGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.userGroupUsecase.AddGroupToGroup(ctx, operator, userGroupID, parentUserGroupID); err != nil {
+		handleError(c, liberrors.Errorf("h.userGroupUsecase.AddGroupToGroup. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveGroupFromGroup removes the user group from the parent user group
+func (h *UserGroupHandler) RemoveGroupFromGroup(c *gin.Context) {
+	ctx := c.Request.Context()
+	operator, err := GetOperator(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	userGroupID, err := userGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	parentUserGroupID, err := parentUserGroupIDParam(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.userGroupUsecase.RemoveGroupFromGroup(ctx, operator, userGroupID, parentUserGroupID); err != nil {
+		handleError(c, liberrors.Errorf("h.userGroupUsecase.RemoveGroupFromGroup. err: %w", err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
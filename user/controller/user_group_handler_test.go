@@ -0,0 +1,288 @@
+package controller_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type fakeUserGroupUsecase struct {
+	userGroups    []*domain.UserGroupModel
+	members       map[int][]int
+	addUserErr    error
+	removeUserErr error
+	parents       map[int][]int
+	groupErr      error
+}
+
+func (u *fakeUserGroupUsecase) FindAllUserGroups(ctx context.Context, operator service.AppUserInterface) ([]*domain.UserGroupModel, error) {
+	return u.userGroups, nil
+}
+
+func (u *fakeUserGroupUsecase) FindUserGroupByID(ctx context.Context, operator service.AppUserInterface, userGroupID *domain.UserGroupID) (*service.UserGroup, error) {
+	for _, userGroup := range u.userGroups {
+		if userGroup.UserGroupID.Int() == userGroupID.Int() {
+			return service.NewUserGroup(userGroup)
+		}
+	}
+	return nil, liberrors.Errorf("FindUserGroupByID. err: %w", service.ErrUserGroupNotFound)
+}
+
+func (u *fakeUserGroupUsecase) AddUserGroup(ctx context.Context, operator service.OwnerModelInterface, param service.UserGroupAddParameterInterface) (*domain.UserGroupID, error) {
+	return domain.NewUserGroupID(20)
+}
+
+// UpdateUserGroup accepts only version 1 because the versions of the test user groups are unknown
+func (u *fakeUserGroupUsecase) UpdateUserGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, version int, param service.UserGroupUpdateParameterInterface) (*service.UserGroup, error) {
+	if version != 1 {
+		return nil, liberrors.Errorf("UpdateUserGroup. err: %w", libdomain.ErrConcurrentModification)
+	}
+	baseModel, err := libdomain.NewBaseModel(version+1, time.Now(), time.Now(), 1, operator.AppUserID().Int())
+	if err != nil {
+		return nil, err
+	}
+	userGroup, err := domain.NewUserGroupModel(baseModel, userGroupID, operator.OrganizationID(), "KEY", param.Name(), param.Description())
+	if err != nil {
+		return nil, err
+	}
+	return service.NewUserGroup(userGroup)
+}
+
+func (u *fakeUserGroupUsecase) AddUserToGroup(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	if u.addUserErr != nil {
+		return u.addUserErr
+	}
+	u.members[userGroupID.Int()] = append(u.members[userGroupID.Int()], appUserID.Int())
+	return nil
+}
+
+func (u *fakeUserGroupUsecase) RemoveUserFromGroup(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	if u.removeUserErr != nil {
+		return u.removeUserErr
+	}
+	members := make([]int, 0)
+	for _, member := range u.members[userGroupID.Int()] {
+		if member != appUserID.Int() {
+			members = append(members, member)
+		}
+	}
+	u.members[userGroupID.Int()] = members
+	return nil
+}
+
+func (u *fakeUserGroupUsecase) AddGroupToGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error {
+	if u.groupErr != nil {
+		return u.groupErr
+	}
+	u.parents[childUserGroupID.Int()] = append(u.parents[childUserGroupID.Int()], parentUserGroupID.Int())
+	return nil
+}
+
+func (u *fakeUserGroupUsecase) RemoveGroupFromGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error {
+	if u.groupErr != nil {
+		return u.groupErr
+	}
+	delete(u.parents, childUserGroupID.Int())
+	return nil
+}
+
+func newUserGroupRouter(operator service.AppUserInterface, usecase *fakeUserGroupUsecase) *gin.Engine {
+	return newTestRouter(operator, func(router gin.IRouter, middleware gin.HandlerFunc) {
+		controller.InitRouterGroup(router, controller.NewAppUserHandler(nil), controller.NewUserGroupHandler(usecase), controller.NewPolicyHandler(nil), middleware)
+	})
+}
+
+func newTestUserGroupModel(t *testing.T, userGroupID int, key string) *domain.UserGroupModel {
+	t.Helper()
+	userGroupIDTmp, err := domain.NewUserGroupID(userGroupID)
+	require.NoError(t, err)
+	organizationID, err := domain.NewOrganizationID(1)
+	require.NoError(t, err)
+	userGroup, err := domain.NewUserGroupModel(nil, userGroupIDTmp, organizationID, key, key+"_NAME", "")
+	require.NoError(t, err)
+	return userGroup
+}
+
+func TestUserGroupHandler_FindUserGroups(t *testing.T) {
+	t.Parallel()
+	usecase := &fakeUserGroupUsecase{userGroups: []*domain.UserGroupModel{
+		newTestUserGroupModel(t, 1, "__owner"),
+		newTestUserGroupModel(t, 2, "KEY"),
+	}}
+	router := newUserGroupRouter(newTestAppUser(t), usecase)
+
+	status, body := doRequest(t, router, http.MethodGet, "/v1/user_group", nil)
+	require.Equal(t, http.StatusOK, status)
+	resp := controller.UserGroupsResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Len(t, resp.UserGroups, 2)
+	assert.Equal(t, "KEY", resp.UserGroups[1].Key)
+
+	status, body = doRequest(t, router, http.MethodGet, "/v1/user_group/2", nil)
+	require.Equal(t, http.StatusOK, status)
+	userGroup := controller.UserGroupResponse{}
+	require.NoError(t, json.Unmarshal(body, &userGroup))
+	assert.Equal(t, controller.UserGroupResponse{ID: 2, OrganizationID: 1, Key: "KEY", Name: "KEY_NAME"}, userGroup)
+
+	status, body = doRequest(t, router, http.MethodGet, "/v1/user_group/3", nil)
+	assertErrorResponse(t, http.StatusNotFound, "not_found", status, body)
+}
+
+func TestUserGroupHandler_AddUserGroup(t *testing.T) {
+	t.Parallel()
+	reqBody := map[string]string{"key": "KEY", "name": "NAME"}
+
+	status, body := doRequest(t, newUserGroupRouter(newTestOwner(t), &fakeUserGroupUsecase{}), http.MethodPost, "/v1/user_group", reqBody)
+	require.Equal(t, http.StatusCreated, status)
+	resp := controller.IDResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, 20, resp.ID)
+
+	status, body = doRequest(t, newUserGroupRouter(newTestAppUser(t), &fakeUserGroupUsecase{}), http.MethodPost, "/v1/user_group", reqBody)
+	assertErrorResponse(t, http.StatusForbidden, "permission_denied", status, body)
+}
+
+func TestUserGroupHandler_UpdateUserGroup(t *testing.T) {
+	t.Parallel()
+	reqBody := map[string]string{"name": "NEW_NAME", "description": "DESCRIPTION"}
+	router := newUserGroupRouter(newTestOwner(t), &fakeUserGroupUsecase{})
+
+	status, header, body := doRequestWithHeader(t, router, http.MethodPut, "/v1/user_group/2", map[string]string{"If-Match": `"1"`}, reqBody)
+	require.Equal(t, http.StatusOK, status, string(body))
+	assert.Equal(t, `"2"`, header.Get("ETag"))
+	resp := controller.UserGroupResponse{}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, controller.UserGroupResponse{ID: 2, OrganizationID: 1, Key: "KEY", Name: "NEW_NAME", Description: "DESCRIPTION"}, resp)
+
+	// - weak ETags are accepted too
+	status, _, body = doRequestWithHeader(t, router, http.MethodPut, "/v1/user_group/2", map[string]string{"If-Match": `W/"1"`}, reqBody)
+	require.Equal(t, http.StatusOK, status, string(body))
+
+	status, _, body = doRequestWithHeader(t, router, http.MethodPut, "/v1/user_group/2", map[string]string{"If-Match": `"3"`}, reqBody)
+	assertErrorResponse(t, http.StatusConflict, "concurrent_modification", status, body)
+
+	status, _, body = doRequestWithHeader(t, router, http.MethodPut, "/v1/user_group/2", nil, reqBody)
+	assertErrorResponse(t, http.StatusPreconditionRequired, "precondition_required", status, body)
+
+	status, _, body = doRequestWithHeader(t, newUserGroupRouter(newTestAppUser(t), &fakeUserGroupUsecase{}), http.MethodPut, "/v1/user_group/2", map[string]string{"If-Match": `"1"`}, reqBody)
+	assertErrorResponse(t, http.StatusForbidden, "permission_denied", status, body)
+}
+
+func TestUserGroupHandler_AddUserToGroup(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		operator   service.AppUserInterface
+		addUserErr error
+		path       string
+		status     int
+		code       string
+	}{
+		{name: "added", operator: newTestOwner(t), path: "/v1/user_group/2/user/5", status: http.StatusNoContent},
+		{name: "not owner", operator: newTestAppUser(t), path: "/v1/user_group/2/user/5", status: http.StatusForbidden, code: "permission_denied"},
+		{name: "invalid user ID", operator: newTestOwner(t), path: "/v1/user_group/2/user/0", status: http.StatusBadRequest, code: "invalid_argument"},
+		{name: "user not found", operator: newTestOwner(t), addUserErr: liberrors.Errorf("err: %w", service.ErrAppUserNotFound), path: "/v1/user_group/2/user/5", status: http.StatusNotFound, code: "not_found"},
+		{name: "permission denied", operator: newTestOwner(t), addUserErr: liberrors.Errorf("err: %w", libdomain.ErrPermissionDenied), path: "/v1/user_group/2/user/5", status: http.StatusForbidden, code: "permission_denied"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := &fakeUserGroupUsecase{members: map[int][]int{}, addUserErr: tt.addUserErr}
+			status, body := doRequest(t, newUserGroupRouter(tt.operator, usecase), http.MethodPut, tt.path, nil)
+			if tt.code != "" {
+				assertErrorResponse(t, tt.status, tt.code, status, body)
+				assert.Empty(t, usecase.members)
+				return
+			}
+
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, map[int][]int{2: {5}}, usecase.members)
+		})
+	}
+}
+
+func TestUserGroupHandler_RemoveUserFromGroup(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		operator      service.AppUserInterface
+		removeUserErr error
+		path          string
+		status        int
+		code          string
+	}{
+		{name: "removed", operator: newTestAppUser(t), path: "/v1/user_group/2/user/5", status: http.StatusNoContent},
+		{name: "unauthenticated", operator: nil, path: "/v1/user_group/2/user/5", status: http.StatusUnauthorized, code: "unauthenticated"},
+		{name: "invalid group ID", operator: newTestOwner(t), path: "/v1/user_group/abc/user/5", status: http.StatusBadRequest, code: "invalid_argument"},
+		{name: "not a member", operator: newTestOwner(t), removeUserErr: liberrors.Errorf("err: %w", service.ErrPairOfUserAndGroupNotFound), path: "/v1/user_group/2/user/5", status: http.StatusNotFound, code: "not_found"},
+		{name: "permission denied", operator: newTestAppUser(t), removeUserErr: liberrors.Errorf("err: %w", libdomain.ErrPermissionDenied), path: "/v1/user_group/2/user/5", status: http.StatusForbidden, code: "permission_denied"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := &fakeUserGroupUsecase{members: map[int][]int{2: {3, 5}}, removeUserErr: tt.removeUserErr}
+			status, body := doRequest(t, newUserGroupRouter(tt.operator, usecase), http.MethodDelete, tt.path, nil)
+			if tt.code != "" {
+				assertErrorResponse(t, tt.status, tt.code, status, body)
+				assert.Equal(t, map[int][]int{2: {3, 5}}, usecase.members)
+				return
+			}
+
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, map[int][]int{2: {3}}, usecase.members)
+		})
+	}
+}
+
+func TestUserGroupHandler_AddAndRemoveGroupToGroup(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		method   string
+		groupErr error
+		path     string
+		status   int
+		code     string
+		parents  map[int][]int
+	}{
+		{name: "added", method: http.MethodPut, path: "/v1/user_group/2/parent/4", status: http.StatusNoContent, parents: map[int][]int{2: {4}}},
+		{name: "removed", method: http.MethodDelete, path: "/v1/user_group/2/parent/4", status: http.StatusNoContent, parents: map[int][]int{}},
+		{name: "invalid parent ID", method: http.MethodPut, path: "/v1/user_group/2/parent/0", status: http.StatusBadRequest, code: "invalid_argument"},
+		{name: "cycle", method: http.MethodPut, groupErr: liberrors.Errorf("err: %w", service.ErrUserGroupCycle), path: "/v1/user_group/2/parent/4", status: http.StatusBadRequest, code: "invalid_argument"},
+		{name: "already exists", method: http.MethodPut, groupErr: liberrors.Errorf("err: %w", service.ErrPairOfGroupAndGroupAlreadyExists), path: "/v1/user_group/2/parent/4", status: http.StatusConflict, code: "already_exists"},
+		{name: "not a child", method: http.MethodDelete, groupErr: liberrors.Errorf("err: %w", service.ErrPairOfGroupAndGroupNotFound), path: "/v1/user_group/2/parent/4", status: http.StatusNotFound, code: "not_found"},
+		{name: "permission denied", method: http.MethodDelete, groupErr: liberrors.Errorf("err: %w", libdomain.ErrPermissionDenied), path: "/v1/user_group/2/parent/4", status: http.StatusForbidden, code: "permission_denied"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			usecase := &fakeUserGroupUsecase{parents: map[int][]int{}, groupErr: tt.groupErr}
+			if tt.method == http.MethodDelete {
+				usecase.parents[2] = []int{4}
+			}
+			status, body := doRequest(t, newUserGroupRouter(newTestAppUser(t), usecase), tt.method, tt.path, nil)
+			if tt.code != "" {
+				assertErrorResponse(t, tt.status, tt.code, status, body)
+				return
+			}
+
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.parents, usecase.parents)
+		})
+	}
+}
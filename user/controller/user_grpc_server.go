@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	liblog "github.com/kujilabo/redstart/lib/log"
+	userv1 "github.com/kujilabo/redstart/proto/redstart/user/v1"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+// UserGRPCServer serves userv1.UserService for the other services
+type UserGRPCServer struct {
+	userv1.UnimplementedUserServiceServer
+	rf service.RepositoryFactory
+}
+
+func NewUserGRPCServer(rf service.RepositoryFactory) *UserGRPCServer {
+	return &UserGRPCServer{
+		rf: rf,
+	}
+}
+
+// NewGRPCServer returns the gRPC server which traces the calls with tp and accepts the bearer tokens of the services in the "authorization" metadata
+func NewGRPCServer(tp trace.TracerProvider, propagator propagation.TextMapPropagator, serviceTokens map[string]string, userServer userv1.UserServiceServer) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithTracerProvider(tp), otelgrpc.WithPropagators(propagator))),
+		grpc.ChainUnaryInterceptor(
+			newGRPCServiceTokenInterceptor(serviceTokens),
+			grpcErrorInterceptor,
+		),
+	)
+	userv1.RegisterUserServiceServer(server, userServer)
+
+	return server
+}
+
+func newGRPCServiceTokenInterceptor(serviceTokens map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, service.ErrUnauthenticated.Error())
+		}
+
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok || token == "" {
+			return nil, status.Error(codes.Unauthenticated, service.ErrUnauthenticated.Error())
+		}
+
+		if _, ok := findServiceByToken(serviceTokens, token); !ok {
+			return nil, status.Error(codes.Unauthenticated, service.ErrUnauthenticated.Error())
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// grpcErrorInterceptor converts the errors into the gRPC status in the same way as handleError
+func grpcErrorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+
+	logger := liblog.GetLoggerFromContext(ctx, UserControllerContextKey)
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			logger.InfoContext(ctx, fmt.Sprintf("request failed. method: %s, code: %s, err: %v", info.FullMethod, m.grpcCode, err))
+			return nil, status.Error(m.grpcCode, m.err.Error())
+		}
+	}
+
+	logger.ErrorContext(ctx, fmt.Sprintf("request failed. method: %s, err: %+v", info.FullMethod, err))
+	return nil, status.Error(codes.Internal, "internal error")
+}
+
+func (s *UserGRPCServer) findSystemOwner(ctx context.Context, organizationID int32) (*service.SystemOwner, error) {
+	if organizationID <= 0 {
+		return nil, liberrors.Errorf("invalid organizationId. organizationId: %d, err: %w", organizationID, libdomain.ErrInvalidArgument)
+	}
+
+	orgID, err := domain.NewOrganizationID(int(organizationID))
+	if err != nil {
+		return nil, liberrors.Errorf("domain.NewOrganizationID. err: %w", err)
+	}
+
+	sysAd, err := service.NewSystemAdmin(ctx, s.rf)
+	if err != nil {
+		return nil, liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+	}
+
+	sysOwner, err := sysAd.FindSystemOwnerByOrganizationID(ctx, orgID)
+	if err != nil {
+		return nil, liberrors.Errorf("sysAd.FindSystemOwnerByOrganizationID. err: %w", err)
+	}
+
+	return sysOwner, nil
+}
+
+func (s *UserGRPCServer) findAppUser(ctx context.Context, organizationID, appUserID int32, options ...service.Option) (*service.AppUser, error) {
+	sysOwner, err := s.findSystemOwner(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if appUserID <= 0 {
+		return nil, liberrors.Errorf("invalid appUserId. appUserId: %d, err: %w", appUserID, libdomain.ErrInvalidArgument)
+	}
+
+	id, err := domain.NewAppUserID(int(appUserID))
+	if err != nil {
+		return nil, liberrors.Errorf("domain.NewAppUserID. err: %w", err)
+	}
+
+	appUser, err := s.rf.NewAppUserRepository(ctx).FindAppUserByID(ctx, sysOwner, id, options...)
+	if err != nil {
+		return nil, liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", err)
+	}
+
+	return appUser, nil
+}
+
+func toAppUserProto(appUser *service.AppUser) *userv1.AppUser {
+	return &userv1.AppUser{
+		Id:             int32(appUser.AppUserID().Int()),
+		OrganizationId: int32(appUser.OrganizationID().Int()),
+		LoginId:        appUser.LoginID(),
+		Username:       appUser.Username(),
+	}
+}
+
+func (s *UserGRPCServer) Authorize(ctx context.Context, req *userv1.AuthorizeRequest) (*userv1.AuthorizeResponse, error) {
+	resp, err := s.authorizeBatch(ctx, &userv1.AuthorizeBatchRequest{Requests: []*userv1.AuthorizeRequest{req}}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Results[0], nil
+}
+
+// AuthorizeBatch accepts up to 100 requests. The requests may be of different app users.
+// The requests of the app users who are not found are denied instead of failing the whole batch
+func (s *UserGRPCServer) AuthorizeBatch(ctx context.Context, req *userv1.AuthorizeBatchRequest) (*userv1.AuthorizeBatchResponse, error) {
+	return s.authorizeBatch(ctx, req, true)
+}
+
+// authorizeBatch returns service.ErrAppUserNotFound when one of the app users is not found unless denyNotFoundAppUsers is true
+func (s *UserGRPCServer) authorizeBatch(ctx context.Context, req *userv1.AuthorizeBatchRequest, denyNotFoundAppUsers bool) (*userv1.AuthorizeBatchResponse, error) {
+	if len(req.Requests) == 0 || len(req.Requests) > 100 {
+		return nil, liberrors.Errorf("the number of the requests must be between 1 and 100. requests: %d, err: %w", len(req.Requests), libdomain.ErrInvalidArgument)
+	}
+
+	// the requests are grouped by the app users so that the policies of each app user are loaded once
+	pending := make(map[[2]int32][]int)
+	keys := make([][2]int32, 0)
+	for i, r := range req.Requests {
+		if r.Action == "" || r.Object == "" {
+			return nil, liberrors.Errorf("action and object are required. err: %w", libdomain.ErrInvalidArgument)
+		}
+
+		key := [2]int32{r.OrganizationId, r.AppUserId}
+		if _, ok := pending[key]; !ok {
+			keys = append(keys, key)
+		}
+		pending[key] = append(pending[key], i)
+	}
+
+	authorizationManager := s.rf.NewAuthorizationManager(ctx)
+	resp := userv1.AuthorizeBatchResponse{Results: make([]*userv1.AuthorizeResponse, len(req.Requests))}
+	for _, key := range keys {
+		indexes := pending[key]
+		appUser, err := s.findAppUser(ctx, key[0], key[1])
+		if denyNotFoundAppUsers && errors.Is(err, service.ErrAppUserNotFound) {
+			for _, i := range indexes {
+				resp.Results[i] = &userv1.AuthorizeResponse{Allowed: false}
+			}
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		authRequests := make([]service.AuthRequest, len(indexes))
+		for j, i := range indexes {
+			r := req.Requests[i]
+			authRequests[j] = service.AuthRequest{Action: domain.NewRBACAction(r.Action), Object: domain.NewRBACObject(r.Object), Attributes: r.Attributes.AsMap()}
+		}
+
+		results, err := authorizationManager.AuthorizeBatch(ctx, appUser, authRequests)
+		if err != nil {
+			return nil, liberrors.Errorf("authorizationManager.AuthorizeBatch. err: %w", err)
+		}
+
+		for j, i := range indexes {
+			resp.Results[i] = &userv1.AuthorizeResponse{Allowed: results[j]}
+		}
+	}
+
+	return &resp, nil
+}
+
+func (s *UserGRPCServer) FindAppUserByID(ctx context.Context, req *userv1.FindAppUserByIDRequest) (*userv1.FindAppUserByIDResponse, error) {
+	appUser, err := s.findAppUser(ctx, req.OrganizationId, req.AppUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.FindAppUserByIDResponse{AppUser: toAppUserProto(appUser)}, nil
+}
+
+func (s *UserGRPCServer) FindAppUserByLoginID(ctx context.Context, req *userv1.FindAppUserByLoginIDRequest) (*userv1.FindAppUserByLoginIDResponse, error) {
+	if req.LoginId == "" {
+		return nil, liberrors.Errorf("loginId is required. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	sysOwner, err := s.findSystemOwner(ctx, req.OrganizationId)
+	if err != nil {
+		return nil, err
+	}
+
+	appUser, err := sysOwner.FindAppUserByLoginID(ctx, req.LoginId)
+	if err != nil {
+		return nil, liberrors.Errorf("sysOwner.FindAppUserByLoginID. err: %w", err)
+	}
+
+	return &userv1.FindAppUserByLoginIDResponse{AppUser: toAppUserProto(appUser)}, nil
+}
+
+func (s *UserGRPCServer) ListUserGroupsOfAppUser(ctx context.Context, req *userv1.ListUserGroupsOfAppUserRequest) (*userv1.ListUserGroupsOfAppUserResponse, error) {
+	appUser, err := s.findAppUser(ctx, req.OrganizationId, req.AppUserId, service.IncludeGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := userv1.ListUserGroupsOfAppUserResponse{UserGroups: make([]*userv1.UserGroup, len(appUser.UserGroups))}
+	for i, userGroup := range appUser.UserGroups {
+		resp.UserGroups[i] = &userv1.UserGroup{
+			Id:             int32(userGroup.UserGroupID.Int()),
+			OrganizationId: int32(userGroup.OrganizationID.Int()),
+			Key:            userGroup.Key,
+			Name:           userGroup.Name,
+			Description:    userGroup.Description,
+		}
+	}
+
+	return &resp, nil
+}
+
+// IsMemberOfUserGroup returns whether the app user belongs to the user group directly
+func (s *UserGRPCServer) IsMemberOfUserGroup(ctx context.Context, req *userv1.IsMemberOfUserGroupRequest) (*userv1.IsMemberOfUserGroupResponse, error) {
+	appUser, err := s.findAppUser(ctx, req.OrganizationId, req.AppUserId, service.IncludeGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userGroup := range appUser.UserGroups {
+		if userGroup.UserGroupID.Int() == int(req.UserGroupId) {
+			return &userv1.IsMemberOfUserGroupResponse{Member: true}, nil
+		}
+	}
+
+	return &userv1.IsMemberOfUserGroupResponse{Member: false}, nil
+}
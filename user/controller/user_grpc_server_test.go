@@ -0,0 +1,256 @@
+package controller_test
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
+	userv1 "github.com/kujilabo/redstart/proto/redstart/user/v1"
+	"github.com/kujilabo/redstart/sqls"
+	testlibgateway "github.com/kujilabo/redstart/testlib/gateway"
+	"github.com/kujilabo/redstart/user/controller"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+const grpcServiceToken = "BILLING_SERVICE_TOKEN"
+
+var (
+	grpcDBOnce sync.Once
+	grpcRF     service.RepositoryFactory
+)
+
+// newGRPCRepositoryFactory returns the repository factory over the SQLite database, which is created once in the package
+func newGRPCRepositoryFactory(t *testing.T) service.RepositoryFactory {
+	t.Helper()
+	grpcDBOnce.Do(func() {
+		ctx := context.Background()
+		db, err := testlibgateway.InitSQLiteInFile(sqls.SQL)
+		require.NoError(t, err)
+
+		dialect := libgateway.DialectSQLite{}
+		rf, err := gateway.NewRepositoryFactory(ctx, &dialect, dialect.Name(), db, time.UTC, nil)
+		require.NoError(t, err)
+		require.NoError(t, rf.NewAuthorizationManager(ctx).Init(ctx))
+		grpcRF = rf
+	})
+	require.NotNil(t, grpcRF)
+	return grpcRF
+}
+
+func randString(n int) string {
+	letters := []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+	b := make([]rune, n)
+	for i := range b {
+		val, err := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
+		if err != nil {
+			panic(err)
+		}
+		b[i] = letters[val.Int64()]
+	}
+	return string(b)
+}
+
+// setupGRPCOrganization adds an organization whose first owner is "OWNER_ID"
+func setupGRPCOrganization(ctx context.Context, t *testing.T, rf service.RepositoryFactory) (*service.SystemOwner, *service.AppUser) {
+	t.Helper()
+	sysAd, err := service.NewSystemAdmin(ctx, rf)
+	require.NoError(t, err)
+	firstOwnerAddParam, err := service.NewAppUserAddParameter("OWNER_ID", "OWNER_NAME", "OWNER_PASSWORD", "", "", "", "")
+	require.NoError(t, err)
+	orgAddParam, err := service.NewOrganizationAddParameter(randString(8), firstOwnerAddParam)
+	require.NoError(t, err)
+	orgID, err := sysAd.AddOrganization(ctx, orgAddParam)
+	require.NoError(t, err)
+
+	sysOwner, err := sysAd.FindSystemOwnerByOrganizationID(ctx, orgID)
+	require.NoError(t, err)
+	owner, err := sysOwner.FindAppUserByLoginID(ctx, "OWNER_ID")
+	require.NoError(t, err)
+	return sysOwner, owner
+}
+
+// newGRPCClient serves the user service over bufconn and returns the client which sends token
+func newGRPCClient(t *testing.T, rf service.RepositoryFactory, token string) (userv1.UserServiceClient, *tracetest.SpanRecorder) {
+	t.Helper()
+	spanRecorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder))
+	server := controller.NewGRPCServer(tp, propagation.TraceContext{}, map[string]string{"billing": grpcServiceToken}, controller.NewUserGRPCServer(rf))
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return userv1.NewUserServiceClient(conn), spanRecorder
+}
+
+func TestUserGRPCServer_Authorize(t *testing.T) {
+	ctx := context.Background()
+	rf := newGRPCRepositoryFactory(t)
+	_, owner := setupGRPCOrganization(ctx, t, rf)
+	client, spanRecorder := newGRPCClient(t, rf, grpcServiceToken)
+
+	orgID := int32(owner.OrganizationID().Int())
+	ownerID := int32(owner.AppUserID().Int())
+	allUserRoles := service.NewRBACAllUserRolesObject(owner.OrganizationID()).Object()
+
+	// - the owner can set all the user roles
+	resp, err := client.Authorize(ctx, &userv1.AuthorizeRequest{OrganizationId: orgID, AppUserId: ownerID, Action: service.RBACSetAction.Action(), Object: allUserRoles})
+	require.NoError(t, err)
+	assert.True(t, resp.Allowed)
+
+	// - the owner cannot perform the unknown action
+	resp, err = client.Authorize(ctx, &userv1.AuthorizeRequest{OrganizationId: orgID, AppUserId: ownerID, Action: "UNKNOWN", Object: allUserRoles})
+	require.NoError(t, err)
+	assert.False(t, resp.Allowed)
+
+	// - the results are in the order of the requests
+	batchResp, err := client.AuthorizeBatch(ctx, &userv1.AuthorizeBatchRequest{Requests: []*userv1.AuthorizeRequest{
+		{OrganizationId: orgID, AppUserId: ownerID, Action: "UNKNOWN", Object: allUserRoles},
+		{OrganizationId: orgID, AppUserId: ownerID, Action: service.RBACUnsetAction.Action(), Object: allUserRoles},
+	}})
+	require.NoError(t, err)
+	require.Len(t, batchResp.Results, 2)
+	assert.False(t, batchResp.Results[0].Allowed)
+	assert.True(t, batchResp.Results[1].Allowed)
+
+	// - only the requests of the app user who is not found are denied
+	batchResp, err = client.AuthorizeBatch(ctx, &userv1.AuthorizeBatchRequest{Requests: []*userv1.AuthorizeRequest{
+		{OrganizationId: orgID, AppUserId: ownerID, Action: service.RBACSetAction.Action(), Object: allUserRoles},
+		{OrganizationId: orgID, AppUserId: 99999, Action: service.RBACSetAction.Action(), Object: allUserRoles},
+		{OrganizationId: orgID, AppUserId: ownerID, Action: service.RBACUnsetAction.Action(), Object: allUserRoles},
+	}})
+	require.NoError(t, err)
+	require.Len(t, batchResp.Results, 3)
+	assert.True(t, batchResp.Results[0].Allowed)
+	assert.False(t, batchResp.Results[1].Allowed)
+	assert.True(t, batchResp.Results[2].Allowed)
+
+	assert.NotEmpty(t, spanRecorder.Ended())
+}
+
+func TestUserGRPCServer_Authorize_error(t *testing.T) {
+	ctx := context.Background()
+	rf := newGRPCRepositoryFactory(t)
+	_, owner := setupGRPCOrganization(ctx, t, rf)
+	orgID := int32(owner.OrganizationID().Int())
+	ownerID := int32(owner.AppUserID().Int())
+
+	tests := []struct {
+		name         string
+		token        string
+		req          *userv1.AuthorizeRequest
+		expectedCode codes.Code
+	}{
+		{name: "no token", req: &userv1.AuthorizeRequest{OrganizationId: orgID, AppUserId: ownerID, Action: "read", Object: "doc:1"}, expectedCode: codes.Unauthenticated},
+		{name: "invalid token", token: "ACCESS_TOKEN", req: &userv1.AuthorizeRequest{OrganizationId: orgID, AppUserId: ownerID, Action: "read", Object: "doc:1"}, expectedCode: codes.Unauthenticated},
+		{name: "no object", token: grpcServiceToken, req: &userv1.AuthorizeRequest{OrganizationId: orgID, AppUserId: ownerID, Action: "read"}, expectedCode: codes.InvalidArgument},
+		{name: "invalid organization ID", token: grpcServiceToken, req: &userv1.AuthorizeRequest{OrganizationId: -1, AppUserId: ownerID, Action: "read", Object: "doc:1"}, expectedCode: codes.InvalidArgument},
+		{name: "app user not found", token: grpcServiceToken, req: &userv1.AuthorizeRequest{OrganizationId: orgID, AppUserId: 99999, Action: "read", Object: "doc:1"}, expectedCode: codes.NotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newGRPCClient(t, rf, tt.token)
+			_, err := client.Authorize(ctx, tt.req)
+			assert.Equal(t, tt.expectedCode, status.Code(err), err)
+		})
+	}
+
+	// - too many requests
+	client, _ := newGRPCClient(t, rf, grpcServiceToken)
+	reqs := make([]*userv1.AuthorizeRequest, 101)
+	for i := range reqs {
+		reqs[i] = &userv1.AuthorizeRequest{OrganizationId: orgID, AppUserId: ownerID, Action: "read", Object: "doc:1"}
+	}
+	_, err := client.AuthorizeBatch(ctx, &userv1.AuthorizeBatchRequest{Requests: reqs})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestUserGRPCServer_FindAppUser(t *testing.T) {
+	ctx := context.Background()
+	rf := newGRPCRepositoryFactory(t)
+	_, owner := setupGRPCOrganization(ctx, t, rf)
+	client, _ := newGRPCClient(t, rf, grpcServiceToken)
+	orgID := int32(owner.OrganizationID().Int())
+	ownerID := int32(owner.AppUserID().Int())
+
+	byIDResp, err := client.FindAppUserByID(ctx, &userv1.FindAppUserByIDRequest{OrganizationId: orgID, AppUserId: ownerID})
+	require.NoError(t, err)
+	assert.Equal(t, ownerID, byIDResp.AppUser.Id)
+	assert.Equal(t, orgID, byIDResp.AppUser.OrganizationId)
+	assert.Equal(t, "OWNER_ID", byIDResp.AppUser.LoginId)
+	assert.Equal(t, "OWNER_NAME", byIDResp.AppUser.Username)
+
+	byLoginIDResp, err := client.FindAppUserByLoginID(ctx, &userv1.FindAppUserByLoginIDRequest{OrganizationId: orgID, LoginId: "OWNER_ID"})
+	require.NoError(t, err)
+	assert.Equal(t, ownerID, byLoginIDResp.AppUser.Id)
+
+	// - not found
+	_, err = client.FindAppUserByID(ctx, &userv1.FindAppUserByIDRequest{OrganizationId: orgID, AppUserId: 99999})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.FindAppUserByLoginID(ctx, &userv1.FindAppUserByLoginIDRequest{OrganizationId: orgID, LoginId: "NOT_FOUND"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestUserGRPCServer_UserGroups(t *testing.T) {
+	ctx := context.Background()
+	rf := newGRPCRepositoryFactory(t)
+	_, owner := setupGRPCOrganization(ctx, t, rf)
+	client, _ := newGRPCClient(t, rf, grpcServiceToken)
+	orgID := int32(owner.OrganizationID().Int())
+	ownerID := int32(owner.AppUserID().Int())
+
+	resp, err := client.ListUserGroupsOfAppUser(ctx, &userv1.ListUserGroupsOfAppUserRequest{OrganizationId: orgID, AppUserId: ownerID})
+	require.NoError(t, err)
+	var ownerGroup *userv1.UserGroup
+	for _, userGroup := range resp.UserGroups {
+		if userGroup.Key == service.OwnerGroupKey {
+			ownerGroup = userGroup
+		}
+	}
+	require.NotNil(t, ownerGroup)
+	assert.Equal(t, orgID, ownerGroup.OrganizationId)
+
+	memberResp, err := client.IsMemberOfUserGroup(ctx, &userv1.IsMemberOfUserGroupRequest{OrganizationId: orgID, AppUserId: ownerID, UserGroupId: ownerGroup.Id})
+	require.NoError(t, err)
+	assert.True(t, memberResp.Member)
+
+	memberResp, err = client.IsMemberOfUserGroup(ctx, &userv1.IsMemberOfUserGroupRequest{OrganizationId: orgID, AppUserId: ownerID, UserGroupId: 99999})
+	require.NoError(t, err)
+	assert.False(t, memberResp.Member)
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+type AuditAction string
+
+const (
+	AuditActionAddOrganization      AuditAction = "organization.add"
+	AuditActionUpdateOrganization   AuditAction = "organization.update"
+	AuditActionAddAppUser           AuditAction = "app_user.add"
+	AuditActionUpdateAppUser        AuditAction = "app_user.update"
+	AuditActionChangePassword       AuditAction = "app_user.change_password"
+	AuditActionRemoveAppUser        AuditAction = "app_user.remove"
+	AuditActionRestoreAppUser       AuditAction = "app_user.restore"
+	AuditActionAddUserGroup         AuditAction = "user_group.add"
+	AuditActionUpdateUserGroup      AuditAction = "user_group.update"
+	AuditActionAddUserToGroup       AuditAction = "membership.add_user"
+	AuditActionRemoveUserFromGroup  AuditAction = "membership.remove_user"
+	AuditActionAddGroupToGroup      AuditAction = "membership.add_group"
+	AuditActionRemoveGroupFromGroup AuditAction = "membership.remove_group"
+	AuditActionAddPolicy            AuditAction = "policy.add"
+	AuditActionRemovePolicy         AuditAction = "policy.remove"
+	AuditActionImportPolicies       AuditAction = "policy.import"
+)
+
+type AuditTargetType string
+
+const (
+	AuditTargetTypeOrganization AuditTargetType = "organization"
+	AuditTargetTypeAppUser      AuditTargetType = "app_user"
+	AuditTargetTypeUserGroup    AuditTargetType = "user_group"
+	// AuditTargetTypeRBACSubject is the subject of the policies, such as "user:1" and "domain:1_role:2"
+	AuditTargetTypeRBACSubject AuditTargetType = "rbac_subject"
+)
+
+// AuditTarget is the record changed by the action. ID is the ID of the record, or the value of the RBAC subject
+type AuditTarget struct {
+	Type AuditTargetType `validate:"required,max=20"`
+	ID   string          `validate:"required,max=200"`
+}
+
+func NewAuditTarget(targetType AuditTargetType, id string) (*AuditTarget, error) {
+	m := &AuditTarget{
+		Type: targetType,
+		ID:   id,
+	}
+	if err := libdomain.Validator.Struct(m); err != nil {
+		return nil, liberrors.Errorf("libdomain.Validator.Struct. err: %w", err)
+	}
+
+	return m, nil
+}
+
+// AuditEventModel is the record of the action performed by the actor.
+// Before and After are the JSON of the target before and after the action, and are empty when the target does not exist
+type AuditEventModel struct {
+	ID             int
+	OccurredAt     time.Time
+	ActorID        *AppUserID
+	OrganizationID *OrganizationID
+	Action         AuditAction
+	Target         *AuditTarget
+	Before         string
+	After          string
+	RequestID      string
+	TraceID        string
+}
@@ -1,5 +1,12 @@
 package domain
 
+import (
+	"fmt"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
 // type RBACUser string
 // type RBACRole string
 // type RBACObject string
@@ -108,3 +115,100 @@ func NewRBACEffect(value string) RBACEffect {
 func (r *rbacEffect) Effect() string {
 	return r.value
 }
+
+var RBACAllowEffect = NewRBACEffect("allow")
+var RBACDenyEffect = NewRBACEffect("deny")
+
+// ParseRBACEffect returns RBACAllowEffect or RBACDenyEffect. libdomain.ErrInvalidArgument is returned for the other values
+func ParseRBACEffect(value string) (RBACEffect, error) {
+	switch value {
+	case RBACAllowEffect.Effect():
+		return RBACAllowEffect, nil
+	case RBACDenyEffect.Effect():
+		return RBACDenyEffect, nil
+	default:
+		return nil, liberrors.Errorf("unsupported effect. effect: %s, err: %w", value, libdomain.ErrInvalidArgument)
+	}
+}
+
+type RBACPolicy struct {
+	Domain  RBACDomain
+	Subject RBACSubject
+	Action  RBACAction
+	Object  RBACObject
+	Effect  RBACEffect
+	// Condition is nil when the policy is applied unconditionally
+	Condition RBACCondition
+}
+
+func NewRBACPolicy(domain RBACDomain, subject RBACSubject, action RBACAction, object RBACObject, effect RBACEffect) *RBACPolicy {
+	return &RBACPolicy{
+		Domain:  domain,
+		Subject: subject,
+		Action:  action,
+		Object:  object,
+		Effect:  effect,
+	}
+}
+
+func NewRBACConditionalPolicy(domain RBACDomain, subject RBACSubject, action RBACAction, object RBACObject, effect RBACEffect, condition RBACCondition) *RBACPolicy {
+	p := NewRBACPolicy(domain, subject, action, object, effect)
+	p.Condition = condition
+	return p
+}
+
+// ConditionExpression returns the expression of the condition, or "" when the policy has no condition
+func (p *RBACPolicy) ConditionExpression() string {
+	if p.Condition == nil {
+		return ""
+	}
+	return p.Condition.Expression()
+}
+
+// RBACGroupingRule is a "g" or "g2" rule which makes Child inherit Parent in Domain
+type RBACGroupingRule struct {
+	PType  string
+	Child  string
+	Parent string
+	Domain RBACDomain
+}
+
+func NewRBACGroupingRule(ptype, child, parent string, domain RBACDomain) *RBACGroupingRule {
+	return &RBACGroupingRule{
+		PType:  ptype,
+		Child:  child,
+		Parent: parent,
+		Domain: domain,
+	}
+}
+
+func (r *RBACGroupingRule) Line() string {
+	return fmt.Sprintf("%s, %s, %s, %s", r.PType, r.Child, r.Parent, r.Domain.Domain())
+}
+
+// RBACExplanation tells which rules decided the result of an authorization
+type RBACExplanation struct {
+	Allowed bool
+	// Policy is the "p" rule which decided the result. It is nil when no rule matched
+	Policy *RBACPolicy
+	// SubjectChain is the "g" rules from the requested subject to the subject of Policy
+	SubjectChain []*RBACGroupingRule
+	// ObjectChain is the "g2" rules from the requested object to the object of Policy
+	ObjectChain []*RBACGroupingRule
+}
+
+// Lines returns the rules in the casbin CSV format
+func (e *RBACExplanation) Lines() []string {
+	lines := make([]string, 0)
+	if e.Policy != nil {
+		lines = append(lines, policyLine(e.Policy))
+	}
+	for _, r := range e.SubjectChain {
+		lines = append(lines, r.Line())
+	}
+	for _, r := range e.ObjectChain {
+		lines = append(lines, r.Line())
+	}
+
+	return lines
+}
@@ -0,0 +1,227 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/govaluate"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+// RBACConditionMaxLength is the size of the column of casbin_rule which stores the condition
+const RBACConditionMaxLength = 100
+
+// the built-in attributes of the authorization request
+const (
+	RBACOperatorSubjectAttribute   = "operator.subject"
+	RBACOperatorAppUserIDAttribute = "operator.appUserId"
+	// RBACTimeHourAttribute is the hour of the request in UTC
+	RBACTimeHourAttribute = "time.hour"
+	// RBACTimeWeekdayAttribute is the day of the week of the request in UTC. Sunday is 0
+	RBACTimeWeekdayAttribute = "time.weekday"
+)
+
+// RBACAttributes are the attributes of an authorization request. e.g. {"resource.owner": "user:2"}
+type RBACAttributes map[string]interface{}
+
+// GetCacheKey is used by casbin to cache the decisions for the attributes
+func (a RBACAttributes) GetCacheKey() string {
+	// the keys of a map are sorted by json.Marshal
+	data, err := json.Marshal(map[string]interface{}(a))
+	if err != nil {
+		return fmt.Sprintf("%v", map[string]interface{}(a))
+	}
+	return string(data)
+}
+
+// RBACCondition is a condition on the attributes of an authorization request.
+// The policy which has a condition is applied only when the condition is satisfied
+type RBACCondition interface {
+	// Expression returns the condition stored in the policy. e.g. "[time.hour] >= 9 && [time.hour] < 18"
+	Expression() string
+	Evaluate(attributes RBACAttributes) (bool, error)
+}
+
+type rbacCondition struct {
+	expression string
+	evaluable  *govaluate.EvaluableExpression
+	// err is the error of parsing the expression built by the builder
+	err error
+}
+
+// ParseRBACCondition parses the expression built by the condition builder, or written in the same syntax
+func ParseRBACCondition(expression string) (RBACCondition, error) {
+	if expression == "" {
+		return nil, liberrors.Errorf("condition is empty. err: %w", libdomain.ErrInvalidArgument)
+	}
+	if len(expression) > RBACConditionMaxLength {
+		return nil, liberrors.Errorf("condition is too long. length: %d, err: %w", len(expression), libdomain.ErrInvalidArgument)
+	}
+
+	evaluable, err := govaluate.NewEvaluableExpression(expression)
+	if err != nil {
+		return nil, liberrors.Errorf("invalid condition. condition: %s, err: %v: %w", expression, err, libdomain.ErrInvalidArgument)
+	}
+
+	// the attributes are not structs
+	for _, token := range evaluable.Tokens() {
+		if token.Kind == govaluate.ACCESSOR || token.Kind == govaluate.FUNCTION {
+			return nil, liberrors.Errorf("unsupported token in condition. condition: %s, err: %w", expression, libdomain.ErrInvalidArgument)
+		}
+	}
+
+	return &rbacCondition{expression: expression, evaluable: evaluable}, nil
+}
+
+// NewRBACCondition returns the condition of the expression without validating it. The invalid condition is rejected when it is added to a policy, and Evaluate returns the error
+func NewRBACCondition(expression string) RBACCondition {
+	c, err := ParseRBACCondition(expression)
+	if err != nil {
+		return &rbacCondition{expression: expression, err: err}
+	}
+	return c
+}
+
+func (c *rbacCondition) Expression() string {
+	return c.expression
+}
+
+// Evaluate returns an error when the attributes do not have the values referred by the condition
+func (c *rbacCondition) Evaluate(attributes RBACAttributes) (bool, error) {
+	if c.err != nil {
+		return false, c.err
+	}
+
+	result, err := c.evaluable.Evaluate(attributes)
+	if err != nil {
+		return false, liberrors.Errorf("c.evaluable.Evaluate. condition: %s, err: %w", c.expression, err)
+	}
+
+	ok, isBool := result.(bool)
+	if !isBool {
+		return false, liberrors.Errorf("condition is not boolean. condition: %s, err: %w", c.expression, libdomain.ErrInvalidArgument)
+	}
+
+	return ok, nil
+}
+
+// RBACOperand is an attribute or a literal value in a condition
+type RBACOperand interface {
+	operand() string
+}
+
+type rbacLiteral struct {
+	value string
+}
+
+func (l *rbacLiteral) operand() string {
+	return l.value
+}
+
+// RBACValue returns the literal of the string, the number or the bool
+func RBACValue(value interface{}) RBACOperand {
+	switch v := value.(type) {
+	case RBACOperand:
+		return v
+	case string:
+		return &rbacLiteral{value: "'" + escapeRBACCondition(v, "'") + "'"}
+	case bool:
+		return &rbacLiteral{value: strconv.FormatBool(v)}
+	case int:
+		return &rbacLiteral{value: strconv.Itoa(v)}
+	case int64:
+		return &rbacLiteral{value: strconv.FormatInt(v, 10)}
+	case float64:
+		return &rbacLiteral{value: strconv.FormatFloat(v, 'f', -1, 64)}
+	default:
+		return &rbacLiteral{value: "'" + escapeRBACCondition(fmt.Sprintf("%v", v), "'") + "'"}
+	}
+}
+
+// RBACAttribute is an attribute of the request in a condition
+type RBACAttribute struct {
+	name string
+}
+
+// RBACAttr returns the attribute of the name. e.g. RBACAttr("time.hour").GreaterThanOrEqual(9)
+func RBACAttr(name string) *RBACAttribute {
+	return &RBACAttribute{name: name}
+}
+
+func (a *RBACAttribute) operand() string {
+	return "[" + escapeRBACCondition(a.name, "]") + "]"
+}
+
+// Equal compares the attribute with the other attribute or the literal value
+func (a *RBACAttribute) Equal(value interface{}) RBACCondition {
+	return a.compare("==", value)
+}
+
+func (a *RBACAttribute) NotEqual(value interface{}) RBACCondition {
+	return a.compare("!=", value)
+}
+
+func (a *RBACAttribute) GreaterThan(value interface{}) RBACCondition {
+	return a.compare(">", value)
+}
+
+func (a *RBACAttribute) GreaterThanOrEqual(value interface{}) RBACCondition {
+	return a.compare(">=", value)
+}
+
+func (a *RBACAttribute) LessThan(value interface{}) RBACCondition {
+	return a.compare("<", value)
+}
+
+func (a *RBACAttribute) LessThanOrEqual(value interface{}) RBACCondition {
+	return a.compare("<=", value)
+}
+
+// In is satisfied when the attribute equals one of the values
+func (a *RBACAttribute) In(values ...interface{}) RBACCondition {
+	operands := make([]string, len(values))
+	for i, v := range values {
+		operands[i] = RBACValue(v).operand()
+	}
+	return NewRBACCondition(a.operand() + " in (" + strings.Join(operands, ", ") + ")")
+}
+
+func (a *RBACAttribute) compare(operator string, value interface{}) RBACCondition {
+	return NewRBACCondition(a.operand() + " " + operator + " " + RBACValue(value).operand())
+}
+
+// RBACAnd is satisfied when all the conditions are satisfied
+func RBACAnd(conditions ...RBACCondition) RBACCondition {
+	return joinRBACConditions(" && ", conditions)
+}
+
+// RBACOr is satisfied when any of the conditions is satisfied
+func RBACOr(conditions ...RBACCondition) RBACCondition {
+	return joinRBACConditions(" || ", conditions)
+}
+
+func RBACNot(condition RBACCondition) RBACCondition {
+	return NewRBACCondition("!(" + condition.Expression() + ")")
+}
+
+func joinRBACConditions(operator string, conditions []RBACCondition) RBACCondition {
+	if len(conditions) == 1 {
+		return conditions[0]
+	}
+
+	expressions := make([]string, len(conditions))
+	for i, c := range conditions {
+		expressions[i] = "(" + c.Expression() + ")"
+	}
+	return NewRBACCondition(strings.Join(expressions, operator))
+}
+
+// escapeRBACCondition escapes the backslashes and the terminator with backslashes
+func escapeRBACCondition(value, terminator string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return strings.ReplaceAll(value, terminator, `\`+terminator)
+}
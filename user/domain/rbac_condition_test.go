@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+)
+
+func TestRBACCondition_builder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		condition  RBACCondition
+		expression string
+	}{
+		{name: "equal", condition: RBACAttr("resource.owner").Equal(RBACAttr("operator.subject")), expression: "[resource.owner] == [operator.subject]"},
+		{name: "string", condition: RBACAttr("name").NotEqual("it's"), expression: `[name] != 'it\'s'`},
+		{name: "in", condition: RBACAttr("time.weekday").In(1, 2, 3), expression: "[time.weekday] in (1, 2, 3)"},
+		{name: "and", condition: RBACAnd(RBACAttr("time.hour").GreaterThanOrEqual(9), RBACAttr("time.hour").LessThan(18)), expression: "([time.hour] >= 9) && ([time.hour] < 18)"},
+		{name: "or", condition: RBACOr(RBACAttr("a").GreaterThan(1.5), RBACAttr("b").LessThanOrEqual(true)), expression: "([a] > 1.5) || ([b] <= true)"},
+		{name: "not", condition: RBACNot(RBACAttr("a").Equal(1)), expression: "!([a] == 1)"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expression, tt.condition.Expression())
+			_, err := ParseRBACCondition(tt.condition.Expression())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRBACCondition_Evaluate(t *testing.T) {
+	t.Parallel()
+	businessHours := RBACAnd(RBACAttr(RBACTimeHourAttribute).GreaterThanOrEqual(9), RBACAttr(RBACTimeHourAttribute).LessThan(18))
+	owner := RBACAttr("resource.owner").Equal(RBACAttr(RBACOperatorSubjectAttribute))
+	tests := []struct {
+		name       string
+		condition  RBACCondition
+		attributes RBACAttributes
+		want       bool
+		wantErr    bool
+	}{
+		{name: "business hours", condition: businessHours, attributes: RBACAttributes{RBACTimeHourAttribute: 9}, want: true},
+		{name: "after business hours", condition: businessHours, attributes: RBACAttributes{RBACTimeHourAttribute: 18}, want: false},
+		{name: "owner", condition: owner, attributes: RBACAttributes{"resource.owner": "user:2", RBACOperatorSubjectAttribute: "user:2"}, want: true},
+		{name: "not owner", condition: owner, attributes: RBACAttributes{"resource.owner": "user:3", RBACOperatorSubjectAttribute: "user:2"}, want: false},
+		{name: "missing attribute", condition: owner, attributes: RBACAttributes{RBACOperatorSubjectAttribute: "user:2"}, wantErr: true},
+		{name: "not boolean", condition: NewRBACCondition("[a] + 1"), attributes: RBACAttributes{"a": 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := tt.condition.Evaluate(tt.attributes)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseRBACCondition_invalid(t *testing.T) {
+	t.Parallel()
+	for _, expression := range []string{
+		"",
+		"[a] ==",
+		"[a].Name == 'x'",
+		"now() > 1",
+		"[a] == '" + strings.Repeat("x", RBACConditionMaxLength) + "'",
+	} {
+		_, err := ParseRBACCondition(expression)
+		assert.ErrorIs(t, err, libdomain.ErrInvalidArgument, expression)
+	}
+
+	// the invalid condition built by the builder is reported by Evaluate
+	_, err := RBACAttr("a").Equal(strings.Repeat("x", RBACConditionMaxLength)).Evaluate(RBACAttributes{"a": "x"})
+	assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+}
+
+func TestRBACAttributes_GetCacheKey(t *testing.T) {
+	t.Parallel()
+	a := RBACAttributes{"b": 1, "a": "x"}
+	b := RBACAttributes{"a": "x", "b": 1}
+	assert.Equal(t, a.GetCacheKey(), b.GetCacheKey())
+	assert.NotEqual(t, a.GetCacheKey(), RBACAttributes{"a": "y", "b": 1}.GetCacheKey())
+}
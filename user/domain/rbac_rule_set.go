@@ -0,0 +1,225 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+type RBACRuleSetFormat string
+
+const (
+	// RBACRuleSetFormatCSV is the format of the casbin policy file. e.g. "p, user:2, data, read, allow, domain:1"
+	RBACRuleSetFormatCSV  RBACRuleSetFormat = "csv"
+	RBACRuleSetFormatJSON RBACRuleSetFormat = "json"
+)
+
+// RBACRuleSet is the "p", "g" and "g2" rules of a domain
+type RBACRuleSet struct {
+	Policies      []*RBACPolicy
+	GroupingRules []*RBACGroupingRule
+}
+
+// RBACRuleSetDiff is the rules added and removed by importing a rule set
+type RBACRuleSetDiff struct {
+	Added   *RBACRuleSet
+	Removed *RBACRuleSet
+}
+
+// policyLine returns the "p" rule. The condition is appended only when the policy has it
+func policyLine(p *RBACPolicy) string {
+	values := []string{"p", p.Subject.Subject(), p.Object.Object(), p.Action.Action(), p.Effect.Effect(), p.Domain.Domain()}
+	if expression := p.ConditionExpression(); expression != "" {
+		values = append(values, quoteCSVValue(expression))
+	}
+	return strings.Join(values, ", ")
+}
+
+// quoteCSVValue quotes the value which contains the separator or the quote
+func quoteCSVValue(value string) string {
+	if !strings.ContainsAny(value, `,"`) {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
+// Lines returns the rules in the casbin CSV format. "p" rules come first
+func (s *RBACRuleSet) Lines() []string {
+	lines := make([]string, 0, len(s.Policies)+len(s.GroupingRules))
+	for _, p := range s.Policies {
+		lines = append(lines, policyLine(p))
+	}
+	for _, r := range s.GroupingRules {
+		lines = append(lines, r.Line())
+	}
+
+	return lines
+}
+
+// Sort orders the rules by their lines so that exported rule sets are stable
+func (s *RBACRuleSet) Sort() {
+	sort.SliceStable(s.Policies, func(i, j int) bool {
+		return policyLine(s.Policies[i]) < policyLine(s.Policies[j])
+	})
+	sort.SliceStable(s.GroupingRules, func(i, j int) bool {
+		return s.GroupingRules[i].Line() < s.GroupingRules[j].Line()
+	})
+}
+
+// Diff returns the rules of s which are not in other
+func (s *RBACRuleSet) Diff(other *RBACRuleSet) *RBACRuleSet {
+	lines := make(map[string]bool)
+	for _, line := range other.Lines() {
+		lines[line] = true
+	}
+
+	diff := RBACRuleSet{Policies: make([]*RBACPolicy, 0), GroupingRules: make([]*RBACGroupingRule, 0)}
+	for _, p := range s.Policies {
+		if !lines[policyLine(p)] {
+			diff.Policies = append(diff.Policies, p)
+		}
+	}
+	for _, r := range s.GroupingRules {
+		if !lines[r.Line()] {
+			diff.GroupingRules = append(diff.GroupingRules, r)
+		}
+	}
+
+	return &diff
+}
+
+type rbacRuleSetJSON struct {
+	Policies      []rbacPolicyJSON       `json:"policies"`
+	GroupingRules []rbacGroupingRuleJSON `json:"groupingRules"`
+}
+
+type rbacPolicyJSON struct {
+	Subject   string `json:"subject"`
+	Object    string `json:"object"`
+	Action    string `json:"action"`
+	Effect    string `json:"effect"`
+	Domain    string `json:"domain"`
+	Condition string `json:"condition,omitempty"`
+}
+
+type rbacGroupingRuleJSON struct {
+	PType  string `json:"ptype"`
+	Child  string `json:"child"`
+	Parent string `json:"parent"`
+	Domain string `json:"domain"`
+}
+
+// MarshalRBACRuleSet encodes the rule set in the format
+func MarshalRBACRuleSet(s *RBACRuleSet, format RBACRuleSetFormat) ([]byte, error) {
+	switch format {
+	case RBACRuleSetFormatCSV:
+		var buf bytes.Buffer
+		for _, line := range s.Lines() {
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+		return buf.Bytes(), nil
+	case RBACRuleSetFormatJSON:
+		v := rbacRuleSetJSON{
+			Policies:      make([]rbacPolicyJSON, len(s.Policies)),
+			GroupingRules: make([]rbacGroupingRuleJSON, len(s.GroupingRules)),
+		}
+		for i, p := range s.Policies {
+			v.Policies[i] = rbacPolicyJSON{Subject: p.Subject.Subject(), Object: p.Object.Object(), Action: p.Action.Action(), Effect: p.Effect.Effect(), Domain: p.Domain.Domain(), Condition: p.ConditionExpression()}
+		}
+		for i, r := range s.GroupingRules {
+			v.GroupingRules[i] = rbacGroupingRuleJSON{PType: r.PType, Child: r.Child, Parent: r.Parent, Domain: r.Domain.Domain()}
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, liberrors.Errorf("json.MarshalIndent. err: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, liberrors.Errorf("unsupported format. format: %s, err: %w", format, libdomain.ErrInvalidArgument)
+	}
+}
+
+// UnmarshalRBACRuleSet decodes the rule set in the format. The domain of the rules may be empty
+func UnmarshalRBACRuleSet(data []byte, format RBACRuleSetFormat) (*RBACRuleSet, error) {
+	s := RBACRuleSet{Policies: make([]*RBACPolicy, 0), GroupingRules: make([]*RBACGroupingRule, 0)}
+	switch format {
+	case RBACRuleSetFormatCSV:
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.Comment = '#'
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, liberrors.Errorf("reader.Read. err: %v: %w", err, libdomain.ErrInvalidArgument)
+			}
+			for i := range record {
+				record[i] = strings.TrimSpace(record[i])
+			}
+			// the domain may be omitted. the condition follows the domain
+			switch {
+			case record[0] == "p" && len(record) >= 5 && len(record) <= 7:
+				policy, err := newRBACPolicyOfValues(valueAt(record, 5), record[1], record[3], record[2], record[4], valueAt(record, 6))
+				if err != nil {
+					return nil, err
+				}
+				s.Policies = append(s.Policies, policy)
+			case (record[0] == "g" || record[0] == "g2") && (len(record) == 3 || len(record) == 4):
+				s.GroupingRules = append(s.GroupingRules, NewRBACGroupingRule(record[0], record[1], record[2], NewRBACDomain(valueAt(record, 3))))
+			default:
+				return nil, liberrors.Errorf("invalid rule. rule: %s, err: %w", strings.Join(record, ", "), libdomain.ErrInvalidArgument)
+			}
+		}
+	case RBACRuleSetFormatJSON:
+		v := rbacRuleSetJSON{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, liberrors.Errorf("json.Unmarshal. err: %v: %w", err, libdomain.ErrInvalidArgument)
+		}
+		for _, p := range v.Policies {
+			policy, err := newRBACPolicyOfValues(p.Domain, p.Subject, p.Action, p.Object, p.Effect, p.Condition)
+			if err != nil {
+				return nil, err
+			}
+			s.Policies = append(s.Policies, policy)
+		}
+		for _, r := range v.GroupingRules {
+			s.GroupingRules = append(s.GroupingRules, NewRBACGroupingRule(r.PType, r.Child, r.Parent, NewRBACDomain(r.Domain)))
+		}
+	default:
+		return nil, liberrors.Errorf("unsupported format. format: %s, err: %w", format, libdomain.ErrInvalidArgument)
+	}
+
+	return &s, nil
+}
+
+func newRBACPolicyOfValues(domain, subject, action, object, effect, condition string) (*RBACPolicy, error) {
+	policy := NewRBACPolicy(NewRBACDomain(domain), NewRBACUser(subject), NewRBACAction(action), NewRBACObject(object), NewRBACEffect(effect))
+	if condition == "" {
+		return policy, nil
+	}
+
+	c, err := ParseRBACCondition(condition)
+	if err != nil {
+		return nil, err
+	}
+	policy.Condition = c
+
+	return policy, nil
+}
+
+func valueAt(record []string, i int) string {
+	if i < len(record) {
+		return record[i]
+	}
+	return ""
+}
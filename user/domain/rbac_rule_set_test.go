@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+)
+
+func newTestRBACRuleSet() *RBACRuleSet {
+	rbacDomain := NewRBACDomain("domain:1")
+	return &RBACRuleSet{
+		Policies: []*RBACPolicy{
+			NewRBACPolicy(rbacDomain, NewRBACUser("user:2"), NewRBACAction("read"), NewRBACObject("data"), NewRBACEffect("allow")),
+			NewRBACPolicy(rbacDomain, NewRBACRole("domain:1_role:3"), NewRBACAction("Set"), NewRBACObject("domain:1_role:*"), NewRBACEffect("deny")),
+		},
+		GroupingRules: []*RBACGroupingRule{
+			NewRBACGroupingRule("g", "user:2", "domain:1_role:3", rbacDomain),
+			NewRBACGroupingRule("g2", "doc:1", "folder:1", rbacDomain),
+		},
+	}
+}
+
+func TestRBACRuleSet_MarshalAndUnmarshal(t *testing.T) {
+	t.Parallel()
+	for _, format := range []RBACRuleSetFormat{RBACRuleSetFormatCSV, RBACRuleSetFormatJSON} {
+		format := format
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+			ruleSet := newTestRBACRuleSet()
+
+			data, err := MarshalRBACRuleSet(ruleSet, format)
+			require.NoError(t, err)
+			unmarshaled, err := UnmarshalRBACRuleSet(data, format)
+			require.NoError(t, err)
+
+			assert.Equal(t, ruleSet.Lines(), unmarshaled.Lines())
+		})
+	}
+}
+
+func TestUnmarshalRBACRuleSet_csv(t *testing.T) {
+	t.Parallel()
+	data := []byte(`# comment
+p, user:2, data, read, allow
+
+g, user:2, domain:1_role:3
+`)
+	ruleSet, err := UnmarshalRBACRuleSet(data, RBACRuleSetFormatCSV)
+	require.NoError(t, err)
+	// the domain is empty when it is omitted
+	assert.Equal(t, []string{"p, user:2, data, read, allow, ", "g, user:2, domain:1_role:3, "}, ruleSet.Lines())
+
+	_, err = UnmarshalRBACRuleSet([]byte("p, user:2, data\n"), RBACRuleSetFormatCSV)
+	assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+
+	_, err = UnmarshalRBACRuleSet([]byte("x, user:2, domain:1_role:3\n"), RBACRuleSetFormatCSV)
+	assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+
+	_, err = UnmarshalRBACRuleSet(data, RBACRuleSetFormat("yaml"))
+	assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+}
+
+func TestRBACRuleSet_Diff(t *testing.T) {
+	t.Parallel()
+	ruleSet := newTestRBACRuleSet()
+	other := &RBACRuleSet{
+		Policies:      ruleSet.Policies[:1],
+		GroupingRules: ruleSet.GroupingRules[1:],
+	}
+
+	assert.Equal(t, []string{
+		"p, domain:1_role:3, domain:1_role:*, Set, deny, domain:1",
+		"g, user:2, domain:1_role:3, domain:1",
+	}, ruleSet.Diff(other).Lines())
+	assert.Empty(t, other.Diff(ruleSet).Lines())
+}
+
+func TestRBACRuleSet_conditionalPolicy(t *testing.T) {
+	t.Parallel()
+	condition := RBACAnd(RBACAttr("resource.owners").In("user:2", "user:3"), RBACAttr("time.hour").LessThan(18))
+	ruleSet := &RBACRuleSet{
+		Policies: []*RBACPolicy{
+			NewRBACConditionalPolicy(NewRBACDomain("domain:1"), NewRBACUser("user:2"), NewRBACAction("write"), NewRBACObject("data"), NewRBACEffect("allow"), condition),
+		},
+	}
+	// the condition which has the separator is quoted
+	assert.Equal(t, []string{`p, user:2, data, write, allow, domain:1, "([resource.owners] in ('user:2', 'user:3')) && ([time.hour] < 18)"`}, ruleSet.Lines())
+
+	for _, format := range []RBACRuleSetFormat{RBACRuleSetFormatCSV, RBACRuleSetFormatJSON} {
+		data, err := MarshalRBACRuleSet(ruleSet, format)
+		require.NoError(t, err)
+		unmarshaled, err := UnmarshalRBACRuleSet(data, format)
+		require.NoError(t, err)
+		assert.Equal(t, ruleSet.Lines(), unmarshaled.Lines(), format)
+		assert.Equal(t, condition.Expression(), unmarshaled.Policies[0].ConditionExpression(), format)
+	}
+
+	_, err := UnmarshalRBACRuleSet([]byte("p, user:2, data, write, allow, domain:1, [a] ==\n"), RBACRuleSetFormatCSV)
+	assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+}
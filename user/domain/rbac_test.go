@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+)
+
+func TestParseRBACEffect(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		value   string
+		want    RBACEffect
+		wantErr bool
+	}{
+		{name: "allow", value: "allow", want: RBACAllowEffect},
+		{name: "deny", value: "deny", want: RBACDenyEffect},
+		{name: "empty", value: "", wantErr: true},
+		{name: "upper case", value: "ALLOW", wantErr: true},
+		{name: "unknown", value: "permit", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseRBACEffect(tt.value)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.Effect(), got.Effect())
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+)
+
+// RBACValidity is the period in which a grant is effective. A nil bound means the period is not bounded on that side
+type RBACValidity struct {
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+}
+
+func NewRBACValidity(validFrom, validUntil *time.Time) (*RBACValidity, error) {
+	if validFrom == nil && validUntil == nil {
+		return nil, liberrors.Errorf("validity has no bound. err: %w", libdomain.ErrInvalidArgument)
+	}
+	if validFrom != nil && validUntil != nil && !validUntil.After(*validFrom) {
+		return nil, liberrors.Errorf("validUntil must be after validFrom. validFrom: %s, validUntil: %s, err: %w", validFrom.Format(time.RFC3339), validUntil.Format(time.RFC3339), libdomain.ErrInvalidArgument)
+	}
+
+	return &RBACValidity{
+		ValidFrom:  validFrom,
+		ValidUntil: validUntil,
+	}, nil
+}
+
+// IsActiveAt returns true when t is in [ValidFrom, ValidUntil)
+func (v *RBACValidity) IsActiveAt(t time.Time) bool {
+	if v.ValidFrom != nil && t.Before(*v.ValidFrom) {
+		return false
+	}
+	if v.ValidUntil != nil && !t.Before(*v.ValidUntil) {
+		return false
+	}
+
+	return true
+}
+
+// RBACExpiredGrant is the record of the "p" or "g" rule removed because its validity has ended.
+// Either Policy or GroupingRule is set
+type RBACExpiredGrant struct {
+	ID           int
+	Policy       *RBACPolicy
+	GroupingRule *RBACGroupingRule
+	ValidFrom    *time.Time
+	ValidUntil   time.Time
+	ExpiredAt    time.Time
+}
+
+// Line returns the expired rule in the casbin CSV format
+func (g *RBACExpiredGrant) Line() string {
+	if g.Policy != nil {
+		return policyLine(g.Policy)
+	}
+
+	return g.GroupingRule.Line()
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+)
+
+func TestNewRBACValidity(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(time.Hour)
+	tests := []struct {
+		name    string
+		from    *time.Time
+		until   *time.Time
+		wantErr bool
+	}{
+		{name: "from and until", from: &from, until: &until},
+		{name: "from only", from: &from},
+		{name: "until only", until: &until},
+		{name: "no bound", wantErr: true},
+		{name: "until equals from", from: &from, until: &from, wantErr: true},
+		{name: "until before from", from: &until, until: &from, wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := NewRBACValidity(tt.from, tt.until)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRBACValidity_IsActiveAt(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(time.Hour)
+	validity, err := NewRBACValidity(&from, &until)
+	require.NoError(t, err)
+
+	assert.False(t, validity.IsActiveAt(from.Add(-time.Second)))
+	assert.True(t, validity.IsActiveAt(from))
+	assert.True(t, validity.IsActiveAt(until.Add(-time.Second)))
+	assert.False(t, validity.IsActiveAt(until))
+}
@@ -0,0 +1,104 @@
+package gateway_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+// testAddOwner adds the app user who belongs to the owner group
+func testAddOwner(t *testing.T, ctx context.Context, ts testService, sysOwner *service.SystemOwner, loginID string) *service.AppUser {
+	t.Helper()
+	appUser := testAddAppUser(t, ctx, ts, sysOwner, loginID, loginID+"_NAME", "PASSWORD")
+	ownerGroup, err := ts.rf.NewUserGroupRepository(ctx).FindUserGroupByKey(ctx, sysOwner, service.OwnerGroupKey)
+	require.NoError(t, err)
+	require.NoError(t, ts.rf.NewAuthorizationManager(ctx).AddUserToGroup(ctx, sysOwner, appUser.AppUserID(), ownerGroup.UserGroupID()))
+	return appUser
+}
+
+func Test_Owner_RemoveAppUser(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		user := testAddAppUser(t, ctx, ts, owner, "USER", "USER_NAME", "PASSWORD")
+		otherOwner := testAddOwner(t, ctx, ts, sysOwner, "OTHER_OWNER")
+
+		// the owner cannot remove itself, the system owner nor the other owners
+		err := owner.RemoveAppUser(ctx, owner.AppUserID(), owner.Version)
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+		err = owner.RemoveAppUser(ctx, sysOwner.AppUserID(), sysOwner.Version)
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+		err = owner.RemoveAppUser(ctx, otherOwner.AppUserID(), otherOwner.Version)
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+		err = owner.ChangePassword(ctx, otherOwner.AppUserID(), "NEW_PASSWORD")
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+
+		// the owner cannot restore itself nor the app users which are not removed
+		err = owner.RestoreAppUser(ctx, owner.AppUserID(), owner.Version)
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+		err = owner.RestoreAppUser(ctx, user.AppUserID(), user.Version)
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+
+		// the owner can remove and restore the app users
+		require.NoError(t, owner.RemoveAppUser(ctx, user.AppUserID(), user.Version))
+		removed, err := ts.rf.NewAppUserRepository(ctx).FindRemovedAppUserByID(ctx, owner, user.AppUserID())
+		require.NoError(t, err)
+		assert.Equal(t, "USER", removed.LoginID())
+		require.NoError(t, owner.RestoreAppUser(ctx, user.AppUserID(), user.Version+1))
+
+		// the system owner can remove the other owners
+		require.NoError(t, sysOwner.RemoveAppUser(ctx, otherOwner.AppUserID(), otherOwner.Version))
+		err = sysOwner.RemoveAppUser(ctx, sysOwner.AppUserID(), sysOwner.Version)
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+
+		// the actions are recorded
+		events, err := ts.rf.NewAuditLogRepository(ctx).FindAuditEvents(ctx, owner, testNewAuditEventSearchCondition(t, time.Time{}, time.Time{}, nil, testNewAuditTarget(t, domain.AuditTargetTypeAppUser, user.AppUserID().Int())))
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, domain.AuditActionRestoreAppUser, events[0].Action)
+		assert.Equal(t, domain.AuditActionRemoveAppUser, events[1].Action)
+		assert.JSONEq(t, `{"loginId":"USER","username":"USER_NAME","version":3}`, events[0].After)
+		assert.JSONEq(t, `{"loginId":"USER","username":"USER_NAME","version":1}`, events[1].Before)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_Owner_ChangePassword(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		sysAd := testNewSystemAdmin(domain.NewSystemAdminModel())
+		user := testAddAppUser(t, ctx, ts, owner, "USER", "USER_NAME", "PASSWORD")
+
+		// the password must satisfy the same rule as the one of the new app users
+		for _, password := range []string{"", "SHORT", strings.Repeat("A", 73), "PASSWORD\n", "パスワードパスワード"} {
+			err := owner.ChangePassword(ctx, user.AppUserID(), password)
+			assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+			_, err = service.NewAppUserAddParameter("LOGIN_ID", "USERNAME", password, "", "", "", "")
+			if password != "" {
+				assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+			}
+		}
+
+		require.NoError(t, owner.ChangePassword(ctx, user.AppUserID(), "NEW_PASSWORD"))
+		require.NoError(t, owner.ChangePassword(ctx, owner.AppUserID(), "NEW_OWNER_PASSWORD"))
+		verified, err := ts.rf.NewAppUserRepository(ctx).VerifyPassword(ctx, sysAd, orgID, "USER", "NEW_PASSWORD")
+		require.NoError(t, err)
+		assert.True(t, verified)
+
+		// the password is not recorded
+		events, err := ts.rf.NewAuditLogRepository(ctx).FindAuditEvents(ctx, owner, testNewAuditEventSearchCondition(t, time.Time{}, time.Time{}, nil, testNewAuditTarget(t, domain.AuditTargetTypeAppUser, user.AppUserID().Int())))
+		require.NoError(t, err)
+		require.NotEmpty(t, events)
+		assert.Equal(t, domain.AuditActionChangePassword, events[0].Action)
+		assert.Empty(t, events[0].Before)
+		assert.Empty(t, events[0].After)
+	}
+	testOrganization(t, fn)
+}
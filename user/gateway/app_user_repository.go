@@ -3,6 +3,7 @@ package gateway
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -31,6 +32,7 @@ type appUserEntity struct {
 	ProviderAccessToken  string
 	ProviderRefreshToken string
 	Removed              bool
+	RemovedLoginID       *string
 }
 
 func (e *appUserEntity) TableName() string {
@@ -203,6 +205,27 @@ func (r *appUserRepository) FindAppUserByID(ctx context.Context, operator servic
 	return r.findAppUserByID(ctx, operator.OrganizationID(), id, options...)
 }
 
+func (r *appUserRepository) FindRemovedAppUserByID(ctx context.Context, operator service.AppUserInterface, id *domain.AppUserID) (*service.AppUser, error) {
+	_, span := tracer.Start(ctx, "appUserRepository.FindRemovedAppUserByID")
+	defer span.End()
+
+	appUserE := appUserEntity{}
+	wrappedDB := wrappedDB{dialect: r.dialect, db: r.db, organizationID: operator.OrganizationID()}
+	db := wrappedDB.WhereOrganizationID(&appUserEntity{}, operator.OrganizationID()).WhereRemoved(&appUserEntity{}).Where("app_user.id = ?", id.Int()).db
+	if result := db.First(&appUserE); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, service.ErrAppUserNotFound
+		}
+
+		return nil, result.Error
+	}
+	if appUserE.RemovedLoginID != nil {
+		appUserE.LoginID = *appUserE.RemovedLoginID
+	}
+
+	return appUserE.toAppUser(ctx, r.rf, nil)
+}
+
 func (r *appUserRepository) findAppUserByID(ctx context.Context, organizationID *domain.OrganizationID, id *domain.AppUserID, options ...service.Option) (*service.AppUser, error) {
 	_, span := tracer.Start(ctx, "appUserRepository.findAppUserByID")
 	defer span.End()
@@ -305,7 +328,7 @@ func (r *appUserRepository) FindOwnerByLoginID(ctx context.Context, operator ser
 }
 
 func (r *appUserRepository) addAppUser(ctx context.Context, appUserEntity *appUserEntity) (*domain.AppUserID, error) {
-	if result := r.db.Create(appUserEntity); result.Error != nil {
+	if result := r.db.WithContext(ctx).Create(appUserEntity); result.Error != nil {
 		return nil, liberrors.Errorf("db.Create. err: %w", libgateway.ConvertDuplicatedError(result.Error, service.ErrAppUserAlreadyExists))
 	}
 
@@ -332,18 +355,17 @@ func (r *appUserRepository) AddAppUser(ctx context.Context, operator service.Own
 	}
 
 	appUserEntity := appUserEntity{
-		BaseModelEntity: BaseModelEntity{
-			Version:   1,
-			CreatedBy: operator.AppUserID().Int(),
-			UpdatedBy: operator.AppUserID().Int(),
-		},
-		OrganizationID: operator.OrganizationID().Int(),
-		LoginID:        param.LoginID(),
-		Username:       param.Username(),
-		HashedPassword: hashedPassword,
-	}
-
-	appUserID, err := r.addAppUser(ctx, &appUserEntity)
+		OrganizationID:       operator.OrganizationID().Int(),
+		LoginID:              param.LoginID(),
+		Username:             param.Username(),
+		HashedPassword:       hashedPassword,
+		Provider:             param.Provider(),
+		ProviderID:           param.ProviderLoginID(),
+		ProviderAccessToken:  param.ProviderAuthToken(),
+		ProviderRefreshToken: param.ProviderRefreshToken(),
+	}
+
+	appUserID, err := r.addAppUser(service.WithOperator(ctx, operator), &appUserEntity)
 	if err != nil {
 		return nil, err
 	}
@@ -356,17 +378,12 @@ func (r *appUserRepository) AddSystemOwner(ctx context.Context, operator service
 	defer span.End()
 
 	appUserEntity := appUserEntity{
-		BaseModelEntity: BaseModelEntity{
-			Version:   1,
-			CreatedBy: operator.AppUserID().Int(),
-			UpdatedBy: operator.AppUserID().Int(),
-		},
 		OrganizationID: organizationID.Int(),
 		LoginID:        service.SystemOwnerLoginID,
 		Username:       "SystemOwner",
 	}
 
-	appUserID, err := r.addAppUser(ctx, &appUserEntity)
+	appUserID, err := r.addAppUser(service.WithOperator(ctx, operator), &appUserEntity)
 	if err != nil {
 		return nil, err
 	}
@@ -374,6 +391,134 @@ func (r *appUserRepository) AddSystemOwner(ctx context.Context, operator service
 	return appUserID, nil
 }
 
+func (r *appUserRepository) UpdateAppUser(ctx context.Context, operator service.OwnerModelInterface, id *domain.AppUserID, version int, param service.AppUserUpdateParameterInterface) error {
+	_, span := tracer.Start(ctx, "appUserRepository.UpdateAppUser")
+	defer span.End()
+
+	if err := updateWithVersion(r.db.WithContext(service.WithOperator(ctx, operator)), &appUserEntity{}, func(db *gorm.DB) *gorm.DB {
+		wrappedDB := wrappedDB{dialect: r.dialect, db: db, organizationID: operator.OrganizationID()}
+		return wrappedDB.WhereAppUser().Where("app_user.id = ?", id.Int()).db
+	}, version, map[string]interface{}{
+		"username": param.Username(),
+	}, service.ErrAppUserNotFound); err != nil {
+		return liberrors.Errorf("updateWithVersion. err: %w", err)
+	}
+
+	return nil
+}
+
+func (r *appUserRepository) ChangePassword(ctx context.Context, operator service.AppUserInterface, id *domain.AppUserID, password string) error {
+	_, span := tracer.Start(ctx, "appUserRepository.ChangePassword")
+	defer span.End()
+
+	hashedPassword, err := libgateway.HashPassword(password)
+	if err != nil {
+		return liberrors.Errorf("libgateway.HashPassword. err: %w", err)
+	}
+
+	wrappedDB := wrappedDB{dialect: r.dialect, db: r.db.WithContext(service.WithOperator(ctx, operator)), organizationID: operator.OrganizationID()}
+	result := wrappedDB.WhereAppUser().Where("app_user.id = ?", id.Int()).db.Model(&appUserEntity{}).Updates(map[string]interface{}{
+		"hashed_password": hashedPassword,
+	})
+	if result.Error != nil {
+		return liberrors.Errorf("update %s. err: %w", AppUserTableName, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return service.ErrAppUserNotFound
+	}
+
+	return nil
+}
+
+// removedLoginID returns the login ID of the removed app user, which releases its own login ID
+func removedLoginID(id *domain.AppUserID) string {
+	return fmt.Sprintf("%s%d", service.RemovedLoginIDPrefix, id.Int())
+}
+
+func (r *appUserRepository) RemoveAppUser(ctx context.Context, operator service.OwnerModelInterface, id *domain.AppUserID, version int) error {
+	_, span := tracer.Start(ctx, "appUserRepository.RemoveAppUser")
+	defer span.End()
+
+	organizationID := operator.OrganizationID()
+	scope := func(db *gorm.DB) *gorm.DB {
+		wrappedDB := wrappedDB{dialect: r.dialect, db: db, organizationID: organizationID}
+		return wrappedDB.WhereAppUser().Where("app_user.id = ?", id.Int()).db
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		appUserE := appUserEntity{}
+		if result := tx.WithContext(ctx).Scopes(scope).First(&appUserE); result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return service.ErrAppUserNotFound
+			}
+
+			return result.Error
+		}
+
+		if err := updateWithVersion(tx.WithContext(service.WithOperator(ctx, operator)), &appUserEntity{}, scope, version, map[string]interface{}{
+			"removed":          true,
+			"login_id":         removedLoginID(id),
+			"removed_login_id": appUserE.LoginID,
+		}, service.ErrAppUserNotFound); err != nil {
+			return liberrors.Errorf("updateWithVersion. err: %w", err)
+		}
+
+		// revoke the memberships of the user groups
+		if result := tx.WithContext(ctx).Where("organization_id = ? and app_user_id = ?", organizationID.Int(), id.Int()).Delete(&pairOfUserAndGroupEntity{}); result.Error != nil {
+			return liberrors.Errorf("delete %s. err: %w", PairOfUserAndGroupTableName, result.Error)
+		}
+
+		rbacRepo := newRBACRepositoryOfFactory(ctx, tx, r.rf)
+		rbacDomain := service.NewRBACOrganization(organizationID)
+		rbacAppUser := service.NewRBACAppUser(organizationID, id)
+		if err := rbacRepo.RemoveSubjectGroupingPolicies(ctx, rbacDomain, rbacAppUser); err != nil {
+			return liberrors.Errorf("rbacRepo.RemoveSubjectGroupingPolicies. err: %w", err)
+		}
+
+		// revoke the policies given to the app user directly
+		if err := rbacRepo.RemoveSubjectPolicy(ctx, rbacDomain, rbacAppUser); err != nil {
+			return liberrors.Errorf("rbacRepo.RemoveSubjectPolicy. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *appUserRepository) RestoreAppUser(ctx context.Context, operator service.OwnerModelInterface, id *domain.AppUserID, version int) error {
+	_, span := tracer.Start(ctx, "appUserRepository.RestoreAppUser")
+	defer span.End()
+
+	organizationID := operator.OrganizationID()
+	scope := func(db *gorm.DB) *gorm.DB {
+		wrappedDB := wrappedDB{dialect: r.dialect, db: db, organizationID: organizationID}
+		return wrappedDB.WhereOrganizationID(&appUserEntity{}, organizationID).WhereRemoved(&appUserEntity{}).Where("app_user.id = ?", id.Int()).db
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		appUserE := appUserEntity{}
+		if result := tx.WithContext(ctx).Scopes(scope).First(&appUserE); result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return service.ErrAppUserNotFound
+			}
+
+			return result.Error
+		}
+		if appUserE.RemovedLoginID == nil {
+			return liberrors.Errorf("login ID of removed app user not found. app user ID: %d", id.Int())
+		}
+
+		if err := updateWithVersion(tx.WithContext(service.WithOperator(ctx, operator)), &appUserEntity{}, scope, version, map[string]interface{}{
+			"removed":          false,
+			"login_id":         *appUserE.RemovedLoginID,
+			"removed_login_id": nil,
+		}, service.ErrAppUserNotFound); err != nil {
+			return liberrors.Errorf("updateWithVersion. err: %w", libgateway.ConvertDuplicatedError(err, service.ErrAppUserAlreadyExists))
+		}
+
+		return nil
+	})
+}
+
 func (r *appUserRepository) VerifyPassword(ctx context.Context, operator service.SystemAdminInterface, organizationID *domain.OrganizationID, loginID, password string) (bool, error) {
 	appUserEntity, err := r.findAppUserEntityByLoginID(ctx, organizationID, loginID)
 	if err != nil {
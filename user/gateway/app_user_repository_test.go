@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	libdomain "github.com/kujilabo/redstart/lib/domain"
 	"github.com/kujilabo/redstart/user/domain"
 	"github.com/kujilabo/redstart/user/gateway"
 	"github.com/kujilabo/redstart/user/service"
@@ -14,7 +15,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func outputOrganization(t *testing.T, db *gorm.DB) {
+func outputOrganization(t testing.TB, db *gorm.DB) {
 	var results []gateway.OrganizationEntity
 	if result := db.Find(&results); result.Error != nil {
 		assert.Fail(t, result.Error.Error())
@@ -238,3 +239,237 @@ func Test_appUserRepository_VerifyPassword_shouldReturnFalse_whenWrongPasswordIs
 	}
 	testOrganization(t, fn)
 }
+
+func Test_appUserRepository_AddAppUser_shouldReturnError_whenDuplicatedLoginIDIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+
+		// given
+		_ = testAddAppUser(t, ctx, ts, owner, "LOGIN_ID", "USERNAME", "PASSWORD")
+
+		// when
+		_, err := appUserRepo.AddAppUser(ctx, owner, testNewAppUserAddParameter(t, "LOGIN_ID", "USERNAME_2", "PASSWORD_2"))
+
+		// then
+		assert.ErrorIs(t, err, service.ErrAppUserAlreadyExists)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_appUserRepository_AddAppUser_shouldReturnError_whenRemovedLoginIDIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+
+		// given
+		// - the login ID of the removed app user is released
+		user := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID", "USERNAME", "PASSWORD")
+		require.NoError(t, appUserRepo.RemoveAppUser(ctx, owner, user.AppUserID(), user.Version))
+
+		// when
+		_, err := service.NewAppUserAddParameter(fmt.Sprintf("__removed_%d", user.AppUserID().Int()), "USERNAME_2", "PASSWORD_2", "", "", "", "")
+
+		// then
+		// - the login ID which the removed app user can take is not accepted
+		assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+		err = appUserRepo.RestoreAppUser(ctx, owner, user.AppUserID(), user.Version+1)
+		require.NoError(t, err)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_appUserRepository_UpdateAppUser_shouldIncrementVersion_whenCurrentVersionIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+		param, err := service.NewAppUserUpdateParameter("NEW_OWNER_NAME")
+		require.NoError(t, err)
+
+		// when
+		err = appUserRepo.UpdateAppUser(ctx, owner, owner.AppUserID(), owner.Version, param)
+
+		// then
+		require.NoError(t, err)
+		appUser, err := appUserRepo.FindAppUserByID(ctx, owner, owner.AppUserID())
+		require.NoError(t, err)
+		assert.Equal(t, "NEW_OWNER_NAME", appUser.Username())
+		assert.Equal(t, owner.Version+1, appUser.Version)
+		assert.Equal(t, owner.AppUserID().Int(), appUser.UpdatedBy)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_appUserRepository_UpdateAppUser_shouldReturnError_whenOldVersionIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+		param, err := service.NewAppUserUpdateParameter("NEW_OWNER_NAME")
+		require.NoError(t, err)
+		require.NoError(t, appUserRepo.UpdateAppUser(ctx, owner, owner.AppUserID(), owner.Version, param))
+
+		// when
+		err = appUserRepo.UpdateAppUser(ctx, owner, owner.AppUserID(), owner.Version, param)
+
+		// then
+		assert.ErrorIs(t, err, libdomain.ErrConcurrentModification)
+
+		// - the app user of the other organization is not found
+		err = appUserRepo.UpdateAppUser(ctx, owner, invalidAppUserID, owner.Version, param)
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+	}
+	testOrganization(t, fn)
+}
+
+func testCountUserGroupings(t *testing.T, ts testService, orgID *domain.OrganizationID, appUserID *domain.AppUserID) (int64, int64) {
+	t.Helper()
+	var pairs int64
+	require.NoError(t, ts.db.Table("user_n_group").Where("app_user_id = ?", appUserID.Int()).Count(&pairs).Error)
+	var rules int64
+	require.NoError(t, ts.db.Table("casbin_rule").Where("ptype = ? and v0 = ?", "g", service.NewRBACAppUser(orgID, appUserID).Subject()).Count(&rules).Error)
+	return pairs, rules
+}
+
+func Test_appUserRepository_ChangePassword_shouldReplacePassword_whenExistingIDIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		sysAd := testNewSystemAdmin(domain.NewSystemAdminModel())
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+
+		// given
+		user := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID", "USERNAME", "PASSWORD")
+
+		// when
+		err := appUserRepo.ChangePassword(ctx, owner, user.AppUserID(), "NEW_PASSWORD")
+
+		// then
+		require.NoError(t, err)
+		verified, err := appUserRepo.VerifyPassword(ctx, sysAd, orgID, "LOGIN_ID", "PASSWORD")
+		require.NoError(t, err)
+		assert.False(t, verified)
+		verified, err = appUserRepo.VerifyPassword(ctx, sysAd, orgID, "LOGIN_ID", "NEW_PASSWORD")
+		require.NoError(t, err)
+		assert.True(t, verified)
+
+		// - the app user of the other organization is not found
+		err = appUserRepo.ChangePassword(ctx, owner, invalidAppUserID, "NEW_PASSWORD")
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_appUserRepository_RemoveAppUser_shouldHideAppUserAndRevokeMemberships_whenCurrentVersionIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		sysAd := testNewSystemAdmin(domain.NewSystemAdminModel())
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+
+		// given
+		user := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID", "USERNAME", "PASSWORD")
+		group := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY", "GROUP_NAME", "GROUP_DESCRIPTION")
+		require.NoError(t, authorizationManager.AddUserToGroup(ctx, owner, user.AppUserID(), group.UserGroupID()))
+		pairs, rules := testCountUserGroupings(t, ts, orgID, user.AppUserID())
+		require.Equal(t, int64(1), pairs)
+		require.Equal(t, int64(1), rules)
+		rbacAppUser := service.NewRBACAppUser(orgID, user.AppUserID())
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, rbacAppUser, domain.NewRBACAction("read"), domain.NewRBACObject("data"), service.RBACAllowEffect))
+
+		// when
+		err := appUserRepo.RemoveAppUser(ctx, owner, user.AppUserID(), user.Version)
+
+		// then
+		require.NoError(t, err)
+		_, err = appUserRepo.FindAppUserByID(ctx, owner, user.AppUserID())
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+		_, err = appUserRepo.FindAppUserByLoginID(ctx, owner, "LOGIN_ID")
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+		_, err = appUserRepo.VerifyPassword(ctx, sysAd, orgID, "LOGIN_ID", "PASSWORD")
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+		pairs, rules = testCountUserGroupings(t, ts, orgID, user.AppUserID())
+		assert.Equal(t, int64(0), pairs)
+		assert.Equal(t, int64(0), rules)
+		// - the policies of the app user are revoked
+		policies, err := authorizationManager.ListPoliciesForSubject(ctx, owner, rbacAppUser)
+		require.NoError(t, err)
+		assert.Empty(t, policies)
+		ok, err := authorizationManager.Authorize(ctx, user, domain.NewRBACAction("read"), domain.NewRBACObject("data"))
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		// - the removed app user cannot be removed again nor changed
+		err = appUserRepo.RemoveAppUser(ctx, owner, user.AppUserID(), user.Version+1)
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+		err = appUserRepo.ChangePassword(ctx, owner, user.AppUserID(), "NEW_PASSWORD")
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_appUserRepository_RemoveAppUser_shouldReturnError_whenOldVersionIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+
+		// given
+		user := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID", "USERNAME", "PASSWORD")
+		param, err := service.NewAppUserUpdateParameter("NEW_USERNAME")
+		require.NoError(t, err)
+		require.NoError(t, appUserRepo.UpdateAppUser(ctx, owner, user.AppUserID(), user.Version, param))
+
+		// when
+		err = appUserRepo.RemoveAppUser(ctx, owner, user.AppUserID(), user.Version)
+
+		// then
+		assert.ErrorIs(t, err, libdomain.ErrConcurrentModification)
+		_, err = appUserRepo.FindAppUserByID(ctx, owner, user.AppUserID())
+		assert.NoError(t, err)
+
+		// - the app user of the other organization is not found
+		err = appUserRepo.RemoveAppUser(ctx, owner, invalidAppUserID, 1)
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_appUserRepository_RestoreAppUser_shouldRestoreLoginID_whenItIsNotUsed(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+
+		// given
+		user := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID", "USERNAME", "PASSWORD")
+		require.NoError(t, appUserRepo.RemoveAppUser(ctx, owner, user.AppUserID(), user.Version))
+
+		// - the login ID of the removed app user can be used by another app user
+		other := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID", "OTHER_USERNAME", "PASSWORD")
+		assert.NotEqual(t, user.AppUserID().Int(), other.AppUserID().Int())
+
+		// when the login ID is used
+		err := appUserRepo.RestoreAppUser(ctx, owner, user.AppUserID(), user.Version+1)
+
+		// then
+		assert.ErrorIs(t, err, service.ErrAppUserAlreadyExists)
+
+		// when the login ID is released
+		require.NoError(t, appUserRepo.RemoveAppUser(ctx, owner, other.AppUserID(), other.Version))
+		err = appUserRepo.RestoreAppUser(ctx, owner, user.AppUserID(), user.Version+1)
+
+		// then
+		require.NoError(t, err)
+		restored, err := appUserRepo.FindAppUserByLoginID(ctx, owner, "LOGIN_ID")
+		require.NoError(t, err)
+		assert.Equal(t, user.AppUserID().Int(), restored.AppUserID().Int())
+		assert.Equal(t, "USERNAME", restored.Username())
+		assert.Equal(t, user.Version+2, restored.Version)
+
+		// - the app user which is not removed cannot be restored
+		err = appUserRepo.RestoreAppUser(ctx, owner, user.AppUserID(), restored.Version)
+		assert.ErrorIs(t, err, service.ErrAppUserNotFound)
+
+		// - the old version is rejected
+		err = appUserRepo.RestoreAppUser(ctx, owner, other.AppUserID(), other.Version)
+		assert.ErrorIs(t, err, libdomain.ErrConcurrentModification)
+	}
+	testOrganization(t, fn)
+}
@@ -0,0 +1,227 @@
+package gateway
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+// appUserCursor is the position of the last app user of the page, which is encoded into the opaque cursor.
+// Key is the sort key with the order so that the cursor of another order is rejected
+type appUserCursor struct {
+	Key   string `json:"k"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func appUserCursorKey(condition *service.AppUserSearchCondition) string {
+	return string(condition.SortKey) + ":" + string(condition.SortOrder)
+}
+
+func encodeAppUserCursor(cursor *appUserCursor) (string, error) {
+	bytes, err := json.Marshal(cursor)
+	if err != nil {
+		return "", liberrors.Errorf("json.Marshal. err: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func decodeAppUserCursor(value string) (*appUserCursor, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, liberrors.Errorf("invalid cursor. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	cursor := appUserCursor{}
+	if err := json.Unmarshal(bytes, &cursor); err != nil {
+		return nil, liberrors.Errorf("invalid cursor. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	return &cursor, nil
+}
+
+// escapeLike escapes the wildcards of LIKE with '!'
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
+
+// appUserSortColumn returns the column of the sort key. The login IDs of the removed app users are in removed_login_id
+func appUserSortColumn(condition *service.AppUserSearchCondition) string {
+	switch condition.SortKey {
+	case service.AppUserSortKeyLoginID:
+		if condition.Filter.Removed {
+			return "app_user.removed_login_id"
+		}
+		return "app_user.login_id"
+	case service.AppUserSortKeyUsername:
+		return "app_user.username"
+	case service.AppUserSortKeyCreatedAt:
+		return "app_user.created_at"
+	default:
+		return "app_user.id"
+	}
+}
+
+// appUserSortValue returns the value of the sort key of the app user, and converts the value in the cursor back for the query
+func appUserSortValue(condition *service.AppUserSearchCondition, e *appUserEntity) string {
+	switch condition.SortKey {
+	case service.AppUserSortKeyLoginID:
+		return e.LoginID
+	case service.AppUserSortKeyUsername:
+		return e.Username
+	case service.AppUserSortKeyCreatedAt:
+		return e.CreatedAt.Format(time.RFC3339Nano)
+	default:
+		return strconv.Itoa(e.ID)
+	}
+}
+
+func parseAppUserSortValue(condition *service.AppUserSearchCondition, value string) (interface{}, error) {
+	switch condition.SortKey {
+	case service.AppUserSortKeyLoginID, service.AppUserSortKeyUsername:
+		return value, nil
+	case service.AppUserSortKeyCreatedAt:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, liberrors.Errorf("invalid cursor. err: %w", libdomain.ErrInvalidArgument)
+		}
+		return t, nil
+	default:
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, liberrors.Errorf("invalid cursor. err: %w", libdomain.ErrInvalidArgument)
+		}
+		return id, nil
+	}
+}
+
+func (r *appUserRepository) whereAppUserFilter(db *gorm.DB, operator service.OwnerModelInterface, filter *service.AppUserFilter) *gorm.DB {
+	wrappedDB := wrappedDB{dialect: r.dialect, db: db, organizationID: operator.OrganizationID()}
+	wrappedDB.WhereOrganizationID(&appUserEntity{}, operator.OrganizationID())
+
+	loginIDColumn := "app_user.login_id"
+	if filter.Removed {
+		wrappedDB.WhereRemoved(&appUserEntity{})
+		loginIDColumn = "app_user.removed_login_id"
+	} else {
+		wrappedDB.WhereNotRemoved(&appUserEntity{})
+	}
+
+	if filter.LoginIDPrefix != "" {
+		wrappedDB.Where(loginIDColumn+" like ? escape '!'", escapeLike(filter.LoginIDPrefix)+"%")
+	}
+	if filter.UsernameContains != "" {
+		wrappedDB.Where("lower(app_user.username) like ? escape '!'", "%"+escapeLike(strings.ToLower(filter.UsernameContains))+"%")
+	}
+	if filter.UserGroupID != nil {
+		wrappedDB.Where("exists (select 1 from user_n_group where user_n_group.organization_id = app_user.organization_id and user_n_group.app_user_id = app_user.id and user_n_group.user_group_id = ?)", filter.UserGroupID.Int())
+	}
+	if filter.Provider != "" {
+		wrappedDB.Where("app_user.provider = ?", filter.Provider)
+	}
+
+	return wrappedDB.db
+}
+
+func (r *appUserRepository) FindAppUsers(ctx context.Context, operator service.OwnerModelInterface, condition *service.AppUserSearchCondition) (*service.AppUserSearchResult, error) {
+	_, span := tracer.Start(ctx, "appUserRepository.FindAppUsers")
+	defer span.End()
+
+	db := r.db.WithContext(ctx).Model(&appUserEntity{})
+	db = r.whereAppUserFilter(db, operator, &condition.Filter)
+
+	var totalCount int64
+	if err := db.Session(&gorm.Session{}).Count(&totalCount).Error; err != nil {
+		return nil, liberrors.Errorf("count %s. err: %w", AppUserTableName, err)
+	}
+
+	column := appUserSortColumn(condition)
+	comparison, order := ">", "asc"
+	if condition.SortOrder == service.SortOrderDesc {
+		comparison, order = "<", "desc"
+	}
+
+	db = db.Session(&gorm.Session{})
+	if condition.Cursor != "" {
+		cursor, err := decodeAppUserCursor(condition.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Key != appUserCursorKey(condition) {
+			return nil, liberrors.Errorf("cursor of another order. key: %s, err: %w", cursor.Key, libdomain.ErrInvalidArgument)
+		}
+
+		value, err := parseAppUserSortValue(condition, cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		// keyset pagination on (column, id)
+		if condition.SortKey == service.AppUserSortKeyID {
+			db = db.Where("app_user.id "+comparison+" ?", value)
+		} else {
+			db = db.Where("("+column+" "+comparison+" ? or ("+column+" = ? and app_user.id "+comparison+" ?))", value, value, cursor.ID)
+		}
+	} else {
+		db = db.Offset((condition.PageNo - 1) * condition.PageSize)
+	}
+
+	if condition.SortKey == service.AppUserSortKeyID {
+		db = db.Order("app_user.id " + order)
+	} else {
+		db = db.Order(column + " " + order).Order("app_user.id " + order)
+	}
+
+	// fetch one more app user to know whether the next page exists
+	appUserEntities := make([]appUserEntity, 0)
+	if err := db.Limit(condition.PageSize + 1).Find(&appUserEntities).Error; err != nil {
+		return nil, liberrors.Errorf("select %s. err: %w", AppUserTableName, err)
+	}
+
+	hasNext := len(appUserEntities) > condition.PageSize
+	if hasNext {
+		appUserEntities = appUserEntities[:condition.PageSize]
+	}
+
+	appUsers := make([]*service.AppUser, len(appUserEntities))
+	for i := range appUserEntities {
+		if condition.Filter.Removed && appUserEntities[i].RemovedLoginID != nil {
+			appUserEntities[i].LoginID = *appUserEntities[i].RemovedLoginID
+		}
+
+		appUser, err := appUserEntities[i].toAppUser(ctx, r.rf, nil)
+		if err != nil {
+			return nil, liberrors.Errorf("toAppUser. err: %w", err)
+		}
+		appUsers[i] = appUser
+	}
+
+	result := service.AppUserSearchResult{
+		AppUsers:   appUsers,
+		TotalCount: int(totalCount),
+	}
+	if hasNext {
+		last := &appUserEntities[len(appUserEntities)-1]
+		nextCursor, err := encodeAppUserCursor(&appUserCursor{
+			Key:   appUserCursorKey(condition),
+			Value: appUserSortValue(condition, last),
+			ID:    last.ID,
+		})
+		if err != nil {
+			return nil, liberrors.Errorf("encodeAppUserCursor. err: %w", err)
+		}
+		result.NextCursor = nextCursor
+	}
+
+	return &result, nil
+}
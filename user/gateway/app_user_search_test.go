@@ -0,0 +1,173 @@
+package gateway_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func testNewAppUserSearchCondition(t *testing.T, filter service.AppUserFilter, sortKey service.AppUserSortKey, sortOrder service.SortOrder, pageNo, pageSize int, cursor string) *service.AppUserSearchCondition {
+	t.Helper()
+	condition, err := service.NewAppUserSearchCondition(filter, sortKey, sortOrder, pageNo, pageSize, cursor)
+	require.NoError(t, err)
+	return condition
+}
+
+func testAppUserLoginIDs(result *service.AppUserSearchResult) []string {
+	loginIDs := make([]string, len(result.AppUsers))
+	for i, appUser := range result.AppUsers {
+		loginIDs[i] = appUser.LoginID()
+	}
+	return loginIDs
+}
+
+func testAddAppUsersToSearch(t *testing.T, ctx context.Context, ts testService, owner *service.Owner) {
+	t.Helper()
+	testAddAppUser(t, ctx, ts, owner, "USER_A1", "Alice", "PASSWORD")
+	testAddAppUser(t, ctx, ts, owner, "USER_A2", "Alfred", "PASSWORD")
+	testAddAppUser(t, ctx, ts, owner, "USER_B1", "Bob", "PASSWORD")
+	testAddAppUser(t, ctx, ts, owner, "USER_%1", "Percent", "PASSWORD")
+}
+
+func Test_appUserRepository_FindAppUsers_shouldReturnMatchingAppUsers_whenFilterIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+
+		// given
+		testAddAppUsersToSearch(t, ctx, ts, owner)
+		userA1, err := appUserRepo.FindAppUserByLoginID(ctx, owner, "USER_A1")
+		require.NoError(t, err)
+		group := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY", "GROUP_NAME", "GROUP_DESCRIPTION")
+		require.NoError(t, authorizationManager.AddUserToGroup(ctx, owner, userA1.AppUserID(), group.UserGroupID()))
+		providerParam, err := service.NewAppUserAddParameter("USER_G1", "Google", "", "google", "GOOGLE_ID", "", "")
+		require.NoError(t, err)
+		_, err = appUserRepo.AddAppUser(ctx, owner, providerParam)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name     string
+			filter   service.AppUserFilter
+			loginIDs []string
+		}{
+			{name: "no filter", filter: service.AppUserFilter{}, loginIDs: []string{service.SystemOwnerLoginID, "OWNER_ID", "USER_A1", "USER_A2", "USER_B1", "USER_%1", "USER_G1"}},
+			{name: "login ID prefix", filter: service.AppUserFilter{LoginIDPrefix: "USER_A"}, loginIDs: []string{"USER_A1", "USER_A2"}},
+			{name: "login ID prefix with wildcard", filter: service.AppUserFilter{LoginIDPrefix: "USER_%"}, loginIDs: []string{"USER_%1"}},
+			{name: "username case-insensitively", filter: service.AppUserFilter{UsernameContains: "aL"}, loginIDs: []string{"USER_A1", "USER_A2"}},
+			{name: "user group", filter: service.AppUserFilter{UserGroupID: group.UserGroupID()}, loginIDs: []string{"USER_A1"}},
+			{name: "provider", filter: service.AppUserFilter{Provider: "google"}, loginIDs: []string{"USER_G1"}},
+			{name: "all filters", filter: service.AppUserFilter{LoginIDPrefix: "USER_", UsernameContains: "b", Provider: "google"}, loginIDs: []string{}},
+		}
+		for _, tt := range tests {
+			// when
+			result, err := appUserRepo.FindAppUsers(ctx, owner, testNewAppUserSearchCondition(t, tt.filter, "", "", 0, 0, ""))
+
+			// then
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, tt.loginIDs, testAppUserLoginIDs(result), tt.name)
+			assert.Equal(t, len(tt.loginIDs), result.TotalCount, tt.name)
+			assert.Empty(t, result.NextCursor, tt.name)
+		}
+	}
+	testOrganization(t, fn)
+}
+
+func Test_appUserRepository_FindAppUsers_shouldReturnPages_whenPageNoOrCursorIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+
+		// given
+		testAddAppUsersToSearch(t, ctx, ts, owner)
+		filter := service.AppUserFilter{LoginIDPrefix: "USER_"}
+
+		tests := []struct {
+			sortKey   service.AppUserSortKey
+			sortOrder service.SortOrder
+			loginIDs  []string
+		}{
+			{sortKey: service.AppUserSortKeyID, sortOrder: service.SortOrderDesc, loginIDs: []string{"USER_%1", "USER_B1", "USER_A2", "USER_A1"}},
+			{sortKey: service.AppUserSortKeyLoginID, sortOrder: service.SortOrderAsc, loginIDs: []string{"USER_%1", "USER_A1", "USER_A2", "USER_B1"}},
+			{sortKey: service.AppUserSortKeyUsername, sortOrder: service.SortOrderDesc, loginIDs: []string{"USER_%1", "USER_B1", "USER_A1", "USER_A2"}},
+			{sortKey: service.AppUserSortKeyCreatedAt, sortOrder: service.SortOrderAsc, loginIDs: []string{"USER_A1", "USER_A2", "USER_B1", "USER_%1"}},
+		}
+		for _, tt := range tests {
+			name := string(tt.sortKey) + " " + string(tt.sortOrder)
+
+			// when the pages are read with the page numbers
+			page1, err := appUserRepo.FindAppUsers(ctx, owner, testNewAppUserSearchCondition(t, filter, tt.sortKey, tt.sortOrder, 1, 3, ""))
+			require.NoError(t, err, name)
+			page2, err := appUserRepo.FindAppUsers(ctx, owner, testNewAppUserSearchCondition(t, filter, tt.sortKey, tt.sortOrder, 2, 3, ""))
+			require.NoError(t, err, name)
+
+			// then
+			assert.Equal(t, tt.loginIDs[:3], testAppUserLoginIDs(page1), name)
+			assert.Equal(t, tt.loginIDs[3:], testAppUserLoginIDs(page2), name)
+			assert.Equal(t, 4, page1.TotalCount, name)
+			assert.Equal(t, 4, page2.TotalCount, name)
+			assert.Empty(t, page2.NextCursor, name)
+
+			// when the pages are read with the cursors
+			loginIDs := make([]string, 0)
+			cursor := ""
+			for i := 0; i < 4; i++ {
+				page, err := appUserRepo.FindAppUsers(ctx, owner, testNewAppUserSearchCondition(t, filter, tt.sortKey, tt.sortOrder, 0, 2, cursor))
+				require.NoError(t, err, name)
+				loginIDs = append(loginIDs, testAppUserLoginIDs(page)...)
+				cursor = page.NextCursor
+				if cursor == "" {
+					break
+				}
+			}
+
+			// then
+			assert.Equal(t, tt.loginIDs, loginIDs, name)
+		}
+
+		// - the cursor of another order is rejected
+		page, err := appUserRepo.FindAppUsers(ctx, owner, testNewAppUserSearchCondition(t, filter, service.AppUserSortKeyUsername, service.SortOrderAsc, 0, 2, ""))
+		require.NoError(t, err)
+		require.NotEmpty(t, page.NextCursor)
+		_, err = appUserRepo.FindAppUsers(ctx, owner, testNewAppUserSearchCondition(t, filter, service.AppUserSortKeyUsername, service.SortOrderDesc, 0, 2, page.NextCursor))
+		assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+		_, err = appUserRepo.FindAppUsers(ctx, owner, testNewAppUserSearchCondition(t, filter, service.AppUserSortKeyUsername, service.SortOrderAsc, 0, 2, "INVALID"))
+		assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_appUserRepository_FindAppUsers_shouldReturnRemovedAppUsers_whenRemovedIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		appUserRepo := gateway.NewAppUserRepository(ctx, ts.dialect, ts.db, ts.rf)
+
+		// given
+		testAddAppUsersToSearch(t, ctx, ts, owner)
+		userB1, err := appUserRepo.FindAppUserByLoginID(ctx, owner, "USER_B1")
+		require.NoError(t, err)
+		require.NoError(t, appUserRepo.RemoveAppUser(ctx, owner, userB1.AppUserID(), userB1.Version))
+
+		// when
+		active, err := appUserRepo.FindAppUsers(ctx, owner, testNewAppUserSearchCondition(t, service.AppUserFilter{LoginIDPrefix: "USER_"}, "", "", 0, 0, ""))
+		require.NoError(t, err)
+		removed, err := appUserRepo.FindAppUsers(ctx, owner, testNewAppUserSearchCondition(t, service.AppUserFilter{LoginIDPrefix: "USER_B", Removed: true}, service.AppUserSortKeyLoginID, "", 0, 0, ""))
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, []string{"USER_A1", "USER_A2", "USER_%1"}, testAppUserLoginIDs(active))
+		assert.Equal(t, []string{"USER_B1"}, testAppUserLoginIDs(removed))
+		assert.Equal(t, 1, removed.TotalCount)
+		assert.Equal(t, userB1.AppUserID().Int(), removed.AppUsers[0].AppUserID().Int())
+		// - the version is the one to restore the app user with
+		require.NoError(t, appUserRepo.RestoreAppUser(ctx, owner, userB1.AppUserID(), removed.AppUsers[0].Version))
+	}
+	testOrganization(t, fn)
+}
@@ -0,0 +1,182 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+var (
+	AuditEventTableName = "audit_event"
+)
+
+type auditEventEntity struct {
+	ID             int
+	OccurredAt     time.Time
+	ActorID        int
+	OrganizationID int
+	Action         string
+	TargetType     string
+	TargetID       string
+	BeforeJSON     *string
+	AfterJSON      *string
+	RequestID      string
+	TraceID        string
+}
+
+func (e *auditEventEntity) TableName() string {
+	return AuditEventTableName
+}
+
+func (e *auditEventEntity) toModel() (*domain.AuditEventModel, error) {
+	actorID, err := domain.NewAppUserID(e.ActorID)
+	if err != nil {
+		return nil, liberrors.Errorf("domain.NewAppUserID. err: %w", err)
+	}
+
+	organizationID, err := domain.NewOrganizationID(e.OrganizationID)
+	if err != nil {
+		return nil, liberrors.Errorf("domain.NewOrganizationID. err: %w", err)
+	}
+
+	target, err := domain.NewAuditTarget(domain.AuditTargetType(e.TargetType), e.TargetID)
+	if err != nil {
+		return nil, liberrors.Errorf("domain.NewAuditTarget. err: %w", err)
+	}
+
+	m := &domain.AuditEventModel{
+		ID:             e.ID,
+		OccurredAt:     e.OccurredAt,
+		ActorID:        actorID,
+		OrganizationID: organizationID,
+		Action:         domain.AuditAction(e.Action),
+		Target:         target,
+		RequestID:      e.RequestID,
+		TraceID:        e.TraceID,
+	}
+	if e.BeforeJSON != nil {
+		m.Before = *e.BeforeJSON
+	}
+	if e.AfterJSON != nil {
+		m.After = *e.AfterJSON
+	}
+
+	return m, nil
+}
+
+type auditLogRepository struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+func NewAuditLogRepository(ctx context.Context, db *gorm.DB) service.AuditLogRepository {
+	return &auditLogRepository{
+		db:  db,
+		now: time.Now,
+	}
+}
+
+func marshalAuditValue(value interface{}) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, liberrors.Errorf("json.Marshal. err: %w", err)
+	}
+
+	s := string(bytes)
+	return &s, nil
+}
+
+func (r *auditLogRepository) AddAuditEvent(ctx context.Context, operator service.OperatorInterface, param *service.AuditEventAddParameter) error {
+	ctx, span := tracer.Start(ctx, "auditLogRepository.AddAuditEvent")
+	defer span.End()
+
+	before, err := marshalAuditValue(param.Before)
+	if err != nil {
+		return liberrors.Errorf("marshalAuditValue. before, err: %w", err)
+	}
+
+	after, err := marshalAuditValue(param.After)
+	if err != nil {
+		return liberrors.Errorf("marshalAuditValue. after, err: %w", err)
+	}
+
+	traceID := ""
+	if spanContext := span.SpanContext(); spanContext.HasTraceID() {
+		traceID = spanContext.TraceID().String()
+	}
+
+	entity := auditEventEntity{
+		OccurredAt:     r.now().UTC(),
+		ActorID:        operator.AppUserID().Int(),
+		OrganizationID: param.OrganizationID.Int(),
+		Action:         string(param.Action),
+		TargetType:     string(param.Target.Type),
+		TargetID:       param.Target.ID,
+		BeforeJSON:     before,
+		AfterJSON:      after,
+		RequestID:      libdomain.RequestIDFromContext(ctx),
+		TraceID:        traceID,
+	}
+	if err := r.db.WithContext(ctx).Create(&entity).Error; err != nil {
+		return liberrors.Errorf("insert %s. err: %w", AuditEventTableName, err)
+	}
+
+	return nil
+}
+
+func (r *auditLogRepository) FindAuditEvents(ctx context.Context, operator service.AppUserInterface, condition *service.AuditEventSearchCondition) ([]*domain.AuditEventModel, error) {
+	_, span := tracer.Start(ctx, "auditLogRepository.FindAuditEvents")
+	defer span.End()
+
+	return r.findAuditEvents(ctx, operator.OrganizationID(), condition)
+}
+
+func (r *auditLogRepository) FindAuditEventsBySystemAdmin(ctx context.Context, operator service.SystemAdminInterface, organizationID *domain.OrganizationID, condition *service.AuditEventSearchCondition) ([]*domain.AuditEventModel, error) {
+	_, span := tracer.Start(ctx, "auditLogRepository.FindAuditEventsBySystemAdmin")
+	defer span.End()
+
+	return r.findAuditEvents(ctx, organizationID, condition)
+}
+
+func (r *auditLogRepository) findAuditEvents(ctx context.Context, organizationID *domain.OrganizationID, condition *service.AuditEventSearchCondition) ([]*domain.AuditEventModel, error) {
+	db := r.db.WithContext(ctx).Where("organization_id = ?", organizationID.Int())
+	if !condition.From.IsZero() {
+		db = db.Where("occurred_at >= ?", condition.From.UTC())
+	}
+	if !condition.To.IsZero() {
+		db = db.Where("occurred_at < ?", condition.To.UTC())
+	}
+	if condition.ActorID != nil {
+		db = db.Where("actor_id = ?", condition.ActorID.Int())
+	}
+	if condition.Target != nil {
+		db = db.Where("target_type = ? and target_id = ?", string(condition.Target.Type), condition.Target.ID)
+	}
+
+	entities := make([]auditEventEntity, 0)
+	if err := db.Order("occurred_at desc, id desc").Limit(condition.Limit).Find(&entities).Error; err != nil {
+		return nil, liberrors.Errorf("select %s. err: %w", AuditEventTableName, err)
+	}
+
+	events := make([]*domain.AuditEventModel, len(entities))
+	for i := range entities {
+		event, err := entities[i].toModel()
+		if err != nil {
+			return nil, liberrors.Errorf("toModel. err: %w", err)
+		}
+		events[i] = event
+	}
+
+	return events, nil
+}
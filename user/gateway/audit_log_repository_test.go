@@ -0,0 +1,189 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func testNewAuditEventSearchCondition(t *testing.T, from, to time.Time, actorID *domain.AppUserID, target *domain.AuditTarget) *service.AuditEventSearchCondition {
+	t.Helper()
+	condition, err := service.NewAuditEventSearchCondition(from, to, actorID, target, 0)
+	require.NoError(t, err)
+	return condition
+}
+
+func testNewAuditTarget(t *testing.T, targetType domain.AuditTargetType, id int) *domain.AuditTarget {
+	t.Helper()
+	target, err := domain.NewAuditTarget(targetType, strconv.Itoa(id))
+	require.NoError(t, err)
+	return target
+}
+
+func Test_auditLogRepository_FindAuditEvents(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// the events recorded on setting up the organization are out of the time range
+		base := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		end := base.Add(24 * time.Hour)
+		userTarget := testNewAuditTarget(t, domain.AuditTargetTypeAppUser, 10)
+		groupTarget := testNewAuditTarget(t, domain.AuditTargetTypeUserGroup, 20)
+
+		auditLogRepo := gateway.NewAuditLogRepository(ctx, ts.db)
+		for i, p := range []struct {
+			operator service.OperatorInterface
+			action   domain.AuditAction
+			target   *domain.AuditTarget
+			before   interface{}
+			after    interface{}
+		}{
+			{operator: owner, action: domain.AuditActionAddAppUser, target: userTarget, after: map[string]string{"loginId": "USER"}},
+			{operator: owner, action: domain.AuditActionUpdateAppUser, target: userTarget, before: map[string]string{"username": "A"}, after: map[string]string{"username": "B"}},
+			{operator: sysOwner, action: domain.AuditActionAddUserGroup, target: groupTarget, after: map[string]string{"key": "GROUP"}},
+		} {
+			gateway.SetAuditLogRepositoryNow(auditLogRepo, func() time.Time { return base.Add(time.Duration(i) * time.Hour) })
+			param, err := service.NewAuditEventAddParameter(orgID, p.action, p.target, p.before, p.after)
+			require.NoError(t, err)
+			require.NoError(t, auditLogRepo.AddAuditEvent(libdomain.WithRequestID(ctx, "REQUEST_"+strconv.Itoa(i)), p.operator, param))
+		}
+
+		// all the events, the latest first
+		events, err := auditLogRepo.FindAuditEvents(ctx, owner, testNewAuditEventSearchCondition(t, base, end, nil, nil))
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		assert.Equal(t, domain.AuditActionAddUserGroup, events[0].Action)
+		assert.Equal(t, sysOwner.AppUserID().Int(), events[0].ActorID.Int())
+		assert.Equal(t, domain.AuditActionUpdateAppUser, events[1].Action)
+		assert.Equal(t, owner.AppUserID().Int(), events[1].ActorID.Int())
+		assert.Equal(t, orgID.Int(), events[1].OrganizationID.Int())
+		assert.Equal(t, userTarget, events[1].Target)
+		assert.JSONEq(t, `{"username":"A"}`, events[1].Before)
+		assert.JSONEq(t, `{"username":"B"}`, events[1].After)
+		assert.Equal(t, "REQUEST_1", events[1].RequestID)
+		assert.True(t, events[1].OccurredAt.Equal(base.Add(time.Hour)))
+		assert.Empty(t, events[2].Before)
+
+		// time range
+		events, err = auditLogRepo.FindAuditEvents(ctx, owner, testNewAuditEventSearchCondition(t, base, base.Add(2*time.Hour), nil, nil))
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, domain.AuditActionUpdateAppUser, events[0].Action)
+		assert.Equal(t, domain.AuditActionAddAppUser, events[1].Action)
+
+		// actor
+		events, err = auditLogRepo.FindAuditEvents(ctx, owner, testNewAuditEventSearchCondition(t, base, end, sysOwner.AppUserID(), nil))
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, domain.AuditActionAddUserGroup, events[0].Action)
+
+		// target
+		events, err = auditLogRepo.FindAuditEvents(ctx, owner, testNewAuditEventSearchCondition(t, base, end, nil, userTarget))
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+
+		// limit
+		condition, err := service.NewAuditEventSearchCondition(base, end, nil, nil, 1)
+		require.NoError(t, err)
+		events, err = auditLogRepo.FindAuditEvents(ctx, owner, condition)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, domain.AuditActionAddUserGroup, events[0].Action)
+
+		// the events of the other organizations are not returned
+		events, err = auditLogRepo.FindAuditEventsBySystemAdmin(ctx, testNewSystemAdmin(domain.NewSystemAdminModel()), invalidOrgID, testNewAuditEventSearchCondition(t, time.Time{}, time.Time{}, nil, nil))
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_auditLogRepository_RecordedActions(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		auditLogRepo := gateway.NewAuditLogRepository(ctx, ts.db)
+		findEvents := func(target *domain.AuditTarget) []*domain.AuditEventModel {
+			events, err := auditLogRepo.FindAuditEvents(ctx, owner, testNewAuditEventSearchCondition(t, time.Time{}, time.Time{}, nil, target))
+			require.NoError(t, err)
+			return events
+		}
+
+		// the membership of the first owner is recorded
+		events := findEvents(testNewAuditTarget(t, domain.AuditTargetTypeAppUser, owner.AppUserID().Int()))
+		require.Len(t, events, 1)
+		assert.Equal(t, domain.AuditActionAddUserToGroup, events[0].Action)
+		assert.Equal(t, sysOwner.AppUserID().Int(), events[0].ActorID.Int())
+
+		// the policies of the system owner are recorded
+		rbacSysOwner := service.NewRBACAppUser(orgID, sysOwner.AppUserID())
+		policyTarget, err := domain.NewAuditTarget(domain.AuditTargetTypeRBACSubject, rbacSysOwner.Subject())
+		require.NoError(t, err)
+		events = findEvents(policyTarget)
+		require.Len(t, events, 2)
+		assert.Equal(t, domain.AuditActionAddPolicy, events[0].Action)
+		assert.Equal(t, domain.SystemAdminID.Int(), events[0].ActorID.Int())
+
+		// the app user added by the owner is recorded without the password
+		appUserID, err := owner.AddAppUser(ctx, testNewAppUserAddParameter(t, "LOGIN_ID", "USERNAME", "PASSWORD"))
+		require.NoError(t, err)
+		events = findEvents(testNewAuditTarget(t, domain.AuditTargetTypeAppUser, appUserID.Int()))
+		require.Len(t, events, 1)
+		assert.Equal(t, domain.AuditActionAddAppUser, events[0].Action)
+		assert.Equal(t, owner.AppUserID().Int(), events[0].ActorID.Int())
+		assert.JSONEq(t, `{"loginId":"LOGIN_ID","username":"USERNAME"}`, events[0].After)
+
+		// the update of the organization is recorded with the values before and after it
+		sysAd, err := service.NewSystemAdmin(ctx, ts.rf)
+		require.NoError(t, err)
+		org := getOrganization(t, ctx, ts, orgID)
+		updateParam, err := service.NewOrganizationUpdateParameter("NEW_NAME")
+		require.NoError(t, err)
+		require.NoError(t, sysAd.UpdateOrganization(ctx, orgID, org.Version, updateParam))
+		events = findEvents(testNewAuditTarget(t, domain.AuditTargetTypeOrganization, orgID.Int()))
+		require.Len(t, events, 1)
+		assert.Equal(t, domain.AuditActionUpdateOrganization, events[0].Action)
+		before := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(events[0].Before), &before))
+		assert.Equal(t, org.Name(), before["name"])
+		assert.JSONEq(t, `{"name":"NEW_NAME","version":`+strconv.Itoa(org.Version+1)+`}`, events[0].After)
+
+		// nothing is recorded when the update fails
+		err = sysAd.UpdateOrganization(ctx, orgID, org.Version, updateParam)
+		assert.ErrorIs(t, err, libdomain.ErrConcurrentModification)
+		events = findEvents(testNewAuditTarget(t, domain.AuditTargetTypeOrganization, orgID.Int()))
+		assert.Len(t, events, 1)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_auditLogRepository_Rollback(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		errRollback := errors.New("rollback")
+		target := testNewAuditTarget(t, domain.AuditTargetTypeOrganization, orgID.Int())
+
+		err := ts.db.Transaction(func(tx *gorm.DB) error {
+			param, err := service.NewAuditEventAddParameter(orgID, domain.AuditActionUpdateOrganization, target, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, gateway.NewAuditLogRepository(ctx, tx).AddAuditEvent(ctx, owner, param))
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+
+		events, err := gateway.NewAuditLogRepository(ctx, ts.db).FindAuditEvents(ctx, owner, testNewAuditEventSearchCondition(t, time.Time{}, time.Time{}, nil, target))
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	}
+	testOrganization(t, fn)
+}
@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
+type appUserClaims struct {
+	OrganizationID int    `json:"orgId"`
+	AppUserID      int    `json:"appUserId"`
+	LoginID        string `json:"loginId"`
+	TokenType      string `json:"tokenType"`
+	jwt.RegisteredClaims
+}
+
+type authTokenManager struct {
+	signingMethod   jwt.SigningMethod
+	signingKey      interface{}
+	verifyKey       interface{}
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	now             func() time.Time
+}
+
+// NewAuthTokenManager returns an AuthTokenManager issuing JWTs.
+// signingKey and verifyKey are the same []byte for HMAC, ed25519.PrivateKey and ed25519.PublicKey for EdDSA.
+func NewAuthTokenManager(signingMethod jwt.SigningMethod, signingKey, verifyKey interface{}, accessTokenTTL, refreshTokenTTL time.Duration) (service.AuthTokenManager, error) {
+	if signingMethod == nil {
+		return nil, liberrors.Errorf("signingMethod is nil. err: %w", libdomain.ErrInvalidArgument)
+	}
+	if signingKey == nil || verifyKey == nil {
+		return nil, liberrors.Errorf("signingKey or verifyKey is nil. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	return &authTokenManager{
+		signingMethod:   signingMethod,
+		signingKey:      signingKey,
+		verifyKey:       verifyKey,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+		now:             time.Now,
+	}, nil
+}
+
+func (m *authTokenManager) CreateTokenSet(ctx context.Context, appUser service.AppUserInterface) (*service.TokenSet, error) {
+	_, span := tracer.Start(ctx, "authTokenManager.CreateTokenSet")
+	defer span.End()
+
+	accessToken, err := m.createToken(appUser, accessTokenType, m.accessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := m.createToken(appUser, refreshTokenType, m.refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.TokenSet{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (m *authTokenManager) CreateAccessToken(ctx context.Context, appUser service.AppUserInterface) (string, error) {
+	_, span := tracer.Start(ctx, "authTokenManager.CreateAccessToken")
+	defer span.End()
+
+	return m.createToken(appUser, accessTokenType, m.accessTokenTTL)
+}
+
+func (m *authTokenManager) ParseAccessToken(ctx context.Context, accessToken string) (*service.AppUserTokenInfo, error) {
+	_, span := tracer.Start(ctx, "authTokenManager.ParseAccessToken")
+	defer span.End()
+
+	return m.parseToken(accessToken, accessTokenType)
+}
+
+func (m *authTokenManager) ParseRefreshToken(ctx context.Context, refreshToken string) (*service.AppUserTokenInfo, error) {
+	_, span := tracer.Start(ctx, "authTokenManager.ParseRefreshToken")
+	defer span.End()
+
+	return m.parseToken(refreshToken, refreshTokenType)
+}
+
+func (m *authTokenManager) createToken(appUser service.AppUserInterface, tokenType string, ttl time.Duration) (string, error) {
+	now := m.now()
+	claims := appUserClaims{
+		OrganizationID: appUser.OrganizationID().Int(),
+		AppUserID:      appUser.AppUserID().Int(),
+		LoginID:        appUser.LoginID(),
+		TokenType:      tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(appUser.AppUserID().Int()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	token := jwt.NewWithClaims(m.signingMethod, claims)
+	signed, err := token.SignedString(m.signingKey)
+	if err != nil {
+		return "", liberrors.Errorf("token.SignedString. err: %w", err)
+	}
+
+	return signed, nil
+}
+
+func (m *authTokenManager) parseToken(tokenString, tokenType string) (*service.AppUserTokenInfo, error) {
+	claims := appUserClaims{}
+	if _, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		return m.verifyKey, nil
+	}, jwt.WithValidMethods([]string{m.signingMethod.Alg()}), jwt.WithTimeFunc(m.now)); err != nil {
+		return nil, liberrors.Errorf("jwt.ParseWithClaims. err: %v, %w", err, service.ErrUnauthenticated)
+	}
+
+	if claims.TokenType != tokenType {
+		return nil, liberrors.Errorf("unexpected token type. type: %s, err: %w", claims.TokenType, service.ErrUnauthenticated)
+	}
+
+	organizationID, err := domain.NewOrganizationID(claims.OrganizationID)
+	if err != nil {
+		return nil, liberrors.Errorf("domain.NewOrganizationID. err: %w", err)
+	}
+
+	appUserID, err := domain.NewAppUserID(claims.AppUserID)
+	if err != nil {
+		return nil, liberrors.Errorf("domain.NewAppUserID. err: %w", err)
+	}
+
+	return &service.AppUserTokenInfo{
+		OrganizationID: organizationID,
+		AppUserID:      appUserID,
+	}, nil
+}
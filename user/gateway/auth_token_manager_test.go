@@ -0,0 +1,127 @@
+package gateway_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type testTokenAppUser struct {
+	appUserID      *domain.AppUserID
+	organizationID *domain.OrganizationID
+}
+
+func (m *testTokenAppUser) AppUserID() *domain.AppUserID {
+	return m.appUserID
+}
+func (m *testTokenAppUser) OrganizationID() *domain.OrganizationID {
+	return m.organizationID
+}
+func (m *testTokenAppUser) LoginID() string {
+	return "LOGIN_ID"
+}
+func (m *testTokenAppUser) Username() string {
+	return "USERNAME"
+}
+
+func newTestTokenAppUser(t *testing.T) service.AppUserInterface {
+	t.Helper()
+	appUserID, err := domain.NewAppUserID(3)
+	require.NoError(t, err)
+	organizationID, err := domain.NewOrganizationID(2)
+	require.NoError(t, err)
+	return &testTokenAppUser{appUserID: appUserID, organizationID: organizationID}
+}
+
+func Test_authTokenManager_CreateTokenSet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		signingMethod jwt.SigningMethod
+		signingKey    interface{}
+		verifyKey     interface{}
+	}{
+		{name: "HS256", signingMethod: jwt.SigningMethodHS256, signingKey: []byte("KEY"), verifyKey: []byte("KEY")},
+		{name: "EdDSA", signingMethod: jwt.SigningMethodEdDSA, signingKey: privateKey, verifyKey: publicKey},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m, err := gateway.NewAuthTokenManager(tt.signingMethod, tt.signingKey, tt.verifyKey, time.Minute, time.Hour)
+			require.NoError(t, err)
+
+			tokenSet, err := m.CreateTokenSet(ctx, newTestTokenAppUser(t))
+			require.NoError(t, err)
+
+			info, err := m.ParseAccessToken(ctx, tokenSet.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, 2, info.OrganizationID.Int())
+			assert.Equal(t, 3, info.AppUserID.Int())
+
+			info, err = m.ParseRefreshToken(ctx, tokenSet.RefreshToken)
+			require.NoError(t, err)
+			assert.Equal(t, 3, info.AppUserID.Int())
+
+			// a refresh token is not an access token and vice versa
+			_, err = m.ParseAccessToken(ctx, tokenSet.RefreshToken)
+			assert.ErrorIs(t, err, service.ErrUnauthenticated)
+			_, err = m.ParseRefreshToken(ctx, tokenSet.AccessToken)
+			assert.ErrorIs(t, err, service.ErrUnauthenticated)
+		})
+	}
+}
+
+func Test_authTokenManager_ParseAccessToken_invalid(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	m, err := gateway.NewAuthTokenManager(jwt.SigningMethodHS256, []byte("KEY"), []byte("KEY"), time.Minute, time.Hour)
+	require.NoError(t, err)
+	tokenSet, err := m.CreateTokenSet(ctx, newTestTokenAppUser(t))
+	require.NoError(t, err)
+
+	t.Run("expired", func(t *testing.T) {
+		t.Parallel()
+		expiredM, err := gateway.NewAuthTokenManager(jwt.SigningMethodHS256, []byte("KEY"), []byte("KEY"), time.Minute, time.Hour)
+		require.NoError(t, err)
+		gateway.SetAuthTokenManagerNow(expiredM, func() time.Time { return time.Now().Add(2 * time.Minute) })
+		_, err = expiredM.ParseAccessToken(ctx, tokenSet.AccessToken)
+		assert.ErrorIs(t, err, service.ErrUnauthenticated)
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		t.Parallel()
+		otherM, err := gateway.NewAuthTokenManager(jwt.SigningMethodHS256, []byte("OTHER_KEY"), []byte("OTHER_KEY"), time.Minute, time.Hour)
+		require.NoError(t, err)
+		_, err = otherM.ParseAccessToken(ctx, tokenSet.AccessToken)
+		assert.ErrorIs(t, err, service.ErrUnauthenticated)
+	})
+
+	t.Run("other signing method", func(t *testing.T) {
+		t.Parallel()
+		otherM, err := gateway.NewAuthTokenManager(jwt.SigningMethodHS512, []byte("KEY"), []byte("KEY"), time.Minute, time.Hour)
+		require.NoError(t, err)
+		_, err = otherM.ParseAccessToken(ctx, tokenSet.AccessToken)
+		assert.ErrorIs(t, err, service.ErrUnauthenticated)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		t.Parallel()
+		_, err := m.ParseAccessToken(ctx, "TOKEN")
+		assert.ErrorIs(t, err, service.ErrUnauthenticated)
+	})
+}
@@ -2,146 +2,657 @@ package gateway
 
 import (
 	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/casbin/casbin/v2/util"
+	"gorm.io/gorm"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
 	liberrors "github.com/kujilabo/redstart/lib/errors"
 	libgateway "github.com/kujilabo/redstart/lib/gateway"
 	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway/casbinquery"
 	"github.com/kujilabo/redstart/user/service"
-	"gorm.io/gorm"
 )
 
 type authorizationManager struct {
-	dialect libgateway.DialectRDBMS
-	db      *gorm.DB
-	rf      service.RepositoryFactory
+	dialect       libgateway.DialectRDBMS
+	db            *gorm.DB
+	rf            service.RepositoryFactory
+	enforcerCache *EnforcerCache
+	now           func() time.Time
 }
 
 func NewAuthorizationManager(ctx context.Context, dialect libgateway.DialectRDBMS, db *gorm.DB, rf service.RepositoryFactory) service.AuthorizationManager {
-	return &authorizationManager{
+	m := &authorizationManager{
 		dialect: dialect,
 		db:      db,
 		rf:      rf,
+		now:     time.Now,
 	}
+	if f, ok := rf.(*repositoryFactory); ok {
+		m.enforcerCache = f.enforcerCache
+	}
+
+	return m
 }
 
 func (m *authorizationManager) Init(ctx context.Context) error {
-	rbacRepo := newRBACRepository(ctx, m.db)
-	return rbacRepo.Init()
+	rbacRepo := m.newRBACRepository(ctx, m.db)
+	if err := rbacRepo.Init(); err != nil {
+		return err
+	}
+
+	if m.enforcerCache != nil {
+		m.enforcerCache.InvalidateAll()
+	}
+
+	return nil
+}
+
+func (m *authorizationManager) newRBACRepository(ctx context.Context, db *gorm.DB) service.RBACRepository {
+	return newRBACRepositoryOfFactory(ctx, db, m.rf)
+}
+
+// newRBACRepositoryOfFactory returns the repository which reads the rules from the cached enforcers and discards them when the rules are changed
+func newRBACRepositoryOfFactory(ctx context.Context, db *gorm.DB, rf service.RepositoryFactory) service.RBACRepository {
+	f, ok := rf.(*repositoryFactory)
+	if !ok {
+		return newRBACRepository(ctx, db)
+	}
+
+	return newRBACRepositoryWithListener(ctx, db, f.enforcerCache, f.policyChanged)
 }
 
 func (m *authorizationManager) AddUserToGroupBySystemAdmin(ctx context.Context, operator service.SystemAdminInterface, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
-	pairOfUserAndGroupRepo := NewPairOfUserAndGroupRepository(ctx, m.dialect, m.db, m.rf)
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		pairOfUserAndGroupRepo := NewPairOfUserAndGroupRepository(ctx, m.dialect, tx, m.rf)
+		if err := pairOfUserAndGroupRepo.AddPairOfUserAndGroupBySystemAdmin(ctx, operator, organizationID, appUserID, userGroupID); err != nil {
+			return err
+		}
+
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		rbacAppUser := service.NewRBACAppUser(organizationID, appUserID)
+		rbacUserRole := service.NewRBACUserRole(organizationID, userGroupID)
+		rbacDomain := service.NewRBACOrganization(organizationID)
+
+		// app-user belongs to user-role
+		if err := rbacRepo.AddSubjectGroupingPolicy(ctx, rbacDomain, rbacAppUser, rbacUserRole); err != nil {
+			return liberrors.Errorf("rbacRepo.AddNamedGroupingPolicy. err: %w", err)
+		}
+
+		return m.addUserMembershipAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionAddUserToGroup, appUserID, &membershipAuditValue{
+			UserGroupID: userGroupID.Int(),
+		})
+	})
+}
+func (m *authorizationManager) AddUserToGroup(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	organizationID := operator.OrganizationID()
 
-	if err := pairOfUserAndGroupRepo.AddPairOfUserAndGroupBySystemAdmin(ctx, operator, organizationID, appUserID, userGroupID); err != nil {
-		return err
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		pairOfUserAndGroupRepo := NewPairOfUserAndGroupRepository(ctx, m.dialect, tx, m.rf)
+		if err := pairOfUserAndGroupRepo.AddPairOfUserAndGroup(ctx, operator, appUserID, userGroupID); err != nil {
+			return err
+		}
+
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		rbacAppUser := service.NewRBACAppUser(organizationID, appUserID)
+		rbacUserRole := service.NewRBACUserRole(organizationID, userGroupID)
+		rbacDomain := service.NewRBACOrganization(organizationID)
+
+		// app-user belongs to user-role
+		if err := rbacRepo.AddSubjectGroupingPolicy(ctx, rbacDomain, rbacAppUser, rbacUserRole); err != nil {
+			return liberrors.Errorf("rbacRepo.AddNamedGroupingPolicy. err: %w", err)
+		}
+
+		return m.addUserMembershipAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionAddUserToGroup, appUserID, &membershipAuditValue{
+			UserGroupID: userGroupID.Int(),
+		})
+	})
+}
+
+// AddUserToGroupWithValidity adds the app user to the user group only during the validity
+func (m *authorizationManager) AddUserToGroupWithValidity(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID, validity *domain.RBACValidity) error {
+	organizationID := operator.OrganizationID()
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		pairOfUserAndGroupRepo := NewPairOfUserAndGroupRepository(ctx, m.dialect, tx, m.rf)
+		if err := pairOfUserAndGroupRepo.AddPairOfUserAndGroup(ctx, operator, appUserID, userGroupID); err != nil {
+			return err
+		}
+		if err := pairOfUserAndGroupRepo.SetPairOfUserAndGroupValidity(ctx, operator, appUserID, userGroupID, validity); err != nil {
+			return err
+		}
+
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		rbacAppUser := service.NewRBACAppUser(organizationID, appUserID)
+		rbacUserRole := service.NewRBACUserRole(organizationID, userGroupID)
+		rbacDomain := service.NewRBACOrganization(organizationID)
+
+		if err := rbacRepo.AddSubjectGroupingPolicy(ctx, rbacDomain, rbacAppUser, rbacUserRole); err != nil {
+			return liberrors.Errorf("rbacRepo.AddSubjectGroupingPolicy. err: %w", err)
+		}
+		if err := rbacRepo.SetSubjectGroupingPolicyValidity(ctx, rbacDomain, rbacAppUser, rbacUserRole, validity); err != nil {
+			return liberrors.Errorf("rbacRepo.SetSubjectGroupingPolicyValidity. err: %w", err)
+		}
+
+		value := &membershipAuditValue{
+			UserGroupID: userGroupID.Int(),
+		}
+		if validity != nil {
+			value.ValidFrom = validity.ValidFrom
+			value.ValidUntil = validity.ValidUntil
+		}
+		return m.addUserMembershipAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionAddUserToGroup, appUserID, value)
+	})
+}
+
+func (m *authorizationManager) RemoveUserFromGroup(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	organizationID := operator.OrganizationID()
+
+	rbacUserRoleObject := service.NewRBACUserRoleObject(organizationID, userGroupID)
+	ok, err := m.Authorize(ctx, operator, service.RBACUnsetAction, rbacUserRoleObject)
+	if err != nil {
+		return liberrors.Errorf("m.Authorize. err: %w", err)
 	}
+	if !ok {
+		return liberrors.Errorf("operator cannot unset user group. user group ID: %d, err: %w", userGroupID.Int(), libdomain.ErrPermissionDenied)
+	}
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		pairOfUserAndGroupRepo := NewPairOfUserAndGroupRepository(ctx, m.dialect, tx, m.rf)
+		if err := pairOfUserAndGroupRepo.RemovePairOfUserAndGroup(ctx, operator, appUserID, userGroupID); err != nil {
+			return err
+		}
+
+		if err := m.removeSubjectGroupingPolicy(ctx, tx, organizationID, appUserID, userGroupID); err != nil {
+			return err
+		}
 
-	rbacRepo := newRBACRepository(ctx, m.db)
+		return m.addUserMembershipAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionRemoveUserFromGroup, appUserID, &membershipAuditValue{
+			UserGroupID: userGroupID.Int(),
+		})
+	})
+}
+
+func (m *authorizationManager) RemoveUserFromGroupBySystemAdmin(ctx context.Context, operator service.SystemAdminInterface, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		pairOfUserAndGroupRepo := NewPairOfUserAndGroupRepository(ctx, m.dialect, tx, m.rf)
+		if err := pairOfUserAndGroupRepo.RemovePairOfUserAndGroupBySystemAdmin(ctx, operator, organizationID, appUserID, userGroupID); err != nil {
+			return err
+		}
+
+		if err := m.removeSubjectGroupingPolicy(ctx, tx, organizationID, appUserID, userGroupID); err != nil {
+			return err
+		}
+
+		return m.addUserMembershipAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionRemoveUserFromGroup, appUserID, &membershipAuditValue{
+			UserGroupID: userGroupID.Int(),
+		})
+	})
+}
+
+func (m *authorizationManager) removeSubjectGroupingPolicy(ctx context.Context, tx *gorm.DB, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	rbacRepo := m.newRBACRepository(ctx, tx)
 	rbacAppUser := service.NewRBACAppUser(organizationID, appUserID)
 	rbacUserRole := service.NewRBACUserRole(organizationID, userGroupID)
 	rbacDomain := service.NewRBACOrganization(organizationID)
 
-	// app-user belongs to user-role
-	if err := rbacRepo.AddSubjectGroupingPolicy(ctx, rbacDomain, rbacAppUser, rbacUserRole); err != nil {
-		return liberrors.Errorf("rbacRepo.AddNamedGroupingPolicy. err: %w", err)
+	if err := rbacRepo.RemoveSubjectGroupingPolicy(ctx, rbacDomain, rbacAppUser, rbacUserRole); err != nil {
+		return liberrors.Errorf("rbacRepo.RemoveSubjectGroupingPolicy. err: %w", err)
 	}
 
 	return nil
 }
-func (m *authorizationManager) AddUserToGroup(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
-	pairOfUserAndGroupRepo := NewPairOfUserAndGroupRepository(ctx, m.dialect, m.db, m.rf)
 
-	if err := pairOfUserAndGroupRepo.AddPairOfUserAndGroup(ctx, operator, appUserID, userGroupID); err != nil {
-		return err
+func (m *authorizationManager) AddGroupToGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error {
+	organizationID := operator.OrganizationID()
+
+	// the child user group gets all the permissions of the parent user group
+	rbacParentRoleObject := service.NewRBACUserRoleObject(organizationID, parentUserGroupID)
+	ok, err := m.Authorize(ctx, operator, service.RBACSetAction, rbacParentRoleObject)
+	if err != nil {
+		return liberrors.Errorf("m.Authorize. err: %w", err)
+	}
+	if !ok {
+		return liberrors.Errorf("operator cannot set user group. user group ID: %d, err: %w", parentUserGroupID.Int(), libdomain.ErrPermissionDenied)
 	}
 
-	organizationID := operator.OrganizationID()
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		pairOfGroupAndGroupRepo := NewPairOfGroupAndGroupRepository(ctx, m.dialect, tx)
+		if err := pairOfGroupAndGroupRepo.AddPairOfGroupAndGroup(ctx, operator, childUserGroupID, parentUserGroupID); err != nil {
+			return err
+		}
+
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		rbacChildRole := service.NewRBACUserRole(organizationID, childUserGroupID)
+		rbacParentRole := service.NewRBACUserRole(organizationID, parentUserGroupID)
+		rbacDomain := service.NewRBACOrganization(organizationID)
+
+		// child-role inherits parent-role
+		if err := rbacRepo.AddSubjectGroupingPolicy(ctx, rbacDomain, rbacChildRole, rbacParentRole); err != nil {
+			return liberrors.Errorf("rbacRepo.AddSubjectGroupingPolicy. err: %w", err)
+		}
+
+		return m.addAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionAddGroupToGroup, domain.AuditTargetTypeUserGroup, strconv.Itoa(childUserGroupID.Int()), nil, &membershipAuditValue{
+			ParentUserGroupID: parentUserGroupID.Int(),
+		})
+	})
+}
 
-	rbacRepo := newRBACRepository(ctx, m.db)
-	rbacAppUser := service.NewRBACAppUser(organizationID, appUserID)
-	rbacUserRole := service.NewRBACUserRole(organizationID, userGroupID)
-	rbacDomain := service.NewRBACOrganization(organizationID)
+func (m *authorizationManager) RemoveGroupFromGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error {
+	organizationID := operator.OrganizationID()
 
-	// app-user belongs to user-role
-	if err := rbacRepo.AddSubjectGroupingPolicy(ctx, rbacDomain, rbacAppUser, rbacUserRole); err != nil {
-		return liberrors.Errorf("rbacRepo.AddNamedGroupingPolicy. err: %w", err)
+	rbacParentRoleObject := service.NewRBACUserRoleObject(organizationID, parentUserGroupID)
+	ok, err := m.Authorize(ctx, operator, service.RBACUnsetAction, rbacParentRoleObject)
+	if err != nil {
+		return liberrors.Errorf("m.Authorize. err: %w", err)
+	}
+	if !ok {
+		return liberrors.Errorf("operator cannot unset user group. user group ID: %d, err: %w", parentUserGroupID.Int(), libdomain.ErrPermissionDenied)
 	}
 
-	return nil
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		pairOfGroupAndGroupRepo := NewPairOfGroupAndGroupRepository(ctx, m.dialect, tx)
+		if err := pairOfGroupAndGroupRepo.RemovePairOfGroupAndGroup(ctx, operator, childUserGroupID, parentUserGroupID); err != nil {
+			return err
+		}
+
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		rbacChildRole := service.NewRBACUserRole(organizationID, childUserGroupID)
+		rbacParentRole := service.NewRBACUserRole(organizationID, parentUserGroupID)
+		rbacDomain := service.NewRBACOrganization(organizationID)
+
+		if err := rbacRepo.RemoveSubjectGroupingPolicy(ctx, rbacDomain, rbacChildRole, rbacParentRole); err != nil {
+			return liberrors.Errorf("rbacRepo.RemoveSubjectGroupingPolicy. err: %w", err)
+		}
+
+		return m.addAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionRemoveGroupFromGroup, domain.AuditTargetTypeUserGroup, strconv.Itoa(childUserGroupID.Int()), &membershipAuditValue{
+			ParentUserGroupID: parentUserGroupID.Int(),
+		}, nil)
+	})
 }
 
 func (m *authorizationManager) AddPolicyToUser(ctx context.Context, operator service.AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
-	rbacRepo := newRBACRepository(ctx, m.db)
-	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+	organizationID := operator.OrganizationID()
+	rbacDomain := service.NewRBACOrganization(organizationID)
 
-	if err := rbacRepo.AddPolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
-		return liberrors.Errorf("Failed to AddNamedPolicy. priv: read, err: %w", err)
-	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		if err := rbacRepo.AddPolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
+			return liberrors.Errorf("rbacRepo.AddPolicy. err: %w", err)
+		}
 
-	return nil
+		return m.addPolicyAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionAddPolicy, subject, newPolicyAuditValue(action, object, effect, nil, nil))
+	})
 }
 
 func (m *authorizationManager) AddPolicyToUserBySystemAdmin(ctx context.Context, operator service.SystemAdminInterface, organizationID *domain.OrganizationID, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
-	rbacRepo := newRBACRepository(ctx, m.db)
 	rbacDomain := service.NewRBACOrganization(organizationID)
 
-	if err := rbacRepo.AddPolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
-		return liberrors.Errorf("Failed to AddNamedPolicy. priv: read, err: %w", err)
-	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		if err := rbacRepo.AddPolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
+			return liberrors.Errorf("rbacRepo.AddPolicy. err: %w", err)
+		}
 
-	return nil
+		return m.addPolicyAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionAddPolicy, subject, newPolicyAuditValue(action, object, effect, nil, nil))
+	})
 }
 
-func (m *authorizationManager) AddPolicyToGroup(ctx context.Context, operator service.AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
-	rbacRepo := newRBACRepository(ctx, m.db)
+func (m *authorizationManager) AddConditionalPolicy(ctx context.Context, operator service.AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect, condition domain.RBACCondition) error {
+	organizationID := operator.OrganizationID()
+	rbacDomain := service.NewRBACOrganization(organizationID)
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		if err := rbacRepo.AddConditionalPolicy(ctx, rbacDomain, subject, action, object, effect, condition); err != nil {
+			return liberrors.Errorf("rbacRepo.AddConditionalPolicy. err: %w", err)
+		}
+
+		return m.addPolicyAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionAddPolicy, subject, newPolicyAuditValue(action, object, effect, condition, nil))
+	})
+}
+
+func (m *authorizationManager) AddTimeBoundPolicy(ctx context.Context, operator service.AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect, validity *domain.RBACValidity) error {
+	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		if err := rbacRepo.AddPolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
+			return liberrors.Errorf("rbacRepo.AddPolicy. err: %w", err)
+		}
+		if err := rbacRepo.SetPolicyValidity(ctx, rbacDomain, subject, action, object, effect, validity); err != nil {
+			return liberrors.Errorf("rbacRepo.SetPolicyValidity. err: %w", err)
+		}
+
+		return m.addPolicyAuditEvent(ctx, tx, operator, operator.OrganizationID(), domain.AuditActionAddPolicy, subject, newPolicyAuditValue(action, object, effect, nil, validity))
+	})
+}
+
+func (m *authorizationManager) ListExpiredGrants(ctx context.Context, operator service.AppUserInterface, since time.Time, limit int) ([]*domain.RBACExpiredGrant, error) {
 	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
 
-	if err := rbacRepo.AddPolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
-		return liberrors.Errorf("Failed to AddNamedPolicy. priv: read, err: %w", err)
+	grants, err := findExpiredGrants(ctx, m.db, rbacDomain, since, limit)
+	if err != nil {
+		return nil, liberrors.Errorf("findExpiredGrants. err: %w", err)
 	}
 
-	return nil
+	return grants, nil
+}
+
+func (m *authorizationManager) AddPolicyToGroup(ctx context.Context, operator service.AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	organizationID := operator.OrganizationID()
+	rbacDomain := service.NewRBACOrganization(organizationID)
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		if err := rbacRepo.AddPolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
+			return liberrors.Errorf("rbacRepo.AddPolicy. err: %w", err)
+		}
+
+		return m.addPolicyAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionAddPolicy, subject, newPolicyAuditValue(action, object, effect, nil, nil))
+	})
 }
 
 func (m *authorizationManager) AddPolicyToGroupBySystemAdmin(ctx context.Context, operator service.SystemAdminInterface, organizationID *domain.OrganizationID, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
-	rbacRepo := newRBACRepository(ctx, m.db)
 	rbacDomain := service.NewRBACOrganization(organizationID)
 
-	if err := rbacRepo.AddPolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
-		return liberrors.Errorf("Failed to AddNamedPolicy. priv: read, err: %w", err)
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		if err := rbacRepo.AddPolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
+			return liberrors.Errorf("rbacRepo.AddPolicy. err: %w", err)
+		}
+
+		return m.addPolicyAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionAddPolicy, subject, newPolicyAuditValue(action, object, effect, nil, nil))
+	})
+}
+
+func (m *authorizationManager) ListPoliciesForSubject(ctx context.Context, operator service.AppUserInterface, subject domain.RBACSubject) ([]*domain.RBACPolicy, error) {
+	rbacRepo := m.newRBACRepository(ctx, m.db)
+	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+
+	policies, err := rbacRepo.FindPoliciesBySubject(ctx, rbacDomain, subject)
+	if err != nil {
+		return nil, liberrors.Errorf("rbacRepo.FindPoliciesBySubject. err: %w", err)
 	}
 
-	return nil
+	return policies, nil
 }
 
-func (m *authorizationManager) Authorize(ctx context.Context, operator service.AppUserInterface, rbacAction domain.RBACAction, rbacObject domain.RBACObject) (bool, error) {
+func (m *authorizationManager) ListPoliciesForObject(ctx context.Context, operator service.AppUserInterface, object domain.RBACObject) ([]*domain.RBACPolicy, error) {
+	rbacRepo := m.newRBACRepository(ctx, m.db)
 	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
 
+	policies, err := rbacRepo.FindPoliciesByObject(ctx, rbacDomain, object)
+	if err != nil {
+		return nil, liberrors.Errorf("rbacRepo.FindPoliciesByObject. err: %w", err)
+	}
+
+	return policies, nil
+}
+
+func (m *authorizationManager) RemovePolicyFromUser(ctx context.Context, operator service.AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	// the policies of the app users can be removed by the operator who can unset all the user roles
+	rbacAllUserRolesObject := service.NewRBACAllUserRolesObject(operator.OrganizationID())
+	return m.removePolicy(ctx, operator, rbacAllUserRolesObject, subject, action, object, effect)
+}
+
+func (m *authorizationManager) RemovePolicyFromGroup(ctx context.Context, operator service.AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	// the subject of the user group is the same as the object of the user role
+	rbacUserRoleObject := domain.NewRBACObject(subject.Subject())
+	return m.removePolicy(ctx, operator, rbacUserRoleObject, subject, action, object, effect)
+}
+
+// removePolicy removes the policy of the subject if the operator can unset the subject object
+func (m *authorizationManager) removePolicy(ctx context.Context, operator service.AppUserInterface, subjectObject domain.RBACObject, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	organizationID := operator.OrganizationID()
+
+	ok, err := m.Authorize(ctx, operator, service.RBACUnsetAction, subjectObject)
+	if err != nil {
+		return liberrors.Errorf("m.Authorize. err: %w", err)
+	}
+	if !ok {
+		return liberrors.Errorf("operator cannot remove the policy. subject: %s, err: %w", subject.Subject(), libdomain.ErrPermissionDenied)
+	}
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		rbacRepo := m.newRBACRepository(ctx, tx)
+		rbacDomain := service.NewRBACOrganization(organizationID)
+
+		if err := rbacRepo.RemovePolicy(ctx, rbacDomain, subject, action, object, effect); err != nil {
+			return liberrors.Errorf("rbacRepo.RemovePolicy. err: %w", err)
+		}
+
+		return m.addPolicyAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionRemovePolicy, subject, newPolicyAuditValue(action, object, effect, nil, nil))
+	})
+}
+
+// rbacEnforcer is implemented by both the cached enforcer and the enforcer loaded for an operator
+type rbacEnforcer interface {
+	Enforce(rvals ...interface{}) (bool, error)
+	EnforceEx(rvals ...interface{}) (bool, []string, error)
+	GetFilteredNamedGroupingPolicy(ptype string, fieldIndex int, fieldValues ...string) [][]string
+	GetImplicitRolesForUser(name string, domain ...string) ([]string, error)
+}
+
+// enforcer returns the enforcer which has the groups and the policies of the operator
+func (m *authorizationManager) enforcer(ctx context.Context, operator service.AppUserInterface) (rbacEnforcer, error) {
+	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+
+	if m.enforcerCache != nil {
+		e, err := m.enforcerCache.Enforcer(ctx, m.db, rbacDomain)
+		if err != nil {
+			return nil, liberrors.Errorf("m.enforcerCache.Enforcer. err: %w", err)
+		}
+
+		return e, nil
+	}
+
 	userGroupRepo := m.rf.NewUserGroupRepository(ctx)
 	userGroups, err := userGroupRepo.FindAllUserGroups(ctx, operator)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
+	// rules of all the groups are loaded so that permissions are inherited through nested groups
 	rbacRoles := make([]domain.RBACRole, 0)
 	for _, userGroup := range userGroups {
 		rbacRoles = append(rbacRoles, service.NewRBACUserRole(operator.OrganizationID(), userGroup.UserGroupID))
 	}
 
-	rbacRepo := newRBACRepository(ctx, m.db)
+	rbacRepo := m.newRBACRepository(ctx, m.db)
 	rbacOperator := service.NewRBACAppUser(operator.OrganizationID(), operator.AppUserID())
-	e, err := rbacRepo.NewEnforcerWithGroupsAndUsers(ctx, rbacRoles, []domain.RBACUser{rbacOperator})
+	e, err := rbacRepo.NewEnforcerWithGroupsAndUsers(ctx, rbacDomain, rbacRoles, []domain.RBACUser{rbacOperator})
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (m *authorizationManager) Authorize(ctx context.Context, operator service.AppUserInterface, rbacAction domain.RBACAction, rbacObject domain.RBACObject) (bool, error) {
+	results, err := m.AuthorizeBatch(ctx, operator, []service.AuthRequest{{Action: rbacAction, Object: rbacObject}})
 	if err != nil {
 		return false, err
 	}
 
-	ok, err := e.Enforce(rbacOperator.Subject(), rbacObject.Object(), rbacAction.Action(), rbacDomain.Domain())
+	return results[0], nil
+}
+
+func (m *authorizationManager) AuthorizeWithAttributes(ctx context.Context, operator service.AppUserInterface, rbacAction domain.RBACAction, rbacObject domain.RBACObject, attributes domain.RBACAttributes) (bool, error) {
+	results, err := m.AuthorizeBatch(ctx, operator, []service.AuthRequest{{Action: rbacAction, Object: rbacObject, Attributes: attributes}})
 	if err != nil {
 		return false, err
 	}
-	if ok {
-		return true, nil
+
+	return results[0], nil
+}
+
+func (m *authorizationManager) AuthorizeBatch(ctx context.Context, operator service.AppUserInterface, requests []service.AuthRequest) ([]bool, error) {
+	if len(requests) == 0 {
+		return []bool{}, nil
+	}
+
+	e, err := m.enforcer(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	return enforceAll(e, operator, requests, m.now())
+}
+
+func enforceAll(e rbacEnforcer, operator service.AppUserInterface, requests []service.AuthRequest, now time.Time) ([]bool, error) {
+	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+	rbacOperator := service.NewRBACAppUser(operator.OrganizationID(), operator.AppUserID())
+	results := make([]bool, len(requests))
+	for i, req := range requests {
+		attributes := rbacRequestAttributes(operator, req.Attributes, now)
+		ok, err := e.Enforce(rbacOperator.Subject(), req.Object.Object(), req.Action.Action(), rbacDomain.Domain(), attributes)
+		if err != nil {
+			return nil, liberrors.Errorf("e.Enforce. err: %w", err)
+		}
+		results[i] = ok
+	}
+
+	return results, nil
+}
+
+func (m *authorizationManager) FilterAuthorizedObjects(ctx context.Context, operator service.AppUserInterface, rbacAction domain.RBACAction, rbacObjects []domain.RBACObject) ([]domain.RBACObject, error) {
+	requests := make([]service.AuthRequest, len(rbacObjects))
+	for i, rbacObject := range rbacObjects {
+		requests[i] = service.AuthRequest{Action: rbacAction, Object: rbacObject}
+	}
+
+	results, err := m.AuthorizeBatch(ctx, operator, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterObjects(rbacObjects, results), nil
+}
+
+func filterObjects(rbacObjects []domain.RBACObject, results []bool) []domain.RBACObject {
+	authorized := make([]domain.RBACObject, 0)
+	for i, ok := range results {
+		if ok {
+			authorized = append(authorized, rbacObjects[i])
+		}
+	}
+
+	return authorized
+}
+
+func (m *authorizationManager) ListAccessibleObjects(ctx context.Context, operator service.AppUserInterface, objectPrefix string, rbacAction domain.RBACAction) ([]domain.RBACObject, error) {
+	e, err := m.enforcer(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+	rbacOperator := service.NewRBACAppUser(operator.OrganizationID(), operator.AppUserID())
+	roles, err := e.GetImplicitRolesForUser(rbacOperator.Subject(), rbacDomain.Domain())
+	if err != nil {
+		return nil, liberrors.Errorf("e.GetImplicitRolesForUser. err: %w", err)
+	}
+
+	// candidates are the objects allowed to the operator or its groups
+	subjects := append([]string{rbacOperator.Subject()}, roles...)
+	db, err := casbinquery.QueryAllowedObjects(m.db, objectPrefix, "object_name", subjects, rbacAction.Action(), service.RBACAllowEffect.Effect(), rbacDomain.Domain())
+	if err != nil {
+		return nil, liberrors.Errorf("casbinquery.QueryAllowedObjects. err: %w", err)
+	}
+
+	var objectNames []string
+	if err := db.Pluck("object_name", &objectNames).Error; err != nil {
+		return nil, liberrors.Errorf("db.Pluck. err: %w", err)
+	}
+
+	// the descendants of the candidates inherit the policies through "g2" rules
+	objectHierarchy := newObjectGraph(e.GetFilteredNamedGroupingPolicy("g2", 2, rbacDomain.Domain()))
+	objectNames = append(objectNames, objectHierarchy.descendants(objectNames...)...)
+	objectNames = uniqueStrings(objectNames)
+	sort.Strings(objectNames)
+
+	// candidates denied by other policies are excluded
+	candidates := make([]domain.RBACObject, 0, len(objectNames))
+	requests := make([]service.AuthRequest, 0, len(objectNames))
+	for _, objectName := range objectNames {
+		if strings.HasPrefix(objectName, objectPrefix) {
+			candidates = append(candidates, domain.NewRBACObject(objectName))
+			requests = append(requests, service.AuthRequest{Action: rbacAction, Object: domain.NewRBACObject(objectName)})
+		}
+	}
+
+	results, err := enforceAll(e, operator, requests, m.now())
+	if err != nil {
+		return nil, err
+	}
+
+	return filterObjects(candidates, results), nil
+}
+
+func (m *authorizationManager) Explain(ctx context.Context, operator service.AppUserInterface, rbacAction domain.RBACAction, rbacObject domain.RBACObject) (*domain.RBACExplanation, error) {
+	e, err := m.enforcer(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+	rbacOperator := service.NewRBACAppUser(operator.OrganizationID(), operator.AppUserID())
+	attributes := rbacRequestAttributes(operator, nil, m.now())
+	allowed, rule, err := e.EnforceEx(rbacOperator.Subject(), rbacObject.Object(), rbacAction.Action(), rbacDomain.Domain(), attributes)
+	if err != nil {
+		return nil, liberrors.Errorf("e.EnforceEx. err: %w", err)
+	}
+
+	explanation := domain.RBACExplanation{Allowed: allowed}
+	// rule is "p" rule (sub, obj, act, eft, dom, cond), or empty when no rule matched
+	if len(rule) < 5 {
+		return &explanation, nil
+	}
+
+	explanation.Policy = toRBACPolicies([][]string{rule})[0]
+	explanation.SubjectChain = findGroupingChain(e.GetFilteredNamedGroupingPolicy("g", 2, rbacDomain.Domain()), "g", rbacOperator.Subject(), rule[0], rbacDomain)
+	// the object matches either by keyMatch or through "g2" rules
+	if !util.KeyMatch(rbacObject.Object(), rule[1]) {
+		explanation.ObjectChain = findGroupingChain(e.GetFilteredNamedGroupingPolicy("g2", 2, rbacDomain.Domain()), "g2", rbacObject.Object(), rule[1], rbacDomain)
+	}
+
+	return &explanation, nil
+}
+
+// findGroupingChain returns the shortest chain of the grouping rules from child to parent
+func findGroupingChain(rules [][]string, ptype, child, parent string, rbacDomain domain.RBACDomain) []*domain.RBACGroupingRule {
+	chain := make([]*domain.RBACGroupingRule, 0)
+	if child == parent {
+		return chain
+	}
+
+	// prev has the rule by which each value is reached
+	prev := map[string][]string{child: nil}
+	queue := []string{child}
+	for len(queue) > 0 && prev[parent] == nil {
+		value := queue[0]
+		queue = queue[1:]
+		for _, rule := range rules {
+			if len(rule) < 2 || rule[0] != value {
+				continue
+			}
+			if _, ok := prev[rule[1]]; ok {
+				continue
+			}
+			prev[rule[1]] = rule
+			queue = append(queue, rule[1])
+		}
+	}
+
+	for value := parent; prev[value] != nil; value = prev[value][0] {
+		chain = append([]*domain.RBACGroupingRule{domain.NewRBACGroupingRule(ptype, prev[value][0], prev[value][1], rbacDomain)}, chain...)
 	}
 
-	return false, nil
+	return chain
 }
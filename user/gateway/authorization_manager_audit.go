@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type membershipAuditValue struct {
+	UserGroupID       int        `json:"userGroupId,omitempty"`
+	ParentUserGroupID int        `json:"parentUserGroupId,omitempty"`
+	ValidFrom         *time.Time `json:"validFrom,omitempty"`
+	ValidUntil        *time.Time `json:"validUntil,omitempty"`
+}
+
+type policyAuditValue struct {
+	Action     string     `json:"action"`
+	Object     string     `json:"object"`
+	Effect     string     `json:"effect"`
+	Condition  string     `json:"condition,omitempty"`
+	ValidFrom  *time.Time `json:"validFrom,omitempty"`
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
+}
+
+type ruleSetDiffAuditValue struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+func newPolicyAuditValue(action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect, condition domain.RBACCondition, validity *domain.RBACValidity) *policyAuditValue {
+	v := &policyAuditValue{
+		Action: action.Action(),
+		Object: object.Object(),
+		Effect: effect.Effect(),
+	}
+	if condition != nil {
+		v.Condition = condition.Expression()
+	}
+	if validity != nil {
+		v.ValidFrom = validity.ValidFrom
+		v.ValidUntil = validity.ValidUntil
+	}
+
+	return v
+}
+
+// addAuditEvent records the change of the memberships or the policies with db, which is the transaction of the change
+func (m *authorizationManager) addAuditEvent(ctx context.Context, db *gorm.DB, operator service.OperatorInterface, organizationID *domain.OrganizationID, action domain.AuditAction, targetType domain.AuditTargetType, targetID string, before, after interface{}) error {
+	target, err := domain.NewAuditTarget(targetType, targetID)
+	if err != nil {
+		return liberrors.Errorf("domain.NewAuditTarget. err: %w", err)
+	}
+
+	param, err := service.NewAuditEventAddParameter(organizationID, action, target, before, after)
+	if err != nil {
+		return liberrors.Errorf("service.NewAuditEventAddParameter. err: %w", err)
+	}
+
+	auditLogRepo := NewAuditLogRepository(ctx, db)
+	if err := auditLogRepo.AddAuditEvent(ctx, operator, param); err != nil {
+		return liberrors.Errorf("auditLogRepo.AddAuditEvent. err: %w", err)
+	}
+
+	return nil
+}
+
+func (m *authorizationManager) addUserMembershipAuditEvent(ctx context.Context, db *gorm.DB, operator service.OperatorInterface, organizationID *domain.OrganizationID, action domain.AuditAction, appUserID *domain.AppUserID, value *membershipAuditValue) error {
+	var before, after interface{}
+	if action == domain.AuditActionRemoveUserFromGroup {
+		before = value
+	} else {
+		after = value
+	}
+
+	return m.addAuditEvent(ctx, db, operator, organizationID, action, domain.AuditTargetTypeAppUser, strconv.Itoa(appUserID.Int()), before, after)
+}
+
+func (m *authorizationManager) addPolicyAuditEvent(ctx context.Context, db *gorm.DB, operator service.OperatorInterface, organizationID *domain.OrganizationID, action domain.AuditAction, subject domain.RBACSubject, value *policyAuditValue) error {
+	var before, after interface{}
+	if action == domain.AuditActionRemovePolicy {
+		before = value
+	} else {
+		after = value
+	}
+
+	return m.addAuditEvent(ctx, db, operator, organizationID, action, domain.AuditTargetTypeRBACSubject, subject.Subject(), before, after)
+}
@@ -0,0 +1,440 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+var (
+	// "user:%d"
+	rbacAppUserPattern = regexp.MustCompile(`^user:(\d+)$`)
+	// "domain:%d_role:%d" or "domain:%d_role:*"
+	rbacUserRolePattern = regexp.MustCompile(`^domain:(\d+)_role:(\d+|\*)$`)
+)
+
+func (m *authorizationManager) ExportPoliciesBySystemAdmin(ctx context.Context, operator service.SystemAdminInterface, organizationID *domain.OrganizationID) (*domain.RBACRuleSet, error) {
+	return m.findRuleSet(ctx, m.db, service.NewRBACOrganization(organizationID))
+}
+
+func (m *authorizationManager) findRuleSet(ctx context.Context, db *gorm.DB, rbacDomain domain.RBACDomain) (*domain.RBACRuleSet, error) {
+	rbacRepo := m.newRBACRepository(ctx, db)
+
+	policies, err := rbacRepo.FindPolicies(ctx, rbacDomain)
+	if err != nil {
+		return nil, liberrors.Errorf("rbacRepo.FindPolicies. err: %w", err)
+	}
+
+	groupingRules, err := rbacRepo.FindGroupingRules(ctx, rbacDomain)
+	if err != nil {
+		return nil, liberrors.Errorf("rbacRepo.FindGroupingRules. err: %w", err)
+	}
+
+	ruleSet := domain.RBACRuleSet{Policies: policies, GroupingRules: groupingRules}
+	ruleSet.Sort()
+
+	return &ruleSet, nil
+}
+
+func (m *authorizationManager) ImportPoliciesBySystemAdmin(ctx context.Context, operator service.SystemAdminInterface, organizationID *domain.OrganizationID, ruleSet *domain.RBACRuleSet, mode service.PolicyImportMode, dryRun bool) (*domain.RBACRuleSetDiff, error) {
+	if mode != service.PolicyImportModeMerge && mode != service.PolicyImportModeReplace {
+		return nil, liberrors.Errorf("unsupported mode. mode: %s, err: %w", mode, libdomain.ErrInvalidArgument)
+	}
+
+	var diff *domain.RBACRuleSetDiff
+	if err := m.db.Transaction(func(tx *gorm.DB) error {
+		appUserRepo := NewAppUserRepository(ctx, m.dialect, tx, m.rf)
+		sysOwner, err := appUserRepo.FindSystemOwnerByOrganizationID(ctx, operator, organizationID)
+		if err != nil {
+			return liberrors.Errorf("appUserRepo.FindSystemOwnerByOrganizationID. err: %w", err)
+		}
+
+		validator := newRBACReferenceValidator(ctx, m.dialect, tx, m.rf, sysOwner)
+		imported, err := validator.normalize(ruleSet)
+		if err != nil {
+			return err
+		}
+
+		current, err := m.findRuleSet(ctx, tx, service.NewRBACOrganization(organizationID))
+		if err != nil {
+			return err
+		}
+
+		diff = &domain.RBACRuleSetDiff{
+			Added:   imported.Diff(current),
+			Removed: &domain.RBACRuleSet{Policies: make([]*domain.RBACPolicy, 0), GroupingRules: make([]*domain.RBACGroupingRule, 0)},
+		}
+		if mode == service.PolicyImportModeReplace {
+			diff.Removed = current.Diff(imported)
+		}
+
+		// the "g2" rules are checked as AddChildObject does
+		if err := newObjectGraph(mergeObjectLinks(current, diff)).validate(); err != nil {
+			return err
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		if err := m.applyRuleSetDiff(ctx, tx, operator, sysOwner, diff); err != nil {
+			return err
+		}
+
+		return m.addAuditEvent(ctx, tx, operator, organizationID, domain.AuditActionImportPolicies, domain.AuditTargetTypeOrganization, strconv.Itoa(organizationID.Int()), nil, &ruleSetDiffAuditValue{
+			Added:   diff.Added.Lines(),
+			Removed: diff.Removed.Lines(),
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// applyRuleSetDiff changes the rules and the pairs of user_n_group and group_n_group together
+func (m *authorizationManager) applyRuleSetDiff(ctx context.Context, tx *gorm.DB, operator service.SystemAdminInterface, sysOwner *service.SystemOwner, diff *domain.RBACRuleSetDiff) error {
+	organizationID := sysOwner.OrganizationID()
+	rbacDomain := service.NewRBACOrganization(organizationID)
+	rbacRepo := m.newRBACRepository(ctx, tx)
+	pairOfUserAndGroupRepo := NewPairOfUserAndGroupRepository(ctx, m.dialect, tx, m.rf)
+	pairOfGroupAndGroupRepo := NewPairOfGroupAndGroupRepository(ctx, m.dialect, tx)
+
+	for _, r := range diff.Removed.GroupingRules {
+		if r.PType == "g2" {
+			if err := rbacRepo.RemoveObjectGroupingPolicy(ctx, rbacDomain, domain.NewRBACObject(r.Child), domain.NewRBACObject(r.Parent)); err != nil {
+				return liberrors.Errorf("rbacRepo.RemoveObjectGroupingPolicy. err: %w", err)
+			}
+			continue
+		}
+
+		// the existing rules may not have been written with the pairs
+		_, parentUserGroupID, parentOK := parseRBACUserRole(r.Parent)
+		_, childUserGroupID, childOK := parseRBACUserRole(r.Child)
+		if appUserID, ok := parseRBACAppUser(r.Child); ok && parentUserGroupID != nil {
+			if err := pairOfUserAndGroupRepo.RemovePairOfUserAndGroupBySystemAdmin(ctx, operator, organizationID, appUserID, parentUserGroupID); err != nil && !errors.Is(err, service.ErrPairOfUserAndGroupNotFound) {
+				return err
+			}
+		} else if parentOK && parentUserGroupID != nil && childOK && childUserGroupID != nil {
+			if err := pairOfGroupAndGroupRepo.RemovePairOfGroupAndGroup(ctx, sysOwner, childUserGroupID, parentUserGroupID); err != nil && !errors.Is(err, service.ErrPairOfGroupAndGroupNotFound) {
+				return err
+			}
+		}
+		if err := rbacRepo.RemoveSubjectGroupingPolicy(ctx, rbacDomain, domain.NewRBACUser(r.Child), domain.NewRBACRole(r.Parent)); err != nil {
+			return liberrors.Errorf("rbacRepo.RemoveSubjectGroupingPolicy. err: %w", err)
+		}
+	}
+
+	for _, p := range diff.Removed.Policies {
+		if err := rbacRepo.RemovePolicy(ctx, rbacDomain, p.Subject, p.Action, p.Object, p.Effect); err != nil {
+			return liberrors.Errorf("rbacRepo.RemovePolicy. err: %w", err)
+		}
+	}
+
+	for _, p := range diff.Added.Policies {
+		if err := rbacRepo.AddConditionalPolicy(ctx, rbacDomain, p.Subject, p.Action, p.Object, p.Effect, p.Condition); err != nil {
+			return liberrors.Errorf("rbacRepo.AddConditionalPolicy. err: %w", err)
+		}
+	}
+
+	for _, r := range diff.Added.GroupingRules {
+		if r.PType == "g2" {
+			if err := rbacRepo.AddObjectGroupingPolicy(ctx, rbacDomain, domain.NewRBACObject(r.Child), domain.NewRBACObject(r.Parent)); err != nil {
+				return liberrors.Errorf("rbacRepo.AddObjectGroupingPolicy. err: %w", err)
+			}
+			continue
+		}
+
+		// the pair may exist without the rule. it is checked in advance because a failed insert aborts the transaction on Postgres
+		parentUserGroupID := mustParseRBACUserRole(r.Parent)
+		if appUserID, ok := parseRBACAppUser(r.Child); ok {
+			userGroups, err := pairOfUserAndGroupRepo.FindUserGroupsByUserID(ctx, sysOwner, appUserID)
+			if err != nil {
+				return err
+			}
+			if !containsUserGroup(userGroups, parentUserGroupID) {
+				if err := pairOfUserAndGroupRepo.AddPairOfUserAndGroupBySystemAdmin(ctx, operator, organizationID, appUserID, parentUserGroupID); err != nil {
+					return err
+				}
+			}
+		} else {
+			childUserGroupID := mustParseRBACUserRole(r.Child)
+			userGroups, err := pairOfGroupAndGroupRepo.FindParentUserGroups(ctx, sysOwner, childUserGroupID)
+			if err != nil {
+				return err
+			}
+			if !containsUserGroup(userGroups, parentUserGroupID) {
+				if err := pairOfGroupAndGroupRepo.AddPairOfGroupAndGroup(ctx, sysOwner, childUserGroupID, parentUserGroupID); err != nil {
+					return err
+				}
+			}
+		}
+		if err := rbacRepo.AddSubjectGroupingPolicy(ctx, rbacDomain, domain.NewRBACUser(r.Child), domain.NewRBACRole(r.Parent)); err != nil {
+			return liberrors.Errorf("rbacRepo.AddSubjectGroupingPolicy. err: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// mergeObjectLinks returns the links of the "g2" rules after the diff is applied to current
+func mergeObjectLinks(current *domain.RBACRuleSet, diff *domain.RBACRuleSetDiff) [][]string {
+	removed := make(map[string]bool)
+	for _, r := range diff.Removed.GroupingRules {
+		removed[r.Line()] = true
+	}
+
+	links := make([][]string, 0)
+	for _, rules := range [][]*domain.RBACGroupingRule{current.GroupingRules, diff.Added.GroupingRules} {
+		for _, r := range rules {
+			if r.PType == "g2" && !removed[r.Line()] {
+				links = append(links, []string{r.Child, r.Parent})
+			}
+		}
+	}
+
+	return links
+}
+
+func containsUserGroup(userGroups []*domain.UserGroupModel, userGroupID *domain.UserGroupID) bool {
+	for _, userGroup := range userGroups {
+		if userGroup.UserGroupID.Int() == userGroupID.Int() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseRBACAppUser(subject string) (*domain.AppUserID, bool) {
+	matches := rbacAppUserPattern.FindStringSubmatch(subject)
+	if matches == nil {
+		return nil, false
+	}
+
+	value, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return nil, false
+	}
+
+	appUserID, err := domain.NewAppUserID(value)
+	if err != nil {
+		return nil, false
+	}
+
+	return appUserID, true
+}
+
+// parseRBACUserRole returns the organization ID and the user group ID. The user group ID is nil for "domain:%d_role:*"
+func parseRBACUserRole(role string) (int, *domain.UserGroupID, bool) {
+	matches := rbacUserRolePattern.FindStringSubmatch(role)
+	if matches == nil {
+		return 0, nil, false
+	}
+
+	organizationID, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, nil, false
+	}
+	if matches[2] == "*" {
+		return organizationID, nil, true
+	}
+
+	value, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return 0, nil, false
+	}
+
+	userGroupID, err := domain.NewUserGroupID(value)
+	if err != nil {
+		return 0, nil, false
+	}
+
+	return organizationID, userGroupID, true
+}
+
+// mustParseRBACUserRole is used for the roles checked by rbacReferenceValidator
+func mustParseRBACUserRole(role string) *domain.UserGroupID {
+	_, userGroupID, ok := parseRBACUserRole(role)
+	if !ok || userGroupID == nil {
+		panic("invalid role: " + role)
+	}
+
+	return userGroupID
+}
+
+// rbacReferenceValidator checks that the rules refer to the app users and the user groups of the organization of the operator
+type rbacReferenceValidator struct {
+	ctx           context.Context
+	operator      service.AppUserInterface
+	rbacDomain    domain.RBACDomain
+	appUserRepo   service.AppUserRepository
+	userGroupRepo service.UserGroupRepository
+	appUserIDs    map[int]bool
+	userGroupIDs  map[int]bool
+}
+
+func newRBACReferenceValidator(ctx context.Context, dialect libgateway.DialectRDBMS, db *gorm.DB, rf service.RepositoryFactory, operator service.AppUserInterface) *rbacReferenceValidator {
+	return &rbacReferenceValidator{
+		ctx:           ctx,
+		operator:      operator,
+		rbacDomain:    service.NewRBACOrganization(operator.OrganizationID()),
+		appUserRepo:   NewAppUserRepository(ctx, dialect, db, rf),
+		userGroupRepo: NewUserGroupRepository(ctx, dialect, db),
+		appUserIDs:    make(map[int]bool),
+		userGroupIDs:  make(map[int]bool),
+	}
+}
+
+// normalize returns the rules in the domain of the operator without duplicates
+func (v *rbacReferenceValidator) normalize(ruleSet *domain.RBACRuleSet) (*domain.RBACRuleSet, error) {
+	normalized := domain.RBACRuleSet{Policies: make([]*domain.RBACPolicy, 0), GroupingRules: make([]*domain.RBACGroupingRule, 0)}
+	lines := make(map[string]bool)
+	// a policy has at most one condition
+	conditions := make(map[string]string)
+
+	for _, p := range ruleSet.Policies {
+		if err := v.validateDomain(p.Domain.Domain()); err != nil {
+			return nil, err
+		}
+		if err := v.validateSubject(p.Subject.Subject()); err != nil {
+			return nil, err
+		}
+		if err := v.validateObject(p.Object.Object()); err != nil {
+			return nil, err
+		}
+		if p.Action.Action() == "" {
+			return nil, liberrors.Errorf("action is empty. err: %w", libdomain.ErrInvalidArgument)
+		}
+		if p.Effect.Effect() != service.RBACAllowEffect.Effect() && p.Effect.Effect() != service.RBACDenyEffect.Effect() {
+			return nil, liberrors.Errorf("unsupported effect. effect: %s, err: %w", p.Effect.Effect(), libdomain.ErrInvalidArgument)
+		}
+
+		if p.Condition != nil {
+			if _, err := domain.ParseRBACCondition(p.Condition.Expression()); err != nil {
+				return nil, err
+			}
+		}
+
+		// the line without the condition identifies the policy
+		key := (&domain.RBACRuleSet{Policies: []*domain.RBACPolicy{domain.NewRBACPolicy(v.rbacDomain, p.Subject, p.Action, p.Object, p.Effect)}}).Lines()[0]
+		policy := domain.NewRBACConditionalPolicy(v.rbacDomain, p.Subject, p.Action, p.Object, p.Effect, p.Condition)
+		if expression, ok := conditions[key]; ok {
+			if expression != policy.ConditionExpression() {
+				return nil, liberrors.Errorf("policy has different conditions. policy: %s, err: %w", key, libdomain.ErrInvalidArgument)
+			}
+			continue
+		}
+		conditions[key] = policy.ConditionExpression()
+		normalized.Policies = append(normalized.Policies, policy)
+	}
+
+	for _, r := range ruleSet.GroupingRules {
+		if err := v.validateDomain(r.Domain.Domain()); err != nil {
+			return nil, err
+		}
+		switch r.PType {
+		case "g":
+			if err := v.validateSubject(r.Child); err != nil {
+				return nil, err
+			}
+			if err := v.validateUserRole(r.Parent); err != nil {
+				return nil, err
+			}
+		case "g2":
+			if err := v.validateObject(r.Child); err != nil {
+				return nil, err
+			}
+			if err := v.validateObject(r.Parent); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, liberrors.Errorf("unsupported ptype. ptype: %s, err: %w", r.PType, libdomain.ErrInvalidArgument)
+		}
+
+		rule := domain.NewRBACGroupingRule(r.PType, r.Child, r.Parent, v.rbacDomain)
+		if line := rule.Line(); !lines[line] {
+			lines[line] = true
+			normalized.GroupingRules = append(normalized.GroupingRules, rule)
+		}
+	}
+
+	return &normalized, nil
+}
+
+func (v *rbacReferenceValidator) validateDomain(rbacDomain string) error {
+	if rbacDomain != "" && rbacDomain != v.rbacDomain.Domain() {
+		return liberrors.Errorf("rule of another domain. domain: %s, err: %w", rbacDomain, libdomain.ErrInvalidArgument)
+	}
+
+	return nil
+}
+
+// validateSubject accepts an app user or a user group
+func (v *rbacReferenceValidator) validateSubject(subject string) error {
+	appUserID, ok := parseRBACAppUser(subject)
+	if !ok {
+		return v.validateUserRole(subject)
+	}
+
+	if v.appUserIDs[appUserID.Int()] {
+		return nil
+	}
+	if _, err := v.appUserRepo.FindAppUserByID(v.ctx, v.operator, appUserID); err != nil {
+		return liberrors.Errorf("app user is not found. subject: %s, err: %w", subject, err)
+	}
+	v.appUserIDs[appUserID.Int()] = true
+
+	return nil
+}
+
+func (v *rbacReferenceValidator) validateUserRole(role string) error {
+	organizationID, userGroupID, ok := parseRBACUserRole(role)
+	if !ok || userGroupID == nil || organizationID != v.operator.OrganizationID().Int() {
+		return liberrors.Errorf("invalid user role. role: %s, err: %w", role, libdomain.ErrInvalidArgument)
+	}
+
+	return v.validateUserGroupID(role, userGroupID)
+}
+
+func (v *rbacReferenceValidator) validateUserGroupID(value string, userGroupID *domain.UserGroupID) error {
+	if v.userGroupIDs[userGroupID.Int()] {
+		return nil
+	}
+	if _, err := v.userGroupRepo.FindUserGroupByID(v.ctx, v.operator, userGroupID); err != nil {
+		return liberrors.Errorf("user group is not found. value: %s, err: %w", value, err)
+	}
+	v.userGroupIDs[userGroupID.Int()] = true
+
+	return nil
+}
+
+// validateObject accepts any object outside of the domains. The objects in a domain must belong to the organization
+func (v *rbacReferenceValidator) validateObject(object string) error {
+	if object == "" {
+		return liberrors.Errorf("object is empty. err: %w", libdomain.ErrInvalidArgument)
+	}
+	if !strings.HasPrefix(object, "domain:") {
+		return nil
+	}
+	if !strings.HasPrefix(object, v.rbacDomain.Domain()+"_") {
+		return liberrors.Errorf("object of another domain. object: %s, err: %w", object, libdomain.ErrInvalidArgument)
+	}
+
+	_, userGroupID, ok := parseRBACUserRole(object)
+	if !ok || userGroupID == nil {
+		return nil
+	}
+
+	return v.validateUserGroupID(object, userGroupID)
+}
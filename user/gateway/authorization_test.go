@@ -3,8 +3,12 @@ package gateway_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/domain"
 	"github.com/kujilabo/redstart/user/gateway"
 	"github.com/kujilabo/redstart/user/service"
 	"github.com/stretchr/testify/assert"
@@ -12,7 +16,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func outputCasbinRule(t *testing.T, db *gorm.DB) {
+func outputCasbinRule(t testing.TB, db *gorm.DB) {
 	type Result struct {
 		ID    int
 		Ptype string
@@ -83,3 +87,742 @@ func Test_AddPairOfUserAndGroup(t *testing.T) {
 		testDB(t, fn)
 	}
 }
+
+func Test_authorizationManager_RemoveUserFromGroup(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		user2 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_2", "USERNAME_2", "PASSWORD_2")
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		userGroupRepo := gateway.NewUserGroupRepository(ctx, ts.dialect, ts.db)
+		pairOfUserAndGroupRepo := gateway.NewPairOfUserAndGroupRepository(ctx, ts.dialect, ts.db, ts.rf)
+		ownerGroup, err := userGroupRepo.FindUserGroupByKey(ctx, owner, service.OwnerGroupKey)
+		require.NoError(t, err)
+		rbacRoleObject := service.NewRBACUserRoleObject(orgID, ownerGroup.UserGroupID())
+
+		// - add user1 to owner-group
+		err = authorizationManager.AddUserToGroup(ctx, owner, user1.AppUserID(), ownerGroup.UserGroupID())
+		require.NoError(t, err)
+		ok, err := authorizationManager.Authorize(ctx, user1, service.RBACSetAction, rbacRoleObject)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// when
+		err = authorizationManager.RemoveUserFromGroup(ctx, user2, user1.AppUserID(), ownerGroup.UserGroupID())
+		// then
+		// - standard-user cannot remove users from owner-group
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+		ok, err = authorizationManager.Authorize(ctx, user1, service.RBACSetAction, rbacRoleObject)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		// when
+		err = authorizationManager.RemoveUserFromGroup(ctx, owner, user1.AppUserID(), ownerGroup.UserGroupID())
+		require.NoError(t, err)
+		// then
+		// - user1 loses the permissions of owner-group
+		ok, err = authorizationManager.Authorize(ctx, user1, service.RBACSetAction, rbacRoleObject)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		if ok {
+			outputCasbinRule(t, ts.db)
+		}
+		userGroups, err := pairOfUserAndGroupRepo.FindUserGroupsByUserID(ctx, user1, user1.AppUserID())
+		require.NoError(t, err)
+		assert.Len(t, userGroups, 0)
+
+		// when
+		err = authorizationManager.RemoveUserFromGroup(ctx, owner, user1.AppUserID(), ownerGroup.UserGroupID())
+		// then
+		// - user1 no longer belongs to owner-group
+		assert.ErrorIs(t, err, service.ErrPairOfUserAndGroupNotFound)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_RemoveUserFromGroupBySystemAdmin(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		sysAd := testNewSystemAdmin(domain.NewSystemAdminModel())
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		userGroupRepo := gateway.NewUserGroupRepository(ctx, ts.dialect, ts.db)
+		ownerGroup, err := userGroupRepo.FindUserGroupByKey(ctx, owner, service.OwnerGroupKey)
+		require.NoError(t, err)
+		rbacRoleObject := service.NewRBACUserRoleObject(orgID, ownerGroup.UserGroupID())
+
+		err = authorizationManager.AddUserToGroupBySystemAdmin(ctx, sysAd, orgID, user1.AppUserID(), ownerGroup.UserGroupID())
+		require.NoError(t, err)
+		ok, err := authorizationManager.Authorize(ctx, user1, service.RBACUnsetAction, rbacRoleObject)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// when
+		err = authorizationManager.RemoveUserFromGroupBySystemAdmin(ctx, sysAd, orgID, user1.AppUserID(), ownerGroup.UserGroupID())
+		require.NoError(t, err)
+
+		// then
+		ok, err = authorizationManager.Authorize(ctx, user1, service.RBACUnsetAction, rbacRoleObject)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_AddGroupToGroup(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - user1 belongs to group3
+		// - group1 can read "data"
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESC_1")
+		group2 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_2", "GROUP_NAME_2", "GROUP_DESC_2")
+		group3 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_3", "GROUP_NAME_3", "GROUP_DESC_3")
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		err := authorizationManager.AddUserToGroup(ctx, owner, user1.AppUserID(), group3.UserGroupID())
+		require.NoError(t, err)
+
+		readAction := domain.NewRBACAction("read")
+		dataObject := domain.NewRBACObject("data")
+		err = authorizationManager.AddPolicyToGroup(ctx, owner, service.NewRBACUserRole(orgID, group1.UserGroupID()), readAction, dataObject, service.RBACAllowEffect)
+		require.NoError(t, err)
+
+		ok, err := authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// when
+		// - group3 -> group2 -> group1
+		err = authorizationManager.AddGroupToGroup(ctx, owner, group2.UserGroupID(), group1.UserGroupID())
+		require.NoError(t, err)
+		err = authorizationManager.AddGroupToGroup(ctx, owner, group3.UserGroupID(), group2.UserGroupID())
+		require.NoError(t, err)
+
+		// then
+		// - user1 inherits the permissions of group1 through group2
+		ok, err = authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		if !ok {
+			outputCasbinRule(t, ts.db)
+		}
+
+		// when
+		err = authorizationManager.AddGroupToGroup(ctx, owner, group1.UserGroupID(), group3.UserGroupID())
+		// then
+		assert.ErrorIs(t, err, service.ErrUserGroupCycle)
+
+		// when
+		err = authorizationManager.AddGroupToGroup(ctx, user1, group1.UserGroupID(), group2.UserGroupID())
+		// then
+		// - standard-user cannot make groups belong to other groups
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+
+		// when
+		err = authorizationManager.RemoveGroupFromGroup(ctx, user1, group2.UserGroupID(), group1.UserGroupID())
+		// then
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+
+		// when
+		err = authorizationManager.RemoveGroupFromGroup(ctx, owner, group2.UserGroupID(), group1.UserGroupID())
+		require.NoError(t, err)
+
+		// then
+		// - user1 loses the permissions of group1
+		ok, err = authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		err = authorizationManager.RemoveGroupFromGroup(ctx, owner, group2.UserGroupID(), group1.UserGroupID())
+		assert.ErrorIs(t, err, service.ErrPairOfGroupAndGroupNotFound)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_ListAndRemovePolicies(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - user1 can read "data", group1 can write "data" and read "other"
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESC_1")
+		rbacAppUser := service.NewRBACAppUser(orgID, user1.AppUserID())
+		rbacUserRole := service.NewRBACUserRole(orgID, group1.UserGroupID())
+		readAction := domain.NewRBACAction("read")
+		writeAction := domain.NewRBACAction("write")
+		dataObject := domain.NewRBACObject("data")
+		otherObject := domain.NewRBACObject("other")
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, rbacAppUser, readAction, dataObject, service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddPolicyToGroup(ctx, owner, rbacUserRole, writeAction, dataObject, service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddPolicyToGroup(ctx, owner, rbacUserRole, readAction, otherObject, service.RBACDenyEffect))
+
+		// - the same object in another organization
+		otherOrgID, _, otherOwner := setupOrganization(ctx, t, ts)
+		defer teardownOrganization(t, ts, otherOrgID)
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, otherOwner, service.NewRBACAppUser(otherOrgID, otherOwner.AppUserID()), readAction, dataObject, service.RBACAllowEffect))
+
+		// when
+		userPolicies, err := authorizationManager.ListPoliciesForSubject(ctx, owner, rbacAppUser)
+		require.NoError(t, err)
+		groupPolicies, err := authorizationManager.ListPoliciesForSubject(ctx, owner, rbacUserRole)
+		require.NoError(t, err)
+		objectPolicies, err := authorizationManager.ListPoliciesForObject(ctx, owner, dataObject)
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, userPolicies, 1)
+		assert.Equal(t, rbacAppUser.Subject(), userPolicies[0].Subject.Subject())
+		assert.Equal(t, "read", userPolicies[0].Action.Action())
+		assert.Equal(t, "data", userPolicies[0].Object.Object())
+		assert.Equal(t, service.RBACAllowEffect.Effect(), userPolicies[0].Effect.Effect())
+		assert.Equal(t, service.NewRBACOrganization(orgID).Domain(), userPolicies[0].Domain.Domain())
+
+		require.Len(t, groupPolicies, 2)
+		assert.Equal(t, service.RBACDenyEffect.Effect(), groupPolicies[1].Effect.Effect())
+
+		// - policies of the other organization are not listed
+		require.Len(t, objectPolicies, 2)
+		for _, p := range objectPolicies {
+			assert.Equal(t, "data", p.Object.Object())
+			assert.Equal(t, service.NewRBACOrganization(orgID).Domain(), p.Domain.Domain())
+		}
+
+		// when
+		// - the app user who cannot unset the user roles cannot remove the policies
+		err = authorizationManager.RemovePolicyFromUser(ctx, user1, rbacAppUser, readAction, dataObject, service.RBACAllowEffect)
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+		err = authorizationManager.RemovePolicyFromGroup(ctx, user1, rbacUserRole, readAction, otherObject, service.RBACDenyEffect)
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+		// then
+		userPolicies, err = authorizationManager.ListPoliciesForSubject(ctx, owner, rbacAppUser)
+		require.NoError(t, err)
+		assert.Len(t, userPolicies, 1)
+
+		// when
+		err = authorizationManager.RemovePolicyFromUser(ctx, owner, rbacAppUser, readAction, dataObject, service.RBACAllowEffect)
+		require.NoError(t, err)
+		err = authorizationManager.RemovePolicyFromGroup(ctx, owner, rbacUserRole, readAction, otherObject, service.RBACDenyEffect)
+		require.NoError(t, err)
+
+		// then
+		ok, err := authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		userPolicies, err = authorizationManager.ListPoliciesForSubject(ctx, owner, rbacAppUser)
+		require.NoError(t, err)
+		assert.Len(t, userPolicies, 0)
+		groupPolicies, err = authorizationManager.ListPoliciesForSubject(ctx, owner, rbacUserRole)
+		require.NoError(t, err)
+		assert.Len(t, groupPolicies, 1)
+
+		err = authorizationManager.RemovePolicyFromUser(ctx, owner, rbacAppUser, readAction, dataObject, service.RBACAllowEffect)
+		assert.ErrorIs(t, err, service.ErrRBACPolicyNotFound)
+		// - the policy of the other organization cannot be removed
+		err = authorizationManager.RemovePolicyFromUser(ctx, owner, service.NewRBACAppUser(otherOrgID, otherOwner.AppUserID()), readAction, dataObject, service.RBACAllowEffect)
+		assert.ErrorIs(t, err, service.ErrRBACPolicyNotFound)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_AddPolicy_rolledBack(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - the subject is too long to be the target of the audit event
+		subject := domain.NewRBACUser(strings.Repeat("s", 201))
+		readAction := domain.NewRBACAction("read")
+		dataObject := domain.NewRBACObject("data")
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+
+		// when
+		err := authorizationManager.AddPolicyToUser(ctx, owner, subject, readAction, dataObject, service.RBACAllowEffect)
+		// then
+		// - the policy is not added without the audit event
+		require.Error(t, err)
+		policies, err := authorizationManager.ListPoliciesForSubject(ctx, owner, subject)
+		require.NoError(t, err)
+		assert.Empty(t, policies)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_ExportPoliciesBySystemAdmin_shouldHaveOwnerPolicies(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		sysAd := testNewSystemAdmin(domain.NewSystemAdminModel())
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+
+		// when
+		ruleSet, err := authorizationManager.ExportPoliciesBySystemAdmin(ctx, sysAd, orgID)
+
+		// then
+		// - "system-owner" user and "owner" group can "set" and "unset" "all-user-roles"
+		require.NoError(t, err)
+		policies := ruleSet.Policies
+		assert.Len(t, policies, 4)
+		rbacAllUserRolesObject := service.NewRBACAllUserRolesObject(orgID)
+		for _, p := range policies {
+			assert.Equal(t, service.NewRBACOrganization(orgID).Domain(), p.Domain.Domain())
+			assert.Equal(t, rbacAllUserRolesObject.Object(), p.Object.Object())
+			assert.Equal(t, service.RBACAllowEffect.Effect(), p.Effect.Effect())
+		}
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_AuthorizeBatch(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - user1 belongs to group2, group2 belongs to group1
+		// - user1 can read doc:1
+		// - group1 can read doc:2 and doc:3
+		// - user1 cannot read doc:3
+		// - group2 can write doc:4
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESC_1")
+		group2 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_2", "GROUP_NAME_2", "GROUP_DESC_2")
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, authorizationManager.AddUserToGroup(ctx, owner, user1.AppUserID(), group2.UserGroupID()))
+		require.NoError(t, authorizationManager.AddGroupToGroup(ctx, owner, group2.UserGroupID(), group1.UserGroupID()))
+
+		rbacUser1 := service.NewRBACAppUser(orgID, user1.AppUserID())
+		rbacGroup1 := service.NewRBACUserRole(orgID, group1.UserGroupID())
+		rbacGroup2 := service.NewRBACUserRole(orgID, group2.UserGroupID())
+		objectPrefix := service.NewRBACOrganization(orgID).Domain() + "_doc:"
+		doc := func(id string) domain.RBACObject { return domain.NewRBACObject(objectPrefix + id) }
+		readAction := domain.NewRBACAction("read")
+		writeAction := domain.NewRBACAction("write")
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, rbacUser1, readAction, doc("1"), service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddPolicyToGroup(ctx, owner, rbacGroup1, readAction, doc("2"), service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddPolicyToGroup(ctx, owner, rbacGroup1, readAction, doc("3"), service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, rbacUser1, readAction, doc("3"), service.RBACDenyEffect))
+		require.NoError(t, authorizationManager.AddPolicyToGroup(ctx, owner, rbacGroup2, writeAction, doc("4"), service.RBACAllowEffect))
+
+		for name, m := range map[string]service.AuthorizationManager{
+			"uncached": authorizationManager,
+			"cached":   gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, testCachedRepositoryFactory(t, ctx, ts)),
+		} {
+			// when
+			results, err := m.AuthorizeBatch(ctx, user1, []service.AuthRequest{
+				{Action: readAction, Object: doc("1")},
+				{Action: readAction, Object: doc("2")},
+				{Action: readAction, Object: doc("3")},
+				{Action: readAction, Object: doc("4")},
+				{Action: writeAction, Object: doc("4")},
+			})
+			// then
+			require.NoError(t, err, name)
+			assert.Equal(t, []bool{true, true, false, false, true}, results, name)
+
+			// when
+			objects, err := m.FilterAuthorizedObjects(ctx, user1, readAction, []domain.RBACObject{doc("4"), doc("3"), doc("2"), doc("1")})
+			// then
+			require.NoError(t, err, name)
+			assert.Equal(t, []domain.RBACObject{doc("2"), doc("1")}, objects, name)
+
+			// when
+			objects, err = m.ListAccessibleObjects(ctx, user1, objectPrefix, readAction)
+			// then
+			require.NoError(t, err, name)
+			assert.Equal(t, []domain.RBACObject{doc("1"), doc("2")}, objects, name)
+
+			// when
+			objects, err = m.ListAccessibleObjects(ctx, user1, objectPrefix, writeAction)
+			// then
+			require.NoError(t, err, name)
+			assert.Equal(t, []domain.RBACObject{doc("4")}, objects, name)
+
+			// when
+			results, err = m.AuthorizeBatch(ctx, user1, nil)
+			// then
+			require.NoError(t, err, name)
+			assert.Empty(t, results, name)
+		}
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_Explain(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - user1 belongs to group2, group2 belongs to group1
+		// - group1 can read doc:1, doc:2 and folder:1
+		// - user1 cannot read doc:2
+		// - doc:3 is in folder:1
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESC_1")
+		group2 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_2", "GROUP_NAME_2", "GROUP_DESC_2")
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, authorizationManager.AddUserToGroup(ctx, owner, user1.AppUserID(), group2.UserGroupID()))
+		require.NoError(t, authorizationManager.AddGroupToGroup(ctx, owner, group2.UserGroupID(), group1.UserGroupID()))
+
+		rbacDomain := service.NewRBACOrganization(orgID)
+		rbacUser1 := service.NewRBACAppUser(orgID, user1.AppUserID())
+		rbacGroup1 := service.NewRBACUserRole(orgID, group1.UserGroupID())
+		rbacGroup2 := service.NewRBACUserRole(orgID, group2.UserGroupID())
+		readAction := domain.NewRBACAction("read")
+		doc1 := domain.NewRBACObject("doc:1")
+		doc2 := domain.NewRBACObject("doc:2")
+		doc3 := domain.NewRBACObject("doc:3")
+		folder1 := domain.NewRBACObject("folder:1")
+		require.NoError(t, authorizationManager.AddPolicyToGroup(ctx, owner, rbacGroup1, readAction, doc1, service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddPolicyToGroup(ctx, owner, rbacGroup1, readAction, doc2, service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, rbacUser1, readAction, doc2, service.RBACDenyEffect))
+		require.NoError(t, authorizationManager.AddPolicyToGroup(ctx, owner, rbacGroup1, readAction, folder1, service.RBACAllowEffect))
+		rbacRepo := gateway.NewRBACRepository(ctx, ts.db)
+		require.NoError(t, rbacRepo.AddObjectGroupingPolicy(ctx, rbacDomain, doc3, folder1))
+
+		subjectChain := []*domain.RBACGroupingRule{
+			domain.NewRBACGroupingRule("g", rbacUser1.Subject(), rbacGroup2.Role(), rbacDomain),
+			domain.NewRBACGroupingRule("g", rbacGroup2.Role(), rbacGroup1.Role(), rbacDomain),
+		}
+		for name, m := range map[string]service.AuthorizationManager{
+			"uncached": authorizationManager,
+			"cached":   gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, testCachedRepositoryFactory(t, ctx, ts)),
+		} {
+			// when
+			explanation, err := m.Explain(ctx, user1, readAction, doc1)
+			// then
+			// - allowed by the policy of group1 through group2
+			require.NoError(t, err, name)
+			assert.True(t, explanation.Allowed, name)
+			require.NotNil(t, explanation.Policy, name)
+			assert.Equal(t, rbacGroup1.Subject(), explanation.Policy.Subject.Subject(), name)
+			assert.Equal(t, service.RBACAllowEffect.Effect(), explanation.Policy.Effect.Effect(), name)
+			assert.Equal(t, subjectChain, explanation.SubjectChain, name)
+			assert.Empty(t, explanation.ObjectChain, name)
+			assert.Equal(t, []string{
+				"p, " + rbacGroup1.Subject() + ", doc:1, read, allow, " + rbacDomain.Domain(),
+				"g, " + rbacUser1.Subject() + ", " + rbacGroup2.Role() + ", " + rbacDomain.Domain(),
+				"g, " + rbacGroup2.Role() + ", " + rbacGroup1.Role() + ", " + rbacDomain.Domain(),
+			}, explanation.Lines(), name)
+
+			// when
+			explanation, err = m.Explain(ctx, user1, readAction, doc2)
+			// then
+			// - denied by the policy of user1
+			require.NoError(t, err, name)
+			assert.False(t, explanation.Allowed, name)
+			require.NotNil(t, explanation.Policy, name)
+			assert.Equal(t, rbacUser1.Subject(), explanation.Policy.Subject.Subject(), name)
+			assert.Equal(t, service.RBACDenyEffect.Effect(), explanation.Policy.Effect.Effect(), name)
+			assert.Empty(t, explanation.SubjectChain, name)
+
+			// when
+			explanation, err = m.Explain(ctx, user1, domain.NewRBACAction("write"), doc1)
+			// then
+			// - no policy matched
+			require.NoError(t, err, name)
+			assert.False(t, explanation.Allowed, name)
+			assert.Nil(t, explanation.Policy, name)
+			assert.Empty(t, explanation.Lines(), name)
+
+			// when
+			explanation, err = m.Explain(ctx, user1, readAction, doc3)
+			// then
+			// - allowed by the policy on folder1 which doc3 belongs to
+			require.NoError(t, err, name)
+			assert.True(t, explanation.Allowed, name)
+			require.NotNil(t, explanation.Policy, name)
+			assert.Equal(t, folder1.Object(), explanation.Policy.Object.Object(), name)
+			assert.Equal(t, subjectChain, explanation.SubjectChain, name)
+			assert.Equal(t, []*domain.RBACGroupingRule{domain.NewRBACGroupingRule("g2", doc3.Object(), folder1.Object(), rbacDomain)}, explanation.ObjectChain, name)
+		}
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_ImportAndExportPolicies(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		sysAd := testNewSystemAdmin(domain.NewSystemAdminModel())
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESC_1")
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+
+		rbacDomain := service.NewRBACOrganization(orgID)
+		rbacUser1 := service.NewRBACAppUser(orgID, user1.AppUserID())
+		rbacGroup1 := service.NewRBACUserRole(orgID, group1.UserGroupID())
+		readAction := domain.NewRBACAction("read")
+		doc1 := domain.NewRBACObject("doc:1")
+		doc2 := domain.NewRBACObject("doc:2")
+
+		// when
+		exported, err := authorizationManager.ExportPoliciesBySystemAdmin(ctx, sysAd, orgID)
+		// then
+		// - the policies and the grouping rules created with the organization
+		require.NoError(t, err)
+		assert.Len(t, exported.Policies, 4)
+		assert.NotEmpty(t, exported.GroupingRules)
+
+		// - user1 can read doc:1, user1 belongs to group1 and group1 can read doc:2. the domain may be omitted
+		data := []byte("p, " + rbacUser1.Subject() + ", doc:1, read, allow\n" +
+			"p, " + rbacGroup1.Subject() + ", doc:2, read, allow, " + rbacDomain.Domain() + "\n" +
+			"g, " + rbacUser1.Subject() + ", " + rbacGroup1.Role() + "\n")
+		imported, err := domain.UnmarshalRBACRuleSet(data, domain.RBACRuleSetFormatCSV)
+		require.NoError(t, err)
+		imported.Policies = append(imported.Policies, exported.Policies...)
+		imported.GroupingRules = append(imported.GroupingRules, exported.GroupingRules...)
+		added := []string{
+			"p, " + rbacUser1.Subject() + ", doc:1, read, allow, " + rbacDomain.Domain(),
+			"p, " + rbacGroup1.Subject() + ", doc:2, read, allow, " + rbacDomain.Domain(),
+			"g, " + rbacUser1.Subject() + ", " + rbacGroup1.Role() + ", " + rbacDomain.Domain(),
+		}
+
+		// when
+		diff, err := authorizationManager.ImportPoliciesBySystemAdmin(ctx, sysAd, orgID, imported, service.PolicyImportModeMerge, true)
+		// then
+		// - nothing is changed by dry run
+		require.NoError(t, err)
+		assert.Equal(t, added, diff.Added.Lines())
+		assert.Empty(t, diff.Removed.Lines())
+		results, err := authorizationManager.AuthorizeBatch(ctx, user1, []service.AuthRequest{{Action: readAction, Object: doc1}, {Action: readAction, Object: doc2}})
+		require.NoError(t, err)
+		assert.Equal(t, []bool{false, false}, results)
+
+		// when
+		diff, err = authorizationManager.ImportPoliciesBySystemAdmin(ctx, sysAd, orgID, imported, service.PolicyImportModeMerge, false)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, added, diff.Added.Lines())
+		results, err = authorizationManager.AuthorizeBatch(ctx, user1, []service.AuthRequest{{Action: readAction, Object: doc1}, {Action: readAction, Object: doc2}})
+		require.NoError(t, err)
+		assert.Equal(t, []bool{true, true}, results)
+		// - user1 belongs to group1
+		userGroups, err := gateway.NewPairOfUserAndGroupRepository(ctx, ts.dialect, ts.db, ts.rf).FindUserGroupsByUserID(ctx, owner, user1.AppUserID())
+		require.NoError(t, err)
+		require.Len(t, userGroups, 1)
+		assert.Equal(t, group1.UserGroupID().Int(), userGroups[0].UserGroupID.Int())
+
+		// when
+		// - importing the same rules again changes nothing
+		diff, err = authorizationManager.ImportPoliciesBySystemAdmin(ctx, sysAd, orgID, imported, service.PolicyImportModeMerge, false)
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, diff.Added.Lines())
+
+		// when
+		diff, err = authorizationManager.ImportPoliciesBySystemAdmin(ctx, sysAd, orgID, exported, service.PolicyImportModeReplace, false)
+		// then
+		// - the imported rules are removed
+		require.NoError(t, err)
+		assert.Empty(t, diff.Added.Lines())
+		assert.ElementsMatch(t, added, diff.Removed.Lines())
+		results, err = authorizationManager.AuthorizeBatch(ctx, user1, []service.AuthRequest{{Action: readAction, Object: doc1}, {Action: readAction, Object: doc2}})
+		require.NoError(t, err)
+		assert.Equal(t, []bool{false, false}, results)
+		userGroups, err = gateway.NewPairOfUserAndGroupRepository(ctx, ts.dialect, ts.db, ts.rf).FindUserGroupsByUserID(ctx, owner, user1.AppUserID())
+		require.NoError(t, err)
+		assert.Empty(t, userGroups)
+		current, err := authorizationManager.ExportPoliciesBySystemAdmin(ctx, sysAd, orgID)
+		require.NoError(t, err)
+		assert.Equal(t, exported.Lines(), current.Lines())
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_ImportPolicies_invalidReferences(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		sysAd := testNewSystemAdmin(domain.NewSystemAdminModel())
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESC_1")
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+
+		rbacDomain := service.NewRBACOrganization(orgID).Domain()
+		otherDomain := fmt.Sprintf("domain:%d", orgID.Int()+1)
+		rbacGroup1 := service.NewRBACUserRole(orgID, group1.UserGroupID()).Role()
+		tests := []struct {
+			name string
+			data string
+			err  error
+		}{
+			{name: "another domain", data: "p, " + rbacGroup1 + ", doc:1, read, allow, " + otherDomain, err: libdomain.ErrInvalidArgument},
+			{name: "object of another domain", data: "p, " + rbacGroup1 + ", " + otherDomain + "_doc:1, read, allow", err: libdomain.ErrInvalidArgument},
+			{name: "role of another domain", data: "p, " + otherDomain + "_role:1, doc:1, read, allow", err: libdomain.ErrInvalidArgument},
+			{name: "unknown effect", data: "p, " + rbacGroup1 + ", doc:1, read, maybe", err: libdomain.ErrInvalidArgument},
+			{name: "unknown app user", data: "p, user:999999999, doc:1, read, allow", err: service.ErrAppUserNotFound},
+			{name: "unknown user group", data: "g, " + rbacGroup1 + ", " + rbacDomain + "_role:999999999", err: service.ErrUserGroupNotFound},
+			{name: "parent is not a role", data: "g, " + rbacGroup1 + ", doc:1", err: libdomain.ErrInvalidArgument},
+			{name: "different conditions", data: "p, " + rbacGroup1 + ", doc:1, read, allow, , [a] == 1\np, " + rbacGroup1 + ", doc:1, read, allow, , [a] == 2", err: libdomain.ErrInvalidArgument},
+		}
+		for _, tt := range tests {
+			ruleSet, err := domain.UnmarshalRBACRuleSet([]byte(tt.data), domain.RBACRuleSetFormatCSV)
+			require.NoError(t, err, tt.name)
+
+			// when
+			_, err = authorizationManager.ImportPoliciesBySystemAdmin(ctx, sysAd, orgID, ruleSet, service.PolicyImportModeMerge, false)
+			// then
+			assert.ErrorIs(t, err, tt.err, tt.name)
+		}
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_ImportPolicies_objectHierarchy(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - workspace:1 -> folder:1
+		sysAd := testNewSystemAdmin(domain.NewSystemAdminModel())
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		importRules := func(data string, mode service.PolicyImportMode, dryRun bool) error {
+			ruleSet, err := domain.UnmarshalRBACRuleSet([]byte(data), domain.RBACRuleSetFormatCSV)
+			require.NoError(t, err)
+			_, err = authorizationManager.ImportPoliciesBySystemAdmin(ctx, sysAd, orgID, ruleSet, mode, dryRun)
+			return err
+		}
+		require.NoError(t, importRules("g2, folder:1, workspace:1", service.PolicyImportModeMerge, false))
+		exported, err := authorizationManager.ExportPoliciesBySystemAdmin(ctx, sysAd, orgID)
+		require.NoError(t, err)
+		currentData, err := domain.MarshalRBACRuleSet(exported, domain.RBACRuleSetFormatCSV)
+		require.NoError(t, err)
+		current := string(currentData)
+
+		tooDeep := ""
+		for i := 0; i <= service.MaxObjectHierarchyDepth; i++ {
+			tooDeep += fmt.Sprintf("g2, node:%d, node:%d\n", i+1, i)
+		}
+		tests := []struct {
+			name   string
+			data   string
+			mode   service.PolicyImportMode
+			dryRun bool
+			err    error
+		}{
+			{name: "cycle with the current rules", data: "g2, workspace:1, folder:1", mode: service.PolicyImportModeMerge, err: service.ErrObjectHierarchyCycle},
+			{name: "cycle with the current rules by dry run", data: "g2, workspace:1, folder:1", mode: service.PolicyImportModeMerge, dryRun: true, err: service.ErrObjectHierarchyCycle},
+			{name: "cycle in the imported rules", data: "g2, doc:1, doc:2\ng2, doc:2, doc:1", mode: service.PolicyImportModeMerge, err: service.ErrObjectHierarchyCycle},
+			{name: "self", data: "g2, doc:1, doc:1", mode: service.PolicyImportModeMerge, err: service.ErrObjectHierarchyCycle},
+			{name: "too deep", data: tooDeep, mode: service.PolicyImportModeMerge, err: service.ErrObjectHierarchyTooDeep},
+			{name: "reversed by replace", data: current + "g2, workspace:1, folder:1", mode: service.PolicyImportModeReplace, err: service.ErrObjectHierarchyCycle},
+		}
+		for _, tt := range tests {
+			// when
+			err := importRules(tt.data, tt.mode, tt.dryRun)
+			// then
+			assert.ErrorIs(t, err, tt.err, tt.name)
+		}
+
+		// when
+		// - the current rule is replaced with the reversed one
+		exported.GroupingRules = append(exported.GroupingRules, domain.NewRBACGroupingRule("g2", "workspace:1", "folder:1", service.NewRBACOrganization(orgID)))
+		filtered := exported.GroupingRules[:0]
+		for _, r := range exported.GroupingRules {
+			if !(r.PType == "g2" && r.Child == "folder:1") {
+				filtered = append(filtered, r)
+			}
+		}
+		exported.GroupingRules = filtered
+		_, err = authorizationManager.ImportPoliciesBySystemAdmin(ctx, sysAd, orgID, exported, service.PolicyImportModeReplace, false)
+		// then
+		require.NoError(t, err)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_authorizationManager_AuthorizeWithAttributes(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - user1 can write the docs which user1 owns
+		// - user1 can read doc:1 during business hours
+		// - user1 can delete doc:1 unless it is locked
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		rbacUser1 := service.NewRBACAppUser(orgID, user1.AppUserID())
+		objectPrefix := service.NewRBACOrganization(orgID).Domain() + "_doc:"
+		doc1 := domain.NewRBACObject(objectPrefix + "1")
+		readAction := domain.NewRBACAction("read")
+		writeAction := domain.NewRBACAction("write")
+		deleteAction := domain.NewRBACAction("delete")
+		businessHours := domain.RBACAnd(domain.RBACAttr(domain.RBACTimeHourAttribute).GreaterThanOrEqual(9), domain.RBACAttr(domain.RBACTimeHourAttribute).LessThan(18))
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, authorizationManager.AddConditionalPolicy(ctx, owner, rbacUser1, writeAction, domain.NewRBACObject(objectPrefix+"*"), service.RBACAllowEffect, domain.RBACAttr("resource.owner").Equal(domain.RBACAttr(domain.RBACOperatorSubjectAttribute))))
+		require.NoError(t, authorizationManager.AddConditionalPolicy(ctx, owner, rbacUser1, readAction, doc1, service.RBACAllowEffect, businessHours))
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, rbacUser1, deleteAction, doc1, service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddConditionalPolicy(ctx, owner, rbacUser1, deleteAction, doc1, service.RBACDenyEffect, domain.RBACAttr("resource.locked").Equal(true)))
+
+		for name, m := range map[string]service.AuthorizationManager{
+			"uncached": authorizationManager,
+			"cached":   gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, testCachedRepositoryFactory(t, ctx, ts)),
+		} {
+			// when
+			gateway.SetAuthorizationManagerNow(m, func() time.Time { return time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC) })
+			results, err := m.AuthorizeBatch(ctx, user1, []service.AuthRequest{
+				{Action: writeAction, Object: doc1, Attributes: domain.RBACAttributes{"resource.owner": rbacUser1.Subject()}},
+				{Action: writeAction, Object: doc1, Attributes: domain.RBACAttributes{"resource.owner": "user:0"}},
+				// - the built-in attributes cannot be overridden
+				{Action: writeAction, Object: doc1, Attributes: domain.RBACAttributes{"resource.owner": "user:0", domain.RBACOperatorSubjectAttribute: "user:0"}},
+				// - the condition which refers to the missing attribute is not satisfied
+				{Action: writeAction, Object: doc1},
+				{Action: readAction, Object: doc1},
+				{Action: deleteAction, Object: doc1, Attributes: domain.RBACAttributes{"resource.locked": false}},
+				{Action: deleteAction, Object: doc1, Attributes: domain.RBACAttributes{"resource.locked": true}},
+				// - the "deny" policy is applied when its condition cannot be evaluated
+				{Action: deleteAction, Object: doc1},
+			})
+			// then
+			require.NoError(t, err, name)
+			assert.Equal(t, []bool{true, false, false, false, true, true, false, false}, results, name)
+
+			// when
+			gateway.SetAuthorizationManagerNow(m, func() time.Time { return time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC) })
+			ok, err := m.AuthorizeWithAttributes(ctx, user1, readAction, doc1, nil)
+			// then
+			require.NoError(t, err, name)
+			assert.False(t, ok, name)
+
+			// when
+			explanation, err := m.Explain(ctx, user1, deleteAction, doc1)
+			// then
+			// - the "deny" policy is applied because Explain has only the built-in attributes
+			require.NoError(t, err, name)
+			assert.False(t, explanation.Allowed, name)
+			assert.Equal(t, "[resource.locked] == true", explanation.Policy.ConditionExpression(), name)
+		}
+
+		// when
+		// - the condition of the existing policy is replaced
+		require.NoError(t, authorizationManager.AddConditionalPolicy(ctx, owner, rbacUser1, readAction, doc1, service.RBACAllowEffect, domain.RBACAttr(domain.RBACTimeHourAttribute).GreaterThanOrEqual(0)))
+		// then
+		policies, err := authorizationManager.ListPoliciesForObject(ctx, owner, doc1)
+		require.NoError(t, err)
+		conditions := make(map[string]string)
+		for _, p := range policies {
+			conditions[p.Action.Action()+","+p.Effect.Effect()] = p.ConditionExpression()
+		}
+		assert.Equal(t, map[string]string{
+			"read,allow":   "[time.hour] >= 0",
+			"delete,allow": "",
+			"delete,deny":  "[resource.locked] == true",
+		}, conditions)
+
+		// when
+		err = authorizationManager.AddConditionalPolicy(ctx, owner, rbacUser1, readAction, doc1, service.RBACAllowEffect, domain.NewRBACCondition("[a] =="))
+		// then
+		assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+
+		// when
+		// - the policy is removed whatever its condition is
+		err = authorizationManager.RemovePolicyFromUser(ctx, owner, rbacUser1, readAction, doc1, service.RBACAllowEffect)
+		// then
+		require.NoError(t, err)
+		ok, err := authorizationManager.AuthorizeWithAttributes(ctx, user1, readAction, doc1, nil)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+	testOrganization(t, fn)
+}
@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"errors"
+
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"gorm.io/gorm"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+// casbinAdapter loads the "p" rules without the condition.
+// gorm-adapter drops the empty values at the end of a rule, and casbin rejects the rules shorter than the policy definition.
+// The other operations are done by gorm-adapter
+type casbinAdapter struct {
+	*gormadapter.Adapter
+	db       *gorm.DB
+	filtered bool
+	// inactive is the keys of the rules which are not loaded because they are not effective now
+	inactive map[string]bool
+}
+
+// newCasbinAdapter returns the adapter which does not migrate casbin_rule, which is created by the migrations
+func newCasbinAdapter(db *gorm.DB) (*casbinAdapter, error) {
+	db = db.Session(&gorm.Session{})
+	gormadapter.TurnOffAutoMigrate(db)
+
+	a, err := gormadapter.NewAdapterByDB(db)
+	if err != nil {
+		return nil, liberrors.Errorf("gormadapter.NewAdapterByDB. err: %w", err)
+	}
+
+	return &casbinAdapter{Adapter: a, db: db}, nil
+}
+
+func (a *casbinAdapter) LoadPolicy(m model.Model) error {
+	a.filtered = false
+	return a.loadPolicy(m, gormadapter.Filter{})
+}
+
+// LoadFilteredPolicy accepts gormadapter.Filter or []gormadapter.Filter
+func (a *casbinAdapter) LoadFilteredPolicy(m model.Model, filter interface{}) error {
+	var filters []gormadapter.Filter
+	switch f := filter.(type) {
+	case gormadapter.Filter:
+		filters = []gormadapter.Filter{f}
+	case []gormadapter.Filter:
+		filters = f
+	default:
+		return errors.New("unsupported filter type")
+	}
+
+	a.filtered = true
+	return a.loadPolicy(m, filters...)
+}
+
+func (a *casbinAdapter) IsFiltered() bool {
+	return a.filtered
+}
+
+func (a *casbinAdapter) loadPolicy(m model.Model, filters ...gormadapter.Filter) error {
+	for _, filter := range filters {
+		lines := make([]gormadapter.CasbinRule, 0)
+		if err := a.db.Scopes(casbinRuleFilter(filter)).Order("id").Find(&lines).Error; err != nil {
+			return liberrors.Errorf("select casbin_rule. err: %w", err)
+		}
+
+		for _, line := range lines {
+			if a.inactive[casbinRuleKey(line.Ptype, line.V0, line.V1, line.V2, line.V3, line.V4)] {
+				continue
+			}
+			if err := loadCasbinRule(line, m); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func casbinRuleFilter(filter gormadapter.Filter) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for column, values := range map[string][]string{
+			"ptype": filter.Ptype,
+			"v0":    filter.V0,
+			"v1":    filter.V1,
+			"v2":    filter.V2,
+			"v3":    filter.V3,
+			"v4":    filter.V4,
+			"v5":    filter.V5,
+		} {
+			if len(values) > 0 {
+				db = db.Where(column+" in (?)", values)
+			}
+		}
+		return db
+	}
+}
+
+// domainCasbinRuleFilters returns the filters of the rules of the domain. "p" has the domain in v4, "g" and "g2" have it in v2
+func domainCasbinRuleFilters(rbacDomain domain.RBACDomain) []gormadapter.Filter {
+	return []gormadapter.Filter{
+		{Ptype: []string{"p"}, V4: []string{rbacDomain.Domain()}},
+		{Ptype: []string{"g", "g2"}, V2: []string{rbacDomain.Domain()}},
+	}
+}
+
+func loadCasbinRule(line gormadapter.CasbinRule, m model.Model) error {
+	rule := []string{line.V0, line.V1, line.V2, line.V3, line.V4, line.V5}
+	for len(rule) > 0 && rule[len(rule)-1] == "" {
+		rule = rule[:len(rule)-1]
+	}
+
+	if line.Ptype == "" {
+		return nil
+	}
+	sec := line.Ptype[:1]
+	assertion, ok := m[sec][line.Ptype]
+	if !ok {
+		// the rules which are not in the model are ignored
+		return nil
+	}
+	if sec == "p" {
+		for len(rule) < len(assertion.Tokens) {
+			rule = append(rule, "")
+		}
+	}
+
+	if err := persist.LoadPolicyArray(append([]string{line.Ptype}, rule...), m); err != nil {
+		return liberrors.Errorf("persist.LoadPolicyArray. err: %w", err)
+	}
+
+	return nil
+}
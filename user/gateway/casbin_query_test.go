@@ -0,0 +1,78 @@
+package gateway_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/gateway/casbinquery"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type objectResult struct {
+	ObjectName string
+}
+
+func Test_casbinquery_QueryObjectAndFindObject(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService) {
+		rbacRepo := gateway.NewRBACRepository(ctx, ts.db)
+
+		// given
+		// - user can read doc:1 directly
+		// - user can read doc:2 through role
+		// - user can only write doc:3
+		suffix := RandString(8)
+		objectPrefix := "domain:" + suffix + "_"
+		rbacDomain := domain.NewRBACDomain("domain:" + suffix)
+		rbacUser := domain.NewRBACUser("user:" + suffix)
+		rbacRole := domain.NewRBACRole("role:" + suffix)
+		readAction := domain.NewRBACAction("Read")
+		writeAction := domain.NewRBACAction("Write")
+		err := rbacRepo.AddPolicy(ctx, rbacDomain, rbacUser, readAction, domain.NewRBACObject(objectPrefix+"doc:1"), service.RBACAllowEffect)
+		require.NoError(t, err)
+		err = rbacRepo.AddPolicy(ctx, rbacDomain, rbacRole, readAction, domain.NewRBACObject(objectPrefix+"doc:2"), service.RBACAllowEffect)
+		require.NoError(t, err)
+		err = rbacRepo.AddPolicy(ctx, rbacDomain, rbacUser, writeAction, domain.NewRBACObject(objectPrefix+"doc:3"), service.RBACAllowEffect)
+		require.NoError(t, err)
+		err = rbacRepo.AddSubjectGroupingPolicy(ctx, rbacDomain, rbacUser, rbacRole)
+		require.NoError(t, err)
+
+		// when
+		db, err := casbinquery.QueryObject(ts.db, ts.dialect, objectPrefix, "object_name", rbacUser.Subject(), readAction.Action())
+		require.NoError(t, err)
+		results := []objectResult{}
+		require.NoError(t, db.Scan(&results).Error)
+		sort.Slice(results, func(i, j int) bool { return results[i].ObjectName < results[j].ObjectName })
+
+		// then
+		require.Len(t, results, 2)
+		assert.Equal(t, "doc:1", results[0].ObjectName)
+		assert.Equal(t, "doc:2", results[1].ObjectName)
+
+		// when
+		db, err = casbinquery.FindObject(ts.db, ts.dialect, objectPrefix+"doc:2", "object_name", rbacUser.Subject(), readAction.Action())
+		require.NoError(t, err)
+		results = []objectResult{}
+		require.NoError(t, db.Scan(&results).Error)
+
+		// then
+		require.Len(t, results, 1)
+		assert.Equal(t, "doc:2", results[0].ObjectName)
+
+		// when
+		db, err = casbinquery.FindObject(ts.db, ts.dialect, objectPrefix+"doc:3", "object_name", rbacUser.Subject(), readAction.Action())
+		require.NoError(t, err)
+		results = []objectResult{}
+		require.NoError(t, db.Scan(&results).Error)
+
+		// then
+		assert.Len(t, results, 0)
+	}
+	testDB(t, fn)
+}
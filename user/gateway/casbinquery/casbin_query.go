@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
 )
 
 const mysqlObjectSelectSQL = `
@@ -47,20 +49,42 @@ AND tp.v2 = ?
 AND tp.v1 LIKE ?
 `
 
-func QueryObject(db *gorm.DB, driverName, objectPrefix, columnName, subject, action string) (*gorm.DB, error) {
-	if db == nil {
+const postgresObjectSelectSQL = `
+SELECT SUBSTRING(tp.v1 FROM '[^_]*$') AS %s 
+FROM casbin_rule tg
+INNER JOIN casbin_rule tp ON tg.v1 = tp.v0
+WHERE tg.v0 = ?
+AND tg.ptype = 'g'
+AND tp.ptype = 'p'
+AND tp.v2 = ?
+AND tp.v1 LIKE ?
+
+UNION
+
+SELECT SUBSTRING(tp.v1 FROM '[^_]*$') AS %s 
+FROM casbin_rule tp
+WHERE tp.v0 = ?
+AND tp.ptype = 'p'
+AND tp.v2 = ?
+AND tp.v1 LIKE ?
+`
+
+func QueryObject(db *gorm.DB, dialect libgateway.DialectRDBMS, objectPrefix, columnName, subject, action string) (*gorm.DB, error) {
+	if db == nil || dialect == nil {
 		return nil, errors.New("invalid argument")
 	}
 	objectgKeyword := objectPrefix + "%"
 
 	var objectSelectSQL string
-	switch driverName {
+	switch dialect.Name() {
 	case "mysql":
 		objectSelectSQL = mysqlObjectSelectSQL
+	case "postgres":
+		objectSelectSQL = postgresObjectSelectSQL
 	case "sqlite3":
 		objectSelectSQL = sqlite3ObjectSelectSQL
 	default:
-		return nil, errors.New("invalid driver name")
+		return nil, fmt.Errorf("invalid driver name. driver: %s", dialect.Name())
 	}
 
 	sql := fmt.Sprintf(objectSelectSQL, columnName, columnName)
@@ -108,22 +132,68 @@ AND tp.v2 = ?
 AND tp.v1 = ?
 `
 
-func FindObject(db *gorm.DB, driverName, object, columnName, subject, action string) (*gorm.DB, error) {
-	if db == nil {
+const postgresObjectFindSQL = `
+SELECT SUBSTRING(tp.v1 FROM '[^_]*$') AS %s 
+FROM casbin_rule tg
+INNER JOIN casbin_rule tp ON tg.v1 = tp.v0
+WHERE tg.v0 = ?
+AND tg.ptype = 'g'
+AND tp.ptype = 'p'
+AND tp.v2 = ?
+AND tp.v1 = ?
+
+UNION
+
+SELECT SUBSTRING(tp.v1 FROM '[^_]*$') AS %s 
+FROM casbin_rule tp
+WHERE tp.v0 = ?
+AND tp.ptype = 'p'
+AND tp.v2 = ?
+AND tp.v1 = ?
+`
+
+func FindObject(db *gorm.DB, dialect libgateway.DialectRDBMS, object, columnName, subject, action string) (*gorm.DB, error) {
+	if db == nil || dialect == nil {
 		return nil, errors.New("invalid argument")
 	}
 
 	var objectSelectSQL string
-	switch driverName {
+	switch dialect.Name() {
 	case "mysql":
 		objectSelectSQL = mysqlObjectFindSQL
+	case "postgres":
+		objectSelectSQL = postgresObjectFindSQL
 	case "sqlite3":
 		objectSelectSQL = sqlite3ObjectFindSQL
 	default:
-		return nil, fmt.Errorf("invalid driver name. driver: %s", driverName)
+		return nil, fmt.Errorf("invalid driver name. driver: %s", dialect.Name())
 	}
 
 	sql := fmt.Sprintf(objectSelectSQL, columnName, columnName)
 
 	return db.Raw(sql, subject, action, object, subject, action, object), nil
 }
+
+const allowedObjectSelectSQL = `
+SELECT DISTINCT tp.v1 AS %s
+FROM casbin_rule tp
+WHERE tp.ptype = 'p'
+AND tp.v0 IN ?
+AND tp.v2 = ?
+AND tp.v3 = ?
+AND tp.v4 = ?
+AND tp.v1 LIKE ?
+`
+
+// QueryAllowedObjects returns the objects of the policies of the subjects which have the action and the effect in the domain.
+// '_' and '%' in objectPrefix are not escaped, so the results may contain objects without the prefix
+func QueryAllowedObjects(db *gorm.DB, objectPrefix, columnName string, subjects []string, action, effect, domain string) (*gorm.DB, error) {
+	if db == nil || len(subjects) == 0 {
+		return nil, errors.New("invalid argument")
+	}
+	objectgKeyword := objectPrefix + "%"
+
+	sql := fmt.Sprintf(allowedObjectSelectSQL, columnName)
+
+	return db.Raw(sql, subjects, action, effect, domain, objectgKeyword), nil
+}
@@ -0,0 +1,267 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"gorm.io/gorm"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+// EnforcerCache holds an enforcer per organization so that Authorize does not load the policies from the database on every call.
+// The enforcer of an organization is reloaded after Invalidate is called or ttl has passed. ttl <= 0 means it never expires
+type EnforcerCache struct {
+	mu          sync.Mutex
+	conf        string
+	ttl         time.Duration
+	entries     map[string]*enforcerCacheEntry
+	ruleEntries map[string]*ruleCacheEntry
+	generations map[string]int
+	// generation is incremented by InvalidateAll
+	generation int
+	now        func() time.Time
+}
+
+type enforcerCacheEntry struct {
+	enforcer *casbin.SyncedCachedEnforcer
+	loadedAt time.Time
+	// expiresAt is when any of the rules becomes effective or expires. nil means no rule has the validity
+	expiresAt *time.Time
+}
+
+// ruleCacheEntry has all the rules of a domain including the ones out of their validity
+type ruleCacheEntry struct {
+	enforcer *casbin.SyncedEnforcer
+	loadedAt time.Time
+}
+
+func (e *ruleCacheEntry) isFresh(now time.Time, ttl time.Duration) bool {
+	return ttl <= 0 || now.Sub(e.loadedAt) < ttl
+}
+
+func (e *enforcerCacheEntry) isFresh(now time.Time, ttl time.Duration) bool {
+	if ttl > 0 && now.Sub(e.loadedAt) >= ttl {
+		return false
+	}
+
+	return e.expiresAt == nil || now.Before(*e.expiresAt)
+}
+
+func NewEnforcerCache(ttl time.Duration) *EnforcerCache {
+	return &EnforcerCache{
+		conf:        conf,
+		ttl:         ttl,
+		entries:     make(map[string]*enforcerCacheEntry),
+		ruleEntries: make(map[string]*ruleCacheEntry),
+		generations: make(map[string]int),
+		now:         time.Now,
+	}
+}
+
+// inTransaction returns whether db is a transaction
+func inTransaction(db *gorm.DB) bool {
+	_, ok := db.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
+
+// Enforcer returns the enforcer holding all the policies of the domain. The policies are loaded with db when they are not cached.
+// When db is a transaction, the policies are loaded with it every time and the enforcer is not cached
+// because the other requests must not see the rules which are not committed
+func (c *EnforcerCache) Enforcer(ctx context.Context, db *gorm.DB, rbacDomain domain.RBACDomain) (*casbin.SyncedCachedEnforcer, error) {
+	_, span := tracer.Start(ctx, "EnforcerCache.Enforcer")
+	defer span.End()
+
+	if inTransaction(db) {
+		e, _, err := c.load(ctx, db, rbacDomain, c.now())
+		if err != nil {
+			return nil, err
+		}
+
+		return e, nil
+	}
+
+	key := rbacDomain.Domain()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	generation := c.generations[key]
+	allGeneration := c.generation
+	c.mu.Unlock()
+	if ok && entry.isFresh(c.now(), c.ttl) {
+		return entry.enforcer, nil
+	}
+
+	loadedAt := c.now()
+	e, expiresAt, err := c.load(ctx, db, rbacDomain, loadedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// the policies may have been changed while loading
+	if c.generations[key] == generation && c.generation == allGeneration {
+		c.entries[key] = &enforcerCacheEntry{enforcer: e, loadedAt: loadedAt, expiresAt: expiresAt}
+	}
+
+	return e, nil
+}
+
+// Rules returns the enforcer holding all the rules of the domain including the ones out of their validity, which is used to read the rules.
+// It is cached and loaded in the same way as Enforcer
+func (c *EnforcerCache) Rules(ctx context.Context, db *gorm.DB, rbacDomain domain.RBACDomain) (*casbin.SyncedEnforcer, error) {
+	_, span := tracer.Start(ctx, "EnforcerCache.Rules")
+	defer span.End()
+
+	if inTransaction(db) {
+		return c.loadRules(db, rbacDomain)
+	}
+
+	key := rbacDomain.Domain()
+
+	c.mu.Lock()
+	entry, ok := c.ruleEntries[key]
+	generation := c.generations[key]
+	allGeneration := c.generation
+	c.mu.Unlock()
+	if ok && entry.isFresh(c.now(), c.ttl) {
+		return entry.enforcer, nil
+	}
+
+	loadedAt := c.now()
+	e, err := c.loadRules(db, rbacDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[key] == generation && c.generation == allGeneration {
+		c.ruleEntries[key] = &ruleCacheEntry{enforcer: e, loadedAt: loadedAt}
+	}
+
+	return e, nil
+}
+
+// Invalidate discards the enforcer of the domain. It must be called whenever the policies of the domain are changed
+func (c *EnforcerCache) Invalidate(rbacDomain domain.RBACDomain) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := rbacDomain.Domain()
+	delete(c.entries, key)
+	delete(c.ruleEntries, key)
+	c.generations[key]++
+}
+
+func (c *EnforcerCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[string]*enforcerCacheEntry)
+	c.ruleEntries = make(map[string]*ruleCacheEntry)
+}
+
+// PolicyChanged is the callback of PolicyWatcher. rbacDomain is "*" when all the domains may be changed
+func (c *EnforcerCache) PolicyChanged(rbacDomain string) {
+	if rbacDomain == allPolicyDomains {
+		c.InvalidateAll()
+		return
+	}
+
+	c.Invalidate(domain.NewRBACDomain(rbacDomain))
+}
+
+// load returns the enforcer which has the rules effective at loadedAt, and when it has to be reloaded because of the validities of the rules
+func (c *EnforcerCache) load(ctx context.Context, db *gorm.DB, rbacDomain domain.RBACDomain, loadedAt time.Time) (*casbin.SyncedCachedEnforcer, *time.Time, error) {
+	m, err := model.NewModelFromString(c.conf)
+	if err != nil {
+		return nil, nil, liberrors.Errorf("model.NewModelFromString. err: %w", err)
+	}
+
+	// the enforcer does not keep the adapter because db may be a transaction
+	e, err := casbin.NewSyncedCachedEnforcer(m)
+	if err != nil {
+		return nil, nil, liberrors.Errorf("casbin.NewSyncedCachedEnforcer. err: %w", err)
+	}
+
+	addRBACFunctions(e)
+
+	a, err := newCasbinAdapter(db)
+	if err != nil {
+		return nil, nil, liberrors.Errorf("newCasbinAdapter. err: %w", err)
+	}
+	inactive, expiresAt, err := findInactiveCasbinRules(ctx, db, rbacDomain, loadedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	a.inactive = inactive
+
+	if err := a.LoadFilteredPolicy(e.GetModel(), domainCasbinRuleFilters(rbacDomain)); err != nil {
+		return nil, nil, liberrors.Errorf("a.LoadFilteredPolicy. err: %w", err)
+	}
+
+	if err := e.BuildRoleLinks(); err != nil {
+		return nil, nil, liberrors.Errorf("e.BuildRoleLinks. err: %w", err)
+	}
+
+	return e, expiresAt, nil
+}
+
+func (c *EnforcerCache) loadRules(db *gorm.DB, rbacDomain domain.RBACDomain) (*casbin.SyncedEnforcer, error) {
+	m, err := model.NewModelFromString(c.conf)
+	if err != nil {
+		return nil, liberrors.Errorf("model.NewModelFromString. err: %w", err)
+	}
+
+	e, err := casbin.NewSyncedEnforcer(m)
+	if err != nil {
+		return nil, liberrors.Errorf("casbin.NewSyncedEnforcer. err: %w", err)
+	}
+
+	a, err := newCasbinAdapter(db)
+	if err != nil {
+		return nil, liberrors.Errorf("newCasbinAdapter. err: %w", err)
+	}
+	if err := a.LoadFilteredPolicy(e.GetModel(), domainCasbinRuleFilters(rbacDomain)); err != nil {
+		return nil, liberrors.Errorf("a.LoadFilteredPolicy. err: %w", err)
+	}
+
+	return e, nil
+}
+
+// changedDomains records the domains whose policies are changed in a transaction
+// so that their enforcers are discarded again after the transaction ends
+type changedDomains struct {
+	mu      sync.Mutex
+	domains map[string]domain.RBACDomain
+}
+
+func (d *changedDomains) add(rbacDomain domain.RBACDomain) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.domains == nil {
+		d.domains = make(map[string]domain.RBACDomain)
+	}
+	d.domains[rbacDomain.Domain()] = rbacDomain
+}
+
+func (d *changedDomains) flush() []domain.RBACDomain {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	domains := make([]domain.RBACDomain, 0, len(d.domains))
+	for _, rbacDomain := range d.domains {
+		domains = append(domains, rbacDomain)
+	}
+	d.domains = nil
+
+	return domains
+}
@@ -0,0 +1,257 @@
+package gateway_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	testlibgateway "github.com/kujilabo/redstart/testlib/gateway"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func newCachedRepositoryFactory(t testing.TB, ctx context.Context, ts testService, enforcerCache *gateway.EnforcerCache) gateway.RepositoryFactoryFunc {
+	t.Helper()
+	return func(ctx context.Context, db *gorm.DB) (service.RepositoryFactory, error) {
+		return gateway.NewRepositoryFactory(ctx, ts.dialect, ts.dialect.Name(), db, loc, enforcerCache)
+	}
+}
+
+func testCachedRepositoryFactory(t testing.TB, ctx context.Context, ts testService) service.RepositoryFactory {
+	t.Helper()
+	rf, err := newCachedRepositoryFactory(t, ctx, ts, gateway.NewEnforcerCache(0))(ctx, ts.db)
+	require.NoError(t, err)
+	return rf
+}
+
+func Test_EnforcerCache_invalidatedWhenPoliciesAreChanged(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESC_1")
+		readAction := domain.NewRBACAction("read")
+		dataObject := domain.NewRBACObject("data")
+
+		enforcerCache := gateway.NewEnforcerCache(0)
+		rf, err := newCachedRepositoryFactory(t, ctx, ts, enforcerCache)(ctx, ts.db)
+		require.NoError(t, err)
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+
+		ok, err := authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// when
+		err = authorizationManager.AddPolicyToGroup(ctx, owner, service.NewRBACUserRole(orgID, group1.UserGroupID()), readAction, dataObject, service.RBACAllowEffect)
+		require.NoError(t, err)
+		err = authorizationManager.AddUserToGroup(ctx, owner, user1.AppUserID(), group1.UserGroupID())
+		require.NoError(t, err)
+		// then
+		ok, err = authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		// when
+		err = authorizationManager.RemoveUserFromGroup(ctx, owner, user1.AppUserID(), group1.UserGroupID())
+		require.NoError(t, err)
+		// then
+		ok, err = authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		// when
+		// - the policy is added without the cache
+		uncachedAuthorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		err = uncachedAuthorizationManager.AddPolicyToUser(ctx, owner, service.NewRBACAppUser(orgID, user1.AppUserID()), readAction, dataObject, service.RBACAllowEffect)
+		require.NoError(t, err)
+		// then
+		// - the cached enforcer does not know the policy
+		ok, err = authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		// when
+		enforcerCache.Invalidate(service.NewRBACOrganization(orgID))
+		// then
+		ok, err = authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_EnforcerCache_invalidatedWhenTransactionIsRolledBack(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		readAction := domain.NewRBACAction("read")
+		dataObject := domain.NewRBACObject("data")
+		errRollback := errors.New("rollback")
+
+		enforcerCache := gateway.NewEnforcerCache(0)
+		rff := newCachedRepositoryFactory(t, ctx, ts, enforcerCache)
+		txManager, err := gateway.NewTransactionManager(ts.db, rff)
+		require.NoError(t, err)
+
+		// when
+		// - the policy is visible in the transaction but the transaction is rolled back
+		err = txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+			authorizationManager := rf.NewAuthorizationManager(ctx)
+			if err := authorizationManager.AddPolicyToUser(ctx, owner, service.NewRBACAppUser(orgID, user1.AppUserID()), readAction, dataObject, service.RBACAllowEffect); err != nil {
+				return err
+			}
+
+			ok, err := authorizationManager.Authorize(ctx, user1, readAction, dataObject)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			// - the policy which is not committed is not visible from the other requests
+			otherRF, err := rff(ctx, ts.db)
+			require.NoError(t, err)
+			ok, err = otherRF.NewAuthorizationManager(ctx).Authorize(ctx, user1, readAction, dataObject)
+			require.NoError(t, err)
+			assert.False(t, ok)
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+
+		// then
+		rf, err := rff(ctx, ts.db)
+		require.NoError(t, err)
+		ok, err := rf.NewAuthorizationManager(ctx).Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_EnforcerCache_expired(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		enforcerCache := gateway.NewEnforcerCache(time.Minute)
+		gateway.SetEnforcerCacheNow(enforcerCache, func() time.Time { return now })
+		rbacDomain := service.NewRBACOrganization(orgID)
+
+		e1, err := enforcerCache.Enforcer(ctx, ts.db, rbacDomain)
+		require.NoError(t, err)
+
+		// when
+		now = now.Add(59 * time.Second)
+		e2, err := enforcerCache.Enforcer(ctx, ts.db, rbacDomain)
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+		e3, err := enforcerCache.Enforcer(ctx, ts.db, rbacDomain)
+		require.NoError(t, err)
+
+		// then
+		assert.Same(t, e1, e2)
+		assert.NotSame(t, e1, e3)
+		// - only the rules of the organization are loaded
+		for _, p := range e3.GetPolicy() {
+			assert.Equal(t, rbacDomain.Domain(), p[4])
+		}
+		assert.NotEmpty(t, e3.GetPolicy())
+	}
+	testOrganization(t, fn)
+}
+
+func Test_EnforcerCache_rules(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - user1 can read doc:1 from tomorrow
+		tomorrow := time.Now().Add(24 * time.Hour)
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		rbacUser1 := service.NewRBACAppUser(orgID, user1.AppUserID())
+		readAction := domain.NewRBACAction("read")
+		doc1 := domain.NewRBACObject(service.NewRBACOrganization(orgID).Domain() + "_doc:1")
+		doc2 := domain.NewRBACObject(service.NewRBACOrganization(orgID).Domain() + "_doc:2")
+
+		enforcerCache := gateway.NewEnforcerCache(0)
+		rf, err := newCachedRepositoryFactory(t, ctx, ts, enforcerCache)(ctx, ts.db)
+		require.NoError(t, err)
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		require.NoError(t, authorizationManager.AddTimeBoundPolicy(ctx, owner, rbacUser1, readAction, doc1, service.RBACAllowEffect, testRBACValidity(t, &tomorrow, nil)))
+
+		// when
+		policies, err := authorizationManager.ListPoliciesForSubject(ctx, owner, rbacUser1)
+		// then
+		// - the policy out of its validity is listed
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		assert.Equal(t, doc1.Object(), policies[0].Object.Object())
+
+		// when
+		// - the policy is added without the cache
+		uncachedAuthorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, uncachedAuthorizationManager.AddPolicyToUser(ctx, owner, rbacUser1, readAction, doc2, service.RBACAllowEffect))
+		policies, err = authorizationManager.ListPoliciesForSubject(ctx, owner, rbacUser1)
+		// then
+		// - the cached rules do not have the policy
+		require.NoError(t, err)
+		assert.Len(t, policies, 1)
+
+		// when
+		enforcerCache.Invalidate(service.NewRBACOrganization(orgID))
+		policies, err = authorizationManager.ListPoliciesForSubject(ctx, owner, rbacUser1)
+		// then
+		require.NoError(t, err)
+		assert.Len(t, policies, 2)
+	}
+	testOrganization(t, fn)
+}
+
+// BenchmarkAuthorize compares Authorize which loads the policies every time with the one using EnforcerCache
+func BenchmarkAuthorize(b *testing.B) {
+	ctx := context.Background()
+	for dialect, db := range testlibgateway.ListDB() {
+		dialect := dialect
+		db := db
+		rf, err := gateway.NewRepositoryFactory(ctx, dialect, dialect.Name(), db, loc, nil)
+		require.NoError(b, err)
+		ts := testService{dialect: dialect, db: db, rf: rf}
+
+		orgID, _, owner := setupOrganization(ctx, b, ts)
+		defer teardownOrganization(b, ts, orgID)
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		readAction := domain.NewRBACAction("read")
+		users := make([]*service.AppUser, 0)
+		for i := 0; i < 10; i++ {
+			group := testAddUserGroup(b, ctx, ts, owner, fmt.Sprintf("GROUP_KEY_%d", i), fmt.Sprintf("GROUP_NAME_%d", i), "")
+			user := testAddAppUser(b, ctx, ts, owner, fmt.Sprintf("LOGIN_ID_%d", i), fmt.Sprintf("USERNAME_%d", i), "PASSWORD")
+			require.NoError(b, authorizationManager.AddUserToGroup(ctx, owner, user.AppUserID(), group.UserGroupID()))
+			require.NoError(b, authorizationManager.AddPolicyToGroup(ctx, owner, service.NewRBACUserRole(orgID, group.UserGroupID()), readAction, domain.NewRBACObject(fmt.Sprintf("data_%d", i)), service.RBACAllowEffect))
+			users = append(users, user)
+		}
+
+		cachedRF, err := gateway.NewRepositoryFactory(ctx, dialect, dialect.Name(), db, loc, gateway.NewEnforcerCache(0))
+		require.NoError(b, err)
+
+		for name, m := range map[string]service.AuthorizationManager{
+			"uncached": authorizationManager,
+			"cached":   cachedRF.NewAuthorizationManager(ctx),
+		} {
+			m := m
+			b.Run(dialect.Name()+"/"+name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					user := users[i%len(users)]
+					if _, err := m.Authorize(ctx, user, readAction, domain.NewRBACObject(fmt.Sprintf("data_%d", i%len(users)))); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -1,8 +1,46 @@
 package gateway
 
+import (
+	"context"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/persist"
+	"gorm.io/gorm"
+
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
 type RBACRepository = rbacRepository
 
 var NewRBACRepository = newRBACRepository
 var Conf = conf
+var AddRBACFunctions = addRBACFunctions
+var BumpPolicyRevision = bumpPolicyRevision
+
+func NewCasbinAdapter(db *gorm.DB) (persist.Adapter, error) {
+	return newCasbinAdapter(db)
+}
+
+func InitRBACEnforcer(ctx context.Context, r service.RBACRepository, rbacDomain domain.RBACDomain) (*casbin.Enforcer, error) {
+	return r.(*rbacRepository).initEnforcer(ctx, rbacDomain)
+}
 
 type OrganizationEntity = organizationEntity
+
+func SetAuthTokenManagerNow(m interface{}, now func() time.Time) {
+	m.(*authTokenManager).now = now
+}
+
+func SetEnforcerCacheNow(c *EnforcerCache, now func() time.Time) {
+	c.now = now
+}
+
+func SetAuthorizationManagerNow(m interface{}, now func() time.Time) {
+	m.(*authorizationManager).now = now
+}
+
+func SetAuditLogRepositoryNow(r interface{}, now func() time.Time) {
+	r.(*auditLogRepository).now = now
+}
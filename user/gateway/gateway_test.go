@@ -56,7 +56,7 @@ func testDB(t *testing.T, fn func(t *testing.T, ctx context.Context, ts testServ
 			require.NoError(t, err)
 			defer sqlDB.Close()
 
-			rf, err := gateway.NewRepositoryFactory(ctx, dialect, dialect.Name(), db, loc)
+			rf, err := gateway.NewRepositoryFactory(ctx, dialect, dialect.Name(), db, loc, nil)
 			require.NoError(t, err)
 			testService := testService{dialect: dialect, db: db, rf: rf}
 
@@ -75,7 +75,7 @@ func testOrganization(t *testing.T, fn func(t *testing.T, ctx context.Context, t
 	})
 }
 
-func setupOrganization(ctx context.Context, t *testing.T, ts testService) (*domain.OrganizationID, *service.SystemOwner, *service.Owner) {
+func setupOrganization(ctx context.Context, t testing.TB, ts testService) (*domain.OrganizationID, *service.SystemOwner, *service.Owner) {
 	orgName := RandString(orgNameLength)
 	sysAd, err := service.NewSystemAdmin(ctx, ts.rf)
 	require.NoError(t, err)
@@ -151,9 +151,10 @@ func setupOrganization(ctx context.Context, t *testing.T, ts testService) (*doma
 	return orgID, sysOwner, owner
 }
 
-func teardownOrganization(t *testing.T, ts testService, orgID *domain.OrganizationID) {
+func teardownOrganization(t testing.TB, ts testService, orgID *domain.OrganizationID) {
 	// delete all organizations
 	// ts.db.Exec("delete from space where organization_id = ?", orgID.Int())
+	ts.db.Exec("delete from audit_event where organization_id = ?", orgID.Int())
 	ts.db.Exec("delete from app_user where organization_id = ?", orgID.Int())
 	ts.db.Exec("delete from organization where id = ?", orgID.Int())
 	// db.Where("true").Delete(&spaceEntity{})
@@ -161,7 +162,7 @@ func teardownOrganization(t *testing.T, ts testService, orgID *domain.Organizati
 	// db.Where("true").Delete(&organizationEntity{})
 }
 
-func testAddAppUser(t *testing.T, ctx context.Context, ts testService, owner service.OwnerModelInterface, loginID, username, password string) *service.AppUser {
+func testAddAppUser(t testing.TB, ctx context.Context, ts testService, owner service.OwnerModelInterface, loginID, username, password string) *service.AppUser {
 	appUserRepo := ts.rf.NewAppUserRepository(ctx)
 	userID1, err := appUserRepo.AddAppUser(ctx, owner, testNewAppUserAddParameter(t, loginID, username, password))
 	require.NoError(t, err)
@@ -172,7 +173,7 @@ func testAddAppUser(t *testing.T, ctx context.Context, ts testService, owner ser
 	return user1
 }
 
-func testAddUserGroup(t *testing.T, ctx context.Context, ts testService, owner service.OwnerModelInterface, key, name, description string) *service.UserGroup {
+func testAddUserGroup(t testing.TB, ctx context.Context, ts testService, owner service.OwnerModelInterface, key, name, description string) *service.UserGroup {
 	userGorupRepo := ts.rf.NewUserGroupRepository(ctx)
 	groupID1, err := userGorupRepo.AddUserGroup(ctx, owner, testNewUserGroupAddParameter(t, key, name, description))
 	require.NoError(t, err)
@@ -249,13 +250,13 @@ func testNewUserGroups(userGroupModels []*domain.UserGroupModel) []*testUserGrou
 	return groups
 }
 
-func testNewAppUserAddParameter(t *testing.T, loginID, username, password string) *service.AppUserAddParameter {
+func testNewAppUserAddParameter(t testing.TB, loginID, username, password string) *service.AppUserAddParameter {
 	p, err := service.NewAppUserAddParameter(loginID, username, password, "", "", "", "")
 	require.NoError(t, err)
 	return p
 }
 
-func testNewUserGroupAddParameter(t *testing.T, key, name, description string) *service.UserGroupAddParameter {
+func testNewUserGroupAddParameter(t testing.TB, key, name, description string) *service.UserGroupAddParameter {
 	p, err := service.NewUserGroupAddParameter(key, name, description)
 	require.NoError(t, err)
 	return p
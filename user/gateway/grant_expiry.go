@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gormadapter "github.com/casbin/gorm-adapter/v3"
+	"gorm.io/gorm"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	liblog "github.com/kujilabo/redstart/lib/log"
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+// ExpireGrants removes the rules whose validity has ended at now, together with the memberships of user_n_group of the "g" rules.
+// The removed rules are recorded in rbac_expired_grant
+func ExpireGrants(ctx context.Context, db *gorm.DB, now time.Time) ([]*domain.RBACExpiredGrant, error) {
+	ctx, span := tracer.Start(ctx, "ExpireGrants")
+	defer span.End()
+
+	now = now.UTC()
+	expired := make([]*domain.RBACExpiredGrant, 0)
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		validities := make([]casbinRuleValidityEntity, 0)
+		if err := tx.WithContext(ctx).Where("valid_until <= ?", now).Order("domain, ptype, v0, v1, v2, v3, v4").Find(&validities).Error; err != nil {
+			return liberrors.Errorf("select %s. err: %w", CasbinRuleValidityTableName, err)
+		}
+
+		domains := make(map[string]bool)
+		for i := range validities {
+			v := &validities[i]
+			if err := expireCasbinRule(ctx, tx, v); err != nil {
+				return err
+			}
+
+			grant := rbacExpiredGrantEntity{
+				ExpiredAt:  now,
+				Domain:     v.Domain,
+				Ptype:      v.Ptype,
+				V0:         v.V0,
+				V1:         v.V1,
+				V2:         v.V2,
+				V3:         v.V3,
+				V4:         v.V4,
+				ValidFrom:  v.ValidFrom,
+				ValidUntil: *v.ValidUntil,
+			}
+			if err := tx.WithContext(ctx).Create(&grant).Error; err != nil {
+				return liberrors.Errorf("insert %s. err: %w", RBACExpiredGrantTableName, err)
+			}
+
+			expired = append(expired, grant.toModel())
+			domains[v.Domain] = true
+		}
+
+		for rbacDomain := range domains {
+			if err := bumpPolicyRevision(ctx, tx, rbacDomain); err != nil {
+				return liberrors.Errorf("bumpPolicyRevision. err: %w", err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return expired, nil
+}
+
+func expireCasbinRule(ctx context.Context, tx *gorm.DB, v *casbinRuleValidityEntity) error {
+	// the "p" rule is removed whatever its condition is
+	if err := tx.WithContext(ctx).
+		Where("ptype = ? and v0 = ? and v1 = ? and v2 = ? and v3 = ? and v4 = ?", v.Ptype, v.V0, v.V1, v.V2, v.V3, v.V4).
+		Delete(&gormadapter.CasbinRule{}).Error; err != nil {
+		return liberrors.Errorf("delete casbin_rule. err: %w", err)
+	}
+
+	// the membership of the user group ends with its "g" rule
+	if v.Ptype == "g" {
+		appUserID, userOK := parseRBACAppUser(v.V0)
+		organizationID, userGroupID, roleOK := parseRBACUserRole(v.V1)
+		if userOK && roleOK && userGroupID != nil {
+			if err := tx.WithContext(ctx).
+				Where("organization_id = ? and app_user_id = ? and user_group_id = ?", organizationID, appUserID.Int(), userGroupID.Int()).
+				Delete(&pairOfUserAndGroupEntity{}).Error; err != nil {
+				return liberrors.Errorf("delete %s. err: %w", PairOfUserAndGroupTableName, err)
+			}
+		}
+	}
+
+	if err := tx.WithContext(ctx).
+		Where("ptype = ? and v0 = ? and v1 = ? and v2 = ? and v3 = ? and v4 = ?", v.Ptype, v.V0, v.V1, v.V2, v.V3, v.V4).
+		Delete(&casbinRuleValidityEntity{}).Error; err != nil {
+		return liberrors.Errorf("delete %s. err: %w", CasbinRuleValidityTableName, err)
+	}
+
+	return nil
+}
+
+// GrantExpiryProcess calls ExpireGrants every interval until ctx is done. It is run alongside MetricsServerProcess
+func GrantExpiryProcess(ctx context.Context, db *gorm.DB, interval time.Duration) error {
+	if interval <= 0 {
+		return liberrors.Errorf("interval must be positive. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	logger := liblog.GetLoggerFromContext(ctx, UserGatewayContextKey)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		expired, err := ExpireGrants(ctx, db, time.Now())
+		if err != nil {
+			logger.WarnContext(ctx, fmt.Sprintf("failed to expire the grants. err: %+v", err))
+			continue
+		}
+		for _, grant := range expired {
+			logger.InfoContext(ctx, fmt.Sprintf("grant expired. rule: %s, validUntil: %s", grant.Line(), grant.ValidUntil.Format(time.RFC3339)))
+		}
+	}
+}
+
+// findExpiredGrants returns the grants of the domain expired at or after since, the latest first
+func findExpiredGrants(ctx context.Context, db *gorm.DB, rbacDomain domain.RBACDomain, since time.Time, limit int) ([]*domain.RBACExpiredGrant, error) {
+	entities := make([]rbacExpiredGrantEntity, 0)
+	if err := db.WithContext(ctx).
+		Where("domain = ? and expired_at >= ?", rbacDomain.Domain(), since.UTC()).
+		Order("expired_at desc, id desc").
+		Limit(limit).
+		Find(&entities).Error; err != nil {
+		return nil, liberrors.Errorf("select %s. err: %w", RBACExpiredGrantTableName, err)
+	}
+
+	grants := make([]*domain.RBACExpiredGrant, len(entities))
+	for i := range entities {
+		grants[i] = entities[i].toModel()
+	}
+
+	return grants, nil
+}
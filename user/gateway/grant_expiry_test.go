@@ -0,0 +1,168 @@
+package gateway_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func testRBACValidity(t *testing.T, from, until *time.Time) *domain.RBACValidity {
+	t.Helper()
+	validity, err := domain.NewRBACValidity(from, until)
+	require.NoError(t, err)
+	return validity
+}
+
+func Test_authorizationManager_AddTimeBoundPolicy(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - user1 could read doc:1 until an hour ago, can read doc:2 now and will be able to read doc:3 in an hour
+		now := time.Now()
+		anHourAgo := now.Add(-time.Hour)
+		inAnHour := now.Add(time.Hour)
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		rbacUser1 := service.NewRBACAppUser(orgID, user1.AppUserID())
+		objectPrefix := service.NewRBACOrganization(orgID).Domain() + "_doc:"
+		doc1 := domain.NewRBACObject(objectPrefix + "1")
+		doc2 := domain.NewRBACObject(objectPrefix + "2")
+		doc3 := domain.NewRBACObject(objectPrefix + "3")
+		readAction := domain.NewRBACAction("read")
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, authorizationManager.AddTimeBoundPolicy(ctx, owner, rbacUser1, readAction, doc1, service.RBACAllowEffect, testRBACValidity(t, nil, &anHourAgo)))
+		require.NoError(t, authorizationManager.AddTimeBoundPolicy(ctx, owner, rbacUser1, readAction, doc2, service.RBACAllowEffect, testRBACValidity(t, &anHourAgo, &inAnHour)))
+		require.NoError(t, authorizationManager.AddTimeBoundPolicy(ctx, owner, rbacUser1, readAction, doc3, service.RBACAllowEffect, testRBACValidity(t, &inAnHour, nil)))
+
+		enforcerCache := gateway.NewEnforcerCache(0)
+		cachedRF, err := newCachedRepositoryFactory(t, ctx, ts, enforcerCache)(ctx, ts.db)
+		require.NoError(t, err)
+		cachedNow := now
+		gateway.SetEnforcerCacheNow(enforcerCache, func() time.Time { return cachedNow })
+
+		for name, m := range map[string]service.AuthorizationManager{
+			"uncached": authorizationManager,
+			"cached":   cachedRF.NewAuthorizationManager(ctx),
+		} {
+			// when
+			results, err := m.AuthorizeBatch(ctx, user1, []service.AuthRequest{
+				{Action: readAction, Object: doc1},
+				{Action: readAction, Object: doc2},
+				{Action: readAction, Object: doc3},
+			})
+			// then
+			require.NoError(t, err, name)
+			assert.Equal(t, []bool{false, true, false}, results, name)
+		}
+
+		// when
+		// - the cached enforcer is reloaded when any of the validities starts or ends
+		cachedNow = inAnHour
+		results, err := cachedRF.NewAuthorizationManager(ctx).AuthorizeBatch(ctx, user1, []service.AuthRequest{
+			{Action: readAction, Object: doc2},
+			{Action: readAction, Object: doc3},
+		})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []bool{false, true}, results)
+
+		// when
+		// - the policy which does not exist cannot have a validity
+		rbacRepo := gateway.NewRBACRepository(ctx, ts.db)
+		err = rbacRepo.SetPolicyValidity(ctx, service.NewRBACOrganization(orgID), rbacUser1, domain.NewRBACAction("write"), doc1, service.RBACAllowEffect, testRBACValidity(t, nil, &inAnHour))
+		// then
+		assert.ErrorIs(t, err, service.ErrRBACPolicyNotFound)
+
+		// when
+		// - nil validity makes the policy permanent
+		require.NoError(t, authorizationManager.AddTimeBoundPolicy(ctx, owner, rbacUser1, readAction, doc1, service.RBACAllowEffect, nil))
+		ok, err := authorizationManager.Authorize(ctx, user1, readAction, doc1)
+		// then
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_ExpireGrants(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - user1 belongs to group1 and can read doc:1 for an hour
+		now := time.Now()
+		inAnHour := now.Add(time.Hour)
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESCRIPTION_1")
+		rbacUser1 := service.NewRBACAppUser(orgID, user1.AppUserID())
+		rbacGroup1 := service.NewRBACUserRole(orgID, group1.UserGroupID())
+		objectPrefix := service.NewRBACOrganization(orgID).Domain() + "_doc:"
+		doc1 := domain.NewRBACObject(objectPrefix + "1")
+		doc2 := domain.NewRBACObject(objectPrefix + "2")
+		readAction := domain.NewRBACAction("read")
+
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		pairOfUserAndGroupRepo := gateway.NewPairOfUserAndGroupRepository(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, authorizationManager.AddPolicyToGroup(ctx, owner, rbacGroup1, readAction, doc2, service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddUserToGroupWithValidity(ctx, owner, user1.AppUserID(), group1.UserGroupID(), testRBACValidity(t, nil, &inAnHour)))
+		require.NoError(t, authorizationManager.AddTimeBoundPolicy(ctx, owner, rbacUser1, readAction, doc1, service.RBACAllowEffect, testRBACValidity(t, nil, &inAnHour)))
+
+		results, err := authorizationManager.AuthorizeBatch(ctx, user1, []service.AuthRequest{{Action: readAction, Object: doc1}, {Action: readAction, Object: doc2}})
+		require.NoError(t, err)
+		require.Equal(t, []bool{true, true}, results)
+
+		// when
+		// - the validity has not ended yet
+		expired, err := gateway.ExpireGrants(ctx, ts.db, now)
+		// then
+		require.NoError(t, err)
+		for _, grant := range expired {
+			assert.NotContains(t, grant.Line(), service.NewRBACOrganization(orgID).Domain())
+		}
+
+		// when
+		_, err = gateway.ExpireGrants(ctx, ts.db, inAnHour)
+		// then
+		// - the rules and the membership are removed
+		require.NoError(t, err)
+		results, err = authorizationManager.AuthorizeBatch(ctx, user1, []service.AuthRequest{{Action: readAction, Object: doc1}, {Action: readAction, Object: doc2}})
+		require.NoError(t, err)
+		assert.Equal(t, []bool{false, false}, results)
+		userGroups, err := pairOfUserAndGroupRepo.FindUserGroupsByUserID(ctx, user1, user1.AppUserID())
+		require.NoError(t, err)
+		assert.Len(t, userGroups, 0)
+
+		// - the expired grants are recorded
+		grants, err := authorizationManager.ListExpiredGrants(ctx, owner, now, 10)
+		require.NoError(t, err)
+		lines := make([]string, len(grants))
+		for i, grant := range grants {
+			lines[i] = grant.Line()
+			assert.Equal(t, inAnHour.Unix(), grant.ValidUntil.Unix())
+		}
+		assert.ElementsMatch(t, []string{
+			domain.NewRBACGroupingRule("g", rbacUser1.Subject(), rbacGroup1.Role(), service.NewRBACOrganization(orgID)).Line(),
+			"p, " + rbacUser1.Subject() + ", " + doc1.Object() + ", read, allow, " + service.NewRBACOrganization(orgID).Domain(),
+		}, lines)
+
+		// when
+		// - the membership of the user who does not belong to the group cannot have a validity
+		err = pairOfUserAndGroupRepo.SetPairOfUserAndGroupValidity(ctx, owner, user1.AppUserID(), group1.UserGroupID(), testRBACValidity(t, nil, &inAnHour))
+		// then
+		assert.ErrorIs(t, err, service.ErrPairOfUserAndGroupNotFound)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_GrantExpiryProcess_invalidInterval(t *testing.T) {
+	t.Parallel()
+	err := gateway.GrantExpiryProcess(context.Background(), nil, 0)
+	assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+}
@@ -2,8 +2,10 @@ package gateway_test
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
 
@@ -50,16 +52,25 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
-	fns := []func() (*gorm.DB, error){
-		func() (*gorm.DB, error) {
+	initDBFuncs := map[string]func() (*gorm.DB, error){
+		"mysql": func() (*gorm.DB, error) {
 			return testlibgateway.InitMySQL(sqls.SQL, mysqlHost, mysqlPort)
 		},
-		func() (*gorm.DB, error) {
+		"postgres": func() (*gorm.DB, error) {
 			return testlibgateway.InitPostgres(sqls.SQL, postgresHost, postgresPort)
 		},
-		// func() (*gorm.DB, error) {
-		// 	return testlibgateway.InitSQLiteInFile(sqls.SQL)
-		// },
+		"sqlite3": func() (*gorm.DB, error) {
+			return testlibgateway.InitSQLiteInFile(sqls.SQL)
+		},
+	}
+
+	fns := make([]func() (*gorm.DB, error), 0)
+	for _, driverName := range strings.Split(getEnv("TEST_DB_DRIVERS", "mysql,postgres,sqlite3"), ",") {
+		fn, ok := initDBFuncs[strings.TrimSpace(driverName)]
+		if !ok {
+			panic(fmt.Sprintf("unsupported driver name: %s", driverName))
+		}
+		fns = append(fns, fn)
 	}
 
 	for _, fn := range fns {
@@ -78,7 +89,7 @@ func init() {
 	for dialect, db := range testlibgateway.ListDB() {
 		dialect := dialect
 		db := db
-		rf, err := gateway.NewRepositoryFactory(ctx, dialect, dialect.Name(), db, loc)
+		rf, err := gateway.NewRepositoryFactory(ctx, dialect, dialect.Name(), db, loc, nil)
 		if err != nil {
 			panic(err)
 		}
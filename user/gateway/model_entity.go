@@ -1,8 +1,11 @@
 package gateway
 
 import (
+	"fmt"
 	"time"
 
+	"gorm.io/gorm"
+
 	libdomain "github.com/kujilabo/redstart/lib/domain"
 	liberrors "github.com/kujilabo/redstart/lib/errors"
 )
@@ -24,6 +27,30 @@ func (e *BaseModelEntity) toBaseModel() (*libdomain.BaseModel, error) {
 	return model, nil
 }
 
+// updateWithVersion updates the record selected by scope if its version is the specified one.
+// The version is incremented by the audit columns plugin.
+// notFoundErr is returned when scope selects no record, libdomain.ErrConcurrentModification when the version is different
+func updateWithVersion(db *gorm.DB, table HasTableName, scope func(*gorm.DB) *gorm.DB, version int, values map[string]interface{}, notFoundErr error) error {
+	result := db.Model(table).Scopes(scope).Where(fmt.Sprintf("%s.version = ?", table.TableName()), version).Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	// the version is always changed, so no affected rows means that the record does not exist or its version is different
+	var count int64
+	if result := db.Model(table).Scopes(scope).Count(&count); result.Error != nil {
+		return result.Error
+	}
+	if count == 0 {
+		return notFoundErr
+	}
+
+	return liberrors.Errorf("version: %d, err: %w", version, libdomain.ErrConcurrentModification)
+}
+
 type JunctionModelEntity struct {
 	CreatedAt time.Time
 	CreatedBy int
@@ -0,0 +1,361 @@
+package gateway
+
+import (
+	"context"
+	"sort"
+
+	"gorm.io/gorm"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type objectHierarchy struct {
+	dialect libgateway.DialectRDBMS
+	db      *gorm.DB
+	rf      service.RepositoryFactory
+}
+
+func NewObjectHierarchy(ctx context.Context, dialect libgateway.DialectRDBMS, db *gorm.DB, rf service.RepositoryFactory) service.ObjectHierarchy {
+	return &objectHierarchy{
+		dialect: dialect,
+		db:      db,
+		rf:      rf,
+	}
+}
+
+func (h *objectHierarchy) AddChildObject(ctx context.Context, operator service.AppUserInterface, parent domain.RBACObject, child domain.RBACObject) error {
+	ctx, span := tracer.Start(ctx, "objectHierarchy.AddChildObject")
+	defer span.End()
+
+	if err := h.authorizeLink(ctx, operator, service.RBACSetAction, parent, child); err != nil {
+		return err
+	}
+
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		validator := newRBACReferenceValidator(ctx, h.dialect, tx, h.rf, operator)
+		if err := validator.validateObject(parent.Object()); err != nil {
+			return err
+		}
+		if err := validator.validateObject(child.Object()); err != nil {
+			return err
+		}
+
+		rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+		rbacRepo := newRBACRepositoryOfFactory(ctx, tx, h.rf)
+		graph, err := h.findObjectGraph(ctx, rbacRepo, rbacDomain)
+		if err != nil {
+			return err
+		}
+
+		if graph.hasLink(child.Object(), parent.Object()) {
+			return nil
+		}
+		if parent.Object() == child.Object() || graph.isAncestor(child.Object(), parent.Object()) {
+			return liberrors.Errorf("child: %s, parent: %s, err: %w", child.Object(), parent.Object(), service.ErrObjectHierarchyCycle)
+		}
+		if graph.linksToRoot(parent.Object())+1+graph.linksToLeaf(child.Object()) > service.MaxObjectHierarchyDepth {
+			return liberrors.Errorf("child: %s, parent: %s, err: %w", child.Object(), parent.Object(), service.ErrObjectHierarchyTooDeep)
+		}
+
+		// child-object inherits the policies on parent-object
+		if err := rbacRepo.AddObjectGroupingPolicy(ctx, rbacDomain, child, parent); err != nil {
+			return liberrors.Errorf("rbacRepo.AddObjectGroupingPolicy. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (h *objectHierarchy) RemoveChildObject(ctx context.Context, operator service.AppUserInterface, parent domain.RBACObject, child domain.RBACObject) error {
+	ctx, span := tracer.Start(ctx, "objectHierarchy.RemoveChildObject")
+	defer span.End()
+
+	if err := h.authorizeLink(ctx, operator, service.RBACUnsetAction, parent, child); err != nil {
+		return err
+	}
+
+	return h.db.Transaction(func(tx *gorm.DB) error {
+		rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+		rbacRepo := newRBACRepositoryOfFactory(ctx, tx, h.rf)
+		graph, err := h.findObjectGraph(ctx, rbacRepo, rbacDomain)
+		if err != nil {
+			return err
+		}
+
+		if !graph.hasLink(child.Object(), parent.Object()) {
+			return liberrors.Errorf("child: %s, parent: %s, err: %w", child.Object(), parent.Object(), service.ErrPairOfObjectsNotFound)
+		}
+
+		if err := rbacRepo.RemoveObjectGroupingPolicy(ctx, rbacDomain, child, parent); err != nil {
+			return liberrors.Errorf("rbacRepo.RemoveObjectGroupingPolicy. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// authorizeLink checks that the operator can change both objects.
+// The child inherits all the policies on the parent, so the permission on the parent alone is not enough
+func (h *objectHierarchy) authorizeLink(ctx context.Context, operator service.AppUserInterface, action domain.RBACAction, parent domain.RBACObject, child domain.RBACObject) error {
+	authorizationManager := h.rf.NewAuthorizationManager(ctx)
+	results, err := authorizationManager.AuthorizeBatch(ctx, operator, []service.AuthRequest{
+		{Action: action, Object: parent},
+		{Action: action, Object: child},
+	})
+	if err != nil {
+		return liberrors.Errorf("authorizationManager.AuthorizeBatch. err: %w", err)
+	}
+	if !results[0] || !results[1] {
+		return liberrors.Errorf("operator cannot %s the object hierarchy. child: %s, parent: %s, err: %w", action.Action(), child.Object(), parent.Object(), libdomain.ErrPermissionDenied)
+	}
+
+	return nil
+}
+
+func (h *objectHierarchy) FindParentObjects(ctx context.Context, operator service.AppUserInterface, object domain.RBACObject) ([]domain.RBACObject, error) {
+	ctx, span := tracer.Start(ctx, "objectHierarchy.FindParentObjects")
+	defer span.End()
+
+	graph, err := h.findObjectGraphOfOperator(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	return toRBACObjects(graph.parents[object.Object()]), nil
+}
+
+func (h *objectHierarchy) FindChildObjects(ctx context.Context, operator service.AppUserInterface, object domain.RBACObject) ([]domain.RBACObject, error) {
+	ctx, span := tracer.Start(ctx, "objectHierarchy.FindChildObjects")
+	defer span.End()
+
+	graph, err := h.findObjectGraphOfOperator(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	return toRBACObjects(graph.children[object.Object()]), nil
+}
+
+func (h *objectHierarchy) FindAncestorObjects(ctx context.Context, operator service.AppUserInterface, object domain.RBACObject) ([]domain.RBACObject, error) {
+	ctx, span := tracer.Start(ctx, "objectHierarchy.FindAncestorObjects")
+	defer span.End()
+
+	graph, err := h.findObjectGraphOfOperator(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	return toRBACObjects(graph.ancestors(object.Object())), nil
+}
+
+func (h *objectHierarchy) FindDescendantObjects(ctx context.Context, operator service.AppUserInterface, object domain.RBACObject) ([]domain.RBACObject, error) {
+	ctx, span := tracer.Start(ctx, "objectHierarchy.FindDescendantObjects")
+	defer span.End()
+
+	graph, err := h.findObjectGraphOfOperator(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+
+	return toRBACObjects(graph.descendants(object.Object())), nil
+}
+
+func (h *objectHierarchy) findObjectGraphOfOperator(ctx context.Context, operator service.AppUserInterface) (*objectGraph, error) {
+	rbacDomain := service.NewRBACOrganization(operator.OrganizationID())
+	return h.findObjectGraph(ctx, newRBACRepositoryOfFactory(ctx, h.db, h.rf), rbacDomain)
+}
+
+func (h *objectHierarchy) findObjectGraph(ctx context.Context, rbacRepo service.RBACRepository, rbacDomain domain.RBACDomain) (*objectGraph, error) {
+	rules, err := rbacRepo.FindGroupingRules(ctx, rbacDomain)
+	if err != nil {
+		return nil, liberrors.Errorf("rbacRepo.FindGroupingRules. err: %w", err)
+	}
+
+	links := make([][]string, 0, len(rules))
+	for _, r := range rules {
+		if r.PType == "g2" {
+			links = append(links, []string{r.Child, r.Parent})
+		}
+	}
+
+	return newObjectGraph(links), nil
+}
+
+func toRBACObjects(objects []string) []domain.RBACObject {
+	rbacObjects := make([]domain.RBACObject, len(objects))
+	for i, object := range objects {
+		rbacObjects[i] = domain.NewRBACObject(object)
+	}
+
+	return rbacObjects
+}
+
+// objectGraph is the links of "g2" rules (child, parent, ...)
+type objectGraph struct {
+	parents  map[string][]string
+	children map[string][]string
+}
+
+func newObjectGraph(links [][]string) *objectGraph {
+	g := objectGraph{
+		parents:  make(map[string][]string),
+		children: make(map[string][]string),
+	}
+	for _, link := range links {
+		if len(link) < 2 {
+			continue
+		}
+		g.parents[link[0]] = append(g.parents[link[0]], link[1])
+		g.children[link[1]] = append(g.children[link[1]], link[0])
+	}
+	for _, objects := range g.parents {
+		sort.Strings(objects)
+	}
+	for _, objects := range g.children {
+		sort.Strings(objects)
+	}
+
+	return &g
+}
+
+// validate checks the rules which are not added by AddChildObject, e.g. the imported ones
+func (g *objectGraph) validate() error {
+	// 0: not visited, 1: on the current path, 2: done
+	states := make(map[string]int)
+	var visit func(object string) error
+	visit = func(object string) error {
+		states[object] = 1
+		for _, p := range g.parents[object] {
+			switch states[p] {
+			case 1:
+				return liberrors.Errorf("child: %s, parent: %s, err: %w", object, p, service.ErrObjectHierarchyCycle)
+			case 0:
+				if err := visit(p); err != nil {
+					return err
+				}
+			}
+		}
+		states[object] = 2
+		return nil
+	}
+
+	objects := make([]string, 0, len(g.parents))
+	for object := range g.parents {
+		objects = append(objects, object)
+	}
+	sort.Strings(objects)
+	for _, object := range objects {
+		if states[object] == 0 {
+			if err := visit(object); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, object := range objects {
+		if g.linksToRoot(object) > service.MaxObjectHierarchyDepth {
+			return liberrors.Errorf("object: %s, err: %w", object, service.ErrObjectHierarchyTooDeep)
+		}
+	}
+
+	return nil
+}
+
+func (g *objectGraph) hasLink(child, parent string) bool {
+	for _, p := range g.parents[child] {
+		if p == parent {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isAncestor returns true when ancestor is reachable from object by following child-to-parent links
+func (g *objectGraph) isAncestor(ancestor, object string) bool {
+	for _, a := range g.ancestors(object) {
+		if a == ancestor {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *objectGraph) ancestors(objects ...string) []string {
+	return walkObjectGraph(g.parents, objects)
+}
+
+func (g *objectGraph) descendants(objects ...string) []string {
+	return walkObjectGraph(g.children, objects)
+}
+
+// linksToRoot returns the number of the links on the longest path from the object to its root
+func (g *objectGraph) linksToRoot(object string) int {
+	return longestObjectPath(g.parents, object, make(map[string]int))
+}
+
+// linksToLeaf returns the number of the links on the longest path from the object to its leaf
+func (g *objectGraph) linksToLeaf(object string) int {
+	return longestObjectPath(g.children, object, make(map[string]int))
+}
+
+// walkObjectGraph returns the objects reachable from the objects in breadth-first order, excluding the objects themselves
+func walkObjectGraph(next map[string][]string, objects []string) []string {
+	visited := make(map[string]bool)
+	for _, object := range objects {
+		visited[object] = true
+	}
+
+	reached := make([]string, 0)
+	queue := append([]string{}, objects...)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, n := range next[current] {
+			if !visited[n] {
+				visited[n] = true
+				reached = append(reached, n)
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	return reached
+}
+
+// longestObjectPath expects the graph has no cycle, which is guaranteed by AddChildObject
+func longestObjectPath(next map[string][]string, object string, memo map[string]int) int {
+	if length, ok := memo[object]; ok {
+		return length
+	}
+
+	// guards against the cycles made by the rules added without AddChildObject
+	memo[object] = 0
+	length := 0
+	for _, n := range next[object] {
+		if l := longestObjectPath(next, n, memo) + 1; l > length {
+			length = l
+		}
+	}
+	memo[object] = length
+
+	return length
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+
+	return unique
+}
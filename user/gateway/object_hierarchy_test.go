@@ -0,0 +1,264 @@
+package gateway_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func Test_objectHierarchy_AddChildObject(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - workspace:1 -> folder:1 -> doc:1, doc:2
+		// - workspace:1 -> folder:2 -> doc:2
+		testAllowObjectHierarchy(t, ctx, ts, orgID, owner, domain.NewRBACObject("*"))
+		objectHierarchy := gateway.NewObjectHierarchy(ctx, ts.dialect, ts.db, ts.rf)
+		workspace1 := domain.NewRBACObject("workspace:1")
+		folder1 := domain.NewRBACObject("folder:1")
+		folder2 := domain.NewRBACObject("folder:2")
+		doc1 := domain.NewRBACObject("doc:1")
+		doc2 := domain.NewRBACObject("doc:2")
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, workspace1, folder1))
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, workspace1, folder2))
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, folder1, doc1))
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, folder1, doc2))
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, folder2, doc2))
+		// - adding the same pair again is ignored
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, folder2, doc2))
+		outputCasbinRule(t, ts.db)
+
+		// when
+		parents, err := objectHierarchy.FindParentObjects(ctx, owner, doc2)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []domain.RBACObject{folder1, folder2}, parents)
+
+		// when
+		children, err := objectHierarchy.FindChildObjects(ctx, owner, workspace1)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []domain.RBACObject{folder1, folder2}, children)
+
+		// when
+		ancestors, err := objectHierarchy.FindAncestorObjects(ctx, owner, doc2)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []domain.RBACObject{folder1, folder2, workspace1}, ancestors)
+
+		// when
+		descendants, err := objectHierarchy.FindDescendantObjects(ctx, owner, workspace1)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []domain.RBACObject{folder1, folder2, doc1, doc2}, descendants)
+
+		// when
+		descendants, err = objectHierarchy.FindDescendantObjects(ctx, owner, doc1)
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, descendants)
+
+		// when
+		// - workspace:1 -> folder:1 -> doc:1 -> workspace:1
+		err = objectHierarchy.AddChildObject(ctx, owner, doc1, workspace1)
+		// then
+		assert.ErrorIs(t, err, service.ErrObjectHierarchyCycle)
+
+		// when
+		err = objectHierarchy.AddChildObject(ctx, owner, doc1, doc1)
+		// then
+		assert.ErrorIs(t, err, service.ErrObjectHierarchyCycle)
+
+		// when
+		err = objectHierarchy.AddChildObject(ctx, owner, workspace1, domain.NewRBACObject(fmt.Sprintf("domain:%d_doc:1", orgID.Int()+1)))
+		// then
+		assert.ErrorIs(t, err, libdomain.ErrInvalidArgument)
+
+		// when
+		err = objectHierarchy.RemoveChildObject(ctx, owner, folder1, doc2)
+		// then
+		require.NoError(t, err)
+		ancestors, err = objectHierarchy.FindAncestorObjects(ctx, owner, doc2)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.RBACObject{folder2, workspace1}, ancestors)
+
+		// when
+		err = objectHierarchy.RemoveChildObject(ctx, owner, folder1, doc2)
+		// then
+		assert.ErrorIs(t, err, service.ErrPairOfObjectsNotFound)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_objectHierarchy_permissionDenied(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - workspace:1 -> folder:1, workspace:2 -> folder:2
+		// - user1 can read, set and unset workspace:1
+		workspace1 := domain.NewRBACObject("workspace:1")
+		workspace2 := domain.NewRBACObject("workspace:2")
+		folder1 := domain.NewRBACObject("folder:1")
+		folder2 := domain.NewRBACObject("folder:2")
+		secret := domain.NewRBACObject("doc:secret")
+		testAllowObjectHierarchy(t, ctx, ts, orgID, owner, domain.NewRBACObject("*"))
+		objectHierarchy := gateway.NewObjectHierarchy(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, workspace1, folder1))
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, workspace2, folder2))
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		testAllowObjectHierarchy(t, ctx, ts, orgID, user1, workspace1)
+		readAction := domain.NewRBACAction("read")
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, service.NewRBACAppUser(orgID, user1.AppUserID()), readAction, workspace1, service.RBACAllowEffect))
+
+		// when
+		err := objectHierarchy.AddChildObject(ctx, user1, workspace1, secret)
+		// then
+		// - user1 cannot make the object which user1 cannot set inherit the policies on workspace:1
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+		ok, err := authorizationManager.Authorize(ctx, user1, readAction, secret)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		// when
+		err = objectHierarchy.RemoveChildObject(ctx, user1, workspace2, folder2)
+		// then
+		assert.ErrorIs(t, err, libdomain.ErrPermissionDenied)
+		parents, err := objectHierarchy.FindParentObjects(ctx, user1, folder2)
+		require.NoError(t, err)
+		assert.Equal(t, []domain.RBACObject{workspace2}, parents)
+
+		// when
+		err = objectHierarchy.RemoveChildObject(ctx, user1, workspace1, folder1)
+		// then
+		// - user1 can unset folder:1 through workspace:1
+		require.NoError(t, err)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_objectHierarchy_tooDeep(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - node:0 -> node:1 -> ... -> node:MaxObjectHierarchyDepth
+		// - user1 can read node:0
+		testAllowObjectHierarchy(t, ctx, ts, orgID, owner, domain.NewRBACObject("*"))
+		objectHierarchy := gateway.NewObjectHierarchy(ctx, ts.dialect, ts.db, ts.rf)
+		node := func(i int) domain.RBACObject { return domain.NewRBACObject(fmt.Sprintf("node:%d", i)) }
+		for i := 0; i < service.MaxObjectHierarchyDepth; i++ {
+			require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, node(i), node(i+1)))
+		}
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		readAction := domain.NewRBACAction("read")
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, service.NewRBACAppUser(orgID, user1.AppUserID()), readAction, node(0), service.RBACAllowEffect))
+
+		// when
+		ok, err := authorizationManager.Authorize(ctx, user1, readAction, node(service.MaxObjectHierarchyDepth))
+		// then
+		// - the deepest object inherits the policy on the root
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		// when
+		err = objectHierarchy.AddChildObject(ctx, owner, node(service.MaxObjectHierarchyDepth), node(service.MaxObjectHierarchyDepth+1))
+		// then
+		assert.ErrorIs(t, err, service.ErrObjectHierarchyTooDeep)
+
+		// when
+		err = objectHierarchy.AddChildObject(ctx, owner, domain.NewRBACObject("root"), node(0))
+		// then
+		assert.ErrorIs(t, err, service.ErrObjectHierarchyTooDeep)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_objectHierarchy_Authorize(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - workspace:1 -> folder:1 -> doc:1, doc:2
+		// - user1 can read workspace:1, user1 cannot read doc:2
+		// - user1 can write folder:1
+		objectPrefix := service.NewRBACOrganization(orgID).Domain() + "_"
+		object := func(name string) domain.RBACObject { return domain.NewRBACObject(objectPrefix + name) }
+		workspace1 := object("workspace:1")
+		folder1 := object("folder:1")
+		doc1 := object("doc:1")
+		doc2 := object("doc:2")
+		doc3 := object("doc:3")
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		rbacUser1 := service.NewRBACAppUser(orgID, user1.AppUserID())
+		readAction := domain.NewRBACAction("read")
+		writeAction := domain.NewRBACAction("write")
+
+		testAllowObjectHierarchy(t, ctx, ts, orgID, owner, object("*"))
+		objectHierarchy := gateway.NewObjectHierarchy(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, workspace1, folder1))
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, folder1, doc1))
+		require.NoError(t, objectHierarchy.AddChildObject(ctx, owner, folder1, doc2))
+		authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, rbacUser1, readAction, workspace1, service.RBACAllowEffect))
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, rbacUser1, readAction, doc2, service.RBACDenyEffect))
+		require.NoError(t, authorizationManager.AddPolicyToUser(ctx, owner, rbacUser1, writeAction, folder1, service.RBACAllowEffect))
+
+		cachedRepositoryFactory := testCachedRepositoryFactory(t, ctx, ts)
+		for name, m := range map[string]service.AuthorizationManager{
+			"uncached": authorizationManager,
+			"cached":   gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, cachedRepositoryFactory),
+		} {
+			// when
+			results, err := m.AuthorizeBatch(ctx, user1, []service.AuthRequest{
+				{Action: readAction, Object: workspace1},
+				{Action: readAction, Object: folder1},
+				{Action: readAction, Object: doc1},
+				{Action: readAction, Object: doc2},
+				{Action: readAction, Object: doc3},
+				{Action: writeAction, Object: workspace1},
+				{Action: writeAction, Object: doc1},
+			})
+			// then
+			require.NoError(t, err, name)
+			assert.Equal(t, []bool{true, true, true, false, false, false, true}, results, name)
+
+			// when
+			objects, err := m.ListAccessibleObjects(ctx, user1, objectPrefix, readAction)
+			// then
+			require.NoError(t, err, name)
+			assert.Equal(t, []domain.RBACObject{doc1, folder1, workspace1}, objects, name)
+		}
+
+		// when
+		// - doc:3 is moved into folder:1
+		require.NoError(t, gateway.NewObjectHierarchy(ctx, ts.dialect, ts.db, cachedRepositoryFactory).AddChildObject(ctx, owner, folder1, doc3))
+		// then
+		for name, m := range map[string]service.AuthorizationManager{
+			"uncached": authorizationManager,
+			"cached":   gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, cachedRepositoryFactory),
+		} {
+			ok, err := m.Authorize(ctx, user1, readAction, doc3)
+			require.NoError(t, err, name)
+			assert.True(t, ok, name)
+		}
+	}
+	testOrganization(t, fn)
+}
+
+// testAllowObjectHierarchy allows the operator to set and unset the objects which match the object
+func testAllowObjectHierarchy(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, operator service.AppUserInterface, object domain.RBACObject) {
+	t.Helper()
+	authorizationManager := gateway.NewAuthorizationManager(ctx, ts.dialect, ts.db, ts.rf)
+	rbacOperator := service.NewRBACAppUser(orgID, operator.AppUserID())
+	for _, action := range []domain.RBACAction{service.RBACSetAction, service.RBACUnsetAction} {
+		require.NoError(t, authorizationManager.AddPolicyToUserBySystemAdmin(ctx, testNewSystemAdmin(domain.NewSystemAdminModel()), orgID, rbacOperator, action, object, service.RBACAllowEffect))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	libdomain "github.com/kujilabo/redstart/lib/domain"
 	liberrors "github.com/kujilabo/redstart/lib/errors"
@@ -116,15 +117,10 @@ func (r *organizationRepository) AddOrganization(ctx context.Context, operator s
 	defer span.End()
 
 	organization := organizationEntity{
-		BaseModelEntity: BaseModelEntity{
-			Version:   1,
-			CreatedBy: operator.AppUserID().Int(),
-			UpdatedBy: operator.AppUserID().Int(),
-		},
 		Name: param.Name(),
 	}
 
-	if result := r.db.Create(&organization); result.Error != nil {
+	if result := r.db.WithContext(service.WithOperator(ctx, operator)).Create(&organization); result.Error != nil {
 		return nil, liberrors.Errorf("db.Create. err: %w", libgateway.ConvertDuplicatedError(result.Error, service.ErrOrganizationAlreadyExists))
 	}
 
@@ -135,3 +131,31 @@ func (r *organizationRepository) AddOrganization(ctx context.Context, operator s
 
 	return organizationID, nil
 }
+
+func (r *organizationRepository) UpdateOrganization(ctx context.Context, operator service.SystemAdminInterface, id *domain.OrganizationID, version int, param service.OrganizationUpdateParameterInterface) error {
+	_, span := tracer.Start(ctx, "organizationRepository.UpdateOrganization")
+	defer span.End()
+
+	if err := updateWithVersion(r.db.WithContext(service.WithOperator(ctx, operator)), &organizationEntity{}, func(db *gorm.DB) *gorm.DB {
+		return db.Where("organization.id = ?", id.Int())
+	}, version, map[string]interface{}{
+		"name": param.Name(),
+	}, service.ErrOrganizationNotFound); err != nil {
+		return liberrors.Errorf("updateWithVersion. err: %w", libgateway.ConvertDuplicatedError(err, service.ErrOrganizationAlreadyExists))
+	}
+
+	return nil
+}
+
+// lockOrganization locks the organization row until the end of the transaction. SQLite ignores the lock because it serializes the writing transactions
+func lockOrganization(tx *gorm.DB, organizationID *domain.OrganizationID) error {
+	organization := organizationEntity{}
+	if result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", organizationID.Int()).Take(&organization); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return liberrors.Errorf("organization ID: %d, err: %w", organizationID.Int(), service.ErrOrganizationNotFound)
+		}
+		return liberrors.Errorf("tx.Take. err: %w", result.Error)
+	}
+
+	return nil
+}
@@ -132,3 +132,67 @@ func Test_organizationRepository_FindOrganizationByID(t *testing.T) {
 	}
 	testDB(t, fn)
 }
+
+func Test_organizationRepository_AddOrganization_shouldReturnError_whenDuplicatedNameIsSpecified(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService) {
+		orgID, _, _ := setupOrganization(ctx, t, ts)
+		defer teardownOrganization(t, ts, orgID)
+		sysAdModel := domain.NewSystemAdminModel()
+		sysAd := testNewSystemAdmin(sysAdModel)
+
+		orgRepo := gateway.NewOrganizationRepository(ctx, ts.db)
+		org, err := orgRepo.FindOrganizationByID(ctx, sysAd, orgID)
+		require.NoError(t, err)
+		firstOwnerAddParam := testNewAppUserAddParameter(t, "OWNER_ID", "OWNER_NAME", "OWNER_PASSWORD")
+		orgAddParam, err := service.NewOrganizationAddParameter(org.Name(), firstOwnerAddParam)
+		require.NoError(t, err)
+
+		// when
+		_, err = orgRepo.AddOrganization(ctx, sysAd, orgAddParam)
+
+		// then
+		assert.ErrorIs(t, err, service.ErrOrganizationAlreadyExists)
+	}
+	testDB(t, fn)
+}
+
+func Test_organizationRepository_UpdateOrganization(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService) {
+		orgID, _, _ := setupOrganization(ctx, t, ts)
+		defer teardownOrganization(t, ts, orgID)
+		sysAdModel := domain.NewSystemAdminModel()
+		sysAd := testNewSystemAdmin(sysAdModel)
+
+		orgRepo := gateway.NewOrganizationRepository(ctx, ts.db)
+		org, err := orgRepo.FindOrganizationByID(ctx, sysAd, orgID)
+		require.NoError(t, err)
+		newName := RandString(orgNameLength)
+		param, err := service.NewOrganizationUpdateParameter(newName)
+		require.NoError(t, err)
+
+		// update organization with the current version
+		{
+			err := orgRepo.UpdateOrganization(ctx, sysAd, orgID, org.Version, param)
+			require.NoError(t, err)
+			updated, err := orgRepo.FindOrganizationByID(ctx, sysAd, orgID)
+			require.NoError(t, err)
+			assert.Equal(t, newName, updated.Name())
+			assert.Equal(t, org.Version+1, updated.Version)
+		}
+
+		// update organization with the old version
+		{
+			err := orgRepo.UpdateOrganization(ctx, sysAd, orgID, org.Version, param)
+			assert.ErrorIs(t, err, libdomain.ErrConcurrentModification)
+		}
+
+		// update organization unregistered
+		{
+			err := orgRepo.UpdateOrganization(ctx, sysAd, invalidOrgID, 1, param)
+			assert.ErrorIs(t, err, service.ErrOrganizationNotFound)
+		}
+	}
+	testDB(t, fn)
+}
@@ -0,0 +1,156 @@
+package gateway
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	libgateway "github.com/kujilabo/redstart/lib/gateway"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+var (
+	PairOfGroupAndGroupTableName = "group_n_group"
+)
+
+type pairOfGroupAndGroupRepository struct {
+	dialect libgateway.DialectRDBMS
+	db      *gorm.DB
+}
+
+type pairOfGroupAndGroupEntity struct {
+	JunctionModelEntity
+	OrganizationID    int
+	ChildUserGroupID  int
+	ParentUserGroupID int
+}
+
+func (u *pairOfGroupAndGroupEntity) TableName() string {
+	return PairOfGroupAndGroupTableName
+}
+
+func NewPairOfGroupAndGroupRepository(ctx context.Context, dialect libgateway.DialectRDBMS, db *gorm.DB) service.PairOfGroupAndGroupRepository {
+	return &pairOfGroupAndGroupRepository{
+		dialect: dialect,
+		db:      db,
+	}
+}
+
+// AddPairOfGroupAndGroup locks the organization so that the concurrent additions cannot make a cycle between their check and their insert
+func (r *pairOfGroupAndGroupRepository) AddPairOfGroupAndGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error {
+	_, span := tracer.Start(ctx, "pairOfGroupAndGroupRepository.AddPairOfGroupAndGroup")
+	defer span.End()
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockOrganization(tx, operator.OrganizationID()); err != nil {
+			return err
+		}
+
+		txRepo := pairOfGroupAndGroupRepository{dialect: r.dialect, db: tx}
+		cyclic, err := txRepo.isAncestor(operator.OrganizationID(), childUserGroupID, parentUserGroupID)
+		if err != nil {
+			return err
+		}
+		if cyclic {
+			return liberrors.Errorf("child user group ID: %d, parent user group ID: %d, err: %w", childUserGroupID.Int(), parentUserGroupID.Int(), service.ErrUserGroupCycle)
+		}
+
+		pairOfGroupAndGroup := pairOfGroupAndGroupEntity{
+			OrganizationID:    operator.OrganizationID().Int(),
+			ChildUserGroupID:  childUserGroupID.Int(),
+			ParentUserGroupID: parentUserGroupID.Int(),
+		}
+		if result := tx.WithContext(service.WithOperator(ctx, operator)).Create(&pairOfGroupAndGroup); result.Error != nil {
+			return liberrors.Errorf(". err: %w", libgateway.ConvertDuplicatedError(result.Error, service.ErrPairOfGroupAndGroupAlreadyExists))
+		}
+
+		return nil
+	})
+}
+
+func (r *pairOfGroupAndGroupRepository) RemovePairOfGroupAndGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error {
+	_, span := tracer.Start(ctx, "pairOfGroupAndGroupRepository.RemovePairOfGroupAndGroup")
+	defer span.End()
+
+	wrappedDB := wrappedDB{dialect: r.dialect, db: r.db, organizationID: operator.OrganizationID()}
+	db := wrappedDB.
+		WherePairOfGroupAndGroup().
+		Where("child_user_group_id = ?", childUserGroupID.Int()).
+		Where("parent_user_group_id = ?", parentUserGroupID.Int()).
+		db
+	result := db.Delete(&pairOfGroupAndGroupEntity{})
+	if result.Error != nil {
+		return liberrors.Errorf("db.Delete. err: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return liberrors.Errorf("child user group ID: %d, parent user group ID: %d, err: %w", childUserGroupID.Int(), parentUserGroupID.Int(), service.ErrPairOfGroupAndGroupNotFound)
+	}
+
+	return nil
+}
+
+func (r *pairOfGroupAndGroupRepository) FindParentUserGroups(ctx context.Context, operator service.AppUserInterface, userGroupID *domain.UserGroupID) ([]*domain.UserGroupModel, error) {
+	_, span := tracer.Start(ctx, "pairOfGroupAndGroupRepository.FindParentUserGroups")
+	defer span.End()
+
+	userGroups := []userGroupEntity{}
+	if result := r.db.Table("user_group").Select("user_group.*").
+		Where("user_group.organization_id = ?", operator.OrganizationID().Int()).
+		Where("user_group.removed = ?", r.dialect.BoolDefaultValue()).
+		Where("group_n_group.organization_id = ?", operator.OrganizationID().Int()).
+		Where("group_n_group.child_user_group_id = ?", userGroupID.Int()).
+		Joins("inner join group_n_group on user_group.id = group_n_group.parent_user_group_id").
+		Order("user_group.key_name").
+		Find(&userGroups); result.Error != nil {
+		return nil, result.Error
+	}
+
+	userGroupModels := make([]*domain.UserGroupModel, len(userGroups))
+	for i, e := range userGroups {
+		m, err := e.toUserGroupModel()
+		if err != nil {
+			return nil, err
+		}
+		userGroupModels[i] = m
+	}
+
+	return userGroupModels, nil
+}
+
+// isAncestor returns true when userGroupID is reachable from descendantUserGroupID by following child-to-parent pairs, including the case they are the same group
+func (r *pairOfGroupAndGroupRepository) isAncestor(organizationID *domain.OrganizationID, userGroupID *domain.UserGroupID, descendantUserGroupID *domain.UserGroupID) (bool, error) {
+	if userGroupID.Int() == descendantUserGroupID.Int() {
+		return true, nil
+	}
+
+	pairs := []pairOfGroupAndGroupEntity{}
+	wrappedDB := wrappedDB{dialect: r.dialect, db: r.db, organizationID: organizationID}
+	if result := wrappedDB.WherePairOfGroupAndGroup().db.Find(&pairs); result.Error != nil {
+		return false, liberrors.Errorf("db.Find. err: %w", result.Error)
+	}
+
+	parents := make(map[int][]int)
+	for _, p := range pairs {
+		parents[p.ChildUserGroupID] = append(parents[p.ChildUserGroupID], p.ParentUserGroupID)
+	}
+
+	visited := map[int]bool{descendantUserGroupID.Int(): true}
+	queue := []int{descendantUserGroupID.Int()}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, parent := range parents[current] {
+			if parent == userGroupID.Int() {
+				return true, nil
+			}
+			if !visited[parent] {
+				visited[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	return false, nil
+}
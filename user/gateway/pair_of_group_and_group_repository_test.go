@@ -0,0 +1,105 @@
+package gateway_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func Test_pairOfGroupAndGroupRepository_AddPairOfGroupAndGroup(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESC_1")
+		group2 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_2", "GROUP_NAME_2", "GROUP_DESC_2")
+		group3 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_3", "GROUP_NAME_3", "GROUP_DESC_3")
+		pairOfGroupAndGroupRepo := gateway.NewPairOfGroupAndGroupRepository(ctx, ts.dialect, ts.db)
+
+		// when
+		// - group3 -> group2 -> group1
+		err := pairOfGroupAndGroupRepo.AddPairOfGroupAndGroup(ctx, owner, group2.UserGroupID(), group1.UserGroupID())
+		require.NoError(t, err)
+		err = pairOfGroupAndGroupRepo.AddPairOfGroupAndGroup(ctx, owner, group3.UserGroupID(), group2.UserGroupID())
+		require.NoError(t, err)
+
+		// then
+		parents, err := pairOfGroupAndGroupRepo.FindParentUserGroups(ctx, owner, group3.UserGroupID())
+		require.NoError(t, err)
+		require.Len(t, parents, 1)
+		assert.Equal(t, "GROUP_KEY_2", parents[0].Key)
+
+		err = pairOfGroupAndGroupRepo.AddPairOfGroupAndGroup(ctx, owner, group3.UserGroupID(), group2.UserGroupID())
+		assert.ErrorIs(t, err, service.ErrPairOfGroupAndGroupAlreadyExists)
+
+		tests := []struct {
+			name   string
+			child  *service.UserGroup
+			parent *service.UserGroup
+		}{
+			{name: "self", child: group1, parent: group1},
+			{name: "direct", child: group1, parent: group2},
+			{name: "indirect", child: group1, parent: group3},
+		}
+		for _, tt := range tests {
+			err := pairOfGroupAndGroupRepo.AddPairOfGroupAndGroup(ctx, owner, tt.child.UserGroupID(), tt.parent.UserGroupID())
+			assert.ErrorIs(t, err, service.ErrUserGroupCycle, tt.name)
+		}
+
+		// when
+		err = pairOfGroupAndGroupRepo.RemovePairOfGroupAndGroup(ctx, owner, group3.UserGroupID(), group2.UserGroupID())
+		require.NoError(t, err)
+
+		// then
+		parents, err = pairOfGroupAndGroupRepo.FindParentUserGroups(ctx, owner, group3.UserGroupID())
+		require.NoError(t, err)
+		assert.Len(t, parents, 0)
+		err = pairOfGroupAndGroupRepo.RemovePairOfGroupAndGroup(ctx, owner, group3.UserGroupID(), group2.UserGroupID())
+		assert.ErrorIs(t, err, service.ErrPairOfGroupAndGroupNotFound)
+		// - group1 can belong to group3 once group3 no longer belongs to group1
+		err = pairOfGroupAndGroupRepo.AddPairOfGroupAndGroup(ctx, owner, group1.UserGroupID(), group3.UserGroupID())
+		assert.NoError(t, err)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_pairOfGroupAndGroupRepository_AddPairOfGroupAndGroup_concurrently(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		group1 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_1", "GROUP_NAME_1", "GROUP_DESC_1")
+		group2 := testAddUserGroup(t, ctx, ts, owner, "GROUP_KEY_2", "GROUP_NAME_2", "GROUP_DESC_2")
+		pairOfGroupAndGroupRepo := gateway.NewPairOfGroupAndGroupRepository(ctx, ts.dialect, ts.db)
+
+		// when
+		// - group1 -> group2 and group2 -> group1 are added at the same time
+		errs := make([]error, 2)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs[0] = pairOfGroupAndGroupRepo.AddPairOfGroupAndGroup(ctx, owner, group1.UserGroupID(), group2.UserGroupID())
+		}()
+		go func() {
+			defer wg.Done()
+			errs[1] = pairOfGroupAndGroupRepo.AddPairOfGroupAndGroup(ctx, owner, group2.UserGroupID(), group1.UserGroupID())
+		}()
+		wg.Wait()
+
+		// then
+		// - only one of them is added
+		assert.False(t, errs[0] == nil && errs[1] == nil)
+		parents1, err := pairOfGroupAndGroupRepo.FindParentUserGroups(ctx, owner, group1.UserGroupID())
+		require.NoError(t, err)
+		parents2, err := pairOfGroupAndGroupRepo.FindParentUserGroups(ctx, owner, group2.UserGroupID())
+		require.NoError(t, err)
+		assert.Equal(t, 1, len(parents1)+len(parents2))
+	}
+	testOrganization(t, fn)
+}
@@ -2,7 +2,7 @@ package gateway
 
 import (
 	"context"
-	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -27,6 +27,8 @@ type pairOfUserAndGroupEntity struct {
 	OrganizationID int
 	AppUserID      int
 	UserGroupID    int
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
 }
 
 func (u *pairOfUserAndGroupEntity) TableName() string {
@@ -46,14 +48,11 @@ func (r *pairOfUserAndGroupRepository) AddPairOfUserAndGroupBySystemAdmin(ctx co
 	defer span.End()
 
 	pairOfUserAndGroup := pairOfUserAndGroupEntity{
-		JunctionModelEntity: JunctionModelEntity{
-			CreatedBy: operator.AppUserID().Int(),
-		},
 		OrganizationID: organizationID.Int(),
 		AppUserID:      appUserID.Int(),
 		UserGroupID:    userGroupID.Int(),
 	}
-	if result := r.db.Create(&pairOfUserAndGroup); result.Error != nil {
+	if result := r.db.WithContext(service.WithOperator(ctx, operator)).Create(&pairOfUserAndGroup); result.Error != nil {
 		return liberrors.Errorf(". err: %w", libgateway.ConvertDuplicatedError(result.Error, service.ErrAppUserAlreadyExists))
 	}
 
@@ -71,14 +70,11 @@ func (r *pairOfUserAndGroupRepository) AddPairOfUserAndGroup(ctx context.Context
 	defer span.End()
 
 	pairOfUserAndGroup := pairOfUserAndGroupEntity{
-		JunctionModelEntity: JunctionModelEntity{
-			CreatedBy: operator.AppUserID().Int(),
-		},
 		OrganizationID: operator.OrganizationID().Int(),
 		AppUserID:      appUserID.Int(),
 		UserGroupID:    userGroupID.Int(),
 	}
-	if result := r.db.Create(&pairOfUserAndGroup); result.Error != nil {
+	if result := r.db.WithContext(service.WithOperator(ctx, operator)).Create(&pairOfUserAndGroup); result.Error != nil {
 		return liberrors.Errorf(". err: %w", libgateway.ConvertDuplicatedError(result.Error, service.ErrAppUserAlreadyExists))
 	}
 
@@ -139,18 +135,8 @@ func (r *pairOfUserAndGroupRepository) RemovePairOfUserAndGroup(ctx context.Cont
 	_, span := tracer.Start(ctx, "pairOfUserAndGroupRepository.RemovePairOfUserAndGroup")
 	defer span.End()
 
-	wrappedDB := wrappedDB{dialect: r.dialect, db: r.db, organizationID: operator.OrganizationID()}
-	db := wrappedDB.
-		WherePairOfUserAndGroup().
-		Where("app_user_id = ?", appUserID.Int()).
-		Where("user_group_id = ?", userGroupID.Int()).
-		db
-	result := db.Delete(&pairOfUserAndGroupEntity{})
-	if result.Error != nil {
-		return liberrors.Errorf(". err: %w", libgateway.ConvertDuplicatedError(result.Error, service.ErrAppUserAlreadyExists))
-	}
-	if result.RowsAffected == 0 {
-		return errors.New("ERROR")
+	if err := r.remove(ctx, operator.OrganizationID(), appUserID, userGroupID); err != nil {
+		return err
 	}
 
 	// rbacUserRoleObject := service.NewRBACUserRoleObject(operator.GetOrganizationID(), userGroupID)
@@ -176,6 +162,61 @@ func (r *pairOfUserAndGroupRepository) RemovePairOfUserAndGroup(ctx context.Cont
 	return nil
 }
 
+func (r *pairOfUserAndGroupRepository) SetPairOfUserAndGroupValidity(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID, validity *domain.RBACValidity) error {
+	_, span := tracer.Start(ctx, "pairOfUserAndGroupRepository.SetPairOfUserAndGroupValidity")
+	defer span.End()
+
+	var validFrom, validUntil *time.Time
+	if validity != nil {
+		validFrom = utcTime(validity.ValidFrom)
+		validUntil = utcTime(validity.ValidUntil)
+	}
+
+	wrappedDB := wrappedDB{dialect: r.dialect, db: r.db, organizationID: operator.OrganizationID()}
+	db := wrappedDB.
+		WherePairOfUserAndGroup().
+		Where("app_user_id = ?", appUserID.Int()).
+		Where("user_group_id = ?", userGroupID.Int()).
+		db
+	result := db.Model(&pairOfUserAndGroupEntity{}).Updates(map[string]interface{}{
+		"valid_from":  validFrom,
+		"valid_until": validUntil,
+	})
+	if result.Error != nil {
+		return liberrors.Errorf("db.Updates. err: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return liberrors.Errorf("app user ID: %d, user group ID: %d, err: %w", appUserID.Int(), userGroupID.Int(), service.ErrPairOfUserAndGroupNotFound)
+	}
+
+	return nil
+}
+
+func (r *pairOfUserAndGroupRepository) RemovePairOfUserAndGroupBySystemAdmin(ctx context.Context, operator service.SystemAdminInterface, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	_, span := tracer.Start(ctx, "pairOfUserAndGroupRepository.RemovePairOfUserAndGroupBySystemAdmin")
+	defer span.End()
+
+	return r.remove(ctx, organizationID, appUserID, userGroupID)
+}
+
+func (r *pairOfUserAndGroupRepository) remove(ctx context.Context, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	wrappedDB := wrappedDB{dialect: r.dialect, db: r.db, organizationID: organizationID}
+	db := wrappedDB.
+		WherePairOfUserAndGroup().
+		Where("app_user_id = ?", appUserID.Int()).
+		Where("user_group_id = ?", userGroupID.Int()).
+		db
+	result := db.Delete(&pairOfUserAndGroupEntity{})
+	if result.Error != nil {
+		return liberrors.Errorf("db.Delete. err: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return liberrors.Errorf("app user ID: %d, user group ID: %d, err: %w", appUserID.Int(), userGroupID.Int(), service.ErrPairOfUserAndGroupNotFound)
+	}
+
+	return nil
+}
+
 // func (r *pairOfUserAndGroupRepository) remove(ctx context.Context, operatorID domain.AppUserID, organizationID domain.OrganizationID, appUserID domain.AppUserID, userGroupID domain.UserGroupID,
 
 // /* userGroupKey string*/
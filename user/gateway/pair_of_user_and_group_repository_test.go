@@ -85,6 +85,17 @@ func Test_pairOfUserAndGroupRepository_RemovePairOfUserAndGroup(t *testing.T) {
 		// when
 		err = pairOfUserAndGroupRepo.RemovePairOfUserAndGroup(ctx, owner, user1.AppUserID(), ownerGroup.UserGroupID())
 		require.NoError(t, err)
+		{
+			userGroups1, err := pairOfUserAndGroupRepo.FindUserGroupsByUserID(ctx, user1, user1.AppUserID())
+			require.NoError(t, err)
+			assert.Len(t, userGroups1, 0)
+		}
+
+		// when
+		err = pairOfUserAndGroupRepo.RemovePairOfUserAndGroup(ctx, owner, user1.AppUserID(), ownerGroup.UserGroupID())
+		// then
+		// - the pair has already been removed
+		assert.ErrorIs(t, err, service.ErrPairOfUserAndGroupNotFound)
 
 		// then
 		// - owner can not make user1 belong to owner-group
@@ -0,0 +1,258 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	liblog "github.com/kujilabo/redstart/lib/log"
+)
+
+const (
+	PolicyRevisionTableName = "casbin_policy_revision"
+
+	// policyRevisionChannel is the channel notified on Postgres when a revision is bumped
+	policyRevisionChannel = "casbin_policy_revision"
+
+	// allPolicyDomains is the domain recorded when the changed domain is unknown
+	allPolicyDomains = "*"
+)
+
+type policyRevisionEntity struct {
+	Domain    string
+	Revision  int64
+	UpdatedAt time.Time
+}
+
+func (e *policyRevisionEntity) TableName() string {
+	return PolicyRevisionTableName
+}
+
+// bumpPolicyRevision increments the revision of the policies of the domain.
+// It is executed with db so that the revision is committed or rolled back together with the policies.
+// The row is upserted so that the concurrent first changes of a domain do not conflict
+func bumpPolicyRevision(ctx context.Context, db *gorm.DB, rbacDomain string) error {
+	entity := policyRevisionEntity{
+		Domain:    rbacDomain,
+		Revision:  1,
+		UpdatedAt: time.Now(),
+	}
+	if err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "domain"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"revision":   gorm.Expr(PolicyRevisionTableName + ".revision + 1"),
+			"updated_at": entity.UpdatedAt,
+		}),
+	}).Create(&entity).Error; err != nil {
+		return liberrors.Errorf("upsert %s. err: %w", PolicyRevisionTableName, err)
+	}
+
+	if db.Dialector.Name() == "postgres" {
+		// the notification is delivered when the transaction is committed
+		if err := db.WithContext(ctx).Exec("select pg_notify(?, ?)", policyRevisionChannel, rbacDomain).Error; err != nil {
+			return liberrors.Errorf("pg_notify. err: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PolicyWatcher is a casbin Watcher which detects the policy changes made by any instance sharing the database.
+// It polls casbin_policy_revision every interval, and also listens to the notifications on Postgres.
+// The callback receives the domain whose policies are changed, or "*" when all the domains may be changed.
+// Use SetSyncedEnforcerWatcher to watch with a casbin.SyncedEnforcer
+type PolicyWatcher struct {
+	db        *gorm.DB
+	interval  time.Duration
+	mu        sync.Mutex
+	callback  func(string)
+	revisions map[string]int64
+	notified  chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewPolicyWatcher(ctx context.Context, db *gorm.DB, interval time.Duration) (*PolicyWatcher, error) {
+	if interval <= 0 {
+		return nil, liberrors.Errorf("interval must be positive. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	w := &PolicyWatcher{
+		db:       db,
+		interval: interval,
+		notified: make(chan struct{}, 1),
+		closed:   make(chan struct{}),
+	}
+
+	revisions, err := w.findRevisions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w.revisions = revisions
+
+	return w, nil
+}
+
+// SetSyncedEnforcerWatcher sets the watcher to the enforcer and reloads its policies with the lock held.
+// SyncedEnforcer.SetWatcher registers the unsynchronized Enforcer.LoadPolicy as the callback,
+// which races with Enforce because the callback is called from the goroutine of Run
+func SetSyncedEnforcerWatcher(e *casbin.SyncedEnforcer, w *PolicyWatcher) error {
+	if err := e.SetWatcher(w); err != nil {
+		return liberrors.Errorf("e.SetWatcher. err: %w", err)
+	}
+
+	return w.SetUpdateCallback(func(string) {
+		if err := e.LoadPolicy(); err != nil {
+			logger := liblog.GetLoggerFromContext(context.Background(), UserGatewayContextKey)
+			logger.WarnContext(context.Background(), fmt.Sprintf("failed to reload the policies. err: %+v", err))
+		}
+	})
+}
+
+// SetUpdateCallback sets the function called when the policies are changed by any instance
+func (w *PolicyWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.callback = callback
+	return nil
+}
+
+// Update is called by casbin enforcers after they change the policies. The domain is unknown, so every domain is reloaded
+func (w *PolicyWatcher) Update() error {
+	if err := bumpPolicyRevision(context.Background(), w.db, allPolicyDomains); err != nil {
+		return liberrors.Errorf("bumpPolicyRevision. err: %w", err)
+	}
+
+	return nil
+}
+
+// Close stops Run
+func (w *PolicyWatcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.closed)
+	})
+}
+
+// Run polls the revisions until ctx is done or Close is called
+func (w *PolicyWatcher) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if w.db.Dialector.Name() == "postgres" {
+		go func() {
+			logger := liblog.GetLoggerFromContext(ctx, UserGatewayContextKey)
+			if err := w.listen(ctx); err != nil && ctx.Err() == nil {
+				logger.WarnContext(ctx, fmt.Sprintf("failed to listen to the policy changes. falling back to polling. err: %+v", err))
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.closed:
+			return nil
+		case <-ticker.C:
+		case <-w.notified:
+		}
+
+		if err := w.poll(ctx); err != nil {
+			logger := liblog.GetLoggerFromContext(ctx, UserGatewayContextKey)
+			logger.WarnContext(ctx, fmt.Sprintf("failed to poll the policy revisions. err: %+v", err))
+		}
+	}
+}
+
+func (w *PolicyWatcher) poll(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "PolicyWatcher.poll")
+	defer span.End()
+
+	revisions, err := w.findRevisions(ctx)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	changed := make([]string, 0)
+	for rbacDomain, revision := range revisions {
+		if w.revisions[rbacDomain] != revision {
+			changed = append(changed, rbacDomain)
+		}
+	}
+	w.revisions = revisions
+	callback := w.callback
+	w.mu.Unlock()
+
+	if callback == nil {
+		return nil
+	}
+
+	for _, rbacDomain := range changed {
+		callback(rbacDomain)
+	}
+
+	return nil
+}
+
+func (w *PolicyWatcher) findRevisions(ctx context.Context) (map[string]int64, error) {
+	entities := make([]policyRevisionEntity, 0)
+	if err := w.db.WithContext(ctx).Find(&entities).Error; err != nil {
+		return nil, liberrors.Errorf("select %s. err: %w", PolicyRevisionTableName, err)
+	}
+
+	revisions := make(map[string]int64, len(entities))
+	for _, e := range entities {
+		revisions[e.Domain] = e.Revision
+	}
+
+	return revisions, nil
+}
+
+// listen wakes up Run whenever a revision is bumped on Postgres
+func (w *PolicyWatcher) listen(ctx context.Context) error {
+	sqlDB, err := w.db.DB()
+	if err != nil {
+		return liberrors.Errorf("w.db.DB. err: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return liberrors.Errorf("sqlDB.Conn. err: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return liberrors.Errorf("unsupported connection %T. err: %w", driverConn, libdomain.ErrInvalidArgument)
+		}
+
+		if _, err := c.Conn().Exec(ctx, "listen "+policyRevisionChannel); err != nil {
+			return liberrors.Errorf("listen. err: %w", err)
+		}
+
+		for {
+			if _, err := c.Conn().WaitForNotification(ctx); err != nil {
+				return liberrors.Errorf("WaitForNotification. err: %w", err)
+			}
+
+			select {
+			case w.notified <- struct{}{}:
+			default:
+			}
+		}
+	})
+}
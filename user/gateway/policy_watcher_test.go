@@ -0,0 +1,154 @@
+package gateway_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+const (
+	policyWatcherInterval = 50 * time.Millisecond
+	policyWatcherTimeout  = 5 * time.Second
+)
+
+func newPolicyWatcher(t *testing.T, ctx context.Context, ts testService) *gateway.PolicyWatcher {
+	t.Helper()
+	w, err := gateway.NewPolicyWatcher(ctx, ts.db, policyWatcherInterval)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		w.Close()
+		require.NoError(t, <-done)
+	})
+
+	return w
+}
+
+func newSyncedEnforcerForTest(t *testing.T, ts testService) *casbin.SyncedEnforcer {
+	t.Helper()
+	a, err := gateway.NewCasbinAdapter(ts.db)
+	require.NoError(t, err)
+	m, err := model.NewModelFromString(gateway.Conf)
+	require.NoError(t, err)
+	e, err := casbin.NewSyncedEnforcer(m, a)
+	require.NoError(t, err)
+	gateway.AddRBACFunctions(e)
+	return e
+}
+
+func Test_PolicyWatcher_twoEnforcers(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - two instances sharing the database
+		e1 := newSyncedEnforcerForTest(t, ts)
+		require.NoError(t, gateway.SetSyncedEnforcerWatcher(e1, newPolicyWatcher(t, ctx, ts)))
+		e2 := newSyncedEnforcerForTest(t, ts)
+		require.NoError(t, gateway.SetSyncedEnforcerWatcher(e2, newPolicyWatcher(t, ctx, ts)))
+
+		rbacDomain := service.NewRBACOrganization(orgID).Domain()
+		ok, err := e2.Enforce("user:1", "data", "read", rbacDomain, domain.RBACAttributes{})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// when
+		_, err = e1.AddNamedPolicy("p", "user:1", "data", "read", "allow", rbacDomain, "")
+		require.NoError(t, err)
+
+		// then
+		assert.Eventually(t, func() bool {
+			ok, err := e2.Enforce("user:1", "data", "read", rbacDomain, domain.RBACAttributes{})
+			return err == nil && ok
+		}, policyWatcherTimeout, policyWatcherInterval)
+
+		// when
+		_, err = e1.RemoveNamedPolicy("p", "user:1", "data", "read", "allow", rbacDomain, "")
+		require.NoError(t, err)
+
+		// then
+		assert.Eventually(t, func() bool {
+			ok, err := e2.Enforce("user:1", "data", "read", rbacDomain, domain.RBACAttributes{})
+			return err == nil && !ok
+		}, policyWatcherTimeout, policyWatcherInterval)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_bumpPolicyRevision_concurrently(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		// - the domain has no revision yet
+		rbacDomain := fmt.Sprintf("%s_revision", service.NewRBACOrganization(orgID).Domain())
+		const n = 5
+
+		// when
+		errs := make(chan error, n)
+		for i := 0; i < n; i++ {
+			go func() {
+				errs <- ts.db.Transaction(func(tx *gorm.DB) error {
+					return gateway.BumpPolicyRevision(ctx, tx, rbacDomain)
+				})
+			}()
+		}
+
+		// then
+		for i := 0; i < n; i++ {
+			require.NoError(t, <-errs)
+		}
+		var revision int64
+		require.NoError(t, ts.db.Table(gateway.PolicyRevisionTableName).Where("domain = ?", rbacDomain).Select("revision").Scan(&revision).Error)
+		assert.Equal(t, int64(n), revision)
+	}
+	testOrganization(t, fn)
+}
+
+func Test_PolicyWatcher_enforcerCache(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		// given
+		user1 := testAddAppUser(t, ctx, ts, owner, "LOGIN_ID_1", "USERNAME_1", "PASSWORD_1")
+		readAction := domain.NewRBACAction("read")
+		dataObject := domain.NewRBACObject("data")
+
+		// - instance 1 changes the policies
+		rf1, err := newCachedRepositoryFactory(t, ctx, ts, gateway.NewEnforcerCache(0))(ctx, ts.db)
+		require.NoError(t, err)
+		// - instance 2 caches the policies and watches the changes
+		enforcerCache2 := gateway.NewEnforcerCache(0)
+		rf2, err := newCachedRepositoryFactory(t, ctx, ts, enforcerCache2)(ctx, ts.db)
+		require.NoError(t, err)
+		require.NoError(t, newPolicyWatcher(t, ctx, ts).SetUpdateCallback(enforcerCache2.PolicyChanged))
+
+		authorizationManager2 := rf2.NewAuthorizationManager(ctx)
+		ok, err := authorizationManager2.Authorize(ctx, user1, readAction, dataObject)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// when
+		err = rf1.NewAuthorizationManager(ctx).AddPolicyToUser(ctx, owner, service.NewRBACAppUser(orgID, user1.AppUserID()), readAction, dataObject, service.RBACAllowEffect)
+		require.NoError(t, err)
+
+		// then
+		assert.Eventually(t, func() bool {
+			ok, err := authorizationManager2.Authorize(ctx, user1, readAction, dataObject)
+			return err == nil && ok
+		}, policyWatcherTimeout, policyWatcherInterval)
+	}
+	testOrganization(t, fn)
+}
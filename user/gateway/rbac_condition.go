@@ -0,0 +1,80 @@
+package gateway
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/casbin/govaluate"
+
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+// rbacConditions caches the parsed conditions by their expressions
+var rbacConditions sync.Map
+
+type rbacFunctionAdder interface {
+	AddFunction(name string, function govaluate.ExpressionFunction)
+}
+
+func addRBACFunctions(e rbacFunctionAdder) {
+	e.AddFunction("rbacCondition", rbacConditionFunc)
+}
+
+// rbacConditionFunc is called by the matcher with (p.cond, p.eft, r.attrs).
+// The condition which cannot be evaluated does not match "allow" policies and matches "deny" policies
+func rbacConditionFunc(args ...interface{}) (interface{}, error) {
+	if len(args) != 3 {
+		return nil, errors.New("rbacCondition requires 3 arguments")
+	}
+
+	expression, _ := args[0].(string)
+	if expression == "" {
+		return true, nil
+	}
+	effect, _ := args[1].(string)
+	failClosed := effect == service.RBACDenyEffect.Effect()
+
+	var attributes domain.RBACAttributes
+	switch a := args[2].(type) {
+	case domain.RBACAttributes:
+		attributes = a
+	case map[string]interface{}:
+		attributes = a
+	default:
+		return failClosed, nil
+	}
+
+	condition, ok := rbacConditions.Load(expression)
+	if !ok {
+		parsed, err := domain.ParseRBACCondition(expression)
+		if err != nil {
+			return failClosed, nil
+		}
+		condition, _ = rbacConditions.LoadOrStore(expression, parsed)
+	}
+
+	matched, err := condition.(domain.RBACCondition).Evaluate(attributes)
+	if err != nil {
+		return failClosed, nil
+	}
+
+	return matched, nil
+}
+
+// rbacRequestAttributes returns the attributes of the request. The built-in attributes override the ones of the caller
+func rbacRequestAttributes(operator service.AppUserInterface, attributes domain.RBACAttributes, now time.Time) domain.RBACAttributes {
+	requestAttributes := make(domain.RBACAttributes, len(attributes)+4)
+	for k, v := range attributes {
+		requestAttributes[k] = v
+	}
+
+	now = now.UTC()
+	requestAttributes[domain.RBACOperatorSubjectAttribute] = service.NewRBACAppUser(operator.OrganizationID(), operator.AppUserID()).Subject()
+	requestAttributes[domain.RBACOperatorAppUserIDAttribute] = operator.AppUserID().Int()
+	requestAttributes[domain.RBACTimeHourAttribute] = now.Hour()
+	requestAttributes[domain.RBACTimeWeekdayAttribute] = int(now.Weekday())
+
+	return requestAttributes
+}
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"time"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
@@ -16,10 +17,10 @@ import (
 
 const conf = `
 [request_definition]
-r = sub, obj, act, dom
+r = sub, obj, act, dom, attrs
 
 [policy_definition]
-p = sub, obj, act, eft, dom
+p = sub, obj, act, eft, dom, cond
 
 [role_definition]
 g = _, _, _
@@ -29,23 +30,48 @@ g2 = _, _, _
 e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
 
 [matchers]
-m = g(r.sub, p.sub, r.dom) && (keyMatch(r.obj, p.obj) || g2(r.obj, p.obj, r.dom)) && r.act == p.act
+m = g(r.sub, p.sub, r.dom) && (keyMatch(r.obj, p.obj) || g2(r.obj, p.obj, r.dom)) && r.act == p.act && rbacCondition(p.cond, p.eft, r.attrs)
 `
 
 type rbacRepository struct {
 	DB   *gorm.DB
 	Conf string
+	// onPolicyChanged is called with the domain whose rules are added or removed
+	onPolicyChanged func(domain.RBACDomain)
+	// enforcerCache is used to read the rules. The rules are loaded on every read when it is nil
+	enforcerCache *EnforcerCache
+	now           func() time.Time
 }
 
 func newRBACRepository(ctx context.Context, db *gorm.DB) service.RBACRepository {
+	return newRBACRepositoryWithListener(ctx, db, nil, nil)
+}
+
+func newRBACRepositoryWithListener(ctx context.Context, db *gorm.DB, enforcerCache *EnforcerCache, onPolicyChanged func(domain.RBACDomain)) service.RBACRepository {
 	if db == nil {
 		panic(errors.New("db is nil"))
 	}
 
 	return &rbacRepository{
-		DB:   db,
-		Conf: conf,
+		DB:              db,
+		Conf:            conf,
+		onPolicyChanged: onPolicyChanged,
+		enforcerCache:   enforcerCache,
+		now:             time.Now,
+	}
+}
+
+// policyChanged records the new revision of the policies of the domain so that the other instances reload them
+func (r *rbacRepository) policyChanged(ctx context.Context, rbacDomain domain.RBACDomain) error {
+	if err := bumpPolicyRevision(ctx, r.DB, rbacDomain.Domain()); err != nil {
+		return liberrors.Errorf("bumpPolicyRevision. err: %w", err)
+	}
+
+	if r.onPolicyChanged != nil {
+		r.onPolicyChanged(rbacDomain)
 	}
+
+	return nil
 }
 
 func (r *rbacRepository) Init() error {
@@ -63,14 +89,19 @@ func (r *rbacRepository) Init() error {
 		return liberrors.Errorf(". err: %w", err)
 	}
 
+	if err := bumpPolicyRevision(context.Background(), r.DB, allPolicyDomains); err != nil {
+		return liberrors.Errorf("bumpPolicyRevision. err: %w", err)
+	}
+
 	return nil
 }
 
-func (r *rbacRepository) initEnforcer(ctx context.Context) (*casbin.Enforcer, error) {
-	// logger := log.GetLoggerFromContext(ctx, UserGatewayContextKey)
-	a, err := gormadapter.NewAdapterByDB(r.DB)
+// initEnforcer returns the enforcer which has only the rules of the domain, including the ones out of their validity.
+// The changes of the rules through the enforcer are saved with r.DB
+func (r *rbacRepository) initEnforcer(ctx context.Context, rbacDomain domain.RBACDomain) (*casbin.Enforcer, error) {
+	a, err := newCasbinAdapter(r.DB)
 	if err != nil {
-		return nil, liberrors.Errorf("gormadapter.NewAdapterByDB. err: %w", err)
+		return nil, liberrors.Errorf("newCasbinAdapter. err: %w", err)
 	}
 
 	m, err := model.NewModelFromString(r.Conf)
@@ -78,11 +109,41 @@ func (r *rbacRepository) initEnforcer(ctx context.Context) (*casbin.Enforcer, er
 		return nil, liberrors.Errorf("model.NewModelFromString. err: %w", err)
 	}
 
-	e, err := casbin.NewEnforcer(m, a)
+	// the enforcer is created without the adapter because casbin loads all the rules with it
+	e, err := casbin.NewEnforcer(m)
 	if err != nil {
 		return nil, liberrors.Errorf("casbin.NewEnforcer. err: %w", err)
 	}
+	e.SetAdapter(a)
+	addRBACFunctions(e)
+
+	if err := e.LoadFilteredPolicy(domainCasbinRuleFilters(rbacDomain)); err != nil {
+		return nil, liberrors.Errorf("e.LoadFilteredPolicy. err: %w", err)
+	}
+
+	return e, nil
+}
+
+// rbacRuleReader is *casbin.Enforcer or *casbin.SyncedEnforcer
+type rbacRuleReader interface {
+	GetFilteredNamedPolicy(ptype string, fieldIndex int, fieldValues ...string) [][]string
+	GetFilteredNamedGroupingPolicy(ptype string, fieldIndex int, fieldValues ...string) [][]string
+}
+
+// ruleReader returns the cached rules of the domain, including the ones out of their validity
+func (r *rbacRepository) ruleReader(ctx context.Context, rbacDomain domain.RBACDomain) (rbacRuleReader, error) {
+	if r.enforcerCache != nil {
+		e, err := r.enforcerCache.Rules(ctx, r.DB, rbacDomain)
+		if err != nil {
+			return nil, liberrors.Errorf("r.enforcerCache.Rules. err: %w", err)
+		}
+		return e, nil
+	}
 
+	e, err := r.initEnforcer(ctx, rbacDomain)
+	if err != nil {
+		return nil, liberrors.Errorf("r.initEnforcer. err: %w", err)
+	}
 	return e, nil
 }
 
@@ -98,46 +159,96 @@ func (r *rbacRepository) initEnforcer(ctx context.Context) (*casbin.Enforcer, er
 // g2, domain:2_data_child, domain:2_data_parent, domain2
 
 func (r *rbacRepository) AddPolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
-	e, err := r.initEnforcer(ctx)
+	return r.AddConditionalPolicy(ctx, domain, subject, action, object, effect, nil)
+}
+
+func (r *rbacRepository) AddConditionalPolicy(ctx context.Context, rbacDomain domain.RBACDomain, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect, condition domain.RBACCondition) error {
+	expression := ""
+	if condition != nil {
+		if _, err := domain.ParseRBACCondition(condition.Expression()); err != nil {
+			return liberrors.Errorf("domain.ParseRBACCondition. err: %w", err)
+		}
+		expression = condition.Expression()
+	}
+
+	e, err := r.initEnforcer(ctx, rbacDomain)
 	if err != nil {
 		return liberrors.Errorf("r.initEnforcer. err: %w", err)
 	}
 
-	if _, err := e.AddNamedPolicy("p", subject.Subject(), object.Object(), action.Action(), effect.Effect(), domain.Domain()); err != nil {
+	// a policy has at most one condition. the condition of the existing policy is replaced
+	values := []string{subject.Subject(), object.Object(), action.Action(), effect.Effect(), rbacDomain.Domain()}
+	existing := e.GetFilteredNamedPolicy("p", 0, values...)
+	for _, p := range existing {
+		if p[5] == expression {
+			return nil
+		}
+	}
+	if len(existing) > 0 {
+		if _, err := e.RemoveFilteredNamedPolicy("p", 0, values...); err != nil {
+			return liberrors.Errorf("e.RemoveFilteredNamedPolicy. err: %w", err)
+		}
+	}
+
+	if _, err := e.AddNamedPolicy("p", append(values, expression)); err != nil {
 		return liberrors.Errorf("e.AddNamedPolicy. err: %w", err)
 	}
 
+	if err := r.policyChanged(ctx, rbacDomain); err != nil {
+		return liberrors.Errorf("r.policyChanged. err: %w", err)
+	}
+
 	return nil
 }
 
 func (r *rbacRepository) RemovePolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
-	e, err := r.initEnforcer(ctx)
+	e, err := r.initEnforcer(ctx, domain)
 	if err != nil {
 		return liberrors.Errorf("r.initEnforcer. err: %w", err)
 	}
 
-	if _, err = e.RemoveNamedPolicy("p", subject.Subject(), object.Object(), action.Action(), effect.Effect(), domain.Domain()); err != nil {
-		return liberrors.Errorf("e.AddNamedPolicy. err: %w", err)
+	// the policy is removed whatever its condition is
+	ok, err := e.RemoveFilteredNamedPolicy("p", 0, subject.Subject(), object.Object(), action.Action(), effect.Effect(), domain.Domain())
+	if err != nil {
+		return liberrors.Errorf("e.RemoveFilteredNamedPolicy. err: %w", err)
+	}
+	if !ok {
+		return liberrors.Errorf("subject: %s, action: %s, object: %s, effect: %s, err: %w", subject.Subject(), action.Action(), object.Object(), effect.Effect(), service.ErrRBACPolicyNotFound)
+	}
+	if err := deleteCasbinRuleValidity(ctx, r.DB, "p", subject.Subject(), object.Object(), action.Action(), effect.Effect(), domain.Domain()); err != nil {
+		return err
+	}
+
+	if err := r.policyChanged(ctx, domain); err != nil {
+		return liberrors.Errorf("r.policyChanged. err: %w", err)
 	}
 
 	return nil
 }
 
 func (r *rbacRepository) RemoveSubjectPolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject) error {
-	e, err := r.initEnforcer(ctx)
+	e, err := r.initEnforcer(ctx, domain)
 	if err != nil {
 		return liberrors.Errorf("r.initEnforcer. err: %w", err)
 	}
 
-	if _, err := e.RemoveFilteredNamedPolicy("p", 0, subject.Subject()); err != nil {
-		return liberrors.Errorf("e.AddNamedPolicy. err: %w", err)
+	// empty values match any value
+	if _, err := e.RemoveFilteredNamedPolicy("p", 0, subject.Subject(), "", "", "", domain.Domain()); err != nil {
+		return liberrors.Errorf("e.RemoveFilteredNamedPolicy. err: %w", err)
+	}
+	if err := deleteCasbinRuleValidity(ctx, r.DB, "p", subject.Subject()); err != nil {
+		return err
+	}
+
+	if err := r.policyChanged(ctx, domain); err != nil {
+		return liberrors.Errorf("r.policyChanged. err: %w", err)
 	}
 
 	return nil
 }
 
 func (r *rbacRepository) AddSubjectGroupingPolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACUser, object domain.RBACRole) error {
-	e, err := r.initEnforcer(ctx)
+	e, err := r.initEnforcer(ctx, domain)
 	if err != nil {
 		return liberrors.Errorf("r.initEnforcer. err: %w", err)
 	}
@@ -146,11 +257,15 @@ func (r *rbacRepository) AddSubjectGroupingPolicy(ctx context.Context, domain do
 		return liberrors.Errorf("e.AddNamedGroupingPolicy. err: %w", err)
 	}
 
+	if err := r.policyChanged(ctx, domain); err != nil {
+		return liberrors.Errorf("r.policyChanged. err: %w", err)
+	}
+
 	return nil
 }
 
 func (r *rbacRepository) RemoveSubjectGroupingPolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACUser, object domain.RBACRole) error {
-	e, err := r.initEnforcer(ctx)
+	e, err := r.initEnforcer(ctx, domain)
 	if err != nil {
 		return liberrors.Errorf("r.initEnforcer. err: %w", err)
 	}
@@ -158,12 +273,76 @@ func (r *rbacRepository) RemoveSubjectGroupingPolicy(ctx context.Context, domain
 	if _, err := e.RemoveNamedGroupingPolicy("g", subject.Subject(), object.Role(), domain.Domain()); err != nil {
 		return liberrors.Errorf("e.AddNamedGroupingPolicy. err: %w", err)
 	}
+	if err := deleteCasbinRuleValidity(ctx, r.DB, "g", subject.Subject(), object.Role(), domain.Domain()); err != nil {
+		return err
+	}
+
+	if err := r.policyChanged(ctx, domain); err != nil {
+		return liberrors.Errorf("r.policyChanged. err: %w", err)
+	}
+
+	return nil
+}
+
+func (r *rbacRepository) RemoveSubjectGroupingPolicies(ctx context.Context, domain domain.RBACDomain, subject domain.RBACUser) error {
+	e, err := r.initEnforcer(ctx, domain)
+	if err != nil {
+		return liberrors.Errorf("r.initEnforcer. err: %w", err)
+	}
+
+	if _, err := e.RemoveFilteredNamedGroupingPolicy("g", 0, subject.Subject(), "", domain.Domain()); err != nil {
+		return liberrors.Errorf("e.RemoveFilteredNamedGroupingPolicy. err: %w", err)
+	}
+	if err := deleteCasbinRuleValidity(ctx, r.DB, "g", subject.Subject()); err != nil {
+		return err
+	}
+
+	if err := r.policyChanged(ctx, domain); err != nil {
+		return liberrors.Errorf("r.policyChanged. err: %w", err)
+	}
+
+	return nil
+}
+
+func (r *rbacRepository) SetPolicyValidity(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect, validity *domain.RBACValidity) error {
+	values := []string{subject.Subject(), object.Object(), action.Action(), effect.Effect(), domain.Domain()}
+	return r.setValidity(ctx, domain, "p", values, validity)
+}
+
+func (r *rbacRepository) SetSubjectGroupingPolicyValidity(ctx context.Context, domain domain.RBACDomain, subject domain.RBACUser, object domain.RBACRole, validity *domain.RBACValidity) error {
+	values := []string{subject.Subject(), object.Role(), domain.Domain()}
+	return r.setValidity(ctx, domain, "g", values, validity)
+}
+
+func (r *rbacRepository) setValidity(ctx context.Context, rbacDomain domain.RBACDomain, ptype string, values []string, validity *domain.RBACValidity) error {
+	e, err := r.initEnforcer(ctx, rbacDomain)
+	if err != nil {
+		return liberrors.Errorf("r.initEnforcer. err: %w", err)
+	}
+
+	var rules [][]string
+	if ptype == "p" {
+		rules = e.GetFilteredNamedPolicy(ptype, 0, values...)
+	} else {
+		rules = e.GetFilteredNamedGroupingPolicy(ptype, 0, values...)
+	}
+	if len(rules) == 0 {
+		return liberrors.Errorf("rule: %s, err: %w", casbinRuleKey(ptype, values...), service.ErrRBACPolicyNotFound)
+	}
+
+	if err := setCasbinRuleValidity(ctx, r.DB, rbacDomain, ptype, values, validity); err != nil {
+		return err
+	}
+
+	if err := r.policyChanged(ctx, rbacDomain); err != nil {
+		return liberrors.Errorf("r.policyChanged. err: %w", err)
+	}
 
 	return nil
 }
 
 func (r *rbacRepository) AddObjectGroupingPolicy(ctx context.Context, domain domain.RBACDomain, child domain.RBACObject, parent domain.RBACObject) error {
-	e, err := r.initEnforcer(ctx)
+	e, err := r.initEnforcer(ctx, domain)
 	if err != nil {
 		return liberrors.Errorf("r.initEnforcer. err: %w", err)
 	}
@@ -172,11 +351,15 @@ func (r *rbacRepository) AddObjectGroupingPolicy(ctx context.Context, domain dom
 		return liberrors.Errorf("e.AddNamedGroupingPolicy. err: %w", err)
 	}
 
+	if err := r.policyChanged(ctx, domain); err != nil {
+		return liberrors.Errorf("r.policyChanged. err: %w", err)
+	}
+
 	return nil
 }
 
 func (r *rbacRepository) RemoveObjectGroupingPolicy(ctx context.Context, domain domain.RBACDomain, child domain.RBACObject, parent domain.RBACObject) error {
-	e, err := r.initEnforcer(ctx)
+	e, err := r.initEnforcer(ctx, domain)
 	if err != nil {
 		return liberrors.Errorf("r.initEnforcer. err: %w", err)
 	}
@@ -185,10 +368,81 @@ func (r *rbacRepository) RemoveObjectGroupingPolicy(ctx context.Context, domain
 		return liberrors.Errorf("e.AddNamedGroupingPolicy. err: %w", err)
 	}
 
+	if err := r.policyChanged(ctx, domain); err != nil {
+		return liberrors.Errorf("r.policyChanged. err: %w", err)
+	}
+
 	return nil
 }
 
-func (r *rbacRepository) NewEnforcerWithGroupsAndUsers(ctx context.Context, groups []domain.RBACRole, users []domain.RBACUser) (*casbin.Enforcer, error) {
+func (r *rbacRepository) FindPolicies(ctx context.Context, rbacDomain domain.RBACDomain) ([]*domain.RBACPolicy, error) {
+	e, err := r.ruleReader(ctx, rbacDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := e.GetFilteredNamedPolicy("p", 4, rbacDomain.Domain())
+
+	return toRBACPolicies(policies), nil
+}
+
+func (r *rbacRepository) FindPoliciesBySubject(ctx context.Context, rbacDomain domain.RBACDomain, subject domain.RBACSubject) ([]*domain.RBACPolicy, error) {
+	e, err := r.ruleReader(ctx, rbacDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	// empty values match any value
+	policies := e.GetFilteredNamedPolicy("p", 0, subject.Subject(), "", "", "", rbacDomain.Domain())
+
+	return toRBACPolicies(policies), nil
+}
+
+func (r *rbacRepository) FindPoliciesByObject(ctx context.Context, rbacDomain domain.RBACDomain, object domain.RBACObject) ([]*domain.RBACPolicy, error) {
+	e, err := r.ruleReader(ctx, rbacDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := e.GetFilteredNamedPolicy("p", 1, object.Object(), "", "", rbacDomain.Domain())
+
+	return toRBACPolicies(policies), nil
+}
+
+func (r *rbacRepository) FindGroupingRules(ctx context.Context, rbacDomain domain.RBACDomain) ([]*domain.RBACGroupingRule, error) {
+	e, err := r.ruleReader(ctx, rbacDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*domain.RBACGroupingRule, 0)
+	for _, ptype := range []string{"g", "g2"} {
+		for _, g := range e.GetFilteredNamedGroupingPolicy(ptype, 2, rbacDomain.Domain()) {
+			rules = append(rules, domain.NewRBACGroupingRule(ptype, g[0], g[1], rbacDomain))
+		}
+	}
+
+	return rules, nil
+}
+
+// toRBACPolicies converts "p" rules (sub, obj, act, eft, dom, cond) into policies
+func toRBACPolicies(policies [][]string) []*domain.RBACPolicy {
+	rbacPolicies := make([]*domain.RBACPolicy, 0, len(policies))
+	for _, p := range policies {
+		if len(p) < 5 {
+			continue
+		}
+		policy := domain.NewRBACPolicy(domain.NewRBACDomain(p[4]), domain.NewRBACUser(p[0]), domain.NewRBACAction(p[2]), domain.NewRBACObject(p[1]), domain.NewRBACEffect(p[3]))
+		if len(p) > 5 && p[5] != "" {
+			policy.Condition = domain.NewRBACCondition(p[5])
+		}
+		rbacPolicies = append(rbacPolicies, policy)
+	}
+
+	return rbacPolicies
+}
+
+func (r *rbacRepository) NewEnforcerWithGroupsAndUsers(ctx context.Context, rbacDomain domain.RBACDomain, groups []domain.RBACRole, users []domain.RBACUser) (*casbin.Enforcer, error) {
 	subjects := make([]string, 0)
 	for _, s := range groups {
 		subjects = append(subjects, s.Role())
@@ -196,11 +450,36 @@ func (r *rbacRepository) NewEnforcerWithGroupsAndUsers(ctx context.Context, grou
 	for _, s := range users {
 		subjects = append(subjects, s.Subject())
 	}
-	e, err := r.initEnforcer(ctx)
+
+	a, err := newCasbinAdapter(r.DB)
 	if err != nil {
-		return nil, liberrors.Errorf("r.initEnforcer. err: %w", err)
+		return nil, liberrors.Errorf("newCasbinAdapter. err: %w", err)
+	}
+	// the rules out of their validity are not loaded
+	inactive, _, err := findInactiveCasbinRules(ctx, r.DB, rbacDomain, r.now())
+	if err != nil {
+		return nil, err
+	}
+	a.inactive = inactive
+
+	m, err := model.NewModelFromString(r.Conf)
+	if err != nil {
+		return nil, liberrors.Errorf("model.NewModelFromString. err: %w", err)
+	}
+
+	e, err := casbin.NewEnforcer(m)
+	if err != nil {
+		return nil, liberrors.Errorf("casbin.NewEnforcer. err: %w", err)
+	}
+	e.SetAdapter(a)
+	addRBACFunctions(e)
+
+	// "g2" rules are loaded so that the policies on the ancestors of the objects are applied
+	filters := []gormadapter.Filter{
+		{V0: subjects},
+		{Ptype: []string{"g2"}, V2: []string{rbacDomain.Domain()}},
 	}
-	if err := e.LoadFilteredPolicy(gormadapter.Filter{V0: subjects}); err != nil {
+	if err := e.LoadFilteredPolicy(filters); err != nil {
 		return nil, liberrors.Errorf("e.LoadFilteredPolicy. err: %w", err)
 	}
 	return e, nil
@@ -46,7 +46,7 @@ func initRBACRepository(t *testing.T, db *gorm.DB, conf string) error {
 func initEnforcer(t *testing.T, db *gorm.DB, conf string) *casbin.Enforcer {
 	t.Helper()
 
-	a, err := gormadapter.NewAdapterByDB(db)
+	a, err := gateway.NewCasbinAdapter(db)
 	require.NoError(t, err)
 
 	m, err := model.NewModelFromString(conf)
@@ -54,6 +54,7 @@ func initEnforcer(t *testing.T, db *gorm.DB, conf string) *casbin.Enforcer {
 
 	e, err := casbin.NewEnforcer(m, a)
 	require.NoError(t, err)
+	gateway.AddRBACFunctions(e)
 
 	return e
 }
@@ -108,7 +109,7 @@ func TestA(t *testing.T) {
 		for _, tt := range tests {
 			t.Run(tt.String(), func(t *testing.T) {
 				e := initEnforcer(t, ts.db, gateway.Conf)
-				ok, err := e.Enforce(tt.subject, tt.object, tt.action, tt.domain)
+				ok, err := e.Enforce(tt.subject, tt.object, tt.action, tt.domain, domain.RBACAttributes{})
 				require.NoError(t, err)
 				assert.Equal(t, tt.want, ok)
 			})
@@ -117,10 +118,60 @@ func TestA(t *testing.T) {
 	testDB(t, fn)
 }
 
+func Test_rbacRepository_shouldChangeOnlyRulesOfDomain(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService) {
+		// given
+		domain1 := domain.NewRBACDomain("domain:" + RandString(orgNameLength))
+		domain2 := domain.NewRBACDomain("domain:" + RandString(orgNameLength))
+		defer ts.db.Exec("delete from casbin_rule where v4 in (?, ?) or v2 in (?, ?)", domain1.Domain(), domain2.Domain(), domain1.Domain(), domain2.Domain())
+		rbacRepo := gateway.NewRBACRepository(ctx, ts.db)
+		alice := domain.NewRBACUser("alice")
+		addPolicy(t, ctx, rbacRepo, domain1.Domain(), "alice", "read", "data")
+		addPolicy(t, ctx, rbacRepo, domain2.Domain(), "alice", "read", "data")
+
+		// - only the rules of the domain are loaded
+		e, err := gateway.InitRBACEnforcer(ctx, rbacRepo, domain1)
+		require.NoError(t, err)
+		require.NotEmpty(t, e.GetPolicy())
+		for _, p := range e.GetPolicy() {
+			assert.Equal(t, domain1.Domain(), p[4])
+		}
+
+		// when
+		err = rbacRepo.RemovePolicy(ctx, domain1, alice, domain.NewRBACAction("read"), domain.NewRBACObject("data"), service.RBACAllowEffect)
+		require.NoError(t, err)
+
+		// then
+		policies, err := rbacRepo.FindPoliciesBySubject(ctx, domain1, alice)
+		require.NoError(t, err)
+		assert.Len(t, policies, 0)
+		policies, err = rbacRepo.FindPoliciesBySubject(ctx, domain2, alice)
+		require.NoError(t, err)
+		assert.Len(t, policies, 1)
+
+		// when
+		role := domain.NewRBACRole("role")
+		require.NoError(t, rbacRepo.AddSubjectGroupingPolicy(ctx, domain1, alice, role))
+		require.NoError(t, rbacRepo.AddSubjectGroupingPolicy(ctx, domain2, alice, role))
+		err = rbacRepo.RemoveSubjectGroupingPolicies(ctx, domain1, alice)
+		require.NoError(t, err)
+
+		// then
+		rules, err := rbacRepo.FindGroupingRules(ctx, domain1)
+		require.NoError(t, err)
+		assert.Len(t, rules, 0)
+		rules, err = rbacRepo.FindGroupingRules(ctx, domain2)
+		require.NoError(t, err)
+		assert.Len(t, rules, 1)
+	}
+	testDB(t, fn)
+}
+
 func teardownCasbin(t *testing.T, ts testService) {
 	// delete all organizations
 	// ts.db.Exec("delete from space where organization_id = ?", orgID.Int())
-	ts.db.Exec("delete from casbin_rule")
+	ts.db.Exec("delete from casbin_rule where v4 = ?", "domain1")
 	// db.Where("true").Delete(&spaceEntity{})
 	// db.Where("true").Delete(&appUserEntity{})
 	// db.Where("true").Delete(&organizationEntity{})
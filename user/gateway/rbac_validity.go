@@ -0,0 +1,165 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+const (
+	CasbinRuleValidityTableName = "casbin_rule_validity"
+	RBACExpiredGrantTableName   = "rbac_expired_grant"
+)
+
+// casbinRuleValidityEntity is the validity of the "p" rule (sub, obj, act, eft, dom) or the "g" rule (user, role, dom).
+// The condition of the "p" rule is not a part of the key because a policy has at most one condition
+type casbinRuleValidityEntity struct {
+	CreatedAt  time.Time
+	Ptype      string
+	V0         string
+	V1         string
+	V2         string
+	V3         string
+	V4         string
+	Domain     string
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+}
+
+func (e *casbinRuleValidityEntity) TableName() string {
+	return CasbinRuleValidityTableName
+}
+
+func (e *casbinRuleValidityEntity) key() string {
+	return casbinRuleKey(e.Ptype, e.V0, e.V1, e.V2, e.V3, e.V4)
+}
+
+func (e *casbinRuleValidityEntity) validity() *domain.RBACValidity {
+	return &domain.RBACValidity{ValidFrom: e.ValidFrom, ValidUntil: e.ValidUntil}
+}
+
+type rbacExpiredGrantEntity struct {
+	ID         int
+	ExpiredAt  time.Time
+	Domain     string
+	Ptype      string
+	V0         string
+	V1         string
+	V2         string
+	V3         string
+	V4         string
+	ValidFrom  *time.Time
+	ValidUntil time.Time
+}
+
+func (e *rbacExpiredGrantEntity) TableName() string {
+	return RBACExpiredGrantTableName
+}
+
+func (e *rbacExpiredGrantEntity) toModel() *domain.RBACExpiredGrant {
+	grant := domain.RBACExpiredGrant{
+		ID:         e.ID,
+		ValidFrom:  e.ValidFrom,
+		ValidUntil: e.ValidUntil,
+		ExpiredAt:  e.ExpiredAt,
+	}
+	if e.Ptype == "p" {
+		grant.Policy = toRBACPolicies([][]string{{e.V0, e.V1, e.V2, e.V3, e.V4}})[0]
+	} else {
+		grant.GroupingRule = domain.NewRBACGroupingRule(e.Ptype, e.V0, e.V1, domain.NewRBACDomain(e.V2))
+	}
+
+	return &grant
+}
+
+// casbinRuleKey identifies the rule by the first 5 values, which are the ones of casbinRuleValidityEntity
+func casbinRuleKey(ptype string, values ...string) string {
+	key := make([]string, 6)
+	key[0] = ptype
+	copy(key[1:], values)
+
+	return strings.Join(key, ", ")
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// setCasbinRuleValidity replaces the validity of the rule. nil validity makes the rule permanent
+func setCasbinRuleValidity(ctx context.Context, db *gorm.DB, rbacDomain domain.RBACDomain, ptype string, values []string, validity *domain.RBACValidity) error {
+	if err := deleteCasbinRuleValidity(ctx, db, ptype, values...); err != nil {
+		return err
+	}
+	if validity == nil {
+		return nil
+	}
+
+	entity := casbinRuleValidityEntity{
+		CreatedAt:  time.Now().UTC(),
+		Ptype:      ptype,
+		Domain:     rbacDomain.Domain(),
+		ValidFrom:  utcTime(validity.ValidFrom),
+		ValidUntil: utcTime(validity.ValidUntil),
+	}
+	for i, v := range []*string{&entity.V0, &entity.V1, &entity.V2, &entity.V3, &entity.V4} {
+		if i < len(values) {
+			*v = values[i]
+		}
+	}
+	if err := db.WithContext(ctx).Create(&entity).Error; err != nil {
+		return liberrors.Errorf("insert %s. err: %w", CasbinRuleValidityTableName, err)
+	}
+
+	return nil
+}
+
+// deleteCasbinRuleValidity deletes the validities of the rules which start with the values
+func deleteCasbinRuleValidity(ctx context.Context, db *gorm.DB, ptype string, values ...string) error {
+	db = db.WithContext(ctx).Where("ptype = ?", ptype)
+	for i, v := range values {
+		if i >= 5 {
+			break
+		}
+		db = db.Where(fmt.Sprintf("v%d = ?", i), v)
+	}
+	if err := db.Delete(&casbinRuleValidityEntity{}).Error; err != nil {
+		return liberrors.Errorf("delete %s. err: %w", CasbinRuleValidityTableName, err)
+	}
+
+	return nil
+}
+
+// findInactiveCasbinRules returns the keys of the rules of the domain which are not effective at the time,
+// and the earliest time after it when any of them becomes effective or expires
+func findInactiveCasbinRules(ctx context.Context, db *gorm.DB, rbacDomain domain.RBACDomain, at time.Time) (map[string]bool, *time.Time, error) {
+	entities := make([]casbinRuleValidityEntity, 0)
+	if err := db.WithContext(ctx).Where("domain = ?", rbacDomain.Domain()).Find(&entities).Error; err != nil {
+		return nil, nil, liberrors.Errorf("select %s. err: %w", CasbinRuleValidityTableName, err)
+	}
+
+	inactive := make(map[string]bool)
+	var nextChange *time.Time
+	for i := range entities {
+		e := &entities[i]
+		if !e.validity().IsActiveAt(at) {
+			inactive[e.key()] = true
+		}
+		for _, t := range []*time.Time{e.ValidFrom, e.ValidUntil} {
+			if t != nil && t.After(at) && (nextChange == nil || t.Before(*nextChange)) {
+				nextChange = t
+			}
+		}
+	}
+
+	return inactive, nextChange, nil
+}
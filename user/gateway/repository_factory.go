@@ -9,26 +9,33 @@ import (
 	libdomain "github.com/kujilabo/redstart/lib/domain"
 	liberrors "github.com/kujilabo/redstart/lib/errors"
 	libgateway "github.com/kujilabo/redstart/lib/gateway"
+	"github.com/kujilabo/redstart/user/domain"
 	"github.com/kujilabo/redstart/user/service"
 )
 
 type repositoryFactory struct {
-	dialect    libgateway.DialectRDBMS
-	driverName string
-	db         *gorm.DB
-	location   *time.Location
+	dialect        libgateway.DialectRDBMS
+	driverName     string
+	db             *gorm.DB
+	location       *time.Location
+	enforcerCache  *EnforcerCache
+	changedDomains *changedDomains
 }
 
-func NewRepositoryFactory(ctx context.Context, dialect libgateway.DialectRDBMS, driverName string, db *gorm.DB, location *time.Location) (service.RepositoryFactory, error) {
+// NewRepositoryFactory returns the factory of the repositories using db.
+// enforcerCache is shared by the factories of all the transactions. Authorize loads the policies every time when it is nil
+func NewRepositoryFactory(ctx context.Context, dialect libgateway.DialectRDBMS, driverName string, db *gorm.DB, location *time.Location, enforcerCache *EnforcerCache) (service.RepositoryFactory, error) {
 	if db == nil {
 		return nil, liberrors.Errorf("db is nil. err: %w", libdomain.ErrInvalidArgument)
 	}
 
 	return &repositoryFactory{
-		dialect:    dialect,
-		driverName: driverName,
-		db:         db,
-		location:   location,
+		dialect:        dialect,
+		driverName:     driverName,
+		db:             db,
+		location:       location,
+		enforcerCache:  enforcerCache,
+		changedDomains: &changedDomains{},
 	}, nil
 }
 
@@ -56,4 +63,33 @@ func (f *repositoryFactory) NewAuthorizationManager(ctx context.Context) service
 	return NewAuthorizationManager(ctx, f.dialect, f.db, f)
 }
 
+func (f *repositoryFactory) NewObjectHierarchy(ctx context.Context) service.ObjectHierarchy {
+	return NewObjectHierarchy(ctx, f.dialect, f.db, f)
+}
+
+func (f *repositoryFactory) NewAuditLogRepository(ctx context.Context) service.AuditLogRepository {
+	return NewAuditLogRepository(ctx, f.db)
+}
+
+// policyChanged discards the cached enforcer of the domain
+func (f *repositoryFactory) policyChanged(rbacDomain domain.RBACDomain) {
+	if f.enforcerCache == nil {
+		return
+	}
+
+	f.enforcerCache.Invalidate(rbacDomain)
+	f.changedDomains.add(rbacDomain)
+}
+
+// transactionEnded discards the enforcers loaded while the policies were being changed in the transaction
+func (f *repositoryFactory) transactionEnded() {
+	if f.enforcerCache == nil {
+		return
+	}
+
+	for _, rbacDomain := range f.changedDomains.flush() {
+		f.enforcerCache.Invalidate(rbacDomain)
+	}
+}
+
 type RepositoryFactoryFunc func(ctx context.Context, db *gorm.DB) (service.RepositoryFactory, error)
@@ -21,11 +21,19 @@ func NewTransactionManager(db *gorm.DB, rff RepositoryFactoryFunc) (service.Tran
 }
 
 func (t *transactionManager) Do(ctx context.Context, fn func(rf service.RepositoryFactory) error) error {
+	var rf service.RepositoryFactory
+	defer func() {
+		if f, ok := rf.(*repositoryFactory); ok {
+			f.transactionEnded()
+		}
+	}()
+
 	return t.db.Transaction(func(tx *gorm.DB) error { // nolint:wrapcheck
-		rf, err := t.rff(ctx, tx)
+		tmpRF, err := t.rff(ctx, tx)
 		if err != nil {
 			return err // nolint:wrapcheck
 		}
+		rf = tmpRF
 		return fn(rf)
 	})
 }
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"errors"
 
 	"gorm.io/gorm"
 
@@ -111,6 +112,9 @@ func (r *userGroupRepository) FindSystemOwnerGroup(ctx context.Context, operator
 		OrganizationID: organizationID.Int(),
 		KeyName:        service.SystemOwnerGroupKey,
 	}).First(&userGroup); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, service.ErrUserGroupNotFound
+		}
 		return nil, result.Error
 	}
 	return userGroup.toUserGroup()
@@ -124,6 +128,9 @@ func (r *userGroupRepository) FindUserGroupByID(ctx context.Context, operator se
 	if result := r.db.Where("organization_id = ?", operator.OrganizationID().Int()).
 		Where("id = ? and removed = ?", userGroupID.Int(), r.dialect.BoolDefaultValue()).
 		First(&userGroup); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, service.ErrUserGroupNotFound
+		}
 		return nil, result.Error
 	}
 	return userGroup.toUserGroup()
@@ -137,6 +144,9 @@ func (r *userGroupRepository) FindUserGroupByKey(ctx context.Context, operator s
 	if result := r.db.Where("organization_id = ?", operator.OrganizationID().Int()).
 		Where("key_name = ? and removed = ?", key, r.dialect.BoolDefaultValue()).
 		First(&userGroup); result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, service.ErrUserGroupNotFound
+		}
 		return nil, result.Error
 	}
 	return userGroup.toUserGroup()
@@ -147,16 +157,11 @@ func (r *userGroupRepository) AddSystemOwnerGroup(ctx context.Context, operator
 	defer span.End()
 
 	userGroup := userGroupEntity{
-		BaseModelEntity: BaseModelEntity{
-			Version:   1,
-			CreatedBy: operator.AppUserID().Int(),
-			UpdatedBy: operator.AppUserID().Int(),
-		},
 		OrganizationID: organizationID.Int(),
 		KeyName:        service.SystemOwnerGroupKey,
 		Name:           service.SystemOwnerGroupName,
 	}
-	if result := r.db.Create(&userGroup); result.Error != nil {
+	if result := r.db.WithContext(service.WithOperator(ctx, operator)).Create(&userGroup); result.Error != nil {
 		return nil, liberrors.Errorf(". err: %w", libgateway.ConvertDuplicatedError(result.Error, service.ErrAppUserAlreadyExists))
 	}
 
@@ -173,16 +178,11 @@ func (r *userGroupRepository) AddOwnerGroup(ctx context.Context, operator servic
 	defer span.End()
 
 	userGroup := userGroupEntity{
-		BaseModelEntity: BaseModelEntity{
-			Version:   1,
-			CreatedBy: operator.AppUserID().Int(),
-			UpdatedBy: operator.AppUserID().Int(),
-		},
 		OrganizationID: organizationID.Int(),
 		KeyName:        service.OwnerGroupKey,
 		Name:           service.OwnerGroupName,
 	}
-	if result := r.db.Create(&userGroup); result.Error != nil {
+	if result := r.db.WithContext(service.WithOperator(ctx, operator)).Create(&userGroup); result.Error != nil {
 		return nil, liberrors.Errorf(". err: %w", libgateway.ConvertDuplicatedError(result.Error, service.ErrAppUserAlreadyExists))
 	}
 
@@ -199,17 +199,12 @@ func (r *userGroupRepository) AddUserGroup(ctx context.Context, operator service
 	defer span.End()
 
 	userGroup := userGroupEntity{
-		BaseModelEntity: BaseModelEntity{
-			Version:   1,
-			CreatedBy: operator.AppUserID().Int(),
-			UpdatedBy: operator.AppUserID().Int(),
-		},
 		OrganizationID: operator.OrganizationID().Int(),
 		KeyName:        parameter.Key(),
 		Name:           parameter.Name(),
 		Description:    parameter.Description(),
 	}
-	if result := r.db.Create(&userGroup); result.Error != nil {
+	if result := r.db.WithContext(service.WithOperator(ctx, operator)).Create(&userGroup); result.Error != nil {
 		return nil, liberrors.Errorf(". err: %w", libgateway.ConvertDuplicatedError(result.Error, service.ErrAppUserAlreadyExists))
 	}
 
@@ -220,3 +215,20 @@ func (r *userGroupRepository) AddUserGroup(ctx context.Context, operator service
 
 	return userGroupID, nil
 }
+
+func (r *userGroupRepository) UpdateUserGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, version int, parameter service.UserGroupUpdateParameterInterface) error {
+	_, span := tracer.Start(ctx, "userGroupRepository.UpdateUserGroup")
+	defer span.End()
+
+	if err := updateWithVersion(r.db.WithContext(service.WithOperator(ctx, operator)), &userGroupEntity{}, func(db *gorm.DB) *gorm.DB {
+		wrappedDB := wrappedDB{dialect: r.dialect, db: db, organizationID: operator.OrganizationID()}
+		return wrappedDB.WhereUserGroup().Where("user_group.id = ?", userGroupID.Int()).db
+	}, version, map[string]interface{}{
+		"name":        parameter.Name(),
+		"description": parameter.Description(),
+	}, service.ErrUserGroupNotFound); err != nil {
+		return liberrors.Errorf("updateWithVersion. err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package gateway_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/gateway"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+func Test_userGroupRepository_UpdateUserGroup(t *testing.T) {
+	t.Parallel()
+	fn := func(t *testing.T, ctx context.Context, ts testService, orgID *domain.OrganizationID, sysOwner *service.SystemOwner, owner *service.Owner) {
+		userGroupRepo := gateway.NewUserGroupRepository(ctx, ts.dialect, ts.db)
+		addParam, err := service.NewUserGroupAddParameter("KEY", "NAME", "")
+		require.NoError(t, err)
+		userGroupID, err := userGroupRepo.AddUserGroup(ctx, owner, addParam)
+		require.NoError(t, err)
+		updateParam, err := service.NewUserGroupUpdateParameter("NEW_NAME", "NEW_DESCRIPTION")
+		require.NoError(t, err)
+
+		// update user group with the current version
+		{
+			err := userGroupRepo.UpdateUserGroup(ctx, owner, userGroupID, 1, updateParam)
+			require.NoError(t, err)
+			userGroup, err := userGroupRepo.FindUserGroupByID(ctx, owner, userGroupID)
+			require.NoError(t, err)
+			assert.Equal(t, "KEY", userGroup.UserGroupModel.Key)
+			assert.Equal(t, "NEW_NAME", userGroup.UserGroupModel.Name)
+			assert.Equal(t, "NEW_DESCRIPTION", userGroup.UserGroupModel.Description)
+			assert.Equal(t, 2, userGroup.Version)
+		}
+
+		// update user group with the old version
+		{
+			err := userGroupRepo.UpdateUserGroup(ctx, owner, userGroupID, 1, updateParam)
+			assert.ErrorIs(t, err, libdomain.ErrConcurrentModification)
+		}
+
+		// update user group unregistered
+		{
+			invalidUserGroupID, err := domain.NewUserGroupID(99999)
+			require.NoError(t, err)
+			err = userGroupRepo.UpdateUserGroup(ctx, owner, invalidUserGroupID, 1, updateParam)
+			assert.ErrorIs(t, err, service.ErrUserGroupNotFound)
+		}
+	}
+	testOrganization(t, fn)
+}
@@ -49,6 +49,11 @@ func (x *wrappedDB) WhereNotRemoved(table HasTableName) *wrappedDB {
 	return x
 }
 
+func (x *wrappedDB) WhereRemoved(table HasTableName) *wrappedDB {
+	x.db = x.db.Where(fmt.Sprintf("%s.removed <> ?", table.TableName()), x.dialect.BoolDefaultValue())
+	return x
+}
+
 func (x *wrappedDB) WhereAppUser() *wrappedDB {
 	return x.WhereOrganizationID(&appUserEntity{}, x.organizationID).WhereNotRemoved(&appUserEntity{})
 }
@@ -60,3 +65,7 @@ func (x *wrappedDB) WhereUserGroup() *wrappedDB {
 func (x *wrappedDB) WherePairOfUserAndGroup() *wrappedDB {
 	return x.WhereOrganizationID(&pairOfUserAndGroupEntity{}, x.organizationID)
 }
+
+func (x *wrappedDB) WherePairOfGroupAndGroup() *wrappedDB {
+	return x.WhereOrganizationID(&pairOfGroupAndGroupEntity{}, x.organizationID)
+}
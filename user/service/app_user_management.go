@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+func isSystemOwner(operator OwnerModelInterface) bool {
+	systemOwner, ok := operator.(SystemOwnerInterface)
+	return ok && systemOwner.IsSystemOwner()
+}
+
+func belongsToOwnerGroup(appUser *AppUser) bool {
+	for _, userGroup := range appUser.UserGroups {
+		if userGroup.Key == OwnerGroupKey {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findManageableAppUser returns the app user which the operator can change
+func findManageableAppUser(ctx context.Context, appUserRepo AppUserRepository, operator OwnerModelInterface, appUserID *domain.AppUserID) (*AppUser, error) {
+	appUser, err := appUserRepo.FindAppUserByID(ctx, operator, appUserID, IncludeGroups)
+	if err != nil {
+		return nil, liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", err)
+	}
+
+	if err := checkManageableAppUser(operator, appUser); err != nil {
+		return nil, err
+	}
+
+	return appUser, nil
+}
+
+// checkManageableAppUser returns libdomain.ErrPermissionDenied when the operator cannot change the app user.
+// Nobody can change the system owner, and the other owners can be changed only by the system owner
+func checkManageableAppUser(operator OwnerModelInterface, appUser *AppUser) error {
+	if appUser.LoginID() == SystemOwnerLoginID {
+		return liberrors.Errorf("system owner cannot be changed. err: %w", libdomain.ErrPermissionDenied)
+	}
+
+	if appUser.AppUserID().Int() != operator.AppUserID().Int() && belongsToOwnerGroup(appUser) && !isSystemOwner(operator) {
+		return liberrors.Errorf("owner can be changed only by the system owner. app user ID: %d, err: %w", appUser.AppUserID().Int(), libdomain.ErrPermissionDenied)
+	}
+
+	return nil
+}
+
+func updateAppUser(ctx context.Context, appUserRepo AppUserRepository, auditLogRepo AuditLogRepository, operator OwnerModelInterface, appUserID *domain.AppUserID, version int, param AppUserUpdateParameterInterface) error {
+	appUser, err := findManageableAppUser(ctx, appUserRepo, operator, appUserID)
+	if err != nil {
+		return err
+	}
+
+	if err := appUserRepo.UpdateAppUser(ctx, operator, appUserID, version, param); err != nil {
+		return liberrors.Errorf("appUserRepo.UpdateAppUser. err: %w", err)
+	}
+
+	return addAuditEvent(ctx, auditLogRepo, operator, operator.OrganizationID(), domain.AuditActionUpdateAppUser, domain.AuditTargetTypeAppUser, appUserID.Int(), &appUserAuditValue{
+		LoginID:  appUser.LoginID(),
+		Username: appUser.Username(),
+		Version:  version,
+	}, &appUserAuditValue{
+		LoginID:  appUser.LoginID(),
+		Username: param.Username(),
+		Version:  version + 1,
+	})
+}
+
+// changePassword replaces the password of the app user. The password is not recorded in the audit log
+func changePassword(ctx context.Context, appUserRepo AppUserRepository, auditLogRepo AuditLogRepository, operator OwnerModelInterface, appUserID *domain.AppUserID, password string) error {
+	if err := ValidatePassword(password); err != nil {
+		return err
+	}
+
+	if _, err := findManageableAppUser(ctx, appUserRepo, operator, appUserID); err != nil {
+		return err
+	}
+
+	if err := appUserRepo.ChangePassword(ctx, operator, appUserID, password); err != nil {
+		return liberrors.Errorf("appUserRepo.ChangePassword. err: %w", err)
+	}
+
+	return addAuditEvent(ctx, auditLogRepo, operator, operator.OrganizationID(), domain.AuditActionChangePassword, domain.AuditTargetTypeAppUser, appUserID.Int(), nil, nil)
+}
+
+func removeAppUser(ctx context.Context, appUserRepo AppUserRepository, auditLogRepo AuditLogRepository, operator OwnerModelInterface, appUserID *domain.AppUserID, version int) error {
+	if appUserID.Int() == operator.AppUserID().Int() {
+		return liberrors.Errorf("operator cannot remove itself. err: %w", libdomain.ErrPermissionDenied)
+	}
+
+	appUser, err := findManageableAppUser(ctx, appUserRepo, operator, appUserID)
+	if err != nil {
+		return err
+	}
+
+	if err := appUserRepo.RemoveAppUser(ctx, operator, appUserID, version); err != nil {
+		return liberrors.Errorf("appUserRepo.RemoveAppUser. err: %w", err)
+	}
+
+	return addAuditEvent(ctx, auditLogRepo, operator, operator.OrganizationID(), domain.AuditActionRemoveAppUser, domain.AuditTargetTypeAppUser, appUserID.Int(), &appUserAuditValue{
+		LoginID:  appUser.LoginID(),
+		Username: appUser.Username(),
+		Version:  version,
+	}, nil)
+}
+
+func restoreAppUser(ctx context.Context, appUserRepo AppUserRepository, auditLogRepo AuditLogRepository, operator OwnerModelInterface, appUserID *domain.AppUserID, version int) error {
+	if appUserID.Int() == operator.AppUserID().Int() {
+		return liberrors.Errorf("operator cannot restore itself. err: %w", libdomain.ErrPermissionDenied)
+	}
+
+	appUser, err := appUserRepo.FindRemovedAppUserByID(ctx, operator, appUserID)
+	if err != nil {
+		return liberrors.Errorf("appUserRepo.FindRemovedAppUserByID. err: %w", err)
+	}
+
+	if err := checkManageableAppUser(operator, appUser); err != nil {
+		return err
+	}
+
+	if err := appUserRepo.RestoreAppUser(ctx, operator, appUserID, version); err != nil {
+		return liberrors.Errorf("appUserRepo.RestoreAppUser. err: %w", err)
+	}
+
+	return addAuditEvent(ctx, auditLogRepo, operator, operator.OrganizationID(), domain.AuditActionRestoreAppUser, domain.AuditTargetTypeAppUser, appUserID.Int(), nil, &appUserAuditValue{
+		LoginID:  appUser.LoginID(),
+		Username: appUser.Username(),
+		Version:  version + 1,
+	})
+}
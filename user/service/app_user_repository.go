@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 
 	libdomain "github.com/kujilabo/redstart/lib/domain"
 	liberrors "github.com/kujilabo/redstart/lib/errors"
@@ -14,6 +15,18 @@ var ErrAppUserAlreadyExists = errors.New("AppUser already exists")
 
 var ErrSystemOwnerNotFound = errors.New("SystemOwner not found")
 
+// passwordValidationTag is the rule of the passwords of the app users. bcrypt uses only the first 72 bytes of the password
+const passwordValidationTag = "min=8,max=72,printascii"
+
+// ValidatePassword returns libdomain.ErrInvalidArgument when the password does not satisfy the rule of the passwords of the app users
+func ValidatePassword(password string) error {
+	if err := libdomain.Validator.Var(password, "required,"+passwordValidationTag); err != nil {
+		return liberrors.Errorf("password is invalid. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	return nil
+}
+
 type AppUserAddParameterInterface interface {
 	LoginID() string
 	Username() string
@@ -47,6 +60,15 @@ func NewAppUserAddParameter(loginID, username, password, provider, providerLogin
 	if err := libdomain.Validator.Struct(m); err != nil {
 		return nil, liberrors.Errorf("libdomain.Validator.Struct. err: %w", err)
 	}
+	if strings.HasPrefix(loginID, RemovedLoginIDPrefix) {
+		return nil, liberrors.Errorf("loginID must not start with %q. err: %w", RemovedLoginIDPrefix, libdomain.ErrInvalidArgument)
+	}
+	// the app users signing in with the providers have no password
+	if password != "" {
+		if err := ValidatePassword(password); err != nil {
+			return nil, err
+		}
+	}
 
 	return m, nil
 }
@@ -73,10 +95,116 @@ func (p *AppUserAddParameter) ProviderRefreshToken() string {
 	return p.providerRefreshTokenInternal
 }
 
+type AppUserUpdateParameterInterface interface {
+	Username() string
+}
+
+type AppUserUpdateParameter struct {
+	UsernameInternal string `validate:"required"`
+}
+
+func NewAppUserUpdateParameter(username string) (*AppUserUpdateParameter, error) {
+	m := &AppUserUpdateParameter{
+		UsernameInternal: username,
+	}
+	if err := libdomain.Validator.Struct(m); err != nil {
+		return nil, liberrors.Errorf("libdomain.Validator.Struct. err: %w", err)
+	}
+
+	return m, nil
+}
+
+func (p *AppUserUpdateParameter) Username() string {
+	return p.UsernameInternal
+}
+
 type Option string
 
 var IncludeGroups Option = "IncludeGroups"
 
+type AppUserSortKey string
+
+const (
+	AppUserSortKeyID        AppUserSortKey = "id"
+	AppUserSortKeyLoginID   AppUserSortKey = "loginId"
+	AppUserSortKeyUsername  AppUserSortKey = "username"
+	AppUserSortKeyCreatedAt AppUserSortKey = "createdAt"
+)
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+const (
+	DefaultAppUserPageSize = 20
+	MaxAppUserPageSize     = 1000
+)
+
+// AppUserFilter filters the app users. The empty values do not filter them.
+// LoginIDPrefix matches the beginning of the login ID, and UsernameContains matches a part of the username case-insensitively
+type AppUserFilter struct {
+	LoginIDPrefix    string `validate:"max=200"`
+	UsernameContains string `validate:"max=40"`
+	UserGroupID      *domain.UserGroupID
+	Provider         string `validate:"max=40"`
+	// Removed returns the removed app users instead of the active ones
+	Removed bool
+}
+
+// AppUserSearchCondition is the filter, the order and the page of the app users.
+// The page is specified by either PageNo, which starts from 1, or Cursor, which is NextCursor of the previous page
+type AppUserSearchCondition struct {
+	Filter    AppUserFilter
+	SortKey   AppUserSortKey `validate:"oneof=id loginId username createdAt"`
+	SortOrder SortOrder      `validate:"oneof=asc desc"`
+	PageNo    int            `validate:"gte=1"`
+	PageSize  int            `validate:"gte=1,lte=1000"`
+	Cursor    string
+}
+
+func NewAppUserSearchCondition(filter AppUserFilter, sortKey AppUserSortKey, sortOrder SortOrder, pageNo, pageSize int, cursor string) (*AppUserSearchCondition, error) {
+	if sortKey == "" {
+		sortKey = AppUserSortKeyID
+	}
+	if sortOrder == "" {
+		sortOrder = SortOrderAsc
+	}
+	if cursor != "" && pageNo > 1 {
+		return nil, liberrors.Errorf("pageNo and cursor must not be specified together. err: %w", libdomain.ErrInvalidArgument)
+	}
+	if pageNo == 0 {
+		pageNo = 1
+	}
+	if pageSize == 0 {
+		pageSize = DefaultAppUserPageSize
+	}
+
+	m := &AppUserSearchCondition{
+		Filter:    filter,
+		SortKey:   sortKey,
+		SortOrder: sortOrder,
+		PageNo:    pageNo,
+		PageSize:  pageSize,
+		Cursor:    cursor,
+	}
+	if err := libdomain.Validator.Struct(m); err != nil {
+		return nil, liberrors.Errorf("libdomain.Validator.Struct. err: %w", err)
+	}
+
+	return m, nil
+}
+
+// AppUserSearchResult is the page of the app users. TotalCount is the number of the app users matching the filter,
+// and NextCursor is empty on the last page. The removed app users have their own login IDs
+type AppUserSearchResult struct {
+	AppUsers   []*AppUser
+	TotalCount int
+	NextCursor string
+}
+
 type AppUserRepository interface {
 	FindSystemOwnerByOrganizationID(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID) (*SystemOwner, error)
 
@@ -86,15 +214,34 @@ type AppUserRepository interface {
 
 	FindAppUserByLoginID(ctx context.Context, operator AppUserInterface, loginID string) (*AppUser, error)
 
+	// FindRemovedAppUserByID returns the removed app user with its own login ID. ErrAppUserNotFound is returned when the app user is not removed
+	FindRemovedAppUserByID(ctx context.Context, operator AppUserInterface, id *domain.AppUserID) (*AppUser, error)
+
 	FindOwnerByLoginID(ctx context.Context, operator SystemOwnerInterface, loginID string) (*Owner, error)
 
 	AddAppUser(ctx context.Context, operator OwnerModelInterface, param AppUserAddParameterInterface) (*domain.AppUserID, error)
 
 	AddSystemOwner(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID) (*domain.AppUserID, error)
 
+	// UpdateAppUser updates the app user whose version is the specified one. libdomain.ErrConcurrentModification is returned when it has been updated by others
+	UpdateAppUser(ctx context.Context, operator OwnerModelInterface, id *domain.AppUserID, version int, param AppUserUpdateParameterInterface) error
+
+	// ChangePassword replaces the password of the app user
+	ChangePassword(ctx context.Context, operator AppUserInterface, id *domain.AppUserID, password string) error
+
+	// RemoveAppUser marks the app user whose version is the specified one as removed, and revokes its memberships of the user groups and its policies.
+	// The login ID is released so that another app user can use it
+	RemoveAppUser(ctx context.Context, operator OwnerModelInterface, id *domain.AppUserID, version int) error
+
+	// RestoreAppUser restores the removed app user whose version is the specified one with its login ID.
+	// ErrAppUserAlreadyExists is returned when the login ID has been used by another app user. The revoked memberships and policies are not restored
+	RestoreAppUser(ctx context.Context, operator OwnerModelInterface, id *domain.AppUserID, version int) error
+
 	VerifyPassword(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID, loginID, password string) (bool, error)
 
-	// AddFirstOwner(ctx context.Context, operator domain.SystemOwnerModel, param FirstOwnerAddParameter) (domain.AppUserID, error)
+	// FindAppUsers returns the page of the app users in the organization of the operator matching the condition.
+	// libdomain.ErrInvalidArgument is returned when the cursor is not the one of the same sort key and order
+	FindAppUsers(ctx context.Context, operator OwnerModelInterface, condition *AppUserSearchCondition) (*AppUserSearchResult, error)
 
-	// FindAppUserIDs(ctx context.Context, operator domain.SystemOwnerModel, pageNo, pageSize int) ([]domain.AppUserID, error)
+	// AddFirstOwner(ctx context.Context, operator domain.SystemOwnerModel, param FirstOwnerAddParameter) (domain.AppUserID, error)
 }
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+const (
+	DefaultAuditEventLimit = 100
+	MaxAuditEventLimit     = 1000
+)
+
+// AuditEventAddParameter is the action to record. Before and After are marshaled into JSON, and nil is recorded as empty
+type AuditEventAddParameter struct {
+	OrganizationID *domain.OrganizationID `validate:"required"`
+	Action         domain.AuditAction     `validate:"required"`
+	Target         *domain.AuditTarget    `validate:"required"`
+	Before         interface{}
+	After          interface{}
+}
+
+func NewAuditEventAddParameter(organizationID *domain.OrganizationID, action domain.AuditAction, target *domain.AuditTarget, before, after interface{}) (*AuditEventAddParameter, error) {
+	m := &AuditEventAddParameter{
+		OrganizationID: organizationID,
+		Action:         action,
+		Target:         target,
+		Before:         before,
+		After:          after,
+	}
+	if err := libdomain.Validator.Struct(m); err != nil {
+		return nil, liberrors.Errorf("libdomain.Validator.Struct. err: %w", err)
+	}
+
+	return m, nil
+}
+
+// AuditEventSearchCondition filters the audit events. The events in [From, To) are returned, the latest first.
+// The zero From or To does not bound the time range, nil ActorID or Target does not filter them
+type AuditEventSearchCondition struct {
+	From    time.Time
+	To      time.Time
+	ActorID *domain.AppUserID
+	Target  *domain.AuditTarget
+	Limit   int `validate:"gte=0,lte=1000"`
+}
+
+func NewAuditEventSearchCondition(from, to time.Time, actorID *domain.AppUserID, target *domain.AuditTarget, limit int) (*AuditEventSearchCondition, error) {
+	if limit == 0 {
+		limit = DefaultAuditEventLimit
+	}
+
+	m := &AuditEventSearchCondition{
+		From:    from,
+		To:      to,
+		ActorID: actorID,
+		Target:  target,
+		Limit:   limit,
+	}
+	if err := libdomain.Validator.Struct(m); err != nil {
+		return nil, liberrors.Errorf("libdomain.Validator.Struct. err: %w", err)
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return nil, liberrors.Errorf("to must be after from. err: %w", libdomain.ErrInvalidArgument)
+	}
+
+	return m, nil
+}
+
+// AuditLogRepository records the actions changing the organizations, the app users, the user groups and the policies.
+// The events are never updated nor removed
+type AuditLogRepository interface {
+	// AddAuditEvent records the action performed by the operator. The request ID and the trace ID are taken from ctx
+	AddAuditEvent(ctx context.Context, operator OperatorInterface, param *AuditEventAddParameter) error
+
+	// FindAuditEvents returns the audit events of the organization of the operator
+	FindAuditEvents(ctx context.Context, operator AppUserInterface, condition *AuditEventSearchCondition) ([]*domain.AuditEventModel, error)
+
+	FindAuditEventsBySystemAdmin(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID, condition *AuditEventSearchCondition) ([]*domain.AuditEventModel, error)
+}
+
+type organizationAuditValue struct {
+	Name    string `json:"name"`
+	Version int    `json:"version,omitempty"`
+}
+
+type appUserAuditValue struct {
+	LoginID  string `json:"loginId"`
+	Username string `json:"username"`
+	Version  int    `json:"version,omitempty"`
+}
+
+type userGroupAuditValue struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     int    `json:"version,omitempty"`
+}
+
+// addAuditEvent records the action on the target of the type and the ID
+func addAuditEvent(ctx context.Context, auditLogRepo AuditLogRepository, operator OperatorInterface, organizationID *domain.OrganizationID, action domain.AuditAction, targetType domain.AuditTargetType, targetID int, before, after interface{}) error {
+	target, err := domain.NewAuditTarget(targetType, strconv.Itoa(targetID))
+	if err != nil {
+		return liberrors.Errorf("domain.NewAuditTarget. err: %w", err)
+	}
+
+	param, err := NewAuditEventAddParameter(organizationID, action, target, before, after)
+	if err != nil {
+		return liberrors.Errorf("NewAuditEventAddParameter. err: %w", err)
+	}
+
+	if err := auditLogRepo.AddAuditEvent(ctx, operator, param); err != nil {
+		return liberrors.Errorf("auditLogRepo.AddAuditEvent. err: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+type TokenSet struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// AppUserTokenInfo is the app user identified by a token
+type AppUserTokenInfo struct {
+	OrganizationID *domain.OrganizationID
+	AppUserID      *domain.AppUserID
+}
+
+type AuthTokenManager interface {
+	CreateTokenSet(ctx context.Context, appUser AppUserInterface) (*TokenSet, error)
+
+	CreateAccessToken(ctx context.Context, appUser AppUserInterface) (string, error)
+
+	// ParseAccessToken returns ErrUnauthenticated when the token is invalid or expired
+	ParseAccessToken(ctx context.Context, accessToken string) (*AppUserTokenInfo, error)
+
+	// ParseRefreshToken returns ErrUnauthenticated when the token is invalid or expired
+	ParseRefreshToken(ctx context.Context, refreshToken string) (*AppUserTokenInfo, error)
+}
@@ -2,10 +2,28 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/kujilabo/redstart/user/domain"
 )
 
+// AuthRequest is a pair of an action and an object checked by AuthorizeBatch
+type AuthRequest struct {
+	Action domain.RBACAction
+	Object domain.RBACObject
+	// Attributes are referred by the conditions of the policies. The built-in attributes are added by AuthorizationManager
+	Attributes domain.RBACAttributes
+}
+
+type PolicyImportMode string
+
+const (
+	// PolicyImportModeMerge adds the imported rules and keeps the existing ones
+	PolicyImportModeMerge PolicyImportMode = "merge"
+	// PolicyImportModeReplace also removes the existing rules which are not imported
+	PolicyImportModeReplace PolicyImportMode = "replace"
+)
+
 type AuthorizationManager interface {
 	Init(ctx context.Context) error
 
@@ -13,11 +31,17 @@ type AuthorizationManager interface {
 
 	AddUserToGroupBySystemAdmin(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error
 
-	// RemoveUserFromGroup()
+	// AddUserToGroupWithValidity adds the app user to the user group only during the validity. The membership is removed by the grant expiry process after it ends
+	AddUserToGroupWithValidity(ctx context.Context, operator AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID, validity *domain.RBACValidity) error
 
-	// AddGroupToGroup(ctx context.Context, operator domain.AppUserModel, src domain.UserGroupID, dst domain.UserGroupID) error
+	RemoveUserFromGroup(ctx context.Context, operator AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error
 
-	// RemoveGroupFromGroup()
+	RemoveUserFromGroupBySystemAdmin(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error
+
+	// AddGroupToGroup makes the members of the child user group inherit the permissions of the parent user group
+	AddGroupToGroup(ctx context.Context, operator AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error
+
+	RemoveGroupFromGroup(ctx context.Context, operator AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error
 
 	// AddObjectToObject()
 
@@ -27,13 +51,52 @@ type AuthorizationManager interface {
 
 	AddPolicyToUserBySystemAdmin(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
 
+	// AddConditionalPolicy adds the policy of the user or the group which is applied only when the condition on the attributes of the request is satisfied.
+	// A policy has at most one condition, so the condition of the existing policy is replaced
+	AddConditionalPolicy(ctx context.Context, operator AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect, condition domain.RBACCondition) error
+
+	// AddTimeBoundPolicy adds the policy which is effective only during the validity. nil validity makes the policy permanent
+	AddTimeBoundPolicy(ctx context.Context, operator AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect, validity *domain.RBACValidity) error
+
 	AddPolicyToGroup(ctx context.Context, operator AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
 
 	AddPolicyToGroupBySystemAdmin(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
 
-	// AddPolicyToGroup()
+	// ExportPoliciesBySystemAdmin returns all the "p", "g" and "g2" rules of the organization
+	ExportPoliciesBySystemAdmin(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID) (*domain.RBACRuleSet, error)
+
+	// ImportPoliciesBySystemAdmin validates that the rules only refer to the app users and the user groups of the organization and applies them.
+	// Rules without the domain are imported into the organization. Nothing is changed when dryRun is true
+	ImportPoliciesBySystemAdmin(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID, ruleSet *domain.RBACRuleSet, mode PolicyImportMode, dryRun bool) (*domain.RBACRuleSetDiff, error)
 
-	// RemovePolicyToGroup()
+	// ListPoliciesForSubject returns the policies whose subject is the specified one in the organization of the operator.
+	// Policies inherited from groups are not included
+	ListPoliciesForSubject(ctx context.Context, operator AppUserInterface, subject domain.RBACSubject) ([]*domain.RBACPolicy, error)
+
+	ListPoliciesForObject(ctx context.Context, operator AppUserInterface, object domain.RBACObject) ([]*domain.RBACPolicy, error)
+
+	// ListExpiredGrants returns the grants of the organization of the operator which have expired since the time, the latest first
+	ListExpiredGrants(ctx context.Context, operator AppUserInterface, since time.Time, limit int) ([]*domain.RBACExpiredGrant, error)
+
+	RemovePolicyFromUser(ctx context.Context, operator AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
+
+	RemovePolicyFromGroup(ctx context.Context, operator AppUserInterface, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
 
 	Authorize(ctx context.Context, operator AppUserInterface, rbacAction domain.RBACAction, rbacObject domain.RBACObject) (bool, error)
+
+	// AuthorizeWithAttributes passes the attributes to the conditions of the policies
+	AuthorizeWithAttributes(ctx context.Context, operator AppUserInterface, rbacAction domain.RBACAction, rbacObject domain.RBACObject, attributes domain.RBACAttributes) (bool, error)
+
+	// AuthorizeBatch returns whether each of the requests is allowed. The groups and the policies of the operator are loaded once
+	AuthorizeBatch(ctx context.Context, operator AppUserInterface, requests []AuthRequest) ([]bool, error)
+
+	// FilterAuthorizedObjects returns the objects on which the operator is allowed to perform the action, keeping their order
+	FilterAuthorizedObjects(ctx context.Context, operator AppUserInterface, rbacAction domain.RBACAction, rbacObjects []domain.RBACObject) ([]domain.RBACObject, error)
+
+	// Explain returns the rules which decide whether the operator is allowed to perform the action on the object
+	Explain(ctx context.Context, operator AppUserInterface, rbacAction domain.RBACAction, rbacObject domain.RBACObject) (*domain.RBACExplanation, error)
+
+	// ListAccessibleObjects returns the objects starting with objectPrefix on which the operator is allowed to perform the action.
+	// The candidates are queried from the policies of the operator and its groups, so objects only matched by wildcard policies are returned as the wildcard patterns
+	ListAccessibleObjects(ctx context.Context, operator AppUserInterface, objectPrefix string, rbacAction domain.RBACAction) ([]domain.RBACObject, error)
 }
@@ -9,6 +9,8 @@ const (
 
 	SystemAdminLoginID = "__system_admin"
 	SystemOwnerLoginID = "__system_owner"
+	// RemovedLoginIDPrefix is the prefix of the login IDs of the removed app users, which cannot be used by the other app users
+	RemovedLoginIDPrefix = "__removed_"
 
 	SystemOwnerGroupKey = "__system_owner"
 	OwnerGroupKey       = "__owner"
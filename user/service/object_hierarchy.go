@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+var ErrObjectHierarchyCycle = errors.New("object hierarchy contains a cycle")
+var ErrObjectHierarchyTooDeep = errors.New("object hierarchy is too deep")
+var ErrPairOfObjectsNotFound = errors.New("pair of objects not found")
+
+// MaxObjectHierarchyDepth is the maximum number of the links from an object to its root.
+// casbin does not follow the links beyond its max hierarchy level
+const MaxObjectHierarchyDepth = 10
+
+// ObjectHierarchy manages the parent-child relations of the objects ("g2" rules) in the organization of the operator.
+// e.g. workspace -> folder -> document. The policies on an object are applied to all of its descendants
+type ObjectHierarchy interface {
+	// AddChildObject makes the child object belong to the parent object. The operator must be able to "set" both objects.
+	// It returns ErrObjectHierarchyCycle when the parent already belongs to the child directly or indirectly
+	AddChildObject(ctx context.Context, operator AppUserInterface, parent domain.RBACObject, child domain.RBACObject) error
+
+	// RemoveChildObject removes the child object from the parent object. The operator must be able to "unset" both objects
+	RemoveChildObject(ctx context.Context, operator AppUserInterface, parent domain.RBACObject, child domain.RBACObject) error
+
+	FindParentObjects(ctx context.Context, operator AppUserInterface, object domain.RBACObject) ([]domain.RBACObject, error)
+
+	FindChildObjects(ctx context.Context, operator AppUserInterface, object domain.RBACObject) ([]domain.RBACObject, error)
+
+	// FindAncestorObjects returns the ancestors of the object, nearest first
+	FindAncestorObjects(ctx context.Context, operator AppUserInterface, object domain.RBACObject) ([]domain.RBACObject, error)
+
+	// FindDescendantObjects returns the descendants of the object, nearest first
+	FindDescendantObjects(ctx context.Context, operator AppUserInterface, object domain.RBACObject) ([]domain.RBACObject, error)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+const operatorContextKey libdomain.ContextKey = "operator"
+
+// OperatorInterface is the app user performing the operation
+type OperatorInterface interface {
+	AppUserID() *domain.AppUserID
+}
+
+// WithOperator returns the context carrying the operator.
+// The audit columns of the records created or updated with the context are filled with the ID of the operator
+func WithOperator(ctx context.Context, operator OperatorInterface) context.Context {
+	ctx = context.WithValue(ctx, operatorContextKey, operator)
+	return libdomain.WithOperatorID(ctx, operator.AppUserID().Int())
+}
+
+func OperatorFromContext(ctx context.Context) (OperatorInterface, bool) {
+	operator, ok := ctx.Value(operatorContextKey).(OperatorInterface)
+	return operator, ok
+}
@@ -42,6 +42,29 @@ func (p *OrganizationAddParameter) FirstOwner() AppUserAddParameterInterface {
 	return p.FirstOwner_
 }
 
+type OrganizationUpdateParameterInterface interface {
+	Name() string
+}
+
+type OrganizationUpdateParameter struct {
+	Name_ string `validate:"required"`
+}
+
+func NewOrganizationUpdateParameter(name string) (*OrganizationUpdateParameter, error) {
+	m := &OrganizationUpdateParameter{
+		Name_: name,
+	}
+	if err := libdomain.Validator.Struct(m); err != nil {
+		return nil, liberrors.Errorf("libdomain.Validator.Struct. err: %w", err)
+	}
+
+	return m, nil
+}
+
+func (p *OrganizationUpdateParameter) Name() string {
+	return p.Name_
+}
+
 type OrganizationRepository interface {
 	GetOrganization(ctx context.Context, operator AppUserInterface) (*Organization, error)
 
@@ -51,6 +74,9 @@ type OrganizationRepository interface {
 
 	AddOrganization(ctx context.Context, operator SystemAdminInterface, param OrganizationAddParameterInterface) (*domain.OrganizationID, error)
 
+	// UpdateOrganization updates the organization whose version is the specified one. libdomain.ErrConcurrentModification is returned when it has been updated by others
+	UpdateOrganization(ctx context.Context, operator SystemAdminInterface, id *domain.OrganizationID, version int, param OrganizationUpdateParameterInterface) error
+
 	// FindOrganizationByName(ctx context.Context, operator SystemAdmin, name string) (Organization, error)
 	// FindOrganization(ctx context.Context, operator AppUser) (Organization, error)
 }
@@ -28,6 +28,11 @@ func NewOwner(rf RepositoryFactory, ownerModel *domain.OwnerModel) *Owner {
 	return m
 }
 
+// InTransaction returns the owner whose repositories are created by rf, so that the changes by the owner are written in the transaction of rf
+func (m *Owner) InTransaction(rf RepositoryFactory) *Owner {
+	return NewOwner(rf, m.OwnerModel)
+}
+
 func (m *Owner) AddAppUser(ctx context.Context, param AppUserAddParameterInterface) (*domain.AppUserID, error) {
 	appUserRepo := m.rf.NewAppUserRepository(ctx)
 	appUserID, err := appUserRepo.AddAppUser(ctx, m, param)
@@ -35,9 +40,83 @@ func (m *Owner) AddAppUser(ctx context.Context, param AppUserAddParameterInterfa
 		return nil, liberrors.Errorf("m.appUserRepo.AddAppUser. err: %w", err)
 	}
 
+	if err := addAuditEvent(ctx, m.rf.NewAuditLogRepository(ctx), m, m.OrganizationID(), domain.AuditActionAddAppUser, domain.AuditTargetTypeAppUser, appUserID.Int(), nil, &appUserAuditValue{
+		LoginID:  param.LoginID(),
+		Username: param.Username(),
+	}); err != nil {
+		return nil, err
+	}
+
 	return appUserID, nil
 }
 
+// UpdateAppUser updates the app user whose version is the specified one. The other owners cannot be updated
+func (m *Owner) UpdateAppUser(ctx context.Context, appUserID *domain.AppUserID, version int, param AppUserUpdateParameterInterface) error {
+	return updateAppUser(ctx, m.rf.NewAppUserRepository(ctx), m.rf.NewAuditLogRepository(ctx), m, appUserID, version, param)
+}
+
+// ChangePassword replaces the password of the app user. The passwords of the other owners cannot be changed
+func (m *Owner) ChangePassword(ctx context.Context, appUserID *domain.AppUserID, password string) error {
+	return changePassword(ctx, m.rf.NewAppUserRepository(ctx), m.rf.NewAuditLogRepository(ctx), m, appUserID, password)
+}
+
+// RemoveAppUser removes the app user whose version is the specified one. The owner cannot remove itself nor the other owners
+func (m *Owner) RemoveAppUser(ctx context.Context, appUserID *domain.AppUserID, version int) error {
+	return removeAppUser(ctx, m.rf.NewAppUserRepository(ctx), m.rf.NewAuditLogRepository(ctx), m, appUserID, version)
+}
+
+// RestoreAppUser restores the removed app user whose version is the specified one
+func (m *Owner) RestoreAppUser(ctx context.Context, appUserID *domain.AppUserID, version int) error {
+	return restoreAppUser(ctx, m.rf.NewAppUserRepository(ctx), m.rf.NewAuditLogRepository(ctx), m, appUserID, version)
+}
+
+func (m *Owner) AddUserGroup(ctx context.Context, param UserGroupAddParameterInterface) (*domain.UserGroupID, error) {
+	userGroupRepo := m.rf.NewUserGroupRepository(ctx)
+	userGroupID, err := userGroupRepo.AddUserGroup(ctx, m, param)
+	if err != nil {
+		return nil, liberrors.Errorf("userGroupRepo.AddUserGroup. err: %w", err)
+	}
+
+	if err := addAuditEvent(ctx, m.rf.NewAuditLogRepository(ctx), m, m.OrganizationID(), domain.AuditActionAddUserGroup, domain.AuditTargetTypeUserGroup, userGroupID.Int(), nil, &userGroupAuditValue{
+		Key:         param.Key(),
+		Name:        param.Name(),
+		Description: param.Description(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return userGroupID, nil
+}
+
+// UpdateUserGroup updates the user group whose version is the specified one
+func (m *Owner) UpdateUserGroup(ctx context.Context, userGroupID *domain.UserGroupID, version int, param UserGroupUpdateParameterInterface) error {
+	userGroupRepo := m.rf.NewUserGroupRepository(ctx)
+	userGroup, err := userGroupRepo.FindUserGroupByID(ctx, m, userGroupID)
+	if err != nil {
+		return liberrors.Errorf("userGroupRepo.FindUserGroupByID. err: %w", err)
+	}
+
+	if err := userGroupRepo.UpdateUserGroup(ctx, m, userGroupID, version, param); err != nil {
+		return liberrors.Errorf("userGroupRepo.UpdateUserGroup. err: %w", err)
+	}
+
+	if err := addAuditEvent(ctx, m.rf.NewAuditLogRepository(ctx), m, m.OrganizationID(), domain.AuditActionUpdateUserGroup, domain.AuditTargetTypeUserGroup, userGroupID.Int(), &userGroupAuditValue{
+		Key:         userGroup.Key(),
+		Name:        userGroup.Name(),
+		Description: userGroup.Description(),
+		Version:     version,
+	}, &userGroupAuditValue{
+		Key:         userGroup.Key(),
+		Name:        param.Name(),
+		Description: param.Description(),
+		Version:     version + 1,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *Owner) AppUserID() *domain.AppUserID {
 	return m.AppUserModel.AppUserID
 }
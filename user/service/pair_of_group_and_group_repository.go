@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kujilabo/redstart/user/domain"
+)
+
+var ErrPairOfGroupAndGroupNotFound = errors.New("pair of group and group not found")
+var ErrPairOfGroupAndGroupAlreadyExists = errors.New("pair of group and group already exists")
+var ErrUserGroupCycle = errors.New("user group hierarchy contains a cycle")
+
+type PairOfGroupAndGroupRepository interface {
+	// AddPairOfGroupAndGroup makes the child user group a member of the parent user group.
+	// It returns ErrUserGroupCycle when the parent already belongs to the child directly or indirectly
+	AddPairOfGroupAndGroup(ctx context.Context, operator AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error
+
+	RemovePairOfGroupAndGroup(ctx context.Context, operator AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error
+
+	FindParentUserGroups(ctx context.Context, operator AppUserInterface, userGroupID *domain.UserGroupID) ([]*domain.UserGroupModel, error)
+}
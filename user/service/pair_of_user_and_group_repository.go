@@ -2,16 +2,24 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/kujilabo/redstart/user/domain"
 )
 
+var ErrPairOfUserAndGroupNotFound = errors.New("pair of user and group not found")
+
 type PairOfUserAndGroupRepository interface {
 	AddPairOfUserAndGroupBySystemAdmin(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error
 
 	AddPairOfUserAndGroup(ctx context.Context, operator AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error
 
+	// SetPairOfUserAndGroupValidity sets the period in which the app user is a member of the user group. nil validity makes the membership permanent
+	SetPairOfUserAndGroupValidity(ctx context.Context, operator AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID, validity *domain.RBACValidity) error
+
 	RemovePairOfUserAndGroup(ctx context.Context, operator AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error
 
+	RemovePairOfUserAndGroupBySystemAdmin(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error
+
 	FindUserGroupsByUserID(ctx context.Context, operator AppUserInterface, appUserID *domain.AppUserID) ([]*domain.UserGroupModel, error)
 }
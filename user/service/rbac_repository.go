@@ -2,25 +2,46 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	"github.com/casbin/casbin/v2"
 
 	"github.com/kujilabo/redstart/user/domain"
 )
 
+var ErrRBACPolicyNotFound = errors.New("policy not found")
+
 type RBACRepository interface {
 	Init() error
 
 	AddPolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
+	// AddConditionalPolicy adds the policy applied only when the condition is satisfied. The condition of the existing policy is replaced
+	AddConditionalPolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect, condition domain.RBACCondition) error
 
 	AddSubjectGroupingPolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACUser, object domain.RBACRole) error
 	AddObjectGroupingPolicy(ctx context.Context, domain domain.RBACDomain, child domain.RBACObject, parent domain.RBACObject) error
 
+	// SetPolicyValidity sets the period in which the policy is effective. nil validity makes the policy permanent
+	SetPolicyValidity(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect, validity *domain.RBACValidity) error
+	SetSubjectGroupingPolicyValidity(ctx context.Context, domain domain.RBACDomain, subject domain.RBACUser, object domain.RBACRole, validity *domain.RBACValidity) error
+
+	// RemovePolicy removes the policy whatever its condition is
 	RemovePolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
-	// RemoveSubjectPolicy(domain domain.RBACDomain, subject domain.RBACSubject) error
+	// RemoveSubjectPolicy removes all the policies of the subject in the domain
+	RemoveSubjectPolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject) error
 
 	RemoveSubjectGroupingPolicy(ctx context.Context, domain domain.RBACDomain, subject domain.RBACUser, object domain.RBACRole) error
+	// RemoveSubjectGroupingPolicies removes all the roles of the subject in the domain
+	RemoveSubjectGroupingPolicies(ctx context.Context, domain domain.RBACDomain, subject domain.RBACUser) error
 	RemoveObjectGroupingPolicy(ctx context.Context, domain domain.RBACDomain, child domain.RBACObject, parent domain.RBACObject) error
 
-	NewEnforcerWithGroupsAndUsers(ctx context.Context, roles []domain.RBACRole, users []domain.RBACUser) (*casbin.Enforcer, error)
+	FindPolicies(ctx context.Context, domain domain.RBACDomain) ([]*domain.RBACPolicy, error)
+	FindPoliciesBySubject(ctx context.Context, domain domain.RBACDomain, subject domain.RBACSubject) ([]*domain.RBACPolicy, error)
+	FindPoliciesByObject(ctx context.Context, domain domain.RBACDomain, object domain.RBACObject) ([]*domain.RBACPolicy, error)
+	// FindGroupingRules returns the "g" and "g2" rules of the domain
+	FindGroupingRules(ctx context.Context, domain domain.RBACDomain) ([]*domain.RBACGroupingRule, error)
+
+	// NewEnforcerWithGroupsAndUsers returns the enforcer which has the rules of the roles and the users, and the "g2" rules of the domain.
+	// The rules out of their validity are not loaded
+	NewEnforcerWithGroupsAndUsers(ctx context.Context, domain domain.RBACDomain, roles []domain.RBACRole, users []domain.RBACUser) (*casbin.Enforcer, error)
 }
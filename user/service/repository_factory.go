@@ -12,4 +12,8 @@ type RepositoryFactory interface {
 	// NewRBACRepository(ctx context.Context) RBACRepository
 
 	NewAuthorizationManager(ctx context.Context) AuthorizationManager
+
+	NewObjectHierarchy(ctx context.Context) ObjectHierarchy
+
+	NewAuditLogRepository(ctx context.Context) AuditLogRepository
 }
@@ -20,9 +20,10 @@ type SystemAdminInterface interface {
 
 type SystemAdmin struct {
 	*domain.SystemAdminModel
-	rf          RepositoryFactory
-	orgRepo     OrganizationRepository
-	appUserRepo AppUserRepository
+	rf           RepositoryFactory
+	orgRepo      OrganizationRepository
+	appUserRepo  AppUserRepository
+	auditLogRepo AuditLogRepository
 }
 
 func NewSystemAdmin(ctx context.Context, rf RepositoryFactory) (*SystemAdmin, error) {
@@ -31,12 +32,14 @@ func NewSystemAdmin(ctx context.Context, rf RepositoryFactory) (*SystemAdmin, er
 	}
 	orgRepo := rf.NewOrganizationRepository(ctx)
 	appUserRepo := rf.NewAppUserRepository(ctx)
+	auditLogRepo := rf.NewAuditLogRepository(ctx)
 
 	m := &SystemAdmin{
 		SystemAdminModel: domain.NewSystemAdminModel(),
 		rf:               rf,
 		orgRepo:          orgRepo,
 		appUserRepo:      appUserRepo,
+		auditLogRepo:     auditLogRepo,
 	}
 
 	return m, nil
@@ -85,6 +88,12 @@ func (m *SystemAdmin) AddOrganization(ctx context.Context, param OrganizationAdd
 		return nil, liberrors.Errorf("failed to AddOrganization. error: %w", err)
 	}
 
+	if err := addAuditEvent(ctx, m.auditLogRepo, m, organizationID, domain.AuditActionAddOrganization, domain.AuditTargetTypeOrganization, organizationID.Int(), nil, &organizationAuditValue{
+		Name: param.Name(),
+	}); err != nil {
+		return nil, err
+	}
+
 	userGroupRepo := m.rf.NewUserGroupRepository(ctx)
 
 	// // add system-owner-group
@@ -175,6 +184,30 @@ func (m *SystemAdmin) AddOrganization(ctx context.Context, param OrganizationAdd
 	return organizationID, nil
 }
 
+// UpdateOrganization updates the organization whose version is the specified one
+func (m *SystemAdmin) UpdateOrganization(ctx context.Context, organizationID *domain.OrganizationID, version int, param OrganizationUpdateParameterInterface) error {
+	org, err := m.orgRepo.FindOrganizationByID(ctx, m, organizationID)
+	if err != nil {
+		return liberrors.Errorf("m.orgRepo.FindOrganizationByID. error: %w", err)
+	}
+
+	if err := m.orgRepo.UpdateOrganization(ctx, m, organizationID, version, param); err != nil {
+		return liberrors.Errorf("m.orgRepo.UpdateOrganization. error: %w", err)
+	}
+
+	if err := addAuditEvent(ctx, m.auditLogRepo, m, organizationID, domain.AuditActionUpdateOrganization, domain.AuditTargetTypeOrganization, organizationID.Int(), &organizationAuditValue{
+		Name:    org.Name(),
+		Version: version,
+	}, &organizationAuditValue{
+		Name:    param.Name(),
+		Version: version + 1,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func NewRBACOrganization(organizationID *domain.OrganizationID) domain.RBACDomain {
 	return domain.NewRBACDomain(fmt.Sprintf("domain:%d", organizationID.Int()))
 }
@@ -201,5 +234,5 @@ func NewRBACAllUserRolesObject(organizationID *domain.OrganizationID) domain.RBA
 var RBACSetAction = domain.NewRBACAction("Set")
 var RBACUnsetAction = domain.NewRBACAction("Unset")
 
-var RBACAllowEffect = domain.NewRBACEffect("allow")
-var RBACDenyEffect = domain.NewRBACEffect("deny")
+var RBACAllowEffect = domain.RBACAllowEffect
+var RBACDenyEffect = domain.RBACDenyEffect
@@ -26,6 +26,7 @@ type SystemOwner struct {
 	// pairOfUserAndGroup PairOfUserAndGroupRepository
 	// rbacRepo             RBACRepository
 	authorizationManager AuthorizationManager
+	auditLogRepo         AuditLogRepository
 }
 
 func NewSystemOwner(ctx context.Context, rf RepositoryFactory, systemOwnerModel *domain.SystemOwnerModel) (*SystemOwner, error) {
@@ -35,6 +36,7 @@ func NewSystemOwner(ctx context.Context, rf RepositoryFactory, systemOwnerModel
 	// pairOfUserAndGroup := rf.NewPairOfUserAndGroupRepository(ctx)
 	// rbacRepo := rf.NewRBACRepository(ctx)
 	authorizationManager := rf.NewAuthorizationManager(ctx)
+	auditLogRepo := rf.NewAuditLogRepository(ctx)
 
 	m := &SystemOwner{
 		SystemOwnerModel: systemOwnerModel,
@@ -44,6 +46,7 @@ func NewSystemOwner(ctx context.Context, rf RepositoryFactory, systemOwnerModel
 		// pairOfUserAndGroup:   pairOfUserAndGroup,
 		// rbacRepo:             rbacRepo,
 		authorizationManager: authorizationManager,
+		auditLogRepo:         auditLogRepo,
 	}
 
 	if err := libdomain.Validator.Struct(m); err != nil {
@@ -117,6 +120,13 @@ func (m *SystemOwner) AddFirstOwner(ctx context.Context, param AppUserAddParamet
 		return nil, liberrors.Errorf("failed to AddFirstOwner. error: %w", err)
 	}
 
+	if err := addAuditEvent(ctx, m.auditLogRepo, m, m.OrganizationID(), domain.AuditActionAddAppUser, domain.AuditTargetTypeAppUser, firstOwnerID.Int(), nil, &appUserAuditValue{
+		LoginID:  param.LoginID(),
+		Username: param.Username(),
+	}); err != nil {
+		return nil, err
+	}
+
 	ownerGroup, err := m.userGroupRepo.FindUserGroupByKey(ctx, m, OwnerGroupKey)
 	if err != nil {
 		return nil, err
@@ -155,5 +165,32 @@ func (m *SystemOwner) AddAppUser(ctx context.Context, param AppUserAddParameterI
 		return nil, liberrors.Errorf("m.appUserRepo.AddAppUser. err: %w", err)
 	}
 
+	if err := addAuditEvent(ctx, m.auditLogRepo, m, m.OrganizationID(), domain.AuditActionAddAppUser, domain.AuditTargetTypeAppUser, appUserID.Int(), nil, &appUserAuditValue{
+		LoginID:  param.LoginID(),
+		Username: param.Username(),
+	}); err != nil {
+		return nil, err
+	}
+
 	return appUserID, nil
 }
+
+// UpdateAppUser updates the app user whose version is the specified one
+func (m *SystemOwner) UpdateAppUser(ctx context.Context, appUserID *domain.AppUserID, version int, param AppUserUpdateParameterInterface) error {
+	return updateAppUser(ctx, m.appUserRepo, m.auditLogRepo, m, appUserID, version, param)
+}
+
+// ChangePassword replaces the password of the app user
+func (m *SystemOwner) ChangePassword(ctx context.Context, appUserID *domain.AppUserID, password string) error {
+	return changePassword(ctx, m.appUserRepo, m.auditLogRepo, m, appUserID, password)
+}
+
+// RemoveAppUser removes the app user whose version is the specified one, including the owners
+func (m *SystemOwner) RemoveAppUser(ctx context.Context, appUserID *domain.AppUserID, version int) error {
+	return removeAppUser(ctx, m.appUserRepo, m.auditLogRepo, m, appUserID, version)
+}
+
+// RestoreAppUser restores the removed app user whose version is the specified one
+func (m *SystemOwner) RestoreAppUser(ctx context.Context, appUserID *domain.AppUserID, version int) error {
+	return restoreAppUser(ctx, m.appUserRepo, m.auditLogRepo, m, appUserID, version)
+}
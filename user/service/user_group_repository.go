@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 
 	libdomain "github.com/kujilabo/redstart/lib/domain"
 	liberrors "github.com/kujilabo/redstart/lib/errors"
@@ -9,6 +10,8 @@ import (
 	"github.com/kujilabo/redstart/user/domain"
 )
 
+var ErrUserGroupNotFound = errors.New("user group not found")
+
 type UserGroupAddParameterInterface interface {
 	Key() string
 	Name() string
@@ -44,6 +47,35 @@ func (p *UserGroupAddParameter) Description() string {
 	return p.DescriptionInternal
 }
 
+type UserGroupUpdateParameterInterface interface {
+	Name() string
+	Description() string
+}
+
+type UserGroupUpdateParameter struct {
+	NameInternal        string `validate:"required"`
+	DescriptionInternal string
+}
+
+func NewUserGroupUpdateParameter(name, description string) (*UserGroupUpdateParameter, error) {
+	m := &UserGroupUpdateParameter{
+		NameInternal:        name,
+		DescriptionInternal: description,
+	}
+	if err := libdomain.Validator.Struct(m); err != nil {
+		return nil, liberrors.Errorf("libdomain.Validator.Struct. err: %w", err)
+	}
+
+	return m, nil
+}
+
+func (p *UserGroupUpdateParameter) Name() string {
+	return p.NameInternal
+}
+func (p *UserGroupUpdateParameter) Description() string {
+	return p.DescriptionInternal
+}
+
 type UserGroupRepository interface {
 	FindAllUserGroups(ctx context.Context, operator AppUserInterface) ([]*domain.UserGroupModel, error)
 
@@ -56,4 +88,7 @@ type UserGroupRepository interface {
 	AddSystemOwnerGroup(ctx context.Context, operator SystemAdminInterface, organizationID *domain.OrganizationID) (*domain.UserGroupID, error)
 
 	AddUserGroup(ctx context.Context, operator OwnerModelInterface, parameter UserGroupAddParameterInterface) (*domain.UserGroupID, error)
+
+	// UpdateUserGroup updates the user group whose version is the specified one. The key cannot be changed. libdomain.ErrConcurrentModification is returned when it has been updated by others
+	UpdateUserGroup(ctx context.Context, operator OwnerModelInterface, userGroupID *domain.UserGroupID, version int, parameter UserGroupUpdateParameterInterface) error
 }
@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type AppUserUsecase interface {
+	GetOrganization(ctx context.Context, operator service.AppUserInterface) (*service.Organization, error)
+
+	FindAppUserByID(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID) (*service.AppUser, error)
+
+	// FindAppUsers returns the page of the app users in the organization of the operator
+	FindAppUsers(ctx context.Context, operator service.OwnerModelInterface, condition *service.AppUserSearchCondition) (*service.AppUserSearchResult, error)
+
+	AddAppUser(ctx context.Context, operator service.OwnerModelInterface, param service.AppUserAddParameterInterface) (*domain.AppUserID, error)
+
+	// UpdateAppUser updates the app user whose version is the specified one and returns the updated one
+	UpdateAppUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, version int, param service.AppUserUpdateParameterInterface) (*service.AppUser, error)
+
+	ChangePassword(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, password string) error
+
+	// RemoveAppUser removes the app user whose version is the specified one
+	RemoveAppUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, version int) error
+
+	// RestoreAppUser restores the removed app user whose version is the specified one and returns the restored one
+	RestoreAppUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, version int) (*service.AppUser, error)
+}
+
+type appUserUsecase struct {
+	txManager    service.TransactionManager
+	nonTxManager service.TransactionManager
+}
+
+func NewAppUserUsecase(txManager, nonTxManager service.TransactionManager) AppUserUsecase {
+	return &appUserUsecase{
+		txManager:    txManager,
+		nonTxManager: nonTxManager,
+	}
+}
+
+func (u *appUserUsecase) GetOrganization(ctx context.Context, operator service.AppUserInterface) (*service.Organization, error) {
+	var organization *service.Organization
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		orgRepo := rf.NewOrganizationRepository(ctx)
+		tmpOrganization, err := orgRepo.GetOrganization(ctx, operator)
+		if err != nil {
+			return liberrors.Errorf("orgRepo.GetOrganization. err: %w", err)
+		}
+
+		organization = tmpOrganization
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+func (u *appUserUsecase) FindAppUserByID(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID) (*service.AppUser, error) {
+	var appUser *service.AppUser
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		tmpAppUser, err := appUserRepo.FindAppUserByID(ctx, operator, appUserID)
+		if err != nil {
+			return liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", err)
+		}
+
+		appUser = tmpAppUser
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return appUser, nil
+}
+
+func (u *appUserUsecase) FindAppUsers(ctx context.Context, operator service.OwnerModelInterface, condition *service.AppUserSearchCondition) (*service.AppUserSearchResult, error) {
+	var result *service.AppUserSearchResult
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		tmpResult, err := appUserRepo.FindAppUsers(ctx, operator, condition)
+		if err != nil {
+			return liberrors.Errorf("appUserRepo.FindAppUsers. err: %w", err)
+		}
+
+		result = tmpResult
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (u *appUserUsecase) AddAppUser(ctx context.Context, operator service.OwnerModelInterface, param service.AppUserAddParameterInterface) (*domain.AppUserID, error) {
+	var appUserID *domain.AppUserID
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		owner, err := toOwner(rf, operator)
+		if err != nil {
+			return err
+		}
+
+		tmpAppUserID, err := owner.AddAppUser(ctx, param)
+		if err != nil {
+			return liberrors.Errorf("owner.AddAppUser. err: %w", err)
+		}
+
+		appUserID = tmpAppUserID
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return appUserID, nil
+}
+
+func (u *appUserUsecase) UpdateAppUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, version int, param service.AppUserUpdateParameterInterface) (*service.AppUser, error) {
+	var appUser *service.AppUser
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		owner, err := toOwner(rf, operator)
+		if err != nil {
+			return err
+		}
+
+		if err := owner.UpdateAppUser(ctx, appUserID, version, param); err != nil {
+			return liberrors.Errorf("owner.UpdateAppUser. err: %w", err)
+		}
+
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		tmpAppUser, err := appUserRepo.FindAppUserByID(ctx, operator, appUserID)
+		if err != nil {
+			return liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", err)
+		}
+
+		appUser = tmpAppUser
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return appUser, nil
+}
+
+func (u *appUserUsecase) ChangePassword(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, password string) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		owner, err := toOwner(rf, operator)
+		if err != nil {
+			return err
+		}
+
+		if err := owner.ChangePassword(ctx, appUserID, password); err != nil {
+			return liberrors.Errorf("owner.ChangePassword. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (u *appUserUsecase) RemoveAppUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, version int) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		owner, err := toOwner(rf, operator)
+		if err != nil {
+			return err
+		}
+
+		if err := owner.RemoveAppUser(ctx, appUserID, version); err != nil {
+			return liberrors.Errorf("owner.RemoveAppUser. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (u *appUserUsecase) RestoreAppUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, version int) (*service.AppUser, error) {
+	var appUser *service.AppUser
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		owner, err := toOwner(rf, operator)
+		if err != nil {
+			return err
+		}
+
+		if err := owner.RestoreAppUser(ctx, appUserID, version); err != nil {
+			return liberrors.Errorf("owner.RestoreAppUser. err: %w", err)
+		}
+
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		tmpAppUser, err := appUserRepo.FindAppUserByID(ctx, operator, appUserID)
+		if err != nil {
+			return liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", err)
+		}
+
+		appUser = tmpAppUser
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return appUser, nil
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type AuditLogUsecase interface {
+	// FindAuditEvents returns the audit events of the organization of the operator, the latest first
+	FindAuditEvents(ctx context.Context, operator service.OwnerModelInterface, condition *service.AuditEventSearchCondition) ([]*domain.AuditEventModel, error)
+}
+
+type auditLogUsecase struct {
+	nonTxManager service.TransactionManager
+}
+
+func NewAuditLogUsecase(nonTxManager service.TransactionManager) AuditLogUsecase {
+	return &auditLogUsecase{
+		nonTxManager: nonTxManager,
+	}
+}
+
+func (u *auditLogUsecase) FindAuditEvents(ctx context.Context, operator service.OwnerModelInterface, condition *service.AuditEventSearchCondition) ([]*domain.AuditEventModel, error) {
+	var events []*domain.AuditEventModel
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		auditLogRepo := rf.NewAuditLogRepository(ctx)
+		tmpEvents, err := auditLogRepo.FindAuditEvents(ctx, operator, condition)
+		if err != nil {
+			return liberrors.Errorf("auditLogRepo.FindAuditEvents. err: %w", err)
+		}
+
+		events = tmpEvents
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type AuthenticationUsecase interface {
+	// Authenticate verifies the password and issues a token set.
+	// ErrUnauthenticated is returned whether the organization, the user or the password is wrong.
+	Authenticate(ctx context.Context, organizationName, loginID, password string) (*service.TokenSet, error)
+
+	RefreshAccessToken(ctx context.Context, refreshToken string) (string, error)
+
+	// GetOperator returns the app user of the access token. The app user is an Owner if it belongs to the owner group.
+	GetOperator(ctx context.Context, accessToken string) (service.AppUserInterface, error)
+}
+
+type authenticationUsecase struct {
+	nonTxManager     service.TransactionManager
+	authTokenManager service.AuthTokenManager
+}
+
+func NewAuthenticationUsecase(nonTxManager service.TransactionManager, authTokenManager service.AuthTokenManager) AuthenticationUsecase {
+	return &authenticationUsecase{
+		nonTxManager:     nonTxManager,
+		authTokenManager: authTokenManager,
+	}
+}
+
+func (u *authenticationUsecase) Authenticate(ctx context.Context, organizationName, loginID, password string) (*service.TokenSet, error) {
+	var appUser *service.AppUser
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		sysOwner, err := sysAd.FindSystemOwnerByOrganizationName(ctx, organizationName)
+		if err != nil {
+			return liberrors.Errorf("sysAd.FindSystemOwnerByOrganizationName. err: %w", toUnauthenticated(err, service.ErrSystemOwnerNotFound))
+		}
+
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		ok, err := appUserRepo.VerifyPassword(ctx, sysAd, sysOwner.OrganizationID(), loginID, password)
+		if err != nil {
+			return liberrors.Errorf("appUserRepo.VerifyPassword. err: %w", toUnauthenticated(err, service.ErrAppUserNotFound))
+		}
+		if !ok {
+			return liberrors.Errorf("invalid password. loginID: %s, err: %w", loginID, service.ErrUnauthenticated)
+		}
+
+		tmpAppUser, err := sysOwner.FindAppUserByLoginID(ctx, loginID)
+		if err != nil {
+			return liberrors.Errorf("sysOwner.FindAppUserByLoginID. err: %w", err)
+		}
+
+		appUser = tmpAppUser
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	tokenSet, err := u.authTokenManager.CreateTokenSet(ctx, appUser)
+	if err != nil {
+		return nil, liberrors.Errorf("u.authTokenManager.CreateTokenSet. err: %w", err)
+	}
+
+	return tokenSet, nil
+}
+
+func (u *authenticationUsecase) RefreshAccessToken(ctx context.Context, refreshToken string) (string, error) {
+	tokenInfo, err := u.authTokenManager.ParseRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return "", liberrors.Errorf("u.authTokenManager.ParseRefreshToken. err: %w", err)
+	}
+
+	// the app user may have been removed after the refresh token was issued
+	appUser, err := u.findAppUser(ctx, tokenInfo)
+	if err != nil {
+		return "", err
+	}
+
+	accessToken, err := u.authTokenManager.CreateAccessToken(ctx, appUser)
+	if err != nil {
+		return "", liberrors.Errorf("u.authTokenManager.CreateAccessToken. err: %w", err)
+	}
+
+	return accessToken, nil
+}
+
+func (u *authenticationUsecase) GetOperator(ctx context.Context, accessToken string) (service.AppUserInterface, error) {
+	tokenInfo, err := u.authTokenManager.ParseAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, liberrors.Errorf("u.authTokenManager.ParseAccessToken. err: %w", err)
+	}
+
+	return u.findAppUser(ctx, tokenInfo)
+}
+
+func (u *authenticationUsecase) findAppUser(ctx context.Context, tokenInfo *service.AppUserTokenInfo) (service.AppUserInterface, error) {
+	var operator service.AppUserInterface
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		sysOwner, err := sysAd.FindSystemOwnerByOrganizationID(ctx, tokenInfo.OrganizationID)
+		if err != nil {
+			return liberrors.Errorf("sysAd.FindSystemOwnerByOrganizationID. err: %w", toUnauthenticated(err, service.ErrSystemOwnerNotFound))
+		}
+
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		appUser, err := appUserRepo.FindAppUserByID(ctx, sysOwner, tokenInfo.AppUserID, service.IncludeGroups)
+		if err != nil {
+			return liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", toUnauthenticated(err, service.ErrAppUserNotFound))
+		}
+
+		for _, userGroup := range appUser.UserGroups {
+			if userGroup.Key == service.OwnerGroupKey {
+				ownerModel, err := domain.NewOwnerModel(appUser.AppUserModel)
+				if err != nil {
+					return liberrors.Errorf("domain.NewOwnerModel. err: %w", err)
+				}
+				operator = service.NewOwner(rf, ownerModel)
+				return nil
+			}
+		}
+
+		operator = appUser
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return operator, nil
+}
+
+// toUnauthenticated hides the reason of the authentication failure from the client
+func toUnauthenticated(err, target error) error {
+	if errors.Is(err, target) {
+		return liberrors.Errorf("%v: %w", err, service.ErrUnauthenticated)
+	}
+	return err
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+// authorizeDecisionCacheMaxEntries bounds the memory of the decision cache. The cache is cleared when it is full
+const authorizeDecisionCacheMaxEntries = 100000
+
+// AuthorizeRequest asks whether the app user of the organization is allowed to perform the action on the object
+type AuthorizeRequest struct {
+	OrganizationID *domain.OrganizationID
+	AppUserID      *domain.AppUserID
+	Action         domain.RBACAction
+	Object         domain.RBACObject
+	Attributes     domain.RBACAttributes
+}
+
+func (r *AuthorizeRequest) cacheKey() string {
+	return fmt.Sprintf("%d,%d,%s,%s,%s", r.OrganizationID.Int(), r.AppUserID.Int(), r.Action.Action(), r.Object.Object(), r.Attributes.GetCacheKey())
+}
+
+// AuthorizeUsecase answers the permission checks of the other services
+type AuthorizeUsecase interface {
+	Authorize(ctx context.Context, req *AuthorizeRequest) (bool, error)
+
+	// AuthorizeBatch returns whether each of the requests is allowed, keeping their order. The requests may be of different app users.
+	// The requests of the app users which are not found are denied instead of failing the whole batch
+	AuthorizeBatch(ctx context.Context, reqs []*AuthorizeRequest) ([]bool, error)
+}
+
+type authorizeDecision struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+type authorizeUsecase struct {
+	nonTxManager service.TransactionManager
+	cacheTTL     time.Duration
+	mu           sync.Mutex
+	decisions    map[string]authorizeDecision
+	now          func() time.Time
+}
+
+// NewAuthorizeUsecase returns the usecase which caches the decisions for cacheTTL. 0 disables the cache.
+// The cached decisions are not discarded when the policies are changed, so cacheTTL should be short
+func NewAuthorizeUsecase(nonTxManager service.TransactionManager, cacheTTL time.Duration) AuthorizeUsecase {
+	return &authorizeUsecase{
+		nonTxManager: nonTxManager,
+		cacheTTL:     cacheTTL,
+		decisions:    make(map[string]authorizeDecision),
+		now:          time.Now,
+	}
+}
+
+func (u *authorizeUsecase) Authorize(ctx context.Context, req *AuthorizeRequest) (bool, error) {
+	results, err := u.authorizeBatch(ctx, []*AuthorizeRequest{req}, false)
+	if err != nil {
+		return false, err
+	}
+
+	return results[0], nil
+}
+
+func (u *authorizeUsecase) AuthorizeBatch(ctx context.Context, reqs []*AuthorizeRequest) ([]bool, error) {
+	return u.authorizeBatch(ctx, reqs, true)
+}
+
+// authorizeBatch returns service.ErrAppUserNotFound when one of the app users is not found unless denyNotFoundAppUsers is true
+func (u *authorizeUsecase) authorizeBatch(ctx context.Context, reqs []*AuthorizeRequest, denyNotFoundAppUsers bool) ([]bool, error) {
+	results := make([]bool, len(reqs))
+
+	// the requests which are not cached are grouped by the app users
+	type operatorKey struct {
+		organizationID int
+		appUserID      int
+	}
+	pending := make(map[operatorKey][]int)
+	operatorKeys := make([]operatorKey, 0)
+	for i, req := range reqs {
+		if allowed, ok := u.getDecision(req.cacheKey()); ok {
+			results[i] = allowed
+			continue
+		}
+
+		key := operatorKey{organizationID: req.OrganizationID.Int(), appUserID: req.AppUserID.Int()}
+		if _, ok := pending[key]; !ok {
+			operatorKeys = append(operatorKeys, key)
+		}
+		pending[key] = append(pending[key], i)
+	}
+	if len(operatorKeys) == 0 {
+		return results, nil
+	}
+
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		for _, key := range operatorKeys {
+			indexes := pending[key]
+			first := reqs[indexes[0]]
+
+			sysOwner, err := sysAd.FindSystemOwnerByOrganizationID(ctx, first.OrganizationID)
+			if err != nil {
+				return liberrors.Errorf("sysAd.FindSystemOwnerByOrganizationID. err: %w", err)
+			}
+
+			appUser, err := sysOwner.FindAppUserByID(ctx, first.AppUserID)
+			if denyNotFoundAppUsers && errors.Is(err, service.ErrAppUserNotFound) {
+				// the results stay false. the denials are not cached because the app user may be added later
+				continue
+			} else if err != nil {
+				return liberrors.Errorf("sysOwner.FindAppUserByID. err: %w", err)
+			}
+
+			authRequests := make([]service.AuthRequest, len(indexes))
+			for j, i := range indexes {
+				authRequests[j] = service.AuthRequest{Action: reqs[i].Action, Object: reqs[i].Object, Attributes: reqs[i].Attributes}
+			}
+
+			allowed, err := authorizationManager.AuthorizeBatch(ctx, appUser, authRequests)
+			if err != nil {
+				return liberrors.Errorf("authorizationManager.AuthorizeBatch. err: %w", err)
+			}
+
+			for j, i := range indexes {
+				results[i] = allowed[j]
+				u.setDecision(reqs[i].cacheKey(), allowed[j])
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (u *authorizeUsecase) getDecision(key string) (bool, bool) {
+	if u.cacheTTL <= 0 {
+		return false, false
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	decision, ok := u.decisions[key]
+	if !ok {
+		return false, false
+	}
+	if !u.now().Before(decision.expiresAt) {
+		delete(u.decisions, key)
+		return false, false
+	}
+
+	return decision.allowed, true
+}
+
+func (u *authorizeUsecase) setDecision(key string, allowed bool) {
+	if u.cacheTTL <= 0 {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if len(u.decisions) >= authorizeDecisionCacheMaxEntries {
+		u.decisions = make(map[string]authorizeDecision)
+	}
+	u.decisions[key] = authorizeDecision{allowed: allowed, expiresAt: u.now().Add(u.cacheTTL)}
+}
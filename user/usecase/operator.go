@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	libdomain "github.com/kujilabo/redstart/lib/domain"
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+// toOwner returns the owner whose changes are written in the transaction of rf
+func toOwner(rf service.RepositoryFactory, operator service.OwnerModelInterface) (*service.Owner, error) {
+	owner, ok := operator.(*service.Owner)
+	if !ok || !owner.IsOwner() {
+		return nil, liberrors.Errorf("operator is not an owner. err: %w", libdomain.ErrPermissionDenied)
+	}
+
+	return owner.InTransaction(rf), nil
+}
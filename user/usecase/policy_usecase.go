@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type PolicyUsecase interface {
+	AddPolicyToUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
+
+	AddPolicyToGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
+
+	ListPoliciesOfUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID) ([]*domain.RBACPolicy, error)
+
+	ListPoliciesOfGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID) ([]*domain.RBACPolicy, error)
+
+	ListPoliciesForObject(ctx context.Context, operator service.OwnerModelInterface, object domain.RBACObject) ([]*domain.RBACPolicy, error)
+
+	RemovePolicyFromUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
+
+	RemovePolicyFromGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error
+}
+
+type policyUsecase struct {
+	txManager service.TransactionManager
+}
+
+func NewPolicyUsecase(txManager service.TransactionManager) PolicyUsecase {
+	return &policyUsecase{
+		txManager: txManager,
+	}
+}
+
+func (u *policyUsecase) AddPolicyToUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		if _, err := appUserRepo.FindAppUserByID(ctx, operator, appUserID); err != nil {
+			return liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		rbacAppUser := service.NewRBACAppUser(operator.OrganizationID(), appUserID)
+		if err := authorizationManager.AddPolicyToUser(ctx, operator, rbacAppUser, action, object, effect); err != nil {
+			return liberrors.Errorf("authorizationManager.AddPolicyToUser. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (u *policyUsecase) AddPolicyToGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		userGroupRepo := rf.NewUserGroupRepository(ctx)
+		if _, err := userGroupRepo.FindUserGroupByID(ctx, operator, userGroupID); err != nil {
+			return liberrors.Errorf("userGroupRepo.FindUserGroupByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		rbacUserRole := service.NewRBACUserRole(operator.OrganizationID(), userGroupID)
+		if err := authorizationManager.AddPolicyToGroup(ctx, operator, rbacUserRole, action, object, effect); err != nil {
+			return liberrors.Errorf("authorizationManager.AddPolicyToGroup. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (u *policyUsecase) ListPoliciesOfUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID) ([]*domain.RBACPolicy, error) {
+	var policies []*domain.RBACPolicy
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		if _, err := appUserRepo.FindAppUserByID(ctx, operator, appUserID); err != nil {
+			return liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		rbacAppUser := service.NewRBACAppUser(operator.OrganizationID(), appUserID)
+		tmpPolicies, err := authorizationManager.ListPoliciesForSubject(ctx, operator, rbacAppUser)
+		if err != nil {
+			return liberrors.Errorf("authorizationManager.ListPoliciesForSubject. err: %w", err)
+		}
+
+		policies = tmpPolicies
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (u *policyUsecase) ListPoliciesOfGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID) ([]*domain.RBACPolicy, error) {
+	var policies []*domain.RBACPolicy
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		userGroupRepo := rf.NewUserGroupRepository(ctx)
+		if _, err := userGroupRepo.FindUserGroupByID(ctx, operator, userGroupID); err != nil {
+			return liberrors.Errorf("userGroupRepo.FindUserGroupByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		rbacUserRole := service.NewRBACUserRole(operator.OrganizationID(), userGroupID)
+		tmpPolicies, err := authorizationManager.ListPoliciesForSubject(ctx, operator, rbacUserRole)
+		if err != nil {
+			return liberrors.Errorf("authorizationManager.ListPoliciesForSubject. err: %w", err)
+		}
+
+		policies = tmpPolicies
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (u *policyUsecase) ListPoliciesForObject(ctx context.Context, operator service.OwnerModelInterface, object domain.RBACObject) ([]*domain.RBACPolicy, error) {
+	var policies []*domain.RBACPolicy
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		tmpPolicies, err := authorizationManager.ListPoliciesForObject(ctx, operator, object)
+		if err != nil {
+			return liberrors.Errorf("authorizationManager.ListPoliciesForObject. err: %w", err)
+		}
+
+		policies = tmpPolicies
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (u *policyUsecase) RemovePolicyFromUser(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		if _, err := appUserRepo.FindAppUserByID(ctx, operator, appUserID); err != nil {
+			return liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		rbacAppUser := service.NewRBACAppUser(operator.OrganizationID(), appUserID)
+		if err := authorizationManager.RemovePolicyFromUser(ctx, operator, rbacAppUser, action, object, effect); err != nil {
+			return liberrors.Errorf("authorizationManager.RemovePolicyFromUser. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (u *policyUsecase) RemovePolicyFromGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, action domain.RBACAction, object domain.RBACObject, effect domain.RBACEffect) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		userGroupRepo := rf.NewUserGroupRepository(ctx)
+		if _, err := userGroupRepo.FindUserGroupByID(ctx, operator, userGroupID); err != nil {
+			return liberrors.Errorf("userGroupRepo.FindUserGroupByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		rbacUserRole := service.NewRBACUserRole(operator.OrganizationID(), userGroupID)
+		if err := authorizationManager.RemovePolicyFromGroup(ctx, operator, rbacUserRole, action, object, effect); err != nil {
+			return liberrors.Errorf("authorizationManager.RemovePolicyFromGroup. err: %w", err)
+		}
+
+		return nil
+	})
+}
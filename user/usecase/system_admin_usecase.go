@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"context"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type SystemAdminUsecase interface {
+	AddOrganization(ctx context.Context, param service.OrganizationAddParameterInterface) (*domain.OrganizationID, error)
+
+	FindOrganizationByID(ctx context.Context, organizationID *domain.OrganizationID) (*service.Organization, error)
+
+	// UpdateOrganization updates the organization whose version is the specified one and returns the updated one
+	UpdateOrganization(ctx context.Context, organizationID *domain.OrganizationID, version int, param service.OrganizationUpdateParameterInterface) (*service.Organization, error)
+
+	ListPolicies(ctx context.Context, organizationID *domain.OrganizationID) ([]*domain.RBACPolicy, error)
+
+	// ExplainAuthorization returns the rules which decide whether the app user is allowed to perform the action on the object
+	ExplainAuthorization(ctx context.Context, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, action domain.RBACAction, object domain.RBACObject) (*domain.RBACExplanation, error)
+
+	ExportPolicies(ctx context.Context, organizationID *domain.OrganizationID) (*domain.RBACRuleSet, error)
+
+	// ImportPolicies merges the rule set into the policies of the organization, or replaces them with it. Nothing is changed if dryRun is true
+	ImportPolicies(ctx context.Context, organizationID *domain.OrganizationID, ruleSet *domain.RBACRuleSet, mode service.PolicyImportMode, dryRun bool) (*domain.RBACRuleSetDiff, error)
+
+	// FindAuditEvents returns the audit events of the organization, the latest first
+	FindAuditEvents(ctx context.Context, organizationID *domain.OrganizationID, condition *service.AuditEventSearchCondition) ([]*domain.AuditEventModel, error)
+}
+
+type systemAdminUsecase struct {
+	txManager    service.TransactionManager
+	nonTxManager service.TransactionManager
+}
+
+func NewSystemAdminUsecase(txManager, nonTxManager service.TransactionManager) SystemAdminUsecase {
+	return &systemAdminUsecase{
+		txManager:    txManager,
+		nonTxManager: nonTxManager,
+	}
+}
+
+func (u *systemAdminUsecase) AddOrganization(ctx context.Context, param service.OrganizationAddParameterInterface) (*domain.OrganizationID, error) {
+	var organizationID *domain.OrganizationID
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		tmpOrganizationID, err := sysAd.AddOrganization(ctx, param)
+		if err != nil {
+			return liberrors.Errorf("sysAd.AddOrganization. err: %w", err)
+		}
+
+		organizationID = tmpOrganizationID
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return organizationID, nil
+}
+
+func (u *systemAdminUsecase) FindOrganizationByID(ctx context.Context, organizationID *domain.OrganizationID) (*service.Organization, error) {
+	var organization *service.Organization
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		orgRepo := rf.NewOrganizationRepository(ctx)
+		tmpOrganization, err := orgRepo.FindOrganizationByID(ctx, sysAd, organizationID)
+		if err != nil {
+			return liberrors.Errorf("orgRepo.FindOrganizationByID. err: %w", err)
+		}
+
+		organization = tmpOrganization
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+func (u *systemAdminUsecase) UpdateOrganization(ctx context.Context, organizationID *domain.OrganizationID, version int, param service.OrganizationUpdateParameterInterface) (*service.Organization, error) {
+	var organization *service.Organization
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		if err := sysAd.UpdateOrganization(ctx, organizationID, version, param); err != nil {
+			return liberrors.Errorf("sysAd.UpdateOrganization. err: %w", err)
+		}
+
+		orgRepo := rf.NewOrganizationRepository(ctx)
+		tmpOrganization, err := orgRepo.FindOrganizationByID(ctx, sysAd, organizationID)
+		if err != nil {
+			return liberrors.Errorf("orgRepo.FindOrganizationByID. err: %w", err)
+		}
+
+		organization = tmpOrganization
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+func (u *systemAdminUsecase) ListPolicies(ctx context.Context, organizationID *domain.OrganizationID) ([]*domain.RBACPolicy, error) {
+	var policies []*domain.RBACPolicy
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		orgRepo := rf.NewOrganizationRepository(ctx)
+		if _, err := orgRepo.FindOrganizationByID(ctx, sysAd, organizationID); err != nil {
+			return liberrors.Errorf("orgRepo.FindOrganizationByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		ruleSet, err := authorizationManager.ExportPoliciesBySystemAdmin(ctx, sysAd, organizationID)
+		if err != nil {
+			return liberrors.Errorf("authorizationManager.ExportPoliciesBySystemAdmin. err: %w", err)
+		}
+
+		policies = ruleSet.Policies
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+func (u *systemAdminUsecase) ExplainAuthorization(ctx context.Context, organizationID *domain.OrganizationID, appUserID *domain.AppUserID, action domain.RBACAction, object domain.RBACObject) (*domain.RBACExplanation, error) {
+	var explanation *domain.RBACExplanation
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		sysOwner, err := sysAd.FindSystemOwnerByOrganizationID(ctx, organizationID)
+		if err != nil {
+			return liberrors.Errorf("sysAd.FindSystemOwnerByOrganizationID. err: %w", err)
+		}
+
+		appUser, err := sysOwner.FindAppUserByID(ctx, appUserID)
+		if err != nil {
+			return liberrors.Errorf("sysOwner.FindAppUserByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		tmpExplanation, err := authorizationManager.Explain(ctx, appUser, action, object)
+		if err != nil {
+			return liberrors.Errorf("authorizationManager.Explain. err: %w", err)
+		}
+
+		explanation = tmpExplanation
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return explanation, nil
+}
+
+func (u *systemAdminUsecase) ExportPolicies(ctx context.Context, organizationID *domain.OrganizationID) (*domain.RBACRuleSet, error) {
+	var ruleSet *domain.RBACRuleSet
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		orgRepo := rf.NewOrganizationRepository(ctx)
+		if _, err := orgRepo.FindOrganizationByID(ctx, sysAd, organizationID); err != nil {
+			return liberrors.Errorf("orgRepo.FindOrganizationByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		tmpRuleSet, err := authorizationManager.ExportPoliciesBySystemAdmin(ctx, sysAd, organizationID)
+		if err != nil {
+			return liberrors.Errorf("authorizationManager.ExportPoliciesBySystemAdmin. err: %w", err)
+		}
+
+		ruleSet = tmpRuleSet
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return ruleSet, nil
+}
+
+func (u *systemAdminUsecase) ImportPolicies(ctx context.Context, organizationID *domain.OrganizationID, ruleSet *domain.RBACRuleSet, mode service.PolicyImportMode, dryRun bool) (*domain.RBACRuleSetDiff, error) {
+	var diff *domain.RBACRuleSetDiff
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		orgRepo := rf.NewOrganizationRepository(ctx)
+		if _, err := orgRepo.FindOrganizationByID(ctx, sysAd, organizationID); err != nil {
+			return liberrors.Errorf("orgRepo.FindOrganizationByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		tmpDiff, err := authorizationManager.ImportPoliciesBySystemAdmin(ctx, sysAd, organizationID, ruleSet, mode, dryRun)
+		if err != nil {
+			return liberrors.Errorf("authorizationManager.ImportPoliciesBySystemAdmin. err: %w", err)
+		}
+
+		diff = tmpDiff
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+func (u *systemAdminUsecase) FindAuditEvents(ctx context.Context, organizationID *domain.OrganizationID, condition *service.AuditEventSearchCondition) ([]*domain.AuditEventModel, error) {
+	var events []*domain.AuditEventModel
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		sysAd, err := service.NewSystemAdmin(ctx, rf)
+		if err != nil {
+			return liberrors.Errorf("service.NewSystemAdmin. err: %w", err)
+		}
+
+		orgRepo := rf.NewOrganizationRepository(ctx)
+		if _, err := orgRepo.FindOrganizationByID(ctx, sysAd, organizationID); err != nil {
+			return liberrors.Errorf("orgRepo.FindOrganizationByID. err: %w", err)
+		}
+
+		auditLogRepo := rf.NewAuditLogRepository(ctx)
+		tmpEvents, err := auditLogRepo.FindAuditEventsBySystemAdmin(ctx, sysAd, organizationID, condition)
+		if err != nil {
+			return liberrors.Errorf("auditLogRepo.FindAuditEventsBySystemAdmin. err: %w", err)
+		}
+
+		events = tmpEvents
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
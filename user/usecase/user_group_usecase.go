@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+
+	liberrors "github.com/kujilabo/redstart/lib/errors"
+	"github.com/kujilabo/redstart/user/domain"
+	"github.com/kujilabo/redstart/user/service"
+)
+
+type UserGroupUsecase interface {
+	FindAllUserGroups(ctx context.Context, operator service.AppUserInterface) ([]*domain.UserGroupModel, error)
+
+	FindUserGroupByID(ctx context.Context, operator service.AppUserInterface, userGroupID *domain.UserGroupID) (*service.UserGroup, error)
+
+	AddUserGroup(ctx context.Context, operator service.OwnerModelInterface, param service.UserGroupAddParameterInterface) (*domain.UserGroupID, error)
+
+	// UpdateUserGroup updates the user group whose version is the specified one and returns the updated one
+	UpdateUserGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, version int, param service.UserGroupUpdateParameterInterface) (*service.UserGroup, error)
+
+	AddUserToGroup(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error
+
+	RemoveUserFromGroup(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error
+
+	AddGroupToGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error
+
+	RemoveGroupFromGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error
+}
+
+type userGroupUsecase struct {
+	txManager    service.TransactionManager
+	nonTxManager service.TransactionManager
+}
+
+func NewUserGroupUsecase(txManager, nonTxManager service.TransactionManager) UserGroupUsecase {
+	return &userGroupUsecase{
+		txManager:    txManager,
+		nonTxManager: nonTxManager,
+	}
+}
+
+func (u *userGroupUsecase) FindAllUserGroups(ctx context.Context, operator service.AppUserInterface) ([]*domain.UserGroupModel, error) {
+	var userGroups []*domain.UserGroupModel
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		userGroupRepo := rf.NewUserGroupRepository(ctx)
+		tmpUserGroups, err := userGroupRepo.FindAllUserGroups(ctx, operator)
+		if err != nil {
+			return liberrors.Errorf("userGroupRepo.FindAllUserGroups. err: %w", err)
+		}
+
+		userGroups = tmpUserGroups
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return userGroups, nil
+}
+
+func (u *userGroupUsecase) FindUserGroupByID(ctx context.Context, operator service.AppUserInterface, userGroupID *domain.UserGroupID) (*service.UserGroup, error) {
+	var userGroup *service.UserGroup
+	if err := u.nonTxManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		userGroupRepo := rf.NewUserGroupRepository(ctx)
+		tmpUserGroup, err := userGroupRepo.FindUserGroupByID(ctx, operator, userGroupID)
+		if err != nil {
+			return liberrors.Errorf("userGroupRepo.FindUserGroupByID. err: %w", err)
+		}
+
+		userGroup = tmpUserGroup
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return userGroup, nil
+}
+
+func (u *userGroupUsecase) AddUserGroup(ctx context.Context, operator service.OwnerModelInterface, param service.UserGroupAddParameterInterface) (*domain.UserGroupID, error) {
+	var userGroupID *domain.UserGroupID
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		owner, err := toOwner(rf, operator)
+		if err != nil {
+			return err
+		}
+
+		tmpUserGroupID, err := owner.AddUserGroup(ctx, param)
+		if err != nil {
+			return liberrors.Errorf("owner.AddUserGroup. err: %w", err)
+		}
+
+		userGroupID = tmpUserGroupID
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return userGroupID, nil
+}
+
+func (u *userGroupUsecase) UpdateUserGroup(ctx context.Context, operator service.OwnerModelInterface, userGroupID *domain.UserGroupID, version int, param service.UserGroupUpdateParameterInterface) (*service.UserGroup, error) {
+	var userGroup *service.UserGroup
+	if err := u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		owner, err := toOwner(rf, operator)
+		if err != nil {
+			return err
+		}
+
+		if err := owner.UpdateUserGroup(ctx, userGroupID, version, param); err != nil {
+			return liberrors.Errorf("owner.UpdateUserGroup. err: %w", err)
+		}
+
+		userGroupRepo := rf.NewUserGroupRepository(ctx)
+		tmpUserGroup, err := userGroupRepo.FindUserGroupByID(ctx, operator, userGroupID)
+		if err != nil {
+			return liberrors.Errorf("userGroupRepo.FindUserGroupByID. err: %w", err)
+		}
+
+		userGroup = tmpUserGroup
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return userGroup, nil
+}
+
+func (u *userGroupUsecase) AddUserToGroup(ctx context.Context, operator service.OwnerModelInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		// the user and the group must belong to the organization of the operator
+		appUserRepo := rf.NewAppUserRepository(ctx)
+		if _, err := appUserRepo.FindAppUserByID(ctx, operator, appUserID); err != nil {
+			return liberrors.Errorf("appUserRepo.FindAppUserByID. err: %w", err)
+		}
+
+		userGroupRepo := rf.NewUserGroupRepository(ctx)
+		if _, err := userGroupRepo.FindUserGroupByID(ctx, operator, userGroupID); err != nil {
+			return liberrors.Errorf("userGroupRepo.FindUserGroupByID. err: %w", err)
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		if err := authorizationManager.AddUserToGroup(ctx, operator, appUserID, userGroupID); err != nil {
+			return liberrors.Errorf("authorizationManager.AddUserToGroup. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (u *userGroupUsecase) RemoveUserFromGroup(ctx context.Context, operator service.AppUserInterface, appUserID *domain.AppUserID, userGroupID *domain.UserGroupID) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		if err := authorizationManager.RemoveUserFromGroup(ctx, operator, appUserID, userGroupID); err != nil {
+			return liberrors.Errorf("authorizationManager.RemoveUserFromGroup. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (u *userGroupUsecase) AddGroupToGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		// both groups must belong to the organization of the operator
+		userGroupRepo := rf.NewUserGroupRepository(ctx)
+		for _, userGroupID := range []*domain.UserGroupID{childUserGroupID, parentUserGroupID} {
+			if _, err := userGroupRepo.FindUserGroupByID(ctx, operator, userGroupID); err != nil {
+				return liberrors.Errorf("userGroupRepo.FindUserGroupByID. err: %w", err)
+			}
+		}
+
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		if err := authorizationManager.AddGroupToGroup(ctx, operator, childUserGroupID, parentUserGroupID); err != nil {
+			return liberrors.Errorf("authorizationManager.AddGroupToGroup. err: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (u *userGroupUsecase) RemoveGroupFromGroup(ctx context.Context, operator service.AppUserInterface, childUserGroupID *domain.UserGroupID, parentUserGroupID *domain.UserGroupID) error {
+	return u.txManager.Do(ctx, func(rf service.RepositoryFactory) error {
+		authorizationManager := rf.NewAuthorizationManager(ctx)
+		if err := authorizationManager.RemoveGroupFromGroup(ctx, operator, childUserGroupID, parentUserGroupID); err != nil {
+			return liberrors.Errorf("authorizationManager.RemoveGroupFromGroup. err: %w", err)
+		}
+
+		return nil
+	})
+}